@@ -0,0 +1,252 @@
+package blockexplorer
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// Decimals of the base units of the chains served by the explorers. Tokens
+// use the decimals of their contract.
+const (
+	// DecimalsBTC is the precision of the satoshi, shared by DCR, LTC, DOGE
+	// and ZEC.
+	DecimalsBTC = 8
+	// DecimalsAPT is the precision of the octa.
+	DecimalsAPT = 8
+	// DecimalsXMR is the precision of the piconero.
+	DecimalsXMR = 12
+	// DecimalsETH is the precision of the wei.
+	DecimalsETH = 18
+)
+
+// Amount is an amount of a coin counted in the base units of its chain,
+// along with the number of decimals of these units. The units are a big
+// integer so the amounts of 18 decimals chains and tokens are neither rounded
+// nor overflowed. Amount marshals to a JSON string in coins. The zero value
+// is 0.
+type Amount struct {
+	units    *big.Int
+	decimals int
+}
+
+var bigTen = big.NewInt(10)
+
+func pow10(n int) *big.Int {
+	return new(big.Int).Exp(bigTen, big.NewInt(int64(n)), nil)
+}
+
+// NewAmount returns the amount of units base units of a chain or token with
+// the given number of decimals.
+func NewAmount(units *big.Int, decimals int) Amount {
+	if units == nil {
+		return Amount{decimals: decimals}
+	}
+	return Amount{units: new(big.Int).Set(units), decimals: decimals}
+}
+
+// AmountFromInt64 returns the amount of units base units, satoshis for
+// DecimalsBTC for example.
+func AmountFromInt64(units int64, decimals int) Amount {
+	return Amount{units: big.NewInt(units), decimals: decimals}
+}
+
+// ParseUnits parses an integer amount of base units, like the wei values
+// returned by the explorers as strings.
+func ParseUnits(s string, decimals int) (Amount, error) {
+	units, ok := new(big.Int).SetString(strings.TrimSpace(s), 10)
+	if !ok {
+		return Amount{}, fmt.Errorf("invalid amount units %q", s)
+	}
+	return Amount{units: units, decimals: decimals}, nil
+}
+
+// ParseAmount parses a decimal amount of coins like "0.00012" or "-3". It
+// fails if the amount has more digits than decimals.
+func ParseAmount(s string, decimals int) (Amount, error) {
+	units, exact, err := parseCoins(s, decimals)
+	if err != nil {
+		return Amount{}, err
+	}
+	if !exact {
+		return Amount{}, fmt.Errorf("amount %q has more than %d decimals", s, decimals)
+	}
+	return Amount{units: units, decimals: decimals}, nil
+}
+
+// AmountFromCoin converts an amount of coins to base units, rounded to the
+// nearest unit. AmountFromCoin errors if f is NaN or +-Infinity.
+func AmountFromCoin(f float64, decimals int) (Amount, error) {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return Amount{}, errors.New("invalid coin amount")
+	}
+	str := strconv.FormatFloat(f, 'f', -1, 64)
+	units, _, err := parseCoins(str, decimals+1)
+	if err != nil {
+		return Amount{}, err
+	}
+	// Round half away from zero with the extra digit.
+	rem := new(big.Int)
+	units.QuoRem(units, bigTen, rem)
+	if rem.CmpAbs(big.NewInt(5)) >= 0 {
+		units.Add(units, big.NewInt(int64(rem.Sign())))
+	}
+	return Amount{units: units, decimals: decimals}, nil
+}
+
+// parseCoins returns the base units of a decimal amount of coins, truncated to
+// decimals, and whether no digit was truncated.
+func parseCoins(s string, decimals int) (*big.Int, bool, error) {
+	str := strings.TrimSpace(s)
+	neg := strings.HasPrefix(str, "-")
+	if neg || strings.HasPrefix(str, "+") {
+		str = str[1:]
+	}
+	intPart, fracPart := str, ""
+	if i := strings.IndexByte(str, '.'); i >= 0 {
+		intPart, fracPart = str[:i], str[i+1:]
+	}
+	if intPart+fracPart == "" || strings.Trim(intPart+fracPart, "0123456789") != "" {
+		return nil, false, fmt.Errorf("invalid amount %q", s)
+	}
+	exact := true
+	if len(fracPart) > decimals {
+		exact = strings.Trim(fracPart[decimals:], "0") == ""
+		fracPart = fracPart[:decimals]
+	}
+	units, _ := new(big.Int).SetString(intPart+fracPart+strings.Repeat("0", decimals-len(fracPart)), 10)
+	if neg {
+		units.Neg(units)
+	}
+	return units, exact, nil
+}
+
+// Units returns the amount in base units.
+func (a Amount) Units() *big.Int {
+	if a.units == nil {
+		return new(big.Int)
+	}
+	return new(big.Int).Set(a.units)
+}
+
+// Decimals returns the number of decimals of the base units.
+func (a Amount) Decimals() int {
+	return a.decimals
+}
+
+// rescale returns the units of a counted with decimals, digits beyond
+// decimals are truncated.
+func (a Amount) rescale(decimals int) *big.Int {
+	units := a.Units()
+	if decimals >= a.decimals {
+		return units.Mul(units, pow10(decimals-a.decimals))
+	}
+	return units.Quo(units, pow10(a.decimals-decimals))
+}
+
+// Sign returns -1, 0 or 1 depending on the sign of the amount.
+func (a Amount) Sign() int {
+	if a.units == nil {
+		return 0
+	}
+	return a.units.Sign()
+}
+
+// IsZero reports whether the amount is 0.
+func (a Amount) IsZero() bool {
+	return a.Sign() == 0
+}
+
+// Cmp compares a and b and returns -1, 0 or 1. Amounts of different decimals
+// are compared by value.
+func (a Amount) Cmp(b Amount) int {
+	decimals := maxDecimals(a, b)
+	return a.rescale(decimals).Cmp(b.rescale(decimals))
+}
+
+// Add returns a+b, counted with the largest decimals of a and b.
+func (a Amount) Add(b Amount) Amount {
+	decimals := maxDecimals(a, b)
+	return Amount{units: new(big.Int).Add(a.rescale(decimals), b.rescale(decimals)), decimals: decimals}
+}
+
+// Sub returns a-b, counted with the largest decimals of a and b.
+func (a Amount) Sub(b Amount) Amount {
+	decimals := maxDecimals(a, b)
+	return Amount{units: new(big.Int).Sub(a.rescale(decimals), b.rescale(decimals)), decimals: decimals}
+}
+
+func maxDecimals(a, b Amount) int {
+	if a.decimals > b.decimals {
+		return a.decimals
+	}
+	return b.decimals
+}
+
+// ToCoin returns the nearest float64 amount of coins.
+func (a Amount) ToCoin() float64 {
+	f, _ := strconv.ParseFloat(a.String(), 64)
+	return f
+}
+
+// String returns the exact amount of coins, with all the decimals of the base
+// units.
+func (a Amount) String() string {
+	units := a.Units()
+	digits := new(big.Int).Abs(units).String()
+	if len(digits) <= a.decimals {
+		digits = strings.Repeat("0", a.decimals-len(digits)+1) + digits
+	}
+	var sign string
+	if units.Sign() < 0 {
+		sign = "-"
+	}
+	if a.decimals <= 0 {
+		return sign + digits
+	}
+	point := len(digits) - a.decimals
+	return sign + digits[:point] + "." + digits[point:]
+}
+
+// MarshalJSON implements json.Marshaler, the amount is encoded as a string of
+// coins.
+func (a Amount) MarshalJSON() ([]byte, error) {
+	return []byte(strconv.Quote(a.String())), nil
+}
+
+// UnmarshalJSON implements json.Unmarshaler. It accepts an amount of coins as
+// a JSON string or number, the decimals are the digits after the point, and
+// null.
+func (a *Amount) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if bytes.Equal(data, []byte("null")) {
+		*a = Amount{}
+		return nil
+	}
+	str := string(data)
+	if strings.HasPrefix(str, `"`) {
+		var err error
+		if str, err = strconv.Unquote(str); err != nil {
+			return err
+		}
+	}
+	str = strings.TrimSpace(str)
+	if str == "" {
+		*a = Amount{}
+		return nil
+	}
+	var decimals int
+	if i := strings.IndexByte(str, '.'); i >= 0 {
+		decimals = len(str) - i - 1
+	}
+	amount, err := ParseAmount(str, decimals)
+	if err != nil {
+		return err
+	}
+	*a = amount
+	return nil
+}
@@ -0,0 +1,79 @@
+package blockexplorer
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+)
+
+func TestAmount(t *testing.T) {
+	wei, _ := new(big.Int).SetString("123456789012345678901", 10)
+	tests := []struct {
+		amount Amount
+		str    string
+	}{
+		{AmountFromInt64(150000000, DecimalsBTC), "1.50000000"},
+		{AmountFromInt64(-1, DecimalsBTC), "-0.00000001"},
+		{AmountFromInt64(1234567890123, DecimalsXMR), "1.234567890123"},
+		{NewAmount(wei, DecimalsETH), "123.456789012345678901"},
+		{NewAmount(big.NewInt(1500000), 6), "1.500000"},
+		{Amount{}, "0"},
+	}
+	for _, test := range tests {
+		if test.amount.String() != test.str {
+			t.Errorf("got %s, expected %s", test.amount, test.str)
+		}
+	}
+
+	coins := []struct {
+		f        float64
+		decimals int
+		units    string
+	}{
+		{0.1, DecimalsBTC, "10000000"},
+		{1.23456789, DecimalsBTC, "123456789"},
+		{0.000000015, DecimalsBTC, "2"},
+		{-0.000000015, DecimalsBTC, "-2"},
+		{0.3, DecimalsETH, "300000000000000000"},
+		{2.5, DecimalsXMR, "2500000000000"},
+	}
+	for _, test := range coins {
+		a, err := AmountFromCoin(test.f, test.decimals)
+		if err != nil || a.Units().String() != test.units {
+			t.Errorf("AmountFromCoin(%v, %d) = %s, %v, expected %s units", test.f, test.decimals, a.Units(), err, test.units)
+		}
+	}
+
+	if _, err := ParseAmount("0.000000001", DecimalsBTC); err == nil {
+		t.Error("expected an error for the digits beyond the decimals")
+	}
+	if a, err := ParseUnits(wei.String(), DecimalsETH); err != nil || a.Cmp(NewAmount(wei, DecimalsETH)) != 0 {
+		t.Errorf("ParseUnits: %s, %v", a, err)
+	}
+
+	sats := AmountFromInt64(100000000, DecimalsBTC)
+	one, _ := ParseAmount("1", DecimalsETH)
+	if sats.Cmp(one) != 0 || sats.Sub(one).Sign() != 0 || sats.Add(one).String() != "2.000000000000000000" {
+		t.Errorf("amounts of different decimals: %s, %s", sats.Sub(one), sats.Add(one))
+	}
+}
+
+func TestAmountJSON(t *testing.T) {
+	wei, _ := new(big.Int).SetString("1000000000000000001", 10)
+	tx := ITransaction{
+		Outputs:       []IVOUT{{Value: NewAmount(wei, DecimalsETH)}},
+		OrderedAmount: AmountFromInt64(1, DecimalsBTC),
+	}
+	b, err := json.Marshal(tx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded ITransaction
+	if err := json.Unmarshal(b, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if decoded.Outputs[0].Value.String() != "1.000000000000000001" || decoded.OrderedAmount.String() != "0.00000001" ||
+		!decoded.MissingAmount.IsZero() {
+		t.Errorf("unexpected round trip %s", b)
+	}
+}
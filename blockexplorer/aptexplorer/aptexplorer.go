@@ -2,13 +2,13 @@ package aptexplorer
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"net/http"
 	"strings"
 
 	"github.com/crypto-power/instantswap/blockexplorer"
 	"github.com/crypto-power/instantswap/blockexplorer/global/clients/blockexplorerclient"
-	"github.com/crypto-power/instantswap/blockexplorer/global/interfaces/idaemon"
 )
 
 const (
@@ -17,7 +17,7 @@ const (
 )
 
 func init() {
-	blockexplorer.RegisterExplorer("APT", "", func(config blockexplorer.Config) (blockexplorer.IBlockExplorer, error) {
+	blockexplorer.RegisterExplorerCtx("APT", "", func(config blockexplorer.Config) (blockexplorer.IBlockExplorerCtx, error) {
 		return New(config), nil
 	})
 }
@@ -31,12 +31,14 @@ type aptExplorer struct {
 
 // New return an IBlockExplorer interface
 func New(config blockexplorer.Config) *aptExplorer {
-	client := blockexplorerclient.NewClient(API_BASE, LIBNAME, config.EnableOutput, nil)
+	client := blockexplorerclient.NewClient(config.BaseURLOrDefault(API_BASE), LIBNAME, config.EnableOutput, nil)
+	client.SetHTTPClient(config.HTTPClientOrDefault())
 	return &aptExplorer{client: client, conf: config}
 }
 
-func (a *aptExplorer) VerifyByAddress(req blockexplorer.AddressVerifyRequest) (vr *blockexplorer.VerifyResult, err error) {
-	txs, err := a.getTxsForAddress(req.Address, 25, "")
+func (a *aptExplorer) VerifyByAddress(ctx context.Context, req blockexplorer.AddressVerifyRequest) (vr *blockexplorer.VerifyResult, err error) {
+	orderedAmount := req.Amount
+	txs, err := a.getTxsForAddress(ctx, req.Address, 25, "")
 	if err != nil {
 		return nil, err
 	}
@@ -49,15 +51,13 @@ func (a *aptExplorer) VerifyByAddress(req blockexplorer.AddressVerifyRequest) (v
 					continue
 				}
 				if tArr[1] == "coin" && (tArr[2] == "WithdrawEvent" || tArr[2] == "DepositEvent") {
-					blockExplorerAmount := idaemon.Amount(event.Data.Amount)
-					if blockExplorerAmount.ToCoin() == req.Amount {
+					blockExplorerAmount := event.Data.amount()
+					if blockExplorerAmount.Cmp(orderedAmount) == 0 {
 						return &blockexplorer.VerifyResult{
 							Seen:                true,
 							Verified:            true,
-							OrderedAmount:       req.Amount,
-							BlockExplorerAmount: blockExplorerAmount.ToCoin(),
-							MissingAmount:       0,
-							MissingPercent:      0,
+							OrderedAmount:       orderedAmount,
+							BlockExplorerAmount: blockExplorerAmount,
 						}, nil
 					}
 				}
@@ -67,8 +67,8 @@ func (a *aptExplorer) VerifyByAddress(req blockexplorer.AddressVerifyRequest) (v
 	return nil, fmt.Errorf("tx not found")
 }
 
-func (a *aptExplorer) blockchainInfo() (*Blockchain, error) {
-	r, err := a.client.Do("GET", "", "", false)
+func (a *aptExplorer) blockchainInfo(ctx context.Context) (*Blockchain, error) {
+	r, err := a.client.Do(ctx, "GET", "", "", false)
 	if err != nil {
 		return nil, err
 	}
@@ -77,8 +77,8 @@ func (a *aptExplorer) blockchainInfo() (*Blockchain, error) {
 	return &b, err
 }
 
-func (a *aptExplorer) getTxByHash(hash string) (*Transaction, error) {
-	r, err := a.client.Do("GET", fmt.Sprintf("transactions/by_hash/%s", hash), "", false)
+func (a *aptExplorer) getTxByHash(ctx context.Context, hash string) (*Transaction, error) {
+	r, err := a.client.Do(ctx, "GET", fmt.Sprintf("transactions/by_hash/%s", hash), "", false)
 	if err != nil {
 		return nil, err
 	}
@@ -90,8 +90,8 @@ func (a *aptExplorer) getTxByHash(hash string) (*Transaction, error) {
 	return &aptTx, err
 }
 
-func (a *aptExplorer) getTxByVersion(version string) (*Transaction, error) {
-	r, err := a.client.Do("GET", fmt.Sprintf("transactions/by_version/%s", version), "", false)
+func (a *aptExplorer) getTxByVersion(ctx context.Context, version string) (*Transaction, error) {
+	r, err := a.client.Do(ctx, "GET", fmt.Sprintf("transactions/by_version/%s", version), "", false)
 	if err != nil {
 		return nil, err
 	}
@@ -103,63 +103,71 @@ func (a *aptExplorer) getTxByVersion(version string) (*Transaction, error) {
 	return &aptTx, err
 }
 
-func (a *aptExplorer) GetTransaction(txId string) (tx *blockexplorer.ITransaction, err error) {
-	aptTx, err := a.getTxByHash(txId)
+func (a *aptExplorer) GetTransaction(ctx context.Context, txId string) (tx *blockexplorer.ITransaction, err error) {
+	aptTx, err := a.getTxByHash(ctx, txId)
 	if err != nil {
 		return nil, err
 	}
-	var blockHeight, confirmations int
-	block, _ := a.getBlockByVersion(aptTx.Version)
-	if block != nil {
-		blockHeight = block.BlockHeight
-	}
-	blockchain, _ := a.blockchainInfo()
-	if blockchain != nil {
-		confirmations = blockchain.BlockHeight - blockHeight
+	blockHeight, best, err := a.txBlock(ctx, aptTx)
+	if err != nil {
+		return nil, err
 	}
+	confirmations := best.Confirmations(blockHeight)
 	vIns, vOuts := aptTx.getInOutPuts()
 	return &blockexplorer.ITransaction{
-		BlockHeight:         blockHeight,
-		DoubleSpend:         false,
-		Hash:                aptTx.Hash,
-		Inputs:              vIns,
-		LockTime:            0,
-		Outputs:             vOuts,
-		Rbf:                 false,
-		Size:                0,
-		Time:                0,
-		TxIndex:             0,
-		Version:             0,
-		VinSz:               0,
-		VoutSz:              0,
-		Weight:              0,
-		Confirmations:       confirmations,
-		Seen:                true,
-		Verified:            true,
-		OrderedAmount:       0,
-		BlockExplorerAmount: 0,
-		MissingAmount:       0,
-		MissingPercent:      0,
+		BlockHeight:   blockHeight,
+		DoubleSpend:   false,
+		Hash:          aptTx.Hash,
+		Inputs:        vIns,
+		LockTime:      0,
+		Outputs:       vOuts,
+		Rbf:           false,
+		Size:          0,
+		Time:          0,
+		TxIndex:       0,
+		Version:       0,
+		VinSz:         0,
+		VoutSz:        0,
+		Weight:        0,
+		Confirmations: confirmations,
+		Seen:          true,
+		Verified:      true,
 	}, err
 }
 
-func (a *aptExplorer) GetTxsForAddress(address string, limit int, viewKey string) (tx *blockexplorer.IRawAddrResponse, err error) {
+// txBlock returns the height of the block of aptTx, 0 for a pending
+// transaction, and the best block to count its confirmations.
+func (a *aptExplorer) txBlock(ctx context.Context, aptTx *Transaction) (height int, best *blockexplorer.BestBlock, err error) {
+	if aptTx.Version != "" {
+		block, err := a.getBlockByVersion(ctx, aptTx.Version)
+		if err != nil {
+			return 0, nil, err
+		}
+		height = block.BlockHeight
+	}
+	best, err = a.GetBestBlock(ctx)
+	if err != nil {
+		return 0, nil, err
+	}
+	return height, best, nil
+}
+
+func (a *aptExplorer) GetTxsForAddress(ctx context.Context, address string, limit int, viewKey string) (tx *blockexplorer.IRawAddrResponse, err error) {
 	//r, err := e.client.Do("GET", fmt.Sprintf("accounts/%s/resources", address), "", false)
 	return nil, fmt.Errorf("%s:not supported", LIBNAME)
 }
 
-func (a *aptExplorer) getTxsForAddress(address string, limit int, viewKey string) ([]*Transaction, error) {
+func (a *aptExplorer) getTxsForAddress(ctx context.Context, address string, limit int, viewKey string) ([]*Transaction, error) {
 	query := fmt.Sprintf(`{
 	"operationName":"AccountTransactionsData",
 	"variables":{"address":"%s","limit":%d,"offset":0},
 	"query":"query AccountTransactionsData($address: String, $limit: Int, $offset: Int) {\n  address_version_from_move_resources(\n    where: {address: {_eq: $address}}\n    order_by: {transaction_version: desc}\n    limit: $limit\n    offset: $offset\n  ) {\n    transaction_version\n    __typename\n  }\n}"}`,
 		address, limit)
-	r, err := http.NewRequest("POST", "https://indexer.mainnet.aptoslabs.com/v1/graphql", bytes.NewBuffer([]byte(query)))
+	r, err := http.NewRequestWithContext(ctx, "POST", "https://indexer.mainnet.aptoslabs.com/v1/graphql", bytes.NewBuffer([]byte(query)))
 	if err != nil {
 		return nil, err
 	}
-	client := &http.Client{}
-	res, err := client.Do(r)
+	res, err := a.conf.HTTPClientOrDefault().Do(r)
 	if err != nil {
 		return nil, err
 	}
@@ -172,7 +180,7 @@ func (a *aptExplorer) getTxsForAddress(address string, limit int, viewKey string
 	}
 	var txs []*Transaction
 	for _, txVer := range obj.AddressVersionFromMoveResources {
-		aptTx, err := a.getTxByVersion(fmt.Sprintf("%d", txVer.TransactionVersion))
+		aptTx, err := a.getTxByVersion(ctx, fmt.Sprintf("%d", txVer.TransactionVersion))
 		if err == nil {
 			txs = append(txs, aptTx)
 		}
@@ -180,19 +188,19 @@ func (a *aptExplorer) getTxsForAddress(address string, limit int, viewKey string
 	return txs, nil
 }
 
-func (a *aptExplorer) VerifyTransaction(verifier blockexplorer.TxVerifyRequest) (tx *blockexplorer.ITransaction, err error) {
+func (a *aptExplorer) VerifyTransaction(ctx context.Context, verifier blockexplorer.TxVerifyRequest) (tx *blockexplorer.ITransaction, err error) {
 	tx = &blockexplorer.ITransaction{}
-	aptTx, err := a.getTxByHash(verifier.TxId)
+	aptTx, err := a.getTxByHash(ctx, verifier.TxId)
 	if err != nil {
 		return nil, err
 	}
 	tx.Hash = aptTx.Hash
-	block, _ := a.getBlockByVersion(aptTx.Version)
-	tx.BlockHeight = block.BlockHeight
-	blockchain, _ := a.blockchainInfo()
-	if blockchain != nil {
-		tx.Confirmations = blockchain.BlockHeight - tx.BlockHeight
+	var best *blockexplorer.BestBlock
+	tx.BlockHeight, best, err = a.txBlock(ctx, aptTx)
+	if err != nil {
+		return nil, err
 	}
+	tx.SetConfirmations(*best)
 	for _, event := range aptTx.Events {
 		if event.Guid.AccountAddress == verifier.Address {
 			tx.Seen = true
@@ -202,10 +210,10 @@ func (a *aptExplorer) VerifyTransaction(verifier blockexplorer.TxVerifyRequest)
 				continue
 			}
 			if tArr[1] == "coin" && (tArr[2] == "WithdrawEvent" || tArr[2] == "DepositEvent") {
-				tx.BlockExplorerAmount = idaemon.Amount(event.Data.Amount)
-				coinAmount := tx.BlockExplorerAmount.ToCoin()
-				tx.MissingAmount, _ = idaemon.NewAmount(verifier.Amount - coinAmount)
-				tx.MissingPercent = 100 * (verifier.Amount - coinAmount) / verifier.Amount
+				tx.OrderedAmount = verifier.Amount
+				tx.BlockExplorerAmount = event.Data.amount()
+				tx.MissingAmount = tx.OrderedAmount.Sub(tx.BlockExplorerAmount)
+				tx.MissingPercent = 100 * tx.MissingAmount.ToCoin() / tx.OrderedAmount.ToCoin()
 				break
 			}
 		}
@@ -213,12 +221,39 @@ func (a *aptExplorer) VerifyTransaction(verifier blockexplorer.TxVerifyRequest)
 	return
 }
 
-func (a *aptExplorer) PushTx(rawTxHash string) (result string, err error) {
+func (a *aptExplorer) PushTx(ctx context.Context, rawTxHash string) (result string, err error) {
 	return "", fmt.Errorf("%s:not supported", LIBNAME)
 }
 
-func (a *aptExplorer) getBlockByVersion(version string) (*BlockInfo, error) {
-	r, err := a.client.Do("GET", fmt.Sprintf("blocks/by_version/%s", version), "", false)
+// GetBestBlock returns the block at the height of the ledger.
+func (a *aptExplorer) GetBestBlock(ctx context.Context) (*blockexplorer.BestBlock, error) {
+	blockchain, err := a.blockchainInfo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	block, err := a.getBlockByHeight(ctx, blockchain.BlockHeight)
+	if err != nil {
+		return nil, err
+	}
+	return &blockexplorer.BestBlock{
+		Height: block.BlockHeight,
+		Hash:   block.BlockHash,
+		Time:   block.BlockTimestamp / 1e6, // microseconds
+	}, nil
+}
+
+func (a *aptExplorer) getBlockByHeight(ctx context.Context, height int) (*BlockInfo, error) {
+	r, err := a.client.Do(ctx, "GET", fmt.Sprintf("blocks/by_height/%d", height), "", false)
+	if err != nil {
+		return nil, err
+	}
+	var b BlockInfo
+	err = parseResponseData(r, &b)
+	return &b, err
+}
+
+func (a *aptExplorer) getBlockByVersion(ctx context.Context, version string) (*BlockInfo, error) {
+	r, err := a.client.Do(ctx, "GET", fmt.Sprintf("blocks/by_version/%s", version), "", false)
 	if err != nil {
 		return nil, err
 	}
@@ -7,7 +7,6 @@ import (
 	"strings"
 
 	"github.com/crypto-power/instantswap/blockexplorer"
-	"github.com/crypto-power/instantswap/blockexplorer/global/interfaces/idaemon"
 )
 
 func parseResponseData(r []byte, obj interface{}) error {
@@ -93,11 +92,18 @@ type TxEvent struct {
 		CreationNumber string `json:"creation_number"`
 		AccountAddress string `json:"account_address"`
 	} `json:"guid"`
-	SequenceNumber string `json:"sequence_number"`
-	Type           string `json:"type"`
-	Data           struct {
-		Amount int `json:"amount,string"`
-	} `json:"data"`
+	SequenceNumber string    `json:"sequence_number"`
+	Type           string    `json:"type"`
+	Data           EventData `json:"data"`
+}
+
+type EventData struct {
+	Amount int64 `json:"amount,string"`
+}
+
+// amount returns the amount of a coin event, in octas.
+func (d EventData) amount() blockexplorer.Amount {
+	return blockexplorer.AmountFromInt64(d.Amount, blockexplorer.DecimalsAPT)
 }
 
 type TxPayload struct {
@@ -137,7 +143,7 @@ func (tx *Transaction) getInOutPuts() (vIns []blockexplorer.IVIN, vOuts []blocke
 				TxID:        "",
 				VOUT:        0,
 				Tree:        0,
-				AmountIn:    idaemon.Amount(event.Data.Amount),
+				AmountIn:    event.Data.amount(),
 				BlockIndex:  0,
 				BlockHeight: 0,
 			})
@@ -154,7 +160,7 @@ func (tx *Transaction) getInOutPuts() (vIns []blockexplorer.IVIN, vOuts []blocke
 				Spent:       false,
 				TxIndex:     0,
 				Type:        "",
-				Value:       idaemon.Amount(event.Data.Amount),
+				Value:       event.Data.amount(),
 			})
 		}
 	}
@@ -1,12 +1,14 @@
 package blockchair
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/crypto-power/instantswap/blockexplorer"
 	"github.com/crypto-power/instantswap/blockexplorer/global/clients/blockexplorerclient"
-	"github.com/crypto-power/instantswap/blockexplorer/global/interfaces/idaemon"
 )
 
 const (
@@ -15,15 +17,16 @@ const (
 )
 
 func init() {
-	blockexplorer.RegisterExplorer("ZEC", "", func(conf blockexplorer.Config) (blockexplorer.IBlockExplorer, error) {
+	blockexplorer.RegisterExplorerCtx("ZEC", "", func(conf blockexplorer.Config) (blockexplorer.IBlockExplorerCtx, error) {
 		return New("zec", "zcash", conf), nil
 	})
 }
 
 // New return a ClockChair client
 func New(coinName, network string, conf blockexplorer.Config) *BlockChair {
-	apiBase := fmt.Sprintf("%s/%s/dashboards/", API_BASE, network)
+	apiBase := fmt.Sprintf("%s/%s/dashboards/", strings.TrimSuffix(conf.BaseURLOrDefault(API_BASE), "/"), network)
 	client := blockexplorerclient.NewClient(apiBase, LIBNAME, conf.EnableOutput, nil)
+	client.SetHTTPClient(conf.HTTPClientOrDefault())
 	return &BlockChair{
 		client:   client,
 		coinName: coinName,
@@ -41,17 +44,17 @@ type BlockChair struct {
 	network   string
 }
 
-func (b *BlockChair) VerifyByAddress(req blockexplorer.AddressVerifyRequest) (vr *blockexplorer.VerifyResult, err error) {
+func (b *BlockChair) VerifyByAddress(ctx context.Context, req blockexplorer.AddressVerifyRequest) (vr *blockexplorer.VerifyResult, err error) {
 	return nil, fmt.Errorf("not supported yet")
 }
 
-func (b *BlockChair) getTx(txid string) (*TxWrapper, *Context, error) {
-	r, err := b.client.Do("GET", fmt.Sprintf("transaction/%s", txid), "", false)
+func (b *BlockChair) getTx(ctx context.Context, txid string) (*TxWrapper, *Context, error) {
+	r, err := b.client.Do(ctx, "GET", fmt.Sprintf("transaction/%s", txid), "", false)
 	if err != nil {
 		return nil, nil, err
 	}
 	var txWrapperMap map[string]TxWrapper
-	ctx, err := parseData(r, &txWrapperMap)
+	info, err := parseData(r, &txWrapperMap)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -59,27 +62,27 @@ func (b *BlockChair) getTx(txid string) (*TxWrapper, *Context, error) {
 		return nil, nil, fmt.Errorf("not found")
 	}
 	if txWrapper, ok := txWrapperMap[txid]; ok {
-		return &txWrapper, ctx, nil
+		return &txWrapper, info, nil
 	}
 	return nil, nil, fmt.Errorf("not found")
 }
 
-func (b *BlockChair) GetTransaction(txid string) (tx *blockexplorer.ITransaction, err error) {
-	txW, ctx, err := b.getTx(txid)
+func (b *BlockChair) GetTransaction(ctx context.Context, txid string) (tx *blockexplorer.ITransaction, err error) {
+	txW, info, err := b.getTx(ctx, txid)
 	if err != nil {
 		return nil, err
 	}
-	return b.generalTx(txW, ctx)
+	return b.generalTx(txW, info)
 }
 
-func (b *BlockChair) GetTxsForAddress(address string, limit int, viewKey string) (txs *blockexplorer.IRawAddrResponse, err error) {
-	r, err := b.client.Do("GET", fmt.Sprintf("address/%s?transaction_details=true&omni=true", address), "", false)
+func (b *BlockChair) GetTxsForAddress(ctx context.Context, address string, limit int, viewKey string) (txs *blockexplorer.IRawAddrResponse, err error) {
+	r, err := b.client.Do(ctx, "GET", fmt.Sprintf("address/%s?transaction_details=true&omni=true", address), "", false)
 	fmt.Println(string(r))
 	if err != nil {
 		return nil, err
 	}
 	var addrWrapperMap map[string]AddrWrapper
-	ctx, err := parseData(r, &addrWrapperMap)
+	info, err := parseData(r, &addrWrapperMap)
 	if err != nil {
 		return nil, err
 	}
@@ -87,38 +90,56 @@ func (b *BlockChair) GetTxsForAddress(address string, limit int, viewKey string)
 		return nil, fmt.Errorf("not found")
 	}
 	if addrWrapper, ok := addrWrapperMap[address]; ok {
-		return b.generalAddr(address, &addrWrapper, ctx), nil
+		return b.generalAddr(address, &addrWrapper, info), nil
 	}
 	return nil, fmt.Errorf("not found")
 }
 
-func (b *BlockChair) PushTx(txhash string) (res string, err error) {
+func (b *BlockChair) PushTx(ctx context.Context, txhash string) (res string, err error) {
 	return "", fmt.Errorf("does not support PushTx")
 }
 
-func (b *BlockChair) VerifyTransaction(verifier blockexplorer.TxVerifyRequest) (tx *blockexplorer.ITransaction, err error) {
-	txW, ctx, err := b.getTx(verifier.TxId)
+func (b *BlockChair) VerifyTransaction(ctx context.Context, verifier blockexplorer.TxVerifyRequest) (tx *blockexplorer.ITransaction, err error) {
+	txW, info, err := b.getTx(ctx, verifier.TxId)
 	if err != nil {
 		return nil, err
 	}
-	tx, err = b.generalTx(txW, ctx)
+	tx, err = b.generalTx(txW, info)
 	if err != nil {
 		return nil, err
 	}
-	ordered, _ := idaemon.NewAmount(verifier.Amount)
-	tx.OrderedAmount = ordered
+	tx.OrderedAmount = verifier.Amount
 	for _, out := range tx.Outputs {
 		if out.Addresses[0] == verifier.Address {
 			tx.Seen = true
 			tx.Verified = true
 			tx.BlockExplorerAmount = out.Value
-			tx.MissingAmount = tx.OrderedAmount - tx.BlockExplorerAmount
+			tx.MissingAmount = tx.OrderedAmount.Sub(tx.BlockExplorerAmount)
 			tx.MissingPercent = tx.MissingAmount.ToCoin() / tx.OrderedAmount.ToCoin() * 100
 		}
 	}
 	return tx, nil
 }
 
+// GetBestBlock returns the tip of the chain from the stats of the network.
+func (b *BlockChair) GetBestBlock(ctx context.Context) (*blockexplorer.BestBlock, error) {
+	statsURL := fmt.Sprintf("%s/%s/stats", strings.TrimSuffix(b.conf.BaseURLOrDefault(API_BASE), "/"), b.network)
+	r, err := b.client.Do(ctx, "GET", statsURL, "", false)
+	if err != nil {
+		return nil, err
+	}
+	var stats Stats
+	if _, err = parseData(r, &stats); err != nil {
+		return nil, err
+	}
+	t, _ := time.Parse(timeFormat, stats.BestBlockTime)
+	return &blockexplorer.BestBlock{
+		Height: stats.BestBlockHeight,
+		Hash:   stats.BestBlockHash,
+		Time:   int(t.Unix()),
+	}, nil
+}
+
 func parseData(data []byte, destination interface{}) (*Context, error) {
 	var res jsonResponse
 	if err := json.Unmarshal(data, &res); err != nil {
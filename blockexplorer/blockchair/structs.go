@@ -5,7 +5,6 @@ import (
 	"time"
 
 	"github.com/crypto-power/instantswap/blockexplorer"
-	"github.com/crypto-power/instantswap/blockexplorer/global/interfaces/idaemon"
 )
 
 const timeFormat = "2006-01-02 15:04:05"
@@ -30,6 +29,18 @@ type Context struct {
 	RequestCost    int     `json:"request_cost"`
 }
 
+// bestBlock returns the tip of the chain when the response was served, the
+// state of the context is its height.
+func (c *Context) bestBlock() blockexplorer.BestBlock {
+	return blockexplorer.BestBlock{Height: c.State}
+}
+
+type Stats struct {
+	BestBlockHeight int    `json:"best_block_height"`
+	BestBlockHash   string `json:"best_block_hash"`
+	BestBlockTime   string `json:"best_block_time"`
+}
+
 type BCApi struct {
 	Version         string      `json:"version"`
 	LastMajorUpdate string      `json:"last_major_update"`
@@ -156,7 +167,7 @@ func (b *BlockChair) generalTx(txW *TxWrapper, ctx *Context) (tx *blockexplorer.
 		VinSz:         0,
 		VoutSz:        0,
 		Weight:        0,
-		Confirmations: ctx.State - txW.Transaction.BlockId + 1,
+		Confirmations: ctx.bestBlock().Confirmations(txW.Transaction.BlockId),
 	}
 	for _, txIn := range txW.Inputs {
 		tx.Inputs = append(tx.Inputs, blockexplorer.IVIN{
@@ -166,7 +177,7 @@ func (b *BlockChair) generalTx(txW *TxWrapper, ctx *Context) (tx *blockexplorer.
 			TxID:        txIn.TransactionHash,
 			VOUT:        0,
 			Tree:        0,
-			AmountIn:    idaemon.Amount(txIn.Value),
+			AmountIn:    blockexplorer.AmountFromInt64(int64(txIn.Value), blockexplorer.DecimalsBTC),
 			BlockIndex:  txIn.Index,
 			BlockHeight: txIn.BlockId,
 		})
@@ -181,7 +192,7 @@ func (b *BlockChair) generalTx(txW *TxWrapper, ctx *Context) (tx *blockexplorer.
 			Spent:       false,
 			TxIndex:     txOut.Index,
 			Type:        txOut.Type,
-			Value:       idaemon.Amount(txOut.Value),
+			Value:       blockexplorer.AmountFromInt64(txOut.Value, blockexplorer.DecimalsBTC),
 		})
 	}
 	return
@@ -242,7 +253,7 @@ func (b *BlockChair) generalAddr(address string, addr *AddrWrapper, ctx *Context
 			VinSz:         0,
 			VoutSz:        0,
 			Weight:        0,
-			Confirmations: ctx.State - tx.BlockId + 1,
+			Confirmations: ctx.bestBlock().Confirmations(tx.BlockId),
 		})
 	}
 	return txs
@@ -1,12 +1,13 @@
 package blockcypher
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"strings"
 
 	"github.com/crypto-power/instantswap/blockexplorer"
 	"github.com/crypto-power/instantswap/blockexplorer/global/clients/blockexplorerclient"
-	"github.com/crypto-power/instantswap/blockexplorer/global/interfaces/idaemon"
 )
 
 const (
@@ -15,10 +16,10 @@ const (
 )
 
 func init() {
-	blockexplorer.RegisterExplorer("LTC", "", func(conf blockexplorer.Config) (blockexplorer.IBlockExplorer, error) {
+	blockexplorer.RegisterExplorerCtx("LTC", "", func(conf blockexplorer.Config) (blockexplorer.IBlockExplorerCtx, error) {
 		return New("ltc", "main", conf), nil
 	})
-	blockexplorer.RegisterExplorer("ETH", "", func(conf blockexplorer.Config) (blockexplorer.IBlockExplorer, error) {
+	blockexplorer.RegisterExplorerCtx("ETH", "", func(conf blockexplorer.Config) (blockexplorer.IBlockExplorerCtx, error) {
 		return New("eth", "main", conf), nil
 	})
 }
@@ -35,8 +36,9 @@ type chainzCryptoid struct {
 
 // New return a blockcypher instance
 func New(coinName, network string, conf blockexplorer.Config) *chainzCryptoid {
-	apiBase := fmt.Sprintf("%s/%s/%s/", API_BASE, coinName, network)
+	apiBase := fmt.Sprintf("%s/%s/%s/", strings.TrimSuffix(conf.BaseURLOrDefault(API_BASE), "/"), coinName, network)
 	client := blockexplorerclient.NewClient(apiBase, LIBNAME, conf.EnableOutput, nil)
+	client.SetHTTPClient(conf.HTTPClientOrDefault())
 	return &chainzCryptoid{
 		client:   client,
 		coinName: coinName,
@@ -50,21 +52,23 @@ func (c *chainzCryptoid) SetDebug(enable bool) {
 	c.client.Debug = enable
 }
 
-func (c *chainzCryptoid) VerifyByAddress(req blockexplorer.AddressVerifyRequest) (vr *blockexplorer.VerifyResult, err error) {
-	addr, err := c.getTxsForAddress(req.Address)
+func (c *chainzCryptoid) VerifyByAddress(ctx context.Context, req blockexplorer.AddressVerifyRequest) (vr *blockexplorer.VerifyResult, err error) {
+	orderedAmount := req.Amount
+	addr, err := c.getTxsForAddress(ctx, req.Address)
 	if err != nil {
 		return nil, err
 	}
 	for _, tx := range addr.Txrefs {
-		var value = idaemon.Amount(tx.Value)
-		if value.ToCoin() == req.Amount {
+		value, err := c.amount(tx.Value)
+		if err != nil {
+			return nil, err
+		}
+		if value.Cmp(orderedAmount) == 0 {
 			return &blockexplorer.VerifyResult{
 				Seen:                true,
 				Verified:            true,
-				OrderedAmount:       req.Amount,
-				BlockExplorerAmount: value.ToCoin(),
-				MissingAmount:       0,
-				MissingPercent:      0,
+				OrderedAmount:       orderedAmount,
+				BlockExplorerAmount: value,
 			}, nil
 		}
 	}
@@ -72,8 +76,8 @@ func (c *chainzCryptoid) VerifyByAddress(req blockexplorer.AddressVerifyRequest)
 }
 
 // GetTransaction returns decoded transaction from api
-func (c *chainzCryptoid) GetTransaction(txid string) (tx *blockexplorer.ITransaction, err error) {
-	r, err := c.client.Do("GET", fmt.Sprintf("txs/%s", txid), "", false)
+func (c *chainzCryptoid) GetTransaction(ctx context.Context, txid string) (tx *blockexplorer.ITransaction, err error) {
+	r, err := c.client.Do(ctx, "GET", fmt.Sprintf("txs/%s", txid), "", false)
 	if err != nil {
 		return nil, err
 	}
@@ -82,12 +86,21 @@ func (c *chainzCryptoid) GetTransaction(txid string) (tx *blockexplorer.ITransac
 	if err != nil {
 		return nil, err
 	}
-	return ltcTx.generalTx(c)
+	best, err := c.GetBestBlock(ctx)
+	if err != nil {
+		return nil, err
+	}
+	tx, err = ltcTx.generalTx(c)
+	if err != nil {
+		return nil, err
+	}
+	tx.SetConfirmations(*best)
+	return tx, nil
 }
 
 // GetTransactionsForAddress
-func (c *chainzCryptoid) getTxsForAddress(address string) (addr *Address, err error) {
-	r, err := c.client.Do("GET", fmt.Sprintf("addrs/%s", address), "", false)
+func (c *chainzCryptoid) getTxsForAddress(ctx context.Context, address string) (addr *Address, err error) {
+	r, err := c.client.Do(ctx, "GET", fmt.Sprintf("addrs/%s", address), "", false)
 	if err != nil {
 		return nil, err
 	}
@@ -96,33 +109,60 @@ func (c *chainzCryptoid) getTxsForAddress(address string) (addr *Address, err er
 	return
 }
 
-func (c *chainzCryptoid) GetTxsForAddress(address string, limit int, viewKey string) (txs *blockexplorer.IRawAddrResponse, err error) {
-	addr, err := c.getTxsForAddress(address)
+func (c *chainzCryptoid) GetTxsForAddress(ctx context.Context, address string, limit int, viewKey string) (txs *blockexplorer.IRawAddrResponse, err error) {
+	addr, err := c.getTxsForAddress(ctx, address)
+	if err != nil {
+		return nil, err
+	}
+	best, err := c.GetBestBlock(ctx)
+	if err != nil {
+		return nil, err
+	}
+	txs, err = addr.getIRawAddrResponse(c)
+	if err != nil {
+		return nil, err
+	}
+	txs.SetConfirmations(*best)
+	return txs, nil
+}
+
+// GetBestBlock returns the tip of the chain from api
+func (c *chainzCryptoid) GetBestBlock(ctx context.Context) (best *blockexplorer.BestBlock, err error) {
+	r, err := c.client.Do(ctx, "GET", "", "", false)
+	if err != nil {
+		return nil, err
+	}
+	var chain Chain
+	err = parseData(r, &chain)
 	if err != nil {
 		return nil, err
 	}
-	return addr.getIRawAddrResponse(c)
+	return &blockexplorer.BestBlock{
+		Height: chain.Height,
+		Hash:   c.ethId(chain.Hash),
+		Time:   int(chain.Time.Unix()),
+	}, nil
 }
 
 // PushTx
-func (c *chainzCryptoid) PushTx(txhash string) (res string, err error) {
+func (c *chainzCryptoid) PushTx(ctx context.Context, txhash string) (res string, err error) {
 	return "", fmt.Errorf("ltc is not support PushTx yet")
 }
 
 // VerifyTransaction verifies transaction based on values passed in (params: txid, address, amount, createdAt )
-func (c *chainzCryptoid) VerifyTransaction(verifier blockexplorer.TxVerifyRequest) (tx *blockexplorer.ITransaction, err error) {
+func (c *chainzCryptoid) VerifyTransaction(ctx context.Context, verifier blockexplorer.TxVerifyRequest) (tx *blockexplorer.ITransaction, err error) {
 	tx = new(blockexplorer.ITransaction)
 	if verifier.Address == "" {
 		err = fmt.Errorf("%s:error: address is blank so tx cannot be verified", LIBNAME)
 		return
 	}
-	if verifier.Amount == 0 {
-		err = fmt.Errorf("%s:error: amount is %.8f so tx cannot be verified", LIBNAME, verifier.Amount)
+	if verifier.Amount.IsZero() {
+		err = fmt.Errorf("%s:error: amount is %s so tx cannot be verified", LIBNAME, verifier.Amount)
 		return
 	}
 
 	if verifier.TxId != "" && verifier.Address != "" { //verify tx if txid is available
-		txInfo, err := c.GetTransaction(verifier.TxId)
+		txInfo, err := c.GetTransaction(ctx, verifier.TxId)
 		if err != nil {
 			return tx, err
 		}
@@ -137,16 +177,8 @@ func (c *chainzCryptoid) VerifyTransaction(verifier blockexplorer.TxVerifyReques
 						return tx, err
 					}
 
-					orderedAmount, err := idaemon.NewAmount(verifier.Amount)
-					if err != nil {
-						err = fmt.Errorf("%s:error: orderedAmount %s", LIBNAME, err.Error())
-						return tx, err
-					}
-					var missingAmount idaemon.Amount
-					missingAmount = v.Value - orderedAmount
-					if v.Value == orderedAmount {
-						missingAmount = 0
-					}
+					orderedAmount := verifier.Amount
+					missingAmount := v.Value.Sub(orderedAmount)
 					missingPercent := (missingAmount.ToCoin() / v.Value.ToCoin()) * 100
 
 					tx = txInfo
@@ -164,7 +196,7 @@ func (c *chainzCryptoid) VerifyTransaction(verifier blockexplorer.TxVerifyReques
 			}
 		}
 	} else if verifier.Address != "" { //verify tx on blockchain based on address history for address var
-		txInfo, err := c.GetTxsForAddress(verifier.Address, 10, "")
+		txInfo, err := c.GetTxsForAddress(ctx, verifier.Address, 10, "")
 		if err != nil {
 			return tx, err
 		}
@@ -183,17 +215,9 @@ func (c *chainzCryptoid) VerifyTransaction(verifier blockexplorer.TxVerifyReques
 							return tx, err
 						}
 
-						orderedAmount, err := idaemon.NewAmount(verifier.Amount)
-						if err != nil {
-							err = fmt.Errorf("%s:error: orderedAmount %s", LIBNAME, err.Error())
-							return tx, err
-						}
+						orderedAmount := verifier.Amount
 
-						var missingAmount idaemon.Amount
-						missingAmount = v.Value - orderedAmount
-						if v.Value == orderedAmount {
-							missingAmount = 0
-						}
+						missingAmount := v.Value.Sub(orderedAmount)
 						missingPercent := (missingAmount.ToCoin() / v.Value.ToCoin()) * 100
 
 						tx = &blockexplorer.ITransaction{
@@ -250,7 +274,7 @@ func (c *chainzCryptoid) VerifyTransaction(verifier blockexplorer.TxVerifyReques
 		}
 
 	} else {
-		err = fmt.Errorf("%s:error: vars passed for verification cannot be checked: \ntxid %s address: %s amount %.8f createdAt %v",
+		err = fmt.Errorf("%s:error: vars passed for verification cannot be checked: \ntxid %s address: %s amount %s createdAt %v",
 			LIBNAME, verifier.TxId, verifier.Address, verifier.Amount, verifier.CreatedAt)
 		return tx, err
 	}
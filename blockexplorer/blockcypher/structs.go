@@ -1,11 +1,11 @@
 package blockcypher
 
 import (
+	"encoding/json"
 	"fmt"
 	"time"
 
 	"github.com/crypto-power/instantswap/blockexplorer"
-	"github.com/crypto-power/instantswap/blockexplorer/global/interfaces/idaemon"
 )
 
 type Err struct {
@@ -17,81 +17,89 @@ func (err Err) Error() string {
 }
 
 type Tx struct {
-	BlockHash     string    `json:"block_hash"`
-	BlockHeight   int       `json:"block_height"`
-	BlockIndex    int       `json:"block_index"`
-	Hash          string    `json:"hash"`
-	Addresses     []string  `json:"addresses"`
-	Total         int64     `json:"total"`
-	Fees          int       `json:"fees"`
-	Size          int       `json:"size"`
-	Vsize         int       `json:"vsize"`
-	Preference    string    `json:"preference"`
-	RelayedBy     string    `json:"relayed_by"`
-	Confirmed     time.Time `json:"confirmed"`
-	Received      time.Time `json:"received"`
-	Ver           int       `json:"ver"`
-	DoubleSpend   bool      `json:"double_spend"`
-	VinSz         int       `json:"vin_sz"`
-	VoutSz        int       `json:"vout_sz"`
-	Confirmations int       `json:"confirmations"`
-	Confidence    int       `json:"confidence"`
-	Inputs        []TxInput `json:"inputs"`
-	Outputs       []TxOuput `json:"outputs"`
+	BlockHash     string      `json:"block_hash"`
+	BlockHeight   int         `json:"block_height"`
+	BlockIndex    int         `json:"block_index"`
+	Hash          string      `json:"hash"`
+	Addresses     []string    `json:"addresses"`
+	Total         json.Number `json:"total"`
+	Fees          int         `json:"fees"`
+	Size          int         `json:"size"`
+	Vsize         int         `json:"vsize"`
+	Preference    string      `json:"preference"`
+	RelayedBy     string      `json:"relayed_by"`
+	Confirmed     time.Time   `json:"confirmed"`
+	Received      time.Time   `json:"received"`
+	Ver           int         `json:"ver"`
+	DoubleSpend   bool        `json:"double_spend"`
+	VinSz         int         `json:"vin_sz"`
+	VoutSz        int         `json:"vout_sz"`
+	Confirmations int         `json:"confirmations"`
+	Confidence    int         `json:"confidence"`
+	Inputs        []TxInput   `json:"inputs"`
+	Outputs       []TxOuput   `json:"outputs"`
 }
 
 type TxInput struct {
-	PrevHash    string   `json:"prev_hash"`
-	OutputIndex int      `json:"output_index"`
-	OutputValue int      `json:"output_value"`
-	Sequence    int      `json:"sequence"`
-	Addresses   []string `json:"addresses"`
-	ScriptType  string   `json:"script_type"`
-	Age         int      `json:"age"`
-	Witness     []string `json:"witness"`
+	PrevHash    string      `json:"prev_hash"`
+	OutputIndex int         `json:"output_index"`
+	OutputValue json.Number `json:"output_value"`
+	Sequence    int         `json:"sequence"`
+	Addresses   []string    `json:"addresses"`
+	ScriptType  string      `json:"script_type"`
+	Age         int         `json:"age"`
+	Witness     []string    `json:"witness"`
 }
 
 type TxOuput struct {
-	Value      int      `json:"value"`
-	Script     string   `json:"script"`
-	SpentBy    string   `json:"spent_by"`
-	Addresses  []string `json:"addresses"`
-	ScriptType string   `json:"script_type"`
+	Value      json.Number `json:"value"`
+	Script     string      `json:"script"`
+	SpentBy    string      `json:"spent_by"`
+	Addresses  []string    `json:"addresses"`
+	ScriptType string      `json:"script_type"`
 }
 
 func (t *Tx) generalTx(c *chainzCryptoid) (tx *blockexplorer.ITransaction, err error) {
 	if t.Hash == "" {
 		return nil, fmt.Errorf("tx not found")
 	}
+	inputs, err := t.inputs(c)
+	if err != nil {
+		return nil, err
+	}
+	outputs, err := t.outputs(c)
+	if err != nil {
+		return nil, err
+	}
 	tx = &blockexplorer.ITransaction{
-		BlockHeight:         t.BlockHeight,
-		DoubleSpend:         false,
-		Hash:                c.ethId(t.Hash),
-		Inputs:              t.inputs(c),
-		LockTime:            0,
-		Outputs:             t.outputs(c),
-		Rbf:                 false,
-		Size:                t.Size,
-		Time:                int(t.Received.Unix()),
-		TxIndex:             t.BlockIndex,
-		Version:             t.Ver,
-		VinSz:               t.VinSz,
-		VoutSz:              t.VoutSz,
-		Weight:              0,
-		Confirmations:       t.Confirmations,
-		Seen:                true,
-		Verified:            true,
-		OrderedAmount:       0,
-		BlockExplorerAmount: 0,
-		MissingAmount:       0,
-		MissingPercent:      0,
+		BlockHeight:   t.BlockHeight,
+		DoubleSpend:   false,
+		Hash:          c.ethId(t.Hash),
+		Inputs:        inputs,
+		LockTime:      0,
+		Outputs:       outputs,
+		Rbf:           false,
+		Size:          t.Size,
+		Time:          int(t.Received.Unix()),
+		TxIndex:       t.BlockIndex,
+		Version:       t.Ver,
+		VinSz:         t.VinSz,
+		VoutSz:        t.VoutSz,
+		Weight:        0,
+		Confirmations: 0,
+		Seen:          true,
+		Verified:      true,
 	}
-	return tx, err
+	return tx, nil
 }
 
-func (t *Tx) inputs(c *chainzCryptoid) []blockexplorer.IVIN {
+func (t *Tx) inputs(c *chainzCryptoid) ([]blockexplorer.IVIN, error) {
 	var inputs = make([]blockexplorer.IVIN, len(t.Inputs))
 	for i, input := range t.Inputs {
+		amountIn, err := c.amount(input.OutputValue)
+		if err != nil {
+			return nil, err
+		}
 		inputs[i] = blockexplorer.IVIN{
 			Script:      input.ScriptType,
 			Sequence:    input.Sequence,
@@ -99,17 +107,21 @@ func (t *Tx) inputs(c *chainzCryptoid) []blockexplorer.IVIN {
 			TxID:        c.ethId(input.PrevHash),
 			VOUT:        input.OutputIndex,
 			Tree:        0,
-			AmountIn:    idaemon.Amount(input.OutputValue),
+			AmountIn:    amountIn,
 			BlockIndex:  0,
 			BlockHeight: 0,
 		}
 	}
-	return inputs
+	return inputs, nil
 }
 
-func (t *Tx) outputs(c *chainzCryptoid) []blockexplorer.IVOUT {
+func (t *Tx) outputs(c *chainzCryptoid) ([]blockexplorer.IVOUT, error) {
 	var outputs = make([]blockexplorer.IVOUT, len(t.Outputs))
 	for i, output := range t.Outputs {
+		value, err := c.amount(output.Value)
+		if err != nil {
+			return nil, err
+		}
 		outputs[i] = blockexplorer.IVOUT{
 			Addresses:   c.ethArrayId(output.Addresses),
 			AddrTag:     "",
@@ -119,10 +131,17 @@ func (t *Tx) outputs(c *chainzCryptoid) []blockexplorer.IVOUT {
 			Spent:       false,
 			TxIndex:     0,
 			Type:        output.ScriptType,
-			Value:       idaemon.Amount(output.Value),
+			Value:       value,
 		}
 	}
-	return outputs
+	return outputs, nil
+}
+
+type Chain struct {
+	Name   string    `json:"name"`
+	Height int       `json:"height"`
+	Hash   string    `json:"hash"`
+	Time   time.Time `json:"time"`
 }
 
 type Address struct {
@@ -141,17 +160,17 @@ type Address struct {
 }
 
 type CompactTx struct {
-	TxHash        string    `json:"tx_hash"`
-	BlockHeight   int       `json:"block_height"`
-	TxInputN      int       `json:"tx_input_n"`
-	TxOutputN     int       `json:"tx_output_n"`
-	Value         int64     `json:"value"`
-	RefBalance    int64     `json:"ref_balance"`
-	Confirmations int       `json:"confirmations"`
-	Confirmed     time.Time `json:"confirmed"`
-	DoubleSpend   bool      `json:"double_spend"`
-	Spent         bool      `json:"spent,omitempty"`
-	SpentBy       string    `json:"spent_by,omitempty"`
+	TxHash        string      `json:"tx_hash"`
+	BlockHeight   int         `json:"block_height"`
+	TxInputN      int         `json:"tx_input_n"`
+	TxOutputN     int         `json:"tx_output_n"`
+	Value         json.Number `json:"value"`
+	RefBalance    int64       `json:"ref_balance"`
+	Confirmations int         `json:"confirmations"`
+	Confirmed     time.Time   `json:"confirmed"`
+	DoubleSpend   bool        `json:"double_spend"`
+	Spent         bool        `json:"spent,omitempty"`
+	SpentBy       string      `json:"spent_by,omitempty"`
 }
 
 func (a *Address) getIRawAddrResponse(c *chainzCryptoid) (*blockexplorer.IRawAddrResponse, error) {
@@ -180,12 +199,26 @@ func (a *Address) getIRawAddrResponse(c *chainzCryptoid) (*blockexplorer.IRawAdd
 			VinSz:         0,
 			VoutSz:        0,
 			Weight:        0,
-			Confirmations: tx.Confirmations,
+			Confirmations: 0,
 		}
 	}
 	return &iTx, nil
 }
 
+// decimals returns the decimals of the base units of the coin, wei for eth
+// and satoshi otherwise.
+func (c *chainzCryptoid) decimals() int {
+	if c.coinName == "eth" {
+		return blockexplorer.DecimalsETH
+	}
+	return blockexplorer.DecimalsBTC
+}
+
+// amount returns the amount of a value in base units of the coin.
+func (c *chainzCryptoid) amount(units json.Number) (blockexplorer.Amount, error) {
+	return blockexplorer.ParseUnits(units.String(), c.decimals())
+}
+
 func (c *chainzCryptoid) ethId(id string) string {
 	if c.coinName == "eth" {
 		return fmt.Sprintf("0x%s", id)
@@ -1,8 +1,10 @@
 package blockexplorer
 
 import (
+	"context"
 	"fmt"
 	"log"
+	"net/http"
 	"strings"
 	"sync"
 )
@@ -12,23 +14,53 @@ type Config struct {
 	Symbol       string
 	ApiKey       string
 	Type         NetworkType
+	// HTTPClient is the client sending the requests to the explorer. A
+	// client using Transport is created if nil.
+	HTTPClient *http.Client
+	// Transport is the RoundTripper of the client created when HTTPClient is
+	// nil, to route the requests through a SOCKS5 proxy or Tor for example.
+	Transport http.RoundTripper
+	// BaseURL replaces the api endpoint of the explorer, to use a mirror or
+	// a self-hosted backend. The explorers serving several chains append the
+	// path of the chain to it.
+	BaseURL string
+}
+
+// HTTPClientOrDefault returns HTTPClient, or a new client using Transport if
+// nil.
+func (c *Config) HTTPClientOrDefault() *http.Client {
+	if c.HTTPClient != nil {
+		return c.HTTPClient
+	}
+	return &http.Client{Transport: c.Transport}
+}
+
+// BaseURLOrDefault returns BaseURL with a trailing slash, or def if empty.
+func (c *Config) BaseURLOrDefault(def string) string {
+	if c.BaseURL == "" {
+		return def
+	}
+	return strings.TrimSuffix(c.BaseURL, "/") + "/"
 }
 
 var driv = driver{
 	mux:    new(sync.RWMutex),
-	stack:  make(map[string]NewExplorerFunc),
-	layer2: make(map[NetworkType]NewExplorerFunc),
+	stack:  make(map[string]NewExplorerCtxFunc),
+	layer2: make(map[NetworkType]NewExplorerCtxFunc),
 }
 
 type NewExplorerFunc func(conf Config) (IBlockExplorer, error)
 
+// NewExplorerCtxFunc builds a context-aware explorer client.
+type NewExplorerCtxFunc func(conf Config) (IBlockExplorerCtx, error)
+
 type driver struct {
 	mux    *sync.RWMutex
-	stack  map[string]NewExplorerFunc
-	layer2 map[NetworkType]NewExplorerFunc
+	stack  map[string]NewExplorerCtxFunc
+	layer2 map[NetworkType]NewExplorerCtxFunc
 }
 
-func (d *driver) registerExplorer(symbol string, networkType NetworkType, newExplorer NewExplorerFunc) {
+func (d *driver) registerExplorer(symbol string, networkType NetworkType, newExplorer NewExplorerCtxFunc) {
 	d.mux.Lock()
 	defer d.mux.Unlock()
 	if symbol != "" {
@@ -45,7 +77,7 @@ func (d *driver) registerExplorer(symbol string, networkType NetworkType, newExp
 	}
 }
 
-func (d *driver) newExplorer(conf Config) (IBlockExplorer, error) {
+func (d *driver) newExplorer(conf Config) (IBlockExplorerCtx, error) {
 	d.mux.Lock()
 	defer d.mux.Unlock()
 	if conf.Type == "" {
@@ -64,14 +96,35 @@ func (d *driver) newExplorer(conf Config) (IBlockExplorer, error) {
 	}
 }
 
+// RegisterExplorer registers an explorer implementing the legacy
+// IBlockExplorer interface. Its methods are wrapped with WithContext.
 func RegisterExplorer(symbol string, networkType NetworkType, newDriver NewExplorerFunc) {
+	driv.registerExplorer(strings.ToLower(symbol), networkType, func(conf Config) (IBlockExplorerCtx, error) {
+		explorer, err := newDriver(conf)
+		if err != nil {
+			return nil, err
+		}
+		return WithContext(explorer), nil
+	})
+}
+
+// RegisterExplorerCtx registers an explorer implementing IBlockExplorerCtx.
+func RegisterExplorerCtx(symbol string, networkType NetworkType, newDriver NewExplorerCtxFunc) {
 	driv.registerExplorer(strings.ToLower(symbol), networkType, newDriver)
 }
 
+// NewExplorer returns the registered explorer as an IBlockExplorer, every
+// call runs with context.Background().
 func NewExplorer(conf Config) (IBlockExplorer, error) {
-	if conf.Type == "" {
-		return driv.newExplorer(conf)
+	explorer, err := driv.newExplorer(conf)
+	if err != nil {
+		return nil, err
 	}
+	return WithoutContext(explorer), nil
+}
+
+// NewExplorerCtx returns the registered explorer as an IBlockExplorerCtx.
+func NewExplorerCtx(conf Config) (IBlockExplorerCtx, error) {
 	return driv.newExplorer(conf)
 }
 
@@ -83,11 +136,29 @@ type IBlockExplorer interface {
 	VerifyByAddress(req AddressVerifyRequest) (vr *VerifyResult, err error)
 	//PushTx pushes a raw tx hash
 	PushTx(rawTxHash string) (result string, err error)
+	//GetBestBlock returns the tip of the chain, the confirmations are counted from it
+	GetBestBlock() (best *BestBlock, err error)
+}
+
+// IBlockExplorerCtx is the context-aware form of IBlockExplorer. Every call is
+// bound to ctx, cancelling ctx or reaching its deadline aborts the in-flight
+// requests.
+type IBlockExplorerCtx interface {
+	GetTransaction(ctx context.Context, txId string) (tx *ITransaction, err error)
+	GetTxsForAddress(ctx context.Context, address string, limit int, viewKey string) (tx *IRawAddrResponse, err error)
+	//VerifyTransaction verifies transaction based on values passed in
+	VerifyTransaction(ctx context.Context, verifier TxVerifyRequest) (tx *ITransaction, err error)
+	VerifyByAddress(ctx context.Context, req AddressVerifyRequest) (vr *VerifyResult, err error)
+	//PushTx pushes a raw tx hash
+	PushTx(ctx context.Context, rawTxHash string) (result string, err error)
+	//GetBestBlock returns the tip of the chain, the confirmations are counted from it
+	GetBestBlock(ctx context.Context) (best *BestBlock, err error)
 }
 
 type TxVerifyRequest struct {
-	TxId      string
-	Amount    float64
+	TxId string
+	// Amount is the ordered amount, of any decimals.
+	Amount    Amount
 	CreatedAt int64
 	Address   string
 	Confirms  int
@@ -96,8 +167,9 @@ type TxVerifyRequest struct {
 }
 
 type AddressVerifyRequest struct {
-	Address   string
-	Amount    float64
+	Address string
+	// Amount is the ordered amount, of any decimals.
+	Amount    Amount
 	ViewKey   string
 	Confirm   int
 	Timestamp int
@@ -106,8 +178,8 @@ type AddressVerifyRequest struct {
 type VerifyResult struct {
 	Seen                bool    `json:"seen"` //tx has been seen on block explorer but not verified
 	Verified            bool    `json:"verified"`
-	OrderedAmount       float64 `json:"ordered_amount"`
-	BlockExplorerAmount float64 `json:"block_explorer_amount"`
-	MissingAmount       float64 `json:"missing_amount"`
+	OrderedAmount       Amount  `json:"ordered_amount"`
+	BlockExplorerAmount Amount  `json:"block_explorer_amount"`
+	MissingAmount       Amount  `json:"missing_amount"`
 	MissingPercent      float64 `json:"missing_percent"`
 }
@@ -1,18 +1,18 @@
 package btcexplorer
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 
 	"github.com/crypto-power/instantswap/blockexplorer"
-	"github.com/crypto-power/instantswap/blockexplorer/global/interfaces/idaemon"
 
 	"github.com/crypto-power/instantswap/blockexplorer/global/clients/blockexplorerclient"
 )
 
 func init() {
-	blockexplorer.RegisterExplorer("BTC", "", func(conf blockexplorer.Config) (blockexplorer.IBlockExplorer, error) {
+	blockexplorer.RegisterExplorerCtx("BTC", "", func(conf blockexplorer.Config) (blockexplorer.IBlockExplorerCtx, error) {
 		return New(conf), nil
 	})
 }
@@ -25,7 +25,8 @@ const (
 
 // New return a instanciate cryptopia struct
 func New(conf blockexplorer.Config) *BlockChainInfo {
-	client := blockexplorerclient.NewClient(API_BASE, LIBNAME, conf.EnableOutput, nil)
+	client := blockexplorerclient.NewClient(conf.BaseURLOrDefault(API_BASE), LIBNAME, conf.EnableOutput, nil)
+	client.SetHTTPClient(conf.HTTPClientOrDefault())
 	return &BlockChainInfo{client: client}
 }
 
@@ -55,22 +56,21 @@ func (c *BlockChainInfo) SetDebug(enable bool) {
 	c.client.Debug = enable
 }
 
-func (c *BlockChainInfo) VerifyByAddress(req blockexplorer.AddressVerifyRequest) (vr *blockexplorer.VerifyResult, err error) {
-	rawAddr, err := c.getTxsForAddress(req.Address, 25)
+func (c *BlockChainInfo) VerifyByAddress(ctx context.Context, req blockexplorer.AddressVerifyRequest) (vr *blockexplorer.VerifyResult, err error) {
+	orderedAmount := req.Amount
+	rawAddr, err := c.getTxsForAddress(ctx, req.Address, 25)
 	if err != nil {
 		return nil, err
 	}
 	for _, tx := range rawAddr.Txs {
 		for _, out := range tx.Outputs {
-			value := idaemon.Amount(out.Value)
-			if value.ToCoin() == req.Amount {
+			value := blockexplorer.AmountFromInt64(int64(out.Value), blockexplorer.DecimalsBTC)
+			if value.Cmp(orderedAmount) == 0 {
 				return &blockexplorer.VerifyResult{
 					Seen:                true,
 					Verified:            true,
-					OrderedAmount:       req.Amount,
-					BlockExplorerAmount: value.ToCoin(),
-					MissingAmount:       0,
-					MissingPercent:      0,
+					OrderedAmount:       orderedAmount,
+					BlockExplorerAmount: value,
 				}, nil
 			}
 		}
@@ -79,8 +79,8 @@ func (c *BlockChainInfo) VerifyByAddress(req blockexplorer.AddressVerifyRequest)
 }
 
 // GetTransaction returns decoded transaction from api
-func (c *BlockChainInfo) GetTransaction(txid string) (tx *blockexplorer.ITransaction, err error) {
-	r, err := c.client.Do("GET", "rawtx/"+txid, "", false)
+func (c *BlockChainInfo) GetTransaction(ctx context.Context, txid string) (tx *blockexplorer.ITransaction, err error) {
+	r, err := c.client.Do(ctx, "GET", "rawtx/"+txid, "", false)
 	if err != nil {
 		return
 	}
@@ -89,17 +89,14 @@ func (c *BlockChainInfo) GetTransaction(txid string) (tx *blockexplorer.ITransac
 		return
 	}
 
-	//get latest block to get our confirmations
-	latestBlock, err := c.GetLatestBlock()
+	//get the best block to get our confirmations
+	best, err := c.GetBestBlock(ctx)
 	if err != nil {
 		return
 	}
 
-	//confirmations for this tx
-	tmp.Confirmations = latestBlock.Height - tmp.BlockHeight
-
 	tx = &blockexplorer.ITransaction{
-		Confirmations: tmp.Confirmations,
+		Confirmations: best.Confirmations(tmp.BlockHeight),
 		BlockHeight:   tmp.BlockHeight,
 		DoubleSpend:   tmp.DoubleSpend,
 		Hash:          tmp.Hash,
@@ -132,7 +129,7 @@ func (c *BlockChainInfo) GetTransaction(txid string) (tx *blockexplorer.ITransac
 			Spent:       v.Spent,
 			TxIndex:     v.TxIndex,
 			Type:        fmt.Sprintf("%v", v.Type),
-			Value:       v.Value,
+			Value:       blockexplorer.AmountFromInt64(v.Value, blockexplorer.DecimalsBTC),
 		}
 		tx.Outputs = append(tx.Outputs, tmpOut)
 	}
@@ -140,8 +137,8 @@ func (c *BlockChainInfo) GetTransaction(txid string) (tx *blockexplorer.ITransac
 	return
 }
 
-func (c *BlockChainInfo) getTxsForAddress(address string, limit int) (txs *RawAddrResponse, err error) {
-	r, err := c.client.Do("GET", fmt.Sprintf("rawaddr/%s?&limit=%v", address, limit), "", false)
+func (c *BlockChainInfo) getTxsForAddress(ctx context.Context, address string, limit int) (txs *RawAddrResponse, err error) {
+	r, err := c.client.Do(ctx, "GET", fmt.Sprintf("rawaddr/%s?&limit=%v", address, limit), "", false)
 	if err != nil {
 		return
 	}
@@ -155,15 +152,15 @@ func (c *BlockChainInfo) getTxsForAddress(address string, limit int) (txs *RawAd
 }
 
 // GetTransactionsForAddress
-func (c *BlockChainInfo) GetTxsForAddress(address string, limit int, viewKey string) (txs *blockexplorer.IRawAddrResponse, err error) {
-	tmp, err := c.getTxsForAddress(address, limit)
+func (c *BlockChainInfo) GetTxsForAddress(ctx context.Context, address string, limit int, viewKey string) (txs *blockexplorer.IRawAddrResponse, err error) {
+	tmp, err := c.getTxsForAddress(ctx, address, limit)
 
 	if err != nil {
 		return nil, err
 	}
 
-	//get latest block to get our confirmations
-	latestBlock, err := c.GetLatestBlock()
+	//get the best block to get our confirmations
+	best, err := c.GetBestBlock(ctx)
 	if err != nil {
 		return
 	}
@@ -193,7 +190,7 @@ func (c *BlockChainInfo) GetTxsForAddress(address string, limit int, viewKey str
 			VinSz:         v.VinSz,
 			VoutSz:        v.VoutSz,
 			Weight:        v.Weight,
-			Confirmations: latestBlock.Height - v.BlockHeight,
+			Confirmations: best.Confirmations(v.BlockHeight),
 		}
 		var tmpInputs []blockexplorer.IRawAddrInput
 		for _, w := range v.Inputs {
@@ -204,7 +201,7 @@ func (c *BlockChainInfo) GetTxsForAddress(address string, limit int, viewKey str
 				Witness:  w.Witness,
 			}
 
-			tmpAmount := idaemon.Amount(w.PrevOut.Value)
+			tmpAmount := blockexplorer.AmountFromInt64(int64(w.PrevOut.Value), blockexplorer.DecimalsBTC)
 
 			addresses := []string{w.PrevOut.Addr}
 			tmpPrevOutput := blockexplorer.IRawAddrOutput{
@@ -226,7 +223,7 @@ func (c *BlockChainInfo) GetTxsForAddress(address string, limit int, viewKey str
 		var tmpOuputs []blockexplorer.IRawAddrOutput
 		for _, w := range v.Outputs {
 
-			tmpAmount := idaemon.Amount(w.Value)
+			tmpAmount := blockexplorer.AmountFromInt64(int64(w.Value), blockexplorer.DecimalsBTC)
 
 			addresses := []string{w.Address}
 			tmpOut := blockexplorer.IRawAddrOutput{
@@ -250,8 +247,8 @@ func (c *BlockChainInfo) GetTxsForAddress(address string, limit int, viewKey str
 }
 
 // PushTx
-func (c *BlockChainInfo) PushTx(txhash string) (res string, err error) {
-	r, err := c.client.Do("POST", fmt.Sprintf("pushtx?tx=%s", txhash), "", false)
+func (c *BlockChainInfo) PushTx(ctx context.Context, txhash string) (res string, err error) {
+	r, err := c.client.Do(ctx, "POST", fmt.Sprintf("pushtx?tx=%s", txhash), "", false)
 	if err != nil {
 		return
 	}
@@ -262,20 +259,20 @@ func (c *BlockChainInfo) PushTx(txhash string) (res string, err error) {
 }
 
 // VerifyTransaction verifies transaction based on values passed in (params: txid, address, amount, createdAt )
-func (c *BlockChainInfo) VerifyTransaction(verifier blockexplorer.TxVerifyRequest) (tx *blockexplorer.ITransaction, err error) {
+func (c *BlockChainInfo) VerifyTransaction(ctx context.Context, verifier blockexplorer.TxVerifyRequest) (tx *blockexplorer.ITransaction, err error) {
 	tx = new(blockexplorer.ITransaction)
 	if verifier.Address == "" {
 		err = errors.New(LIBNAME + ":error: address is blank so tx cannot be verified")
 		return
 	}
-	if verifier.Amount == 0 {
-		errStr := fmt.Sprintf(LIBNAME+":error: amount is %.8f so tx cannot be verified", verifier.Amount)
+	if verifier.Amount.IsZero() {
+		errStr := fmt.Sprintf(LIBNAME+":error: amount is %s so tx cannot be verified", verifier.Amount)
 		err = errors.New(errStr)
 		return
 	}
 
 	if verifier.TxId != "" && verifier.Address != "" { //verify tx if txid is available
-		txInfo, err := c.GetTransaction(verifier.TxId)
+		txInfo, err := c.GetTransaction(ctx, verifier.TxId)
 		if err != nil {
 			return tx, err
 		}
@@ -294,17 +291,8 @@ func (c *BlockChainInfo) VerifyTransaction(verifier blockexplorer.TxVerifyReques
 						return tx, err
 					}
 
-					orderedAmount, err := idaemon.NewAmount(verifier.Amount)
-					if err != nil {
-						errStr := fmt.Sprintf(LIBNAME+":error: orderedAmount %s", err.Error())
-						err = errors.New(errStr)
-						return tx, err
-					}
-					var missingAmount idaemon.Amount
-					missingAmount = v.Value - orderedAmount
-					if v.Value == orderedAmount {
-						missingAmount = 0
-					}
+					orderedAmount := verifier.Amount
+					missingAmount := v.Value.Sub(orderedAmount)
 					missingPercent := (missingAmount.ToCoin() / v.Value.ToCoin()) * 100
 
 					tx = txInfo
@@ -322,7 +310,7 @@ func (c *BlockChainInfo) VerifyTransaction(verifier blockexplorer.TxVerifyReques
 			}
 		}
 	} else if verifier.Address != "" { //verify tx on blockchain based on address history for address var
-		txInfo, err := c.GetTxsForAddress(verifier.Address, 10, "")
+		txInfo, err := c.GetTxsForAddress(ctx, verifier.Address, 10, "")
 		if err != nil {
 			return tx, err
 		}
@@ -342,18 +330,9 @@ func (c *BlockChainInfo) VerifyTransaction(verifier blockexplorer.TxVerifyReques
 							return tx, err
 						}
 
-						orderedAmount, err := idaemon.NewAmount(verifier.Amount)
-						if err != nil {
-							errStr := fmt.Sprintf(LIBNAME+":error: orderedAmount %s", err.Error())
-							err = errors.New(errStr)
-							return tx, err
-						}
+						orderedAmount := verifier.Amount
 
-						var missingAmount idaemon.Amount
-						missingAmount = v.Value - orderedAmount
-						if v.Value == orderedAmount {
-							missingAmount = 0
-						}
+						missingAmount := v.Value.Sub(orderedAmount)
 						missingPercent := (missingAmount.ToCoin() / v.Value.ToCoin()) * 100
 
 						tx = &blockexplorer.ITransaction{
@@ -410,7 +389,7 @@ func (c *BlockChainInfo) VerifyTransaction(verifier blockexplorer.TxVerifyReques
 		}
 
 	} else {
-		errStr := fmt.Sprintf(LIBNAME+":error: vars passed for verification cannot be checked: \ntxid %s address: %s amount %.8f createdAt %v",
+		errStr := fmt.Sprintf(LIBNAME+":error: vars passed for verification cannot be checked: \ntxid %s address: %s amount %s createdAt %v",
 			verifier.TxId, verifier.Address, verifier.Amount, verifier.CreatedAt)
 		err = errors.New(errStr)
 		return tx, err
@@ -419,9 +398,9 @@ func (c *BlockChainInfo) VerifyTransaction(verifier blockexplorer.TxVerifyReques
 	return
 }
 
-// GetLatestBlock returns decoded transaction from api
-func (c *BlockChainInfo) GetLatestBlock() (latestBlock LatestBlock, err error) {
-	r, err := c.client.Do("GET", "latestblock", "", false)
+// GetBestBlock returns the latest block from api
+func (c *BlockChainInfo) GetBestBlock(ctx context.Context) (best *blockexplorer.BestBlock, err error) {
+	r, err := c.client.Do(ctx, "GET", "latestblock", "", false)
 	if err != nil {
 		return
 	}
@@ -429,7 +408,9 @@ func (c *BlockChainInfo) GetLatestBlock() (latestBlock LatestBlock, err error) {
 	if err = json.Unmarshal(r, &response); err != nil {
 		return
 	}
-	latestBlock = response
-	//err = errors.New("test failure check error")
-	return
+	return &blockexplorer.BestBlock{
+		Height: response.Height,
+		Hash:   response.Hash,
+		Time:   response.Time,
+	}, nil
 }
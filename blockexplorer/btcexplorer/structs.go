@@ -1,10 +1,6 @@
 package btcexplorer
 
-import (
-	"encoding/json"
-
-	"github.com/crypto-power/instantswap/blockexplorer/global/interfaces/idaemon"
-)
+import "encoding/json"
 
 type jsonResponse struct {
 	Success bool            `json:"Success"`
@@ -23,33 +19,32 @@ type VIN struct {
 	Witness  string `json:"witness"`
 }
 type VOUT struct {
-	Addr        string         `json:"addr"`
-	AddrTag     string         `json:"addr_tag"`
-	AddrTagLink string         `json:"addr_tag_link"`
-	N           int            `json:"n"`
-	Script      string         `json:"script"`
-	Spent       bool           `json:"spent"`
-	TxIndex     int            `json:"tx_index"`
-	Type        int            `json:"type"`
-	Value       idaemon.Amount `json:"value"`
+	Addr        string `json:"addr"`
+	AddrTag     string `json:"addr_tag"`
+	AddrTagLink string `json:"addr_tag_link"`
+	N           int    `json:"n"`
+	Script      string `json:"script"`
+	Spent       bool   `json:"spent"`
+	TxIndex     int    `json:"tx_index"`
+	Type        int    `json:"type"`
+	Value       int64  `json:"value"`
 }
 type Transaction struct {
-	BlockHeight   int    `json:"block_height"`
-	DoubleSpend   bool   `json:"double_spend"`
-	Hash          string `json:"hash"`
-	Inputs        []VIN  `json:"inputs"`
-	LockTime      int    `json:"lock_time"`
-	Out           []VOUT `json:"out"`
-	Rbf           bool   `json:"rbf"`
-	RelayedBy     string `json:"relayed_by"`
-	Size          int    `json:"size"`
-	Time          int    `json:"time"`
-	TxIndex       int    `json:"tx_index"`
-	Ver           int    `json:"ver"`
-	VinSz         int    `json:"vin_sz"`
-	VoutSz        int    `json:"vout_sz"`
-	Weight        int    `json:"weight"`
-	Confirmations int    `json:"confirmations"` //calculated from getting latest block - blockheight
+	BlockHeight int    `json:"block_height"`
+	DoubleSpend bool   `json:"double_spend"`
+	Hash        string `json:"hash"`
+	Inputs      []VIN  `json:"inputs"`
+	LockTime    int    `json:"lock_time"`
+	Out         []VOUT `json:"out"`
+	Rbf         bool   `json:"rbf"`
+	RelayedBy   string `json:"relayed_by"`
+	Size        int    `json:"size"`
+	Time        int    `json:"time"`
+	TxIndex     int    `json:"tx_index"`
+	Ver         int    `json:"ver"`
+	VinSz       int    `json:"vin_sz"`
+	VoutSz      int    `json:"vout_sz"`
+	Weight      int    `json:"weight"`
 }
 type LatestBlock struct {
 	BlockIndex int    `json:"block_index"`
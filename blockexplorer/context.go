@@ -0,0 +1,97 @@
+package blockexplorer
+
+import "context"
+
+// WithoutContext adapts an IBlockExplorerCtx to the IBlockExplorer interface
+// so existing callers keep working. Every call runs with
+// context.Background().
+func WithoutContext(explorer IBlockExplorerCtx) IBlockExplorer {
+	if e, ok := explorer.(*legacyExplorer); ok {
+		return e.explorer
+	}
+	return &backgroundExplorer{explorer: explorer}
+}
+
+// WithContext adapts a legacy IBlockExplorer to the IBlockExplorerCtx
+// interface. The legacy methods can not be interrupted, ctx is only checked
+// before the call is made.
+func WithContext(explorer IBlockExplorer) IBlockExplorerCtx {
+	if e, ok := explorer.(*backgroundExplorer); ok {
+		return e.explorer
+	}
+	return &legacyExplorer{explorer: explorer}
+}
+
+type backgroundExplorer struct {
+	explorer IBlockExplorerCtx
+}
+
+func (e *backgroundExplorer) GetTransaction(txId string) (*ITransaction, error) {
+	return e.explorer.GetTransaction(context.Background(), txId)
+}
+
+func (e *backgroundExplorer) GetTxsForAddress(address string, limit int, viewKey string) (*IRawAddrResponse, error) {
+	return e.explorer.GetTxsForAddress(context.Background(), address, limit, viewKey)
+}
+
+func (e *backgroundExplorer) VerifyTransaction(verifier TxVerifyRequest) (*ITransaction, error) {
+	return e.explorer.VerifyTransaction(context.Background(), verifier)
+}
+
+func (e *backgroundExplorer) VerifyByAddress(req AddressVerifyRequest) (*VerifyResult, error) {
+	return e.explorer.VerifyByAddress(context.Background(), req)
+}
+
+func (e *backgroundExplorer) PushTx(rawTxHash string) (string, error) {
+	return e.explorer.PushTx(context.Background(), rawTxHash)
+}
+
+func (e *backgroundExplorer) GetBestBlock() (*BestBlock, error) {
+	return e.explorer.GetBestBlock(context.Background())
+}
+
+type legacyExplorer struct {
+	explorer IBlockExplorer
+}
+
+func (e *legacyExplorer) GetTransaction(ctx context.Context, txId string) (*ITransaction, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return e.explorer.GetTransaction(txId)
+}
+
+func (e *legacyExplorer) GetTxsForAddress(ctx context.Context, address string, limit int, viewKey string) (*IRawAddrResponse, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return e.explorer.GetTxsForAddress(address, limit, viewKey)
+}
+
+func (e *legacyExplorer) VerifyTransaction(ctx context.Context, verifier TxVerifyRequest) (*ITransaction, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return e.explorer.VerifyTransaction(verifier)
+}
+
+func (e *legacyExplorer) VerifyByAddress(ctx context.Context, req AddressVerifyRequest) (*VerifyResult, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return e.explorer.VerifyByAddress(req)
+}
+
+func (e *legacyExplorer) PushTx(ctx context.Context, rawTxHash string) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	return e.explorer.PushTx(rawTxHash)
+}
+
+func (e *legacyExplorer) GetBestBlock(ctx context.Context) (*BestBlock, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return e.explorer.GetBestBlock()
+}
@@ -1,13 +1,13 @@
 package dcrexplorer
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 
 	"github.com/crypto-power/instantswap/blockexplorer"
 	"github.com/crypto-power/instantswap/blockexplorer/global/clients/blockexplorerclient"
-	"github.com/crypto-power/instantswap/blockexplorer/global/interfaces/idaemon"
 )
 
 const (
@@ -17,14 +17,15 @@ const (
 )
 
 func init() {
-	blockexplorer.RegisterExplorer("DCR", "", func(conf blockexplorer.Config) (blockexplorer.IBlockExplorer, error) {
+	blockexplorer.RegisterExplorerCtx("DCR", "", func(conf blockexplorer.Config) (blockexplorer.IBlockExplorerCtx, error) {
 		return New(conf), nil
 	})
 }
 
 // New return a instanciate cryptopia struct
 func New(conf blockexplorer.Config) *DCRData {
-	client := blockexplorerclient.NewClient(API_BASE, LIBNAME, conf.EnableOutput, nil)
+	client := blockexplorerclient.NewClient(conf.BaseURLOrDefault(API_BASE), LIBNAME, conf.EnableOutput, nil)
+	client.SetHTTPClient(conf.HTTPClientOrDefault())
 	return &DCRData{client: client}
 }
 
@@ -54,22 +55,25 @@ func (c *DCRData) SetDebug(enable bool) {
 	c.client.Debug = enable
 }
 
-func (c *DCRData) VerifyByAddress(req blockexplorer.AddressVerifyRequest) (vr *blockexplorer.VerifyResult, err error) {
-	txs, err := c.getTxsForAddress(req.Address, 25)
+func (c *DCRData) VerifyByAddress(ctx context.Context, req blockexplorer.AddressVerifyRequest) (vr *blockexplorer.VerifyResult, err error) {
+	txs, err := c.getTxsForAddress(ctx, req.Address, 25)
 	if err != nil {
 		return nil, err
 	}
 	for _, tx := range txs {
 		for _, out := range tx.Vout {
 			if len(out.ScriptPubKey.Addresses) == 1 && out.ScriptPubKey.Addresses[0] == req.Address {
-				if out.Value == req.Amount {
+				value, err := blockexplorer.AmountFromCoin(out.Value, blockexplorer.DecimalsBTC)
+				if err != nil {
+					return nil, err
+				}
+				orderedAmount := req.Amount
+				if value.Cmp(orderedAmount) == 0 {
 					return &blockexplorer.VerifyResult{
 						Seen:                true,
 						Verified:            true,
-						OrderedAmount:       req.Amount,
-						BlockExplorerAmount: out.Value,
-						MissingAmount:       0,
-						MissingPercent:      0,
+						OrderedAmount:       orderedAmount,
+						BlockExplorerAmount: value,
 					}, nil
 				}
 			}
@@ -79,8 +83,8 @@ func (c *DCRData) VerifyByAddress(req blockexplorer.AddressVerifyRequest) (vr *b
 }
 
 // GetTransaction returns decoded transaction from explorer.dcrdata.org/api
-func (c *DCRData) GetTransaction(txid string) (tx *blockexplorer.ITransaction, err error) {
-	r, err := c.client.Do("GET", "tx/"+txid, "", false)
+func (c *DCRData) GetTransaction(ctx context.Context, txid string) (tx *blockexplorer.ITransaction, err error) {
+	r, err := c.client.Do(ctx, "GET", "tx/"+txid, "", false)
 	if err != nil {
 		return
 	}
@@ -88,8 +92,12 @@ func (c *DCRData) GetTransaction(txid string) (tx *blockexplorer.ITransaction, e
 	if err = json.Unmarshal(r, &tmp); err != nil {
 		return
 	}
+	best, err := c.GetBestBlock(ctx)
+	if err != nil {
+		return
+	}
 	tx = &blockexplorer.ITransaction{
-		Confirmations: tmp.Confirmations,
+		Confirmations: best.Confirmations(tmp.Block.Blockheight),
 		BlockHeight:   tmp.Block.Blockheight,
 		//DoubleSpend: tmp.DoubleSpend,
 		Hash:     tmp.Txid,
@@ -104,7 +112,7 @@ func (c *DCRData) GetTransaction(txid string) (tx *blockexplorer.ITransaction, e
 		//Weight:  tmp.Weight,
 	}
 	for _, v := range tmp.Vin {
-		amountIn, err := idaemon.NewAmount(v.Amountin)
+		amountIn, err := blockexplorer.AmountFromCoin(v.Amountin, blockexplorer.DecimalsBTC)
 		if err != nil {
 			return tx, err
 		}
@@ -122,7 +130,7 @@ func (c *DCRData) GetTransaction(txid string) (tx *blockexplorer.ITransaction, e
 		tx.Inputs = append(tx.Inputs, tmpIn)
 	}
 	for _, v := range tmp.Vout {
-		valueAmount, err := idaemon.NewAmount(v.Value)
+		valueAmount, err := blockexplorer.AmountFromCoin(v.Value, blockexplorer.DecimalsBTC)
 		if err != nil {
 			return tx, err
 		}
@@ -143,8 +151,8 @@ func (c *DCRData) GetTransaction(txid string) (tx *blockexplorer.ITransaction, e
 	return
 }
 
-func (c *DCRData) getTxsForAddress(address string, limit int) (txs []RawAddrTx, err error) {
-	r, err := c.client.Do("GET", fmt.Sprintf("address/%s/count/%v/raw", address, limit), "", false)
+func (c *DCRData) getTxsForAddress(ctx context.Context, address string, limit int) (txs []RawAddrTx, err error) {
+	r, err := c.client.Do(ctx, "GET", fmt.Sprintf("address/%s/count/%v/raw", address, limit), "", false)
 	if err != nil {
 		return nil, fmt.Errorf(" could not find/parse address %s msg: %s", address, err.Error())
 	}
@@ -153,8 +161,12 @@ func (c *DCRData) getTxsForAddress(address string, limit int) (txs []RawAddrTx,
 }
 
 // GetTransactionsForAddress
-func (c *DCRData) GetTxsForAddress(address string, limit int, viewKey string) (txs *blockexplorer.IRawAddrResponse, err error) {
-	tmp, err := c.getTxsForAddress(address, limit)
+func (c *DCRData) GetTxsForAddress(ctx context.Context, address string, limit int, viewKey string) (txs *blockexplorer.IRawAddrResponse, err error) {
+	tmp, err := c.getTxsForAddress(ctx, address, limit)
+	if err != nil {
+		return nil, err
+	}
+	best, err := c.GetBestBlock(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -171,13 +183,16 @@ func (c *DCRData) GetTxsForAddress(address string, limit int, viewKey string) (t
 	//gather txs for this address and format them for interface
 	for _, v := range tmp {
 		tmpTx := blockexplorer.IRawAddrTx{
-			Hash:          v.Txid,
-			LockTime:      v.Locktime,
-			Size:          v.Size,
-			Time:          v.Time,
-			Version:       v.Version,
-			Confirmations: v.Confirmations,
+			Hash:     v.Txid,
+			LockTime: v.Locktime,
+			Size:     v.Size,
+			Time:     v.Time,
+			Version:  v.Version,
 		}
+		//the raw address txs have no block height, it is recovered from
+		//the confirmations
+		tmpTx.BlockHeight = best.BlockHeight(v.Confirmations)
+		tmpTx.Confirmations = best.Confirmations(tmpTx.BlockHeight)
 		var tmpInputs []blockexplorer.IRawAddrInput
 		for _, w := range v.Vin {
 
@@ -190,7 +205,7 @@ func (c *DCRData) GetTxsForAddress(address string, limit int, viewKey string) (t
 				//Witness: w.Witness,
 			}
 
-			tmpAmount, err := idaemon.NewAmount(w.Amountin)
+			tmpAmount, err := blockexplorer.AmountFromCoin(w.Amountin, blockexplorer.DecimalsBTC)
 			if err != nil {
 				return nil, err
 			}
@@ -211,7 +226,7 @@ func (c *DCRData) GetTxsForAddress(address string, limit int, viewKey string) (t
 		var tmpOuputs []blockexplorer.IRawAddrOutput
 		for _, w := range v.Vout {
 
-			tmpAmount, err := idaemon.NewAmount(w.Value)
+			tmpAmount, err := blockexplorer.AmountFromCoin(w.Value, blockexplorer.DecimalsBTC)
 			if err != nil {
 				return nil, err
 			}
@@ -236,7 +251,7 @@ func (c *DCRData) GetTxsForAddress(address string, limit int, viewKey string) (t
 }
 
 // PushTx pushed a raw tx hash to mainnet
-func (c *DCRData) PushTx(txhash string) (res string, err error) {
+func (c *DCRData) PushTx(ctx context.Context, txhash string) (res string, err error) {
 	err = errors.New("dcrdata:error: pushtx is not available yet... ")
 	/* payload, err := json.Marshal(PushTxRequest{Event: "sendtx", Message: txhash})
 	if err != nil {
@@ -254,20 +269,20 @@ func (c *DCRData) PushTx(txhash string) (res string, err error) {
 }
 
 // VerifyTransaction verifies transaction based on values passed in (params: txid, address, amount, createdAt )
-func (c *DCRData) VerifyTransaction(verifier blockexplorer.TxVerifyRequest) (tx *blockexplorer.ITransaction, err error) {
+func (c *DCRData) VerifyTransaction(ctx context.Context, verifier blockexplorer.TxVerifyRequest) (tx *blockexplorer.ITransaction, err error) {
 	tx = new(blockexplorer.ITransaction)
 	if verifier.Address == "" {
 		err = errors.New(LIBNAME + ":error: address is blank so tx cannot be verified")
 		return
 	}
-	if verifier.Amount == 0 {
-		errStr := fmt.Sprintf(LIBNAME+":error: amount is %.8f so tx cannot be verified", verifier.Amount)
+	if verifier.Amount.IsZero() {
+		errStr := fmt.Sprintf(LIBNAME+":error: amount is %s so tx cannot be verified", verifier.Amount)
 		err = errors.New(errStr)
 		return
 	}
 
 	if verifier.TxId != "" && verifier.Address != "" { //verify tx if txid is available
-		txInfo, err := c.GetTransaction(verifier.TxId)
+		txInfo, err := c.GetTransaction(ctx, verifier.TxId)
 		if err != nil {
 			errStr := fmt.Sprintf("%s", err.Error())
 			err = errors.New(errStr)
@@ -288,17 +303,8 @@ func (c *DCRData) VerifyTransaction(verifier blockexplorer.TxVerifyRequest) (tx
 						return tx, err
 					}
 
-					orderedAmount, err := idaemon.NewAmount(verifier.Amount)
-					if err != nil {
-						errStr := fmt.Sprintf(LIBNAME+":error: orderedAmount %s", err.Error())
-						err = errors.New(errStr)
-						return tx, err
-					}
-					var missingAmount idaemon.Amount
-					missingAmount = v.Value - orderedAmount
-					if v.Value == orderedAmount {
-						missingAmount = 0
-					}
+					orderedAmount := verifier.Amount
+					missingAmount := v.Value.Sub(orderedAmount)
 					missingPercent := (missingAmount.ToCoin() / v.Value.ToCoin()) * 100
 
 					tx = txInfo
@@ -316,7 +322,7 @@ func (c *DCRData) VerifyTransaction(verifier blockexplorer.TxVerifyRequest) (tx
 			}
 		}
 	} else if verifier.Address != "" && verifier.CreatedAt > 0 { //verify tx on blockchain based on address history for address var
-		txInfo, err := c.GetTxsForAddress(verifier.Address, 10, "")
+		txInfo, err := c.GetTxsForAddress(ctx, verifier.Address, 10, "")
 		if err != nil {
 			errStr := fmt.Sprintf(LIBNAME+":error: %s", err.Error())
 			err = errors.New(errStr)
@@ -338,17 +344,9 @@ func (c *DCRData) VerifyTransaction(verifier blockexplorer.TxVerifyRequest) (tx
 							return tx, err
 						}
 
-						orderedAmount, err := idaemon.NewAmount(verifier.Amount)
-						if err != nil {
-							err = fmt.Errorf(LIBNAME+":error: orderedAmount %s", err.Error())
-							return tx, err
-						}
+						orderedAmount := verifier.Amount
 
-						var missingAmount idaemon.Amount
-						missingAmount = v.Value - orderedAmount
-						if v.Value == orderedAmount {
-							missingAmount = 0
-						}
+						missingAmount := v.Value.Sub(orderedAmount)
 						missingPercent := (missingAmount.ToCoin() / v.Value.ToCoin()) * 100
 
 						tx = &blockexplorer.ITransaction{
@@ -405,7 +403,7 @@ func (c *DCRData) VerifyTransaction(verifier blockexplorer.TxVerifyRequest) (tx
 		}
 
 	} else {
-		err := fmt.Errorf(LIBNAME+":error: vars passed for verification cannot be checked: \ntxid %s address: %s amount %.8f createdAt %v",
+		err := fmt.Errorf(LIBNAME+":error: vars passed for verification cannot be checked: \ntxid %s address: %s amount %s createdAt %v",
 			verifier.TxId, verifier.Address, verifier.Amount, verifier.CreatedAt)
 		return tx, err
 	}
@@ -413,9 +411,26 @@ func (c *DCRData) VerifyTransaction(verifier blockexplorer.TxVerifyRequest) (tx
 	return
 }
 
+// GetBestBlock returns the best block from explorer.dcrdata.org/api
+func (c *DCRData) GetBestBlock(ctx context.Context) (best *blockexplorer.BestBlock, err error) {
+	r, err := c.client.Do(ctx, "GET", "block/best", "", false)
+	if err != nil {
+		return
+	}
+	var tmp BestBlock
+	if err = json.Unmarshal(r, &tmp); err != nil {
+		return
+	}
+	return &blockexplorer.BestBlock{
+		Height: tmp.Height,
+		Hash:   tmp.Hash,
+		Time:   tmp.Time,
+	}, nil
+}
+
 // GetTransaction returns decoded transaction from explorer.dcrdata.org/api
-func (c *DCRData) GetDecodedTransaction(txid string) (tx DecodedTransaction, err error) {
-	r, err := c.client.Do("GET", "tx/decoded/"+txid, "", false)
+func (c *DCRData) GetDecodedTransaction(ctx context.Context, txid string) (tx DecodedTransaction, err error) {
+	r, err := c.client.Do(ctx, "GET", "tx/decoded/"+txid, "", false)
 	if err != nil {
 		return
 	}
@@ -65,6 +65,12 @@ type Transaction struct {
 	Vout          []VOUT `json:"vout"`
 }
 
+type BestBlock struct {
+	Hash   string `json:"hash"`
+	Height int    `json:"height"`
+	Time   int    `json:"time"`
+}
+
 type RawAddrTx struct {
 	Blockhash     string          `json:"blockhash"`
 	Blocktime     int             `json:"blocktime"`
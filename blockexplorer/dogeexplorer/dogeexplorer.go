@@ -1,15 +1,15 @@
 package dogeexplorer
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"github.com/crypto-power/instantswap/blockexplorer"
 	"github.com/crypto-power/instantswap/blockexplorer/global/clients/blockexplorerclient"
-	"github.com/crypto-power/instantswap/blockexplorer/global/interfaces/idaemon"
 )
 
 func init() {
-	blockexplorer.RegisterExplorer("DOGE", "", func(config blockexplorer.Config) (blockexplorer.IBlockExplorer, error) {
+	blockexplorer.RegisterExplorerCtx("DOGE", "", func(config blockexplorer.Config) (blockexplorer.IBlockExplorerCtx, error) {
 		return New(config), nil
 	})
 }
@@ -28,31 +28,32 @@ type dogeExplorer struct {
 
 // New return an IBlockExplorer interface
 func New(config blockexplorer.Config) *dogeExplorer {
-	client := blockexplorerclient.NewClient(API_BASE, LIBNAME, config.EnableOutput, nil)
+	client := blockexplorerclient.NewClient(config.BaseURLOrDefault(API_BASE), LIBNAME, config.EnableOutput, nil)
+	client.SetHTTPClient(config.HTTPClientOrDefault())
 	return &dogeExplorer{client: client, conf: config}
 }
-func (d *dogeExplorer) VerifyByAddress(req blockexplorer.AddressVerifyRequest) (vr *blockexplorer.VerifyResult, err error) {
-	txs, err := d.getTxsForAddress(req.Address)
+func (d *dogeExplorer) VerifyByAddress(ctx context.Context, req blockexplorer.AddressVerifyRequest) (vr *blockexplorer.VerifyResult, err error) {
+	orderedAmount := req.Amount
+	txs, err := d.getTxsForAddress(ctx, req.Address)
 	for _, tx := range txs {
-		if tx.Value == req.Amount {
+		value, _ := blockexplorer.AmountFromCoin(tx.Value, blockexplorer.DecimalsBTC)
+		if value.Cmp(orderedAmount) == 0 {
 			return &blockexplorer.VerifyResult{
 				Seen:                true,
 				Verified:            true,
-				OrderedAmount:       req.Amount,
-				BlockExplorerAmount: tx.Value,
-				MissingAmount:       0,
-				MissingPercent:      0,
+				OrderedAmount:       orderedAmount,
+				BlockExplorerAmount: value,
 			}, nil
 		}
 	}
 	return nil, fmt.Errorf("not found")
 }
-func (d *dogeExplorer) GetTransaction(txId string) (tx *blockexplorer.ITransaction, err error) {
+func (d *dogeExplorer) GetTransaction(ctx context.Context, txId string) (tx *blockexplorer.ITransaction, err error) {
 	var response = struct {
 		Res
 		Tx Transaction `json:"transaction"`
 	}{}
-	r, err := d.client.Do("GET", fmt.Sprintf("transaction/%s", txId), "", false)
+	r, err := d.client.Do(ctx, "GET", fmt.Sprintf("transaction/%s", txId), "", false)
 	if err != nil {
 		return nil, err
 	}
@@ -63,14 +64,23 @@ func (d *dogeExplorer) GetTransaction(txId string) (tx *blockexplorer.ITransacti
 	if response.Success == 0 {
 		return nil, fmt.Errorf(response.Error)
 	}
-	return response.Tx.tx(), nil
+	best, err := d.GetBestBlock(ctx)
+	if err != nil {
+		return nil, err
+	}
+	tx = response.Tx.tx()
+	//the transaction has no block height, it is recovered from the
+	//confirmations
+	tx.BlockHeight = best.BlockHeight(response.Tx.Confirmations)
+	tx.SetConfirmations(*best)
+	return tx, nil
 }
-func (d *dogeExplorer) getTxsForAddress(address string) (txs []TxForAddress, err error) {
+func (d *dogeExplorer) getTxsForAddress(ctx context.Context, address string) (txs []TxForAddress, err error) {
 	var response = struct {
 		Res
 		Txs []TxForAddress `json:"transactions"`
 	}{}
-	r, err := d.client.Do("GET", fmt.Sprintf("address/transactions/%s", address), "", false)
+	r, err := d.client.Do(ctx, "GET", fmt.Sprintf("address/transactions/%s", address), "", false)
 	if err != nil {
 		return nil, err
 	}
@@ -80,9 +90,13 @@ func (d *dogeExplorer) getTxsForAddress(address string) (txs []TxForAddress, err
 	}
 	return response.Txs, err
 }
-func (d *dogeExplorer) GetTxsForAddress(address string, limit int, viewKey string) (tx *blockexplorer.IRawAddrResponse, err error) {
+func (d *dogeExplorer) GetTxsForAddress(ctx context.Context, address string, limit int, viewKey string) (tx *blockexplorer.IRawAddrResponse, err error) {
 	tx = &blockexplorer.IRawAddrResponse{}
-	txs, err := d.getTxsForAddress(address)
+	txs, err := d.getTxsForAddress(ctx, address)
+	if err != nil {
+		return nil, err
+	}
+	best, err := d.GetBestBlock(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -108,12 +122,13 @@ func (d *dogeExplorer) GetTxsForAddress(address string, limit int, viewKey strin
 		}
 	}
 	tx.Txs = txsRaw
-	return nil, err
+	tx.SetConfirmations(*best)
+	return tx, nil
 }
 
 // VerifyTransaction verifies transaction based on values passed in
-func (d *dogeExplorer) VerifyTransaction(verifier blockexplorer.TxVerifyRequest) (tx *blockexplorer.ITransaction, err error) {
-	tx, err = d.GetTransaction(verifier.TxId)
+func (d *dogeExplorer) VerifyTransaction(ctx context.Context, verifier blockexplorer.TxVerifyRequest) (tx *blockexplorer.ITransaction, err error) {
+	tx, err = d.GetTransaction(ctx, verifier.TxId)
 	if err != nil {
 		return nil, err
 	}
@@ -121,17 +136,56 @@ func (d *dogeExplorer) VerifyTransaction(verifier blockexplorer.TxVerifyRequest)
 		if output.Addresses[0] == verifier.Address {
 			tx.Seen = true
 			tx.Verified = tx.Confirmations > verifier.Confirms
-			orderedAmount, _ := idaemon.NewAmount(verifier.Amount)
+			orderedAmount := verifier.Amount
 			tx.OrderedAmount = orderedAmount
 			tx.BlockExplorerAmount = output.Value
-			tx.MissingAmount = orderedAmount - output.Value
+			tx.MissingAmount = orderedAmount.Sub(output.Value)
 			tx.MissingPercent = (tx.MissingAmount.ToCoin() / orderedAmount.ToCoin()) * 100
 		}
 	}
 	return tx, err
 }
 
+// GetBestBlock returns the best block of the chain
+func (d *dogeExplorer) GetBestBlock(ctx context.Context) (*blockexplorer.BestBlock, error) {
+	var bestHash = struct {
+		Res
+		Hash string `json:"hash"`
+	}{}
+	r, err := d.client.Do(ctx, "GET", "block/besthash", "", false)
+	if err != nil {
+		return nil, err
+	}
+	err = json.Unmarshal(r, &bestHash)
+	if err != nil {
+		return nil, err
+	}
+	if bestHash.Success == 0 {
+		return nil, fmt.Errorf(bestHash.Error)
+	}
+	var response = struct {
+		Res
+		Block Block `json:"block"`
+	}{}
+	r, err = d.client.Do(ctx, "GET", fmt.Sprintf("block/%s", bestHash.Hash), "", false)
+	if err != nil {
+		return nil, err
+	}
+	err = json.Unmarshal(r, &response)
+	if err != nil {
+		return nil, err
+	}
+	if response.Success == 0 {
+		return nil, fmt.Errorf(response.Error)
+	}
+	return &blockexplorer.BestBlock{
+		Height: response.Block.Height,
+		Hash:   response.Block.Hash,
+		Time:   response.Block.Time,
+	}, nil
+}
+
 // PushTx pushes a raw tx hash
-func (d *dogeExplorer) PushTx(rawTxHash string) (result string, err error) {
+func (d *dogeExplorer) PushTx(ctx context.Context, rawTxHash string) (result string, err error) {
 	return "", fmt.Errorf("not supported")
 }
@@ -1,9 +1,6 @@
 package dogeexplorer
 
-import (
-	"github.com/crypto-power/instantswap/blockexplorer"
-	"github.com/crypto-power/instantswap/blockexplorer/global/interfaces/idaemon"
-)
+import "github.com/crypto-power/instantswap/blockexplorer"
 
 type Transaction struct {
 	Hash          string      `json:"hash"`
@@ -53,6 +50,12 @@ type Input struct {
 	} `json:"previous_output"`
 }
 
+type Block struct {
+	Hash   string `json:"hash"`
+	Height int    `json:"height"`
+	Time   int    `json:"time"`
+}
+
 type Res struct {
 	Error   string `json:"error"`
 	Success int    `json:"success"`
@@ -61,7 +64,7 @@ type Res struct {
 func (tx *Transaction) inputs() []blockexplorer.IVIN {
 	var inputs []blockexplorer.IVIN
 	for _, input := range tx.Inputs {
-		amount, _ := idaemon.NewAmount(input.Value)
+		amount, _ := blockexplorer.AmountFromCoin(input.Value, blockexplorer.DecimalsBTC)
 		inputs = append(inputs, blockexplorer.IVIN{
 			Script:      input.ScriptSig.Hex,
 			Sequence:    0,
@@ -79,7 +82,7 @@ func (tx *Transaction) inputs() []blockexplorer.IVIN {
 func (tx *Transaction) outputs() []blockexplorer.IVOUT {
 	var outputs []blockexplorer.IVOUT
 	for _, output := range tx.Outputs {
-		amount, _ := idaemon.NewAmount(output.Value)
+		amount, _ := blockexplorer.AmountFromCoin(output.Value, blockexplorer.DecimalsBTC)
 		outputs = append(outputs, blockexplorer.IVOUT{
 			Addresses: []string{
 				output.Address,
@@ -114,12 +117,8 @@ func (tx *Transaction) tx() *blockexplorer.ITransaction {
 		Weight:        0,
 		Confirmations: tx.Confirmations,
 		// verification: ignore
-		Seen:                false,
-		Verified:            false,
-		OrderedAmount:       0,
-		BlockExplorerAmount: 0,
-		MissingAmount:       0,
-		MissingPercent:      0,
+		Seen:     false,
+		Verified: false,
 	}
 }
 
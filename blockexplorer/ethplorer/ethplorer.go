@@ -1,15 +1,14 @@
 package ethplorer
 
 import (
+	"context"
 	"fmt"
 	"github.com/crypto-power/instantswap/blockexplorer/global/utils"
-	"math"
 	"net/http"
 	"strings"
 
 	"github.com/crypto-power/instantswap/blockexplorer"
 	"github.com/crypto-power/instantswap/blockexplorer/global/clients/blockexplorerclient"
-	"github.com/crypto-power/instantswap/blockexplorer/global/interfaces/idaemon"
 )
 
 const (
@@ -18,7 +17,7 @@ const (
 )
 
 func init() {
-	blockexplorer.RegisterExplorer("", blockexplorer.NetworkTypeErc20, func(config blockexplorer.Config) (blockexplorer.IBlockExplorer, error) {
+	blockexplorer.RegisterExplorerCtx("", blockexplorer.NetworkTypeErc20, func(config blockexplorer.Config) (blockexplorer.IBlockExplorerCtx, error) {
 		return New(config)
 	})
 }
@@ -29,17 +28,18 @@ type etherScan struct {
 }
 
 func New(conf blockexplorer.Config) (*etherScan, error) {
-	client := blockexplorerclient.NewClient(API_BASE, LIBNAME, conf.EnableOutput, func(r *http.Request) {
+	client := blockexplorerclient.NewClient(conf.BaseURLOrDefault(API_BASE), LIBNAME, conf.EnableOutput, func(r *http.Request) {
 
 	})
+	client.SetHTTPClient(conf.HTTPClientOrDefault())
 	return &etherScan{
 		client: client,
 		conf:   &conf,
 	}, nil
 }
 
-func (e *etherScan) VerifyByAddress(req blockexplorer.AddressVerifyRequest) (vr *blockexplorer.VerifyResult, err error) {
-	txs, err := e.getTxsForAddress(req.Address)
+func (e *etherScan) VerifyByAddress(ctx context.Context, req blockexplorer.AddressVerifyRequest) (vr *blockexplorer.VerifyResult, err error) {
+	txs, err := e.getTxsForAddress(ctx, req.Address)
 	if err != nil {
 		return nil, err
 	}
@@ -49,15 +49,20 @@ func (e *etherScan) VerifyByAddress(req blockexplorer.AddressVerifyRequest) (vr
 			if operation.TokenInfo.Symbol == symbol &&
 				strings.ToLower(operation.To) == strings.ToLower(req.Address) {
 
-				explorerAmount := operation.value()
-				if utils.ApproximateCompare(explorerAmount, req.Amount) {
+				explorerAmount, err := operation.amount()
+				if err != nil {
+					return nil, err
+				}
+				if utils.ApproximateCompare(explorerAmount.ToCoin(), req.Amount.ToCoin()) {
+					orderedAmount := req.Amount
+					missingAmount := orderedAmount.Sub(explorerAmount)
 					return &blockexplorer.VerifyResult{
 						Seen:                true,
 						Verified:            true,
-						OrderedAmount:       req.Amount,
+						OrderedAmount:       orderedAmount,
 						BlockExplorerAmount: explorerAmount,
-						MissingAmount:       req.Amount - explorerAmount,
-						MissingPercent:      (req.Amount - explorerAmount) / req.Amount,
+						MissingAmount:       missingAmount,
+						MissingPercent:      missingAmount.ToCoin() / orderedAmount.ToCoin(),
 					}, nil
 				}
 			}
@@ -66,8 +71,8 @@ func (e *etherScan) VerifyByAddress(req blockexplorer.AddressVerifyRequest) (vr
 	return nil, fmt.Errorf("not found")
 }
 
-func (e *etherScan) getTx(txId string) (*Tx, error) {
-	r, err := e.client.Do("GET", fmt.Sprintf("getTxInfo/%s?apiKey=freekey", txId), "", false)
+func (e *etherScan) getTx(ctx context.Context, txId string) (*Tx, error) {
+	r, err := e.client.Do(ctx, "GET", fmt.Sprintf("getTxInfo/%s?apiKey=freekey", txId), "", false)
 	if err != nil {
 		return nil, err
 	}
@@ -76,16 +81,25 @@ func (e *etherScan) getTx(txId string) (*Tx, error) {
 	return &ethTx, err
 }
 
-func (e *etherScan) GetTransaction(txId string) (tx *blockexplorer.ITransaction, err error) {
-	ethTx, err := e.getTx(txId)
+func (e *etherScan) GetTransaction(ctx context.Context, txId string) (tx *blockexplorer.ITransaction, err error) {
+	ethTx, err := e.getTx(ctx, txId)
+	if err != nil {
+		return nil, err
+	}
+	best, err := e.GetBestBlock(ctx)
+	if err != nil {
+		return nil, err
+	}
+	tx, err = e.generalTx(ethTx)
 	if err != nil {
 		return nil, err
 	}
-	return e.generalTx(ethTx)
+	tx.SetConfirmations(*best)
+	return tx, nil
 }
 
-func (e *etherScan) getTxsForAddress(address string) (txs []TxOperation, err error) {
-	r, err := e.client.Do("GET", fmt.Sprintf("getAddressHistory/%s?apiKey=freekey", address), "", false)
+func (e *etherScan) getTxsForAddress(ctx context.Context, address string) (txs []TxOperation, err error) {
+	r, err := e.client.Do(ctx, "GET", fmt.Sprintf("getAddressHistory/%s?apiKey=freekey", address), "", false)
 	if err != nil {
 		return nil, err
 	}
@@ -98,8 +112,8 @@ func (e *etherScan) getTxsForAddress(address string) (txs []TxOperation, err err
 	}
 	return addrInfo.Operations, nil
 }
-func (e *etherScan) GetTxsForAddress(address string, limit int, viewKey string) (tx *blockexplorer.IRawAddrResponse, err error) {
-	txs, err := e.getTxsForAddress(address)
+func (e *etherScan) GetTxsForAddress(ctx context.Context, address string, limit int, viewKey string) (tx *blockexplorer.IRawAddrResponse, err error) {
+	txs, err := e.getTxsForAddress(ctx, address)
 	if err != nil {
 		return nil, err
 	}
@@ -116,8 +130,10 @@ func (e *etherScan) GetTxsForAddress(address string, limit int, viewKey string)
 		var symbol = strings.ToUpper(e.conf.Symbol)
 		for _, operation := range txs {
 			if operation.TokenInfo.Symbol == symbol {
-				explorerAmount := operation.value()
-				amount, _ := idaemon.NewAmount(explorerAmount)
+				amount, err := operation.amount()
+				if err != nil {
+					return nil, err
+				}
 				tx.Txs = append(tx.Txs, blockexplorer.IRawAddrTx{
 					BlockHeight: 0,
 					Hash:        operation.TransactionHash,
@@ -138,26 +154,43 @@ func (e *etherScan) GetTxsForAddress(address string, limit int, viewKey string)
 					VinSz:         0,
 					VoutSz:        0,
 					Weight:        0,
-					Confirmations: 0,
+					Confirmations: 0, //the address history has no block numbers
 				})
 			}
 		}
 	}
-	return nil, nil
+	return tx, nil
 }
-func (e *etherScan) VerifyTransaction(verifier blockexplorer.TxVerifyRequest) (tx *blockexplorer.ITransaction, err error) {
-	ethTx, err := e.getTx(verifier.TxId)
+
+// GetBestBlock returns the last block, ethplorer only returns its number.
+func (e *etherScan) GetBestBlock(ctx context.Context) (*blockexplorer.BestBlock, error) {
+	r, err := e.client.Do(ctx, "GET", "getLastBlock?apiKey=freekey", "", false)
 	if err != nil {
 		return nil, err
 	}
-	tx, err = e.generalTx(ethTx)
+	var lastBlock struct {
+		LastBlock int `json:"lastBlock"`
+	}
+	err = parse(r, &lastBlock)
+	if err != nil {
+		return nil, err
+	}
+	return &blockexplorer.BestBlock{Height: lastBlock.LastBlock}, nil
+}
+func (e *etherScan) VerifyTransaction(ctx context.Context, verifier blockexplorer.TxVerifyRequest) (tx *blockexplorer.ITransaction, err error) {
+	ethTx, err := e.getTx(ctx, verifier.TxId)
 	if err != nil {
 		return nil, err
 	}
-	orderedAmount, err := idaemon.NewAmount(verifier.Amount)
+	best, err := e.GetBestBlock(ctx)
 	if err != nil {
 		return nil, err
 	}
+	tx, err = e.generalTx(ethTx)
+	if err != nil {
+		return nil, err
+	}
+	tx.SetConfirmations(*best)
 	tx.Seen = verifier.Address == ethTx.To
 	tx.Verified = verifier.Address != ethTx.To
 	if e.conf.Type == blockexplorer.NetworkTypeErc20 {
@@ -166,17 +199,19 @@ func (e *etherScan) VerifyTransaction(verifier blockexplorer.TxVerifyRequest) (t
 		for _, operation := range ethTx.Operations {
 			if operation.TokenInfo.Symbol == symbol && operation.Type == "transfer" {
 				found = true
-				tx.OrderedAmount = orderedAmount
-				explorerAmount := float64(operation.IntValue) / math.Pow(10, float64(operation.TokenInfo.Decimals))
-				tx.BlockExplorerAmount, _ = idaemon.NewAmount(explorerAmount)
-				tx.MissingAmount = tx.OrderedAmount - tx.BlockExplorerAmount
-				tx.MissingPercent = 100 * float64(tx.MissingAmount) / float64(tx.OrderedAmount)
+				tx.OrderedAmount = verifier.Amount
+				tx.BlockExplorerAmount, err = operation.amount()
+				if err != nil {
+					return nil, err
+				}
+				tx.MissingAmount = tx.OrderedAmount.Sub(tx.BlockExplorerAmount)
+				tx.MissingPercent = 100 * tx.MissingAmount.ToCoin() / tx.OrderedAmount.ToCoin()
 			}
 		}
 		tx.Verified = found
 	}
 	return tx, nil
 }
-func (e *etherScan) PushTx(rawTxHash string) (result string, err error) {
+func (e *etherScan) PushTx(ctx context.Context, rawTxHash string) (result string, err error) {
 	return "", fmt.Errorf("not supported")
 }
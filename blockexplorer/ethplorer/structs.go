@@ -2,7 +2,6 @@ package ethplorer
 
 import (
 	"fmt"
-	"math"
 	"math/big"
 	"strings"
 
@@ -37,7 +36,9 @@ func (b *BigFloat) UnmarshalJSON(p []byte) error {
 	if string(p) == "null" {
 		return nil
 	}
+	// The precision holds the integer values of 18 decimals tokens exactly.
 	var z big.Float
+	z.SetPrec(256)
 	var num = string(p)[1 : len(p)-1]
 	_, ok := z.SetString(num)
 	if !ok {
@@ -62,12 +63,14 @@ type TxOperation struct {
 	TokenInfo       TokenInfo `json:"tokenInfo"`
 }
 
-func (t *TxOperation) value() float64 {
-	dec := big.NewFloat(math.Pow(10, -float64(t.TokenInfo.Decimals)))
-	val := big.Float{}
-	val.Mul(&t.Value.Float, dec)
-	fVal, _ := val.Float64()
-	return fVal
+// amount returns the value of the operation, counted in base units of the
+// token.
+func (t *TxOperation) amount() (blockexplorer.Amount, error) {
+	units, accuracy := t.Value.Int(nil)
+	if accuracy != big.Exact {
+		return blockexplorer.Amount{}, fmt.Errorf("invalid operation value %s", t.Value.String())
+	}
+	return blockexplorer.NewAmount(units, t.TokenInfo.Decimals), nil
 }
 
 type TxLog struct {
@@ -108,27 +111,23 @@ type TokenInfo struct {
 
 func (e *etherScan) generalTx(ethTx *Tx) (*blockexplorer.ITransaction, error) {
 	var tx = &blockexplorer.ITransaction{
-		BlockHeight:         ethTx.BlockNumber,
-		DoubleSpend:         false,
-		Hash:                ethTx.Hash,
-		Inputs:              nil,
-		LockTime:            0,
-		Outputs:             nil,
-		Rbf:                 false,
-		Size:                0,
-		Time:                ethTx.Timestamp,
-		TxIndex:             0,
-		Version:             0,
-		VinSz:               0,
-		VoutSz:              0,
-		Weight:              0,
-		Confirmations:       ethTx.Confirmations,
-		Seen:                false,
-		Verified:            false,
-		OrderedAmount:       0,
-		BlockExplorerAmount: 0,
-		MissingAmount:       0,
-		MissingPercent:      0,
+		BlockHeight:   ethTx.BlockNumber,
+		DoubleSpend:   false,
+		Hash:          ethTx.Hash,
+		Inputs:        nil,
+		LockTime:      0,
+		Outputs:       nil,
+		Rbf:           false,
+		Size:          0,
+		Time:          ethTx.Timestamp,
+		TxIndex:       0,
+		Version:       0,
+		VinSz:         0,
+		VoutSz:        0,
+		Weight:        0,
+		Confirmations: 0,
+		Seen:          false,
+		Verified:      false,
 	}
 	if e.conf.Type == blockexplorer.NetworkTypeErc20 {
 		var symbol = strings.ToUpper(e.conf.Symbol)
@@ -140,7 +139,6 @@ func (e *etherScan) generalTx(ethTx *Tx) (*blockexplorer.ITransaction, error) {
 					TxID:        ethTx.Hash,
 					VOUT:        0,
 					Tree:        0,
-					AmountIn:    0,
 					BlockIndex:  0,
 					BlockHeight: 0,
 				})
@@ -1,6 +1,7 @@
 package blockexplorerclient
 
 import (
+	"context"
 	"fmt"
 	"io/ioutil"
 	"log"
@@ -42,6 +43,12 @@ func NewClient(apiBase, apiSecret string, enableOutput bool, handleRequest Handl
 		handleRequest:  handleRequest,
 	}
 }
+
+// SetHTTPClient sets the client sending the requests.
+func (c *Client) SetHTTPClient(httpClient *http.Client) {
+	c.httpClient = httpClient
+}
+
 func (c Client) dumpRequest(r *http.Request) {
 	if r == nil {
 		log.Print("dumpReq ok: <nil>")
@@ -68,38 +75,25 @@ func (c Client) dumpResponse(r *http.Response) {
 	}
 }
 
-// doTimeoutRequest do a HTTP request with timeout
-func (c *Client) doTimeoutRequest(timer *time.Timer, req *http.Request) (*http.Response, error) {
-	// Do the request in the background so we can check the timeout
-	type result struct {
-		resp *http.Response
-		err  error
-	}
-	done := make(chan result, 1)
-	go func() {
-		if c.Debug {
-			c.dumpRequest(req)
-		}
-		resp, err := c.httpClient.Do(req)
-		if c.Debug {
-			c.dumpResponse(resp)
-		}
-		done <- result{resp, err}
-	}()
-	// Wait for the read or the timeout
-	select {
-	case r := <-done:
-		return r.resp, r.err
-	case <-timer.C:
-		errTimeout := &errors.Error{Err: errors.New("timeout on reading data from " + c.libName + " API"), Kind: 8}
-		return nil, errTimeout
+func (c *Client) doRequest(req *http.Request) (*http.Response, error) {
+	if c.Debug {
+		c.dumpRequest(req)
+	}
+	resp, err := c.httpClient.Do(req)
+	if c.Debug {
+		c.dumpResponse(resp)
 	}
+	return resp, err
 }
 
-// Do do prepare and process HTTP request to API
-func (c *Client) Do(method, path string, payload interface{}, authNeeded bool) (response []byte, err error) {
-	var connectTimer *time.Timer
-	//connectTimer := time.NewTimer(DEFAULT_HTTPCLIENT_TIMEOUT * time.Second)
+// Do do prepare and process HTTP request to API. The request is bound to ctx,
+// if ctx has no deadline the default client timeout is applied.
+func (c *Client) Do(ctx context.Context, method, path string, payload interface{}, authNeeded bool) (response []byte, err error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, defaultHttpClientTimeout*time.Second)
+		defer cancel()
+	}
 	var rawUrl string
 	if strings.HasPrefix(path, "http") {
 		rawUrl = path
@@ -109,6 +103,7 @@ func (c *Client) Do(method, path string, payload interface{}, authNeeded bool) (
 	var req *http.Request
 
 	reqInfo := AuthInfo{
+		ctx:      ctx,
 		exchange: c.libName,
 		c:        c,
 		method:   method,
@@ -123,15 +118,17 @@ func (c *Client) Do(method, path string, payload interface{}, authNeeded bool) (
 		c.handleRequest(reqResult.request)
 	}
 	req = reqResult.request
-	connectTimer = reqResult.connectTimer
 
 	if req == nil {
 		err = errors.New("blockexplorerclient error: request was nil")
 		return nil, err
 	}
 
-	resp, err := c.doTimeoutRequest(connectTimer, req)
+	resp, err := c.doRequest(req)
 	if err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			err = &errors.Error{Err: fmt.Errorf("timeout on reading data from %s API: %w", c.libName, ctx.Err()), Kind: errors.Timeout}
+		}
 		return
 	}
 
@@ -167,8 +164,7 @@ func (c *Client) Do(method, path string, payload interface{}, authNeeded bool) (
 }
 
 func getRequestType(info AuthInfo) (result AuthInfo, err error) {
-	result.connectTimer = time.NewTimer(defaultHttpClientTimeout * time.Second)
-	req, err := http.NewRequest(info.method, info.url, strings.NewReader(info.payload.(string)))
+	req, err := http.NewRequestWithContext(info.ctx, info.method, info.url, strings.NewReader(info.payload.(string)))
 	if err != nil {
 		return result, err
 	}
@@ -192,12 +188,12 @@ func sleepPrint(dur int) {
 }
 
 type AuthInfo struct {
-	exchange     string
-	c            *Client
-	request      *http.Request
-	payload      interface{}
-	method       string
-	url          string
-	resource     string //only used for coinswitch right now
-	connectTimer *time.Timer
+	ctx      context.Context
+	exchange string
+	c        *Client
+	request  *http.Request
+	payload  interface{}
+	method   string
+	url      string
+	resource string //only used for coinswitch right now
 }
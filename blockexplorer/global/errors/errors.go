@@ -8,10 +8,6 @@ import (
 	"strings"
 )
 
-type error interface {
-	Error() string
-}
-
 // Kind describes the class of error.
 type Kind int
 
@@ -104,6 +100,11 @@ func (e *Error) Error() string {
 	return s
 }
 
+// Unwrap returns the wrapped error, for errors.Is and errors.As.
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
 // New creates a simple error from a string.  New is identical to "errors".New
 // from the standard library.
 func New(text string) error {
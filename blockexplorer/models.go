@@ -1,29 +1,27 @@
 package blockexplorer
 
-import "github.com/crypto-power/instantswap/blockexplorer/global/interfaces/idaemon"
-
 type IVIN struct {
 	Script   string `json:"script"`
 	Sequence int    `json:"sequence"`
 	Witness  string `json:"witness"`
 	//only for dcrdata
-	TxID        string         `json:"txid,omitempty"`
-	VOUT        int            `json:"vout,omitempty"`
-	Tree        int            `json:"tree,omitempty"`
-	AmountIn    idaemon.Amount `json:"amountIn,omitempty"`
-	BlockIndex  int            `json:"block_index,omitempty"`
-	BlockHeight int            `json:"block_height,omitempty"`
+	TxID        string `json:"txid,omitempty"`
+	VOUT        int    `json:"vout,omitempty"`
+	Tree        int    `json:"tree,omitempty"`
+	AmountIn    Amount `json:"amountIn,omitempty"`
+	BlockIndex  int    `json:"block_index,omitempty"`
+	BlockHeight int    `json:"block_height,omitempty"`
 }
 type IVOUT struct {
-	Addresses   []string       `json:"addr"`
-	AddrTag     string         `json:"addr_tag,omitempty"`
-	AddrTagLink string         `json:"addr_tag_link,omitempty"`
-	N           int            `json:"n"`
-	Script      string         `json:"script,omitempty"`
-	Spent       bool           `json:"spent,omitempty"`
-	TxIndex     int            `json:"tx_index"`
-	Type        string         `json:"type"`
-	Value       idaemon.Amount `json:"value"`
+	Addresses   []string `json:"addr"`
+	AddrTag     string   `json:"addr_tag,omitempty"`
+	AddrTagLink string   `json:"addr_tag_link,omitempty"`
+	N           int      `json:"n"`
+	Script      string   `json:"script,omitempty"`
+	Spent       bool     `json:"spent,omitempty"`
+	TxIndex     int      `json:"tx_index"`
+	Type        string   `json:"type"`
+	Value       Amount   `json:"value"`
 }
 
 type ITransaction struct {
@@ -41,15 +39,15 @@ type ITransaction struct {
 	VinSz         int     `json:"vin_sz,omitempty"`
 	VoutSz        int     `json:"vout_sz,omitempty"`
 	Weight        int     `json:"weight,omitempty"`
-	Confirmations int     `json:"confirmations"` //calculated from getting latest block - blockheight
+	Confirmations int     `json:"confirmations"` //calculated from the best block, see BestBlock.Confirmations
 
 	//Internal vars for verification purposes
-	Seen                bool           `json:"seen"` //tx has been seen on block explorer but not verified
-	Verified            bool           `json:"verified"`
-	OrderedAmount       idaemon.Amount `json:"ordered_amount"`
-	BlockExplorerAmount idaemon.Amount `json:"blockexplorer_amount"`
-	MissingAmount       idaemon.Amount `json:"missing_amount"`
-	MissingPercent      float64        `json:"missing_percent"`
+	Seen                bool    `json:"seen"` //tx has been seen on block explorer but not verified
+	Verified            bool    `json:"verified"`
+	OrderedAmount       Amount  `json:"ordered_amount"`
+	BlockExplorerAmount Amount  `json:"blockexplorer_amount"`
+	MissingAmount       Amount  `json:"missing_amount"`
+	MissingPercent      float64 `json:"missing_percent"`
 }
 
 type IRawAddrResponse struct {
@@ -92,13 +90,52 @@ type IRawAddrInput struct {
 	Tree int    `json:"tree,omitempty"`
 }
 type IRawAddrOutput struct {
-	Addresses []string       `json:"addr"`
-	N         int            `json:"n"`
-	Script    string         `json:"script,omitempty"`
-	Spent     bool           `json:"spent,omitempty"`
-	TxIndex   int            `json:"tx_index,omitempty"`
-	Type      string         `json:"type,omitempty"`
-	Value     idaemon.Amount `json:"value"`
+	Addresses []string `json:"addr"`
+	N         int      `json:"n"`
+	Script    string   `json:"script,omitempty"`
+	Spent     bool     `json:"spent,omitempty"`
+	TxIndex   int      `json:"tx_index,omitempty"`
+	Type      string   `json:"type,omitempty"`
+	Value     Amount   `json:"value"`
+}
+
+// BestBlock is the tip of the chain seen by an explorer.
+type BestBlock struct {
+	Height int    `json:"height"`
+	Hash   string `json:"hash,omitempty"` //empty when the explorer does not return it
+	Time   int    `json:"time,omitempty"` //unix seconds, 0 when the explorer does not return it
+}
+
+// Confirmations returns the confirmations of a transaction mined in the block
+// at height: 1 in the best block, 0 when it is not mined yet (height <= 0) or
+// above the tip.
+func (b BestBlock) Confirmations(height int) int {
+	if height <= 0 || height > b.Height {
+		return 0
+	}
+	return b.Height - height + 1
+}
+
+// BlockHeight is the reverse of Confirmations, it returns the height of the
+// block of a transaction with confirmations, for the explorers returning only
+// the confirmations. It returns 0 when the transaction is not mined yet.
+func (b BestBlock) BlockHeight(confirmations int) int {
+	if confirmations <= 0 {
+		return 0
+	}
+	return b.Height - confirmations + 1
+}
+
+// SetConfirmations sets the confirmations of the transaction from best.
+func (t *ITransaction) SetConfirmations(best BestBlock) {
+	t.Confirmations = best.Confirmations(t.BlockHeight)
+}
+
+// SetConfirmations sets the confirmations of the transactions from best.
+func (r *IRawAddrResponse) SetConfirmations(best BestBlock) {
+	for i := range r.Txs {
+		r.Txs[i].Confirmations = best.Confirmations(r.Txs[i].BlockHeight)
+	}
 }
 
 type IPushTxResult struct {
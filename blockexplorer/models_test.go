@@ -0,0 +1,42 @@
+package blockexplorer
+
+import "testing"
+
+func TestBestBlockConfirmations(t *testing.T) {
+	best := BestBlock{Height: 100}
+	tests := []struct {
+		height        int
+		confirmations int
+	}{
+		{100, 1},
+		{91, 10},
+		{1, 100},
+		{0, 0},   // unmined
+		{-1, 0},  // unmined, blockcypher and blockchair
+		{101, 0}, // above the tip
+	}
+	for _, test := range tests {
+		if c := best.Confirmations(test.height); c != test.confirmations {
+			t.Errorf("height %d: got %d confirmations, expected %d", test.height, c, test.confirmations)
+		}
+		if test.confirmations > 0 {
+			if h := best.BlockHeight(test.confirmations); h != test.height {
+				t.Errorf("%d confirmations: got height %d, expected %d", test.confirmations, h, test.height)
+			}
+		}
+	}
+	if h := best.BlockHeight(0); h != 0 {
+		t.Errorf("0 confirmations: got height %d, expected 0", h)
+	}
+
+	txs := IRawAddrResponse{Txs: []IRawAddrTx{{BlockHeight: 99}, {BlockHeight: 0}}}
+	txs.SetConfirmations(best)
+	if txs.Txs[0].Confirmations != 2 || txs.Txs[1].Confirmations != 0 {
+		t.Errorf("unexpected confirmations %d, %d", txs.Txs[0].Confirmations, txs.Txs[1].Confirmations)
+	}
+	tx := ITransaction{BlockHeight: 100, Confirmations: 7}
+	tx.SetConfirmations(best)
+	if tx.Confirmations != 1 {
+		t.Errorf("got %d confirmations, expected 1", tx.Confirmations)
+	}
+}
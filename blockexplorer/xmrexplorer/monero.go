@@ -1,6 +1,7 @@
 package xmrexplorer
 
 import (
+	"context"
 	"fmt"
 	"github.com/crypto-power/instantswap/blockexplorer/global/utils"
 
@@ -15,14 +16,15 @@ const (
 )
 
 func init() {
-	blockexplorer.RegisterExplorer("XMR", "", func(conf blockexplorer.Config) (blockexplorer.IBlockExplorer, error) {
+	blockexplorer.RegisterExplorerCtx("XMR", "", func(conf blockexplorer.Config) (blockexplorer.IBlockExplorerCtx, error) {
 		return New(conf), nil
 	})
 }
 
 // New return a instanciate cryptopia struct
 func New(conf blockexplorer.Config) *MoneroExplorer {
-	client := blockexplorerclient.NewClient(API_BASE, LIBNAME, conf.EnableOutput, nil)
+	client := blockexplorerclient.NewClient(conf.BaseURLOrDefault(API_BASE), LIBNAME, conf.EnableOutput, nil)
+	client.SetHTTPClient(conf.HTTPClientOrDefault())
 	return &MoneroExplorer{client: client}
 }
 
@@ -30,50 +32,56 @@ type MoneroExplorer struct {
 	client *blockexplorerclient.Client
 }
 
-func (z *MoneroExplorer) VerifyByAddress(req blockexplorer.AddressVerifyRequest) (vr *blockexplorer.VerifyResult, err error) {
-	r, err := z.client.Do("GET", fmt.Sprintf("outputsblocks?address=%s&viewkey=%s&limit=%d&mempool=1",
+func (z *MoneroExplorer) VerifyByAddress(ctx context.Context, req blockexplorer.AddressVerifyRequest) (vr *blockexplorer.VerifyResult, err error) {
+	r, err := z.client.Do(ctx, "GET", fmt.Sprintf("outputsblocks?address=%s&viewkey=%s&limit=%d&mempool=1",
 		req.Address, req.ViewKey, 5), "", false)
 	fmt.Println(string(r))
 	var outputsBlocks OutputsBlocks
 	if err = parseMoneroResponseData(r, &outputsBlocks); err != nil {
 		return nil, err
 	}
+	orderedAmount := req.Amount
 	for _, output := range outputsBlocks.Outputs {
-		value := float64(output.Amount) / 1e12
-		if utils.ApproximateCompare(value, req.Amount) {
+		value := blockexplorer.AmountFromInt64(output.Amount, blockexplorer.DecimalsXMR)
+		if utils.ApproximateCompare(value.ToCoin(), orderedAmount.ToCoin()) {
+			missingAmount := orderedAmount.Sub(value)
 			return &blockexplorer.VerifyResult{
 				Seen:                true,
 				Verified:            true,
-				OrderedAmount:       req.Amount,
+				OrderedAmount:       orderedAmount,
 				BlockExplorerAmount: value,
-				MissingAmount:       req.Amount - value,
-				MissingPercent:      (req.Amount - value) / req.Amount,
+				MissingAmount:       missingAmount,
+				MissingPercent:      missingAmount.ToCoin() / orderedAmount.ToCoin(),
 			}, nil
 		}
 	}
 	return nil, err
 }
 
-func (z *MoneroExplorer) GetTransaction(txId string) (*blockexplorer.ITransaction, error) {
-	r, err := z.client.Do("GET", fmt.Sprintf("transaction/%s", txId), "", false)
+func (z *MoneroExplorer) GetTransaction(ctx context.Context, txId string) (*blockexplorer.ITransaction, error) {
+	r, err := z.client.Do(ctx, "GET", fmt.Sprintf("transaction/%s", txId), "", false)
 	var tx Transaction
 	if err = parseMoneroResponseData(r, &tx); err != nil {
 		return nil, err
 	}
-	return tx.ITransaction(), nil
+	iTx := tx.ITransaction()
+	iTx.SetConfirmations(bestBlock(tx.CurrentHeight))
+	return iTx, nil
 }
-func (z *MoneroExplorer) GetTxsForAddress(address string, limit int, viewKey string) (account *blockexplorer.IRawAddrResponse, err error) {
-	r, err := z.client.Do("GET", fmt.Sprintf("outputsblocks?address=%s&viewkey=%s&limit=%d&mempool=1", address, viewKey, limit), "", false)
+func (z *MoneroExplorer) GetTxsForAddress(ctx context.Context, address string, limit int, viewKey string) (account *blockexplorer.IRawAddrResponse, err error) {
+	r, err := z.client.Do(ctx, "GET", fmt.Sprintf("outputsblocks?address=%s&viewkey=%s&limit=%d&mempool=1", address, viewKey, limit), "", false)
 	var outputsBlocks OutputsBlocks
 	if err = parseMoneroResponseData(r, &outputsBlocks); err != nil {
 		return nil, err
 	}
-	return outputsBlocks.IRawAddrResponse(), nil
+	account = outputsBlocks.IRawAddrResponse()
+	account.SetConfirmations(bestBlock(outputsBlocks.Height))
+	return account, nil
 }
 
 // VerifyTransaction verifies transaction based on values passed in (params: txid, address (required), amount (required), createdAt(unix timestamp) )
-func (z *MoneroExplorer) VerifyTransaction(verifier blockexplorer.TxVerifyRequest) (tx *blockexplorer.ITransaction, err error) {
-	r, err := z.client.Do("GET", fmt.Sprintf("outputs?txhash=%s&address=%s&viewkey=%s&txprove=0",
+func (z *MoneroExplorer) VerifyTransaction(ctx context.Context, verifier blockexplorer.TxVerifyRequest) (tx *blockexplorer.ITransaction, err error) {
+	r, err := z.client.Do(ctx, "GET", fmt.Sprintf("outputs?txhash=%s&address=%s&viewkey=%s&txprove=0",
 		verifier.TxId, verifier.Address, verifier.ViewKey), "", false)
 	if err != nil {
 		return nil, err
@@ -84,10 +92,49 @@ func (z *MoneroExplorer) VerifyTransaction(verifier blockexplorer.TxVerifyReques
 	if err != nil {
 		return nil, err
 	}
-	return txVerify.ITransaction(verifier), nil
+	best, err := z.GetBestBlock(ctx)
+	if err != nil {
+		return nil, err
+	}
+	tx = txVerify.ITransaction(verifier)
+	//the outputs have no block height, it is recovered from the confirmations
+	tx.BlockHeight = best.BlockHeight(txVerify.TxConfirmations)
+	tx.SetConfirmations(*best)
+	return tx, nil
+}
+
+// GetBestBlock returns the top block of the chain
+func (z *MoneroExplorer) GetBestBlock(ctx context.Context) (*blockexplorer.BestBlock, error) {
+	r, err := z.client.Do(ctx, "GET", "networkinfo", "", false)
+	if err != nil {
+		return nil, err
+	}
+	var networkInfo NetworkInfo
+	if err = parseMoneroResponseData(r, &networkInfo); err != nil {
+		return nil, err
+	}
+	r, err = z.client.Do(ctx, "GET", fmt.Sprintf("block/%s", networkInfo.TopBlockHash), "", false)
+	if err != nil {
+		return nil, err
+	}
+	var block Block
+	if err = parseMoneroResponseData(r, &block); err != nil {
+		return nil, err
+	}
+	return &blockexplorer.BestBlock{
+		Height: block.BlockHeight,
+		Hash:   block.Hash,
+		Time:   block.Timestamp,
+	}, nil
+}
+
+// bestBlock returns the top block of a chain of height blocks, the current
+// height returned along with the transactions.
+func bestBlock(height int) blockexplorer.BestBlock {
+	return blockexplorer.BestBlock{Height: height - 1}
 }
 
 // PushTx pushes a raw tx hash
-func (z *MoneroExplorer) PushTx(rawTxHash string) (result string, err error) {
+func (z *MoneroExplorer) PushTx(ctx context.Context, rawTxHash string) (result string, err error) {
 	return "", fmt.Errorf("%s:error: PushTx is not supported yet... ", LIBNAME)
 }
@@ -1,11 +1,6 @@
 package xmrexplorer
 
-import (
-	"github.com/crypto-power/instantswap/blockexplorer"
-	"github.com/crypto-power/instantswap/blockexplorer/global/interfaces/idaemon"
-)
-
-const XMR_EXTRA_UNIT = 10000
+import "github.com/crypto-power/instantswap/blockexplorer"
 
 type Response struct {
 	Data   interface{} `json:"data"`
@@ -38,6 +33,17 @@ type Transaction struct {
 	XmrOutputs    int      `json:"xmr_outputs"`
 }
 
+type NetworkInfo struct {
+	Height       int    `json:"height"`
+	TopBlockHash string `json:"top_block_hash"`
+}
+
+type Block struct {
+	BlockHeight int    `json:"block_height"`
+	Hash        string `json:"hash"`
+	Timestamp   int    `json:"timestamp"`
+}
+
 type Input struct {
 	Amount   int    `json:"amount"`
 	KeyImage string `json:"key_image"`
@@ -60,7 +66,7 @@ func (i *Input) IVIN() blockexplorer.IVIN {
 		TxID:        "",
 		VOUT:        0,
 		Tree:        0,
-		AmountIn:    idaemon.Amount(i.Amount / XMR_EXTRA_UNIT),
+		AmountIn:    blockexplorer.AmountFromInt64(int64(i.Amount), blockexplorer.DecimalsXMR),
 		BlockIndex:  0,
 		BlockHeight: 0,
 	}
@@ -76,7 +82,7 @@ func (o *Output) IVOUT() blockexplorer.IVOUT {
 		Spent:       false,
 		TxIndex:     0,
 		Type:        "",
-		Value:       idaemon.Amount(o.Amount / XMR_EXTRA_UNIT),
+		Value:       blockexplorer.AmountFromInt64(int64(o.Amount), blockexplorer.DecimalsXMR),
 	}
 }
 
@@ -98,27 +104,23 @@ func (t *Transaction) outputs() []blockexplorer.IVOUT {
 
 func (t *Transaction) ITransaction() *blockexplorer.ITransaction {
 	return &blockexplorer.ITransaction{
-		BlockHeight:         t.BlockHeight,
-		DoubleSpend:         false,
-		Hash:                t.TxHash,
-		Inputs:              t.inputs(),
-		LockTime:            0,
-		Outputs:             t.outputs(),
-		Rbf:                 false,
-		Size:                0,
-		Time:                t.Timestamp,
-		TxIndex:             0,
-		Version:             t.TxVersion,
-		VinSz:               0,
-		VoutSz:              0,
-		Weight:              0,
-		Confirmations:       t.Confirmations,
-		Seen:                false,
-		Verified:            t.Confirmations != 0,
-		OrderedAmount:       0,
-		BlockExplorerAmount: 0,
-		MissingAmount:       0,
-		MissingPercent:      0,
+		BlockHeight:   t.BlockHeight,
+		DoubleSpend:   false,
+		Hash:          t.TxHash,
+		Inputs:        t.inputs(),
+		LockTime:      0,
+		Outputs:       t.outputs(),
+		Rbf:           false,
+		Size:          0,
+		Time:          t.Timestamp,
+		TxIndex:       0,
+		Version:       t.TxVersion,
+		VinSz:         0,
+		VoutSz:        0,
+		Weight:        0,
+		Confirmations: t.Confirmations,
+		Seen:          false,
+		Verified:      t.Confirmations != 0,
 	}
 }
 
@@ -148,8 +150,9 @@ func (v *TxVerifier) ITransaction(verifier blockexplorer.TxVerifyRequest) *block
 			amount += output.Amount
 		}
 	}
-	orderedAmount, _ := idaemon.NewAmount(verifier.Amount)
-	explorerAmount := idaemon.Amount(amount / XMR_EXTRA_UNIT)
+	orderedAmount := verifier.Amount
+	explorerAmount := blockexplorer.AmountFromInt64(amount, blockexplorer.DecimalsXMR)
+	missingAmount := orderedAmount.Sub(explorerAmount)
 	return &blockexplorer.ITransaction{
 		BlockHeight:         0,
 		DoubleSpend:         false,
@@ -169,9 +172,9 @@ func (v *TxVerifier) ITransaction(verifier blockexplorer.TxVerifyRequest) *block
 		Seen:                seen,
 		Verified:            seen,
 		OrderedAmount:       orderedAmount,
-		BlockExplorerAmount: idaemon.Amount(amount / XMR_EXTRA_UNIT),
-		MissingAmount:       orderedAmount - explorerAmount,
-		MissingPercent:      100 * float64(orderedAmount-explorerAmount) / float64(orderedAmount),
+		BlockExplorerAmount: explorerAmount,
+		MissingAmount:       missingAmount,
+		MissingPercent:      100 * missingAmount.ToCoin() / orderedAmount.ToCoin(),
 	}
 }
 
@@ -217,7 +220,7 @@ func convertIRawAddrTx(outputs []OutputBlock, address string) []blockexplorer.IR
 			Outputs: []blockexplorer.IRawAddrOutput{
 				{
 					Addresses: []string{address},
-					Value:     idaemon.Amount(output.Amount / XMR_EXTRA_UNIT),
+					Value:     blockexplorer.AmountFromInt64(output.Amount, blockexplorer.DecimalsXMR),
 				},
 			},
 			RelayedBy:     "",
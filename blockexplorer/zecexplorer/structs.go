@@ -1,9 +1,6 @@
 package zecexplorer
 
-import (
-	"github.com/crypto-power/instantswap/blockexplorer"
-	"github.com/crypto-power/instantswap/blockexplorer/global/interfaces/idaemon"
-)
+import "github.com/crypto-power/instantswap/blockexplorer"
 
 type Transaction struct {
 	Hash            string        `json:"hash"`
@@ -63,7 +60,7 @@ func (v *Vout) IRawOutput() blockexplorer.IRawAddrOutput {
 		Spent:     false,
 		TxIndex:   0,
 		Type:      v.ScriptPubKey.Type,
-		Value:     idaemon.Amount(v.ValueZat),
+		Value:     blockexplorer.AmountFromInt64(int64(v.ValueZat), blockexplorer.DecimalsBTC),
 	}
 }
 
@@ -1,9 +1,6 @@
 package zecexplorer
 
-import (
-	"github.com/crypto-power/instantswap/blockexplorer"
-	"github.com/crypto-power/instantswap/blockexplorer/global/interfaces/idaemon"
-)
+import "github.com/crypto-power/instantswap/blockexplorer"
 
 func (t *Transaction) valueZat() int {
 	var amount int
@@ -13,7 +10,7 @@ func (t *Transaction) valueZat() int {
 	return amount
 }
 
-func (t *Transaction) generalTx(network *Network) *blockexplorer.ITransaction {
+func (t *Transaction) generalTx(best *blockexplorer.BestBlock) *blockexplorer.ITransaction {
 	var iTx = &blockexplorer.ITransaction{
 		BlockHeight:         t.BlockHeight,
 		DoubleSpend:         false,
@@ -32,13 +29,10 @@ func (t *Transaction) generalTx(network *Network) *blockexplorer.ITransaction {
 		Confirmations:       0,
 		Seen:                false,
 		Verified:            false,
-		OrderedAmount:       0,
-		BlockExplorerAmount: idaemon.Amount(t.valueZat()),
-		MissingAmount:       0,
-		MissingPercent:      0,
+		BlockExplorerAmount: blockexplorer.AmountFromInt64(int64(t.valueZat()), blockexplorer.DecimalsBTC),
 	}
-	if network != nil {
-		iTx.Confirmations = network.BlockNumber - t.BlockHeight + 1
+	if best != nil {
+		iTx.SetConfirmations(*best)
 		if iTx.Confirmations != 0 {
 			iTx.Verified = true
 		}
@@ -56,7 +50,7 @@ func (t *Transaction) inputs() []blockexplorer.IVIN {
 			TxID:        vin.Txid,
 			VOUT:        vin.Vout,
 			Tree:        vin.RetrievedVout.N,
-			AmountIn:    idaemon.Amount(vin.RetrievedVout.ValueZat),
+			AmountIn:    blockexplorer.AmountFromInt64(int64(vin.RetrievedVout.ValueZat), blockexplorer.DecimalsBTC),
 			BlockIndex:  t.Index,
 			BlockHeight: t.BlockHeight,
 		}
@@ -76,7 +70,7 @@ func (t *Transaction) outputs() []blockexplorer.IVOUT {
 			Spent:       false,
 			TxIndex:     t.Index,
 			Type:        vout.ScriptPubKey.Type,
-			Value:       idaemon.Amount(vout.ValueZat),
+			Value:       blockexplorer.AmountFromInt64(int64(vout.ValueZat), blockexplorer.DecimalsBTC),
 		}
 	}
 	return iVout
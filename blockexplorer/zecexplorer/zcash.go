@@ -1,13 +1,13 @@
 package zecexplorer
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"sort"
 
 	"github.com/crypto-power/instantswap/blockexplorer"
 	"github.com/crypto-power/instantswap/blockexplorer/global/clients/blockexplorerclient"
-	"github.com/crypto-power/instantswap/blockexplorer/global/interfaces/idaemon"
 )
 
 const (
@@ -17,14 +17,15 @@ const (
 )
 
 func init() {
-	blockexplorer.RegisterExplorer("ZEC", "", func(config blockexplorer.Config) (blockexplorer.IBlockExplorer, error) {
+	blockexplorer.RegisterExplorerCtx("ZEC", "", func(config blockexplorer.Config) (blockexplorer.IBlockExplorerCtx, error) {
 		return New(config), nil
 	})
 }
 
 // New return a instanciate cryptopia struct
 func New(conf blockexplorer.Config) *ZcashExplorer {
-	client := blockexplorerclient.NewClient(API_BASE, LIBNAME, conf.EnableOutput, nil)
+	client := blockexplorerclient.NewClient(conf.BaseURLOrDefault(API_BASE), LIBNAME, conf.EnableOutput, nil)
+	client.SetHTTPClient(conf.HTTPClientOrDefault())
 	return &ZcashExplorer{client: client}
 }
 
@@ -32,18 +33,25 @@ type ZcashExplorer struct {
 	client *blockexplorerclient.Client
 }
 
-func (z *ZcashExplorer) getNetwork() (*Network, error) {
-	r, err := z.client.Do("GET", "mainnet/network", "", false)
+// GetBestBlock returns the best block from the network info, which has no
+// block time.
+func (z *ZcashExplorer) GetBestBlock(ctx context.Context) (*blockexplorer.BestBlock, error) {
+	r, err := z.client.Do(ctx, "GET", "mainnet/network", "", false)
 	if err != nil {
 		return nil, err
 	}
 	var network Network
-	err = json.Unmarshal(r, &network)
-	return &network, err
+	if err = json.Unmarshal(r, &network); err != nil {
+		return nil, err
+	}
+	return &blockexplorer.BestBlock{
+		Height: network.BlockNumber,
+		Hash:   network.BlockHash,
+	}, nil
 }
 
-func (z *ZcashExplorer) GetTransaction(txId string) (*blockexplorer.ITransaction, error) {
-	r, err := z.client.Do("GET", fmt.Sprintf("mainnet/transactions/%s", txId), "", false)
+func (z *ZcashExplorer) GetTransaction(ctx context.Context, txId string) (*blockexplorer.ITransaction, error) {
+	r, err := z.client.Do(ctx, "GET", fmt.Sprintf("mainnet/transactions/%s", txId), "", false)
 	if err != nil {
 		return nil, err
 	}
@@ -51,27 +59,27 @@ func (z *ZcashExplorer) GetTransaction(txId string) (*blockexplorer.ITransaction
 	if err = json.Unmarshal(r, &tx); err != nil {
 		return nil, err
 	}
-	network, _ := z.getNetwork()
-	return tx.generalTx(network), nil
+	best, _ := z.GetBestBlock(ctx)
+	return tx.generalTx(best), nil
 }
-func (z *ZcashExplorer) GetTxsForAddress(address string, limit int, viewKey string) (account *blockexplorer.IRawAddrResponse, err error) {
+func (z *ZcashExplorer) GetTxsForAddress(ctx context.Context, address string, limit int, viewKey string) (account *blockexplorer.IRawAddrResponse, err error) {
 	if limit > 20 || limit < 1 {
 		limit = 20
 	}
 	var zcashAccount Account
-	r, err := z.client.Do("GET", fmt.Sprintf("mainnet/accounts/%s", address), "", false)
+	r, err := z.client.Do(ctx, "GET", fmt.Sprintf("mainnet/accounts/%s", address), "", false)
 	if err = json.Unmarshal(r, &zcashAccount); err != nil {
 		return nil, err
 	}
 	account = zcashAccount.acount()
 	var recvTxs []Transaction
-	r, err = z.client.Do("GET",
+	r, err = z.client.Do(ctx, "GET",
 		fmt.Sprintf("mainnet/accounts/%s/recv?limit=%d&offset=0&sort=timestamp&direction=descending", address, limit), "", false)
 	if err = json.Unmarshal(r, &recvTxs); err != nil {
 		return nil, err
 	}
 	var sendTxs []Transaction
-	r, err = z.client.Do("GET",
+	r, err = z.client.Do(ctx, "GET",
 		fmt.Sprintf("mainnet/accounts/%s/sent?limit=%d&offset=0&sort=timestamp&direction=descending", address, limit), "", false)
 	if err = json.Unmarshal(r, &sendTxs); err != nil {
 		return nil, err
@@ -81,20 +89,48 @@ func (z *ZcashExplorer) GetTxsForAddress(address string, limit int, viewKey stri
 		return txs[i].Timestamp < txs[j].Timestamp
 	})
 	account.Txs = convertTxs(txs)
+	best, err := z.GetBestBlock(ctx)
+	if err != nil {
+		return nil, err
+	}
+	account.SetConfirmations(*best)
 	return account, nil
 }
 
+// VerifyByAddress looks for an output of the ordered amount in the latest
+// transactions of the address.
+func (z *ZcashExplorer) VerifyByAddress(ctx context.Context, req blockexplorer.AddressVerifyRequest) (vr *blockexplorer.VerifyResult, err error) {
+	orderedAmount := req.Amount
+	account, err := z.GetTxsForAddress(ctx, req.Address, 20, "")
+	if err != nil {
+		return nil, err
+	}
+	for _, tx := range account.Txs {
+		for _, out := range tx.Outputs {
+			if len(out.Addresses) == 1 && out.Addresses[0] == req.Address && out.Value.Cmp(orderedAmount) == 0 {
+				return &blockexplorer.VerifyResult{
+					Seen:                true,
+					Verified:            true,
+					OrderedAmount:       orderedAmount,
+					BlockExplorerAmount: out.Value,
+				}, nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("not found")
+}
+
 // VerifyTransaction verifies transaction based on values passed in (params: txid, address (required), amount (required), createdAt(unix timestamp) )
-func (z *ZcashExplorer) VerifyTransaction(verifier blockexplorer.TxVerifyRequest) (tx *blockexplorer.ITransaction, err error) {
+func (z *ZcashExplorer) VerifyTransaction(ctx context.Context, verifier blockexplorer.TxVerifyRequest) (tx *blockexplorer.ITransaction, err error) {
 	if verifier.Address == "" {
 		return nil, fmt.Errorf(LIBNAME + ":error: address is blank so tx cannot be verified")
 	}
-	if verifier.Amount == 0 {
-		return nil, fmt.Errorf(LIBNAME+":error: amount is %.8f so tx cannot be verified", verifier.Amount)
+	if verifier.Amount.IsZero() {
+		return nil, fmt.Errorf(LIBNAME+":error: amount is %s so tx cannot be verified", verifier.Amount)
 	}
 	tx = new(blockexplorer.ITransaction)
 	if verifier.TxId != "" && verifier.Address != "" { //verify tx if txid is available
-		txInfo, err := z.GetTransaction(verifier.TxId)
+		txInfo, err := z.GetTransaction(ctx, verifier.TxId)
 		if err != nil {
 			return tx, err
 		}
@@ -111,15 +147,8 @@ func (z *ZcashExplorer) VerifyTransaction(verifier blockexplorer.TxVerifyRequest
 						return tx, fmt.Errorf("seen, waiting for confirms (%v/%v)", txInfo.Confirmations, verifier.Confirms)
 					}
 
-					orderedAmount, err := idaemon.NewAmount(verifier.Amount)
-					if err != nil {
-						return tx, fmt.Errorf(LIBNAME+":error: orderedAmount %s", err.Error())
-					}
-					var missingAmount idaemon.Amount
-					missingAmount = v.Value - orderedAmount
-					if v.Value == orderedAmount {
-						missingAmount = 0
-					}
+					orderedAmount := verifier.Amount
+					missingAmount := v.Value.Sub(orderedAmount)
 					missingPercent := (missingAmount.ToCoin() / v.Value.ToCoin()) * 100
 
 					tx = txInfo
@@ -137,7 +166,7 @@ func (z *ZcashExplorer) VerifyTransaction(verifier blockexplorer.TxVerifyRequest
 			}
 		}
 	} else if verifier.Address != "" { //verify tx on blockchain based on address history for address var
-		txInfo, err := z.GetTxsForAddress(verifier.Address, 10, "")
+		txInfo, err := z.GetTxsForAddress(ctx, verifier.Address, 10, "")
 		if err != nil {
 			return tx, err
 		}
@@ -155,16 +184,9 @@ func (z *ZcashExplorer) VerifyTransaction(verifier blockexplorer.TxVerifyRequest
 							return tx, fmt.Errorf("seen, waiting for confirms (%v/%v)", u.Confirmations, verifier.Confirms)
 						}
 
-						orderedAmount, err := idaemon.NewAmount(verifier.Amount)
-						if err != nil {
-							return tx, fmt.Errorf(LIBNAME+":error: orderedAmount %s", err.Error())
-						}
+						orderedAmount := verifier.Amount
 
-						var missingAmount idaemon.Amount
-						missingAmount = v.Value - orderedAmount
-						if v.Value == orderedAmount {
-							missingAmount = 0
-						}
+						missingAmount := v.Value.Sub(orderedAmount)
 						missingPercent := (missingAmount.ToCoin() / v.Value.ToCoin()) * 100
 
 						tx = &blockexplorer.ITransaction{
@@ -221,13 +243,13 @@ func (z *ZcashExplorer) VerifyTransaction(verifier blockexplorer.TxVerifyRequest
 		}
 
 	} else {
-		return tx, fmt.Errorf(LIBNAME+":error: vars passed for verification cannot be checked: \ntxid %s address: %s amount %.8f createdAt %v",
+		return tx, fmt.Errorf(LIBNAME+":error: vars passed for verification cannot be checked: \ntxid %s address: %s amount %s createdAt %v",
 			verifier.TxId, verifier.Address, verifier.Amount, verifier.CreatedAt)
 	}
 	return nil, nil
 }
 
 // PushTx pushes a raw tx hash
-func (z *ZcashExplorer) PushTx(rawTxHash string) (result string, err error) {
+func (z *ZcashExplorer) PushTx(ctx context.Context, rawTxHash string) (result string, err error) {
 	return "", fmt.Errorf("%s:error: PushTx is not supported yet... ", LIBNAME)
 }
@@ -0,0 +1,486 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/crypto-power/instantswap/cmd/internal/order"
+	"github.com/crypto-power/instantswap/instantswap"
+)
+
+type exchangeView struct {
+	Name         string                   `json:"name"`
+	Configured   bool                     `json:"configured"`
+	Capabilities instantswap.Capabilities `json:"capabilities"`
+}
+
+func (a *app) exchanges(args []string) error {
+	if _, err := a.parseFlags(flag.NewFlagSet("exchanges", flag.ContinueOnError), args, 0, 0); err != nil {
+		return err
+	}
+	var views []exchangeView
+	var rows [][]string
+	for _, name := range instantswap.ExchangeNames() {
+		capabilities, _ := instantswap.ExchangeCapabilities(name)
+		view := exchangeView{Name: name, Configured: a.conf.Configured(name), Capabilities: capabilities}
+		views = append(views, view)
+		credentials := "-"
+		switch {
+		case capabilities.APISecret:
+			credentials = "key+secret"
+		case capabilities.APIKey:
+			credentials = "key"
+		}
+		rows = append(rows, []string{name, credentials, strconv.FormatBool(view.Configured), features(capabilities)})
+	}
+	return a.out.print(views, []string{"EXCHANGE", "CREDENTIALS", "CONFIGURED", "FEATURES"}, rows)
+}
+
+// features lists the supported features of capabilities.
+func features(c instantswap.Capabilities) string {
+	var list []string
+	for _, feature := range []struct {
+		name      string
+		supported bool
+	}{
+		{"fixed", c.FixedRate},
+		{"float", c.FloatingRate},
+		{"pairs", c.PairListing},
+		{"limits", c.Limits},
+		{"networks", c.Networks},
+		{"refund", c.RefundAddress},
+		{"extra-id", c.ExtraID},
+		{"cancel", c.Cancel},
+		{"update", c.Update},
+		{"onion", c.Onion},
+	} {
+		if feature.supported {
+			list = append(list, feature.name)
+		}
+	}
+	return orDash(strings.Join(list, ","))
+}
+
+type currencyView struct {
+	Symbol   string   `json:"symbol"`
+	Name     string   `json:"name"`
+	Networks []string `json:"networks,omitempty"`
+}
+
+func (a *app) printCurrencies(currencies []instantswap.Currency) error {
+	views := make([]currencyView, 0, len(currencies))
+	rows := make([][]string, 0, len(currencies))
+	for _, c := range currencies {
+		views = append(views, currencyView{Symbol: c.Symbol, Name: c.Name, Networks: c.Networks})
+		rows = append(rows, []string{c.Symbol, orDash(c.Name), orDash(strings.Join(c.Networks, ","))})
+	}
+	return a.out.print(views, []string{"SYMBOL", "NAME", "NETWORKS"}, rows)
+}
+
+func (a *app) currencies(args []string) error {
+	args, err := a.parseFlags(flag.NewFlagSet("currencies", flag.ContinueOnError), args, 1, 1)
+	if err != nil {
+		return err
+	}
+	exchange, err := a.exchange(args[0])
+	if err != nil {
+		return err
+	}
+	ctx, cancel := a.requestContext()
+	defer cancel()
+	currencies, err := exchange.GetCurrencies(ctx)
+	if err != nil {
+		return err
+	}
+	return a.printCurrencies(currencies)
+}
+
+func (a *app) pairs(args []string) error {
+	args, err := a.parseFlags(flag.NewFlagSet("pairs", flag.ContinueOnError), args, 2, 2)
+	if err != nil {
+		return err
+	}
+	exchange, err := a.exchange(args[0])
+	if err != nil {
+		return err
+	}
+	ctx, cancel := a.requestContext()
+	defer cancel()
+	currencies, err := exchange.GetCurrenciesToPair(ctx, args[1])
+	if err != nil {
+		return err
+	}
+	return a.printCurrencies(currencies)
+}
+
+type quoteView struct {
+	Exchange        string               `json:"exchange"`
+	Rate            float64              `json:"rate,omitempty"`
+	RateType        instantswap.RateType `json:"rate_type,omitempty"`
+	ValidUntil      string               `json:"valid_until,omitempty"`
+	EstimatedAmount instantswap.Amount   `json:"estimated_amount"`
+	Min             instantswap.Amount   `json:"min"`
+	Max             instantswap.Amount   `json:"max"`
+	Signature       string               `json:"signature,omitempty"`
+	Provider        string               `json:"provider,omitempty"`
+	Fees            *feesView            `json:"fees,omitempty"`
+	LatencyMs       int64                `json:"latency_ms"`
+	Error           string               `json:"error,omitempty"`
+}
+
+// feesView is the fee breakdown of a quote, without the fees the exchange did
+// not report.
+type feesView struct {
+	NetworkFee          *instantswap.Amount `json:"network_fee,omitempty"`
+	ServiceFeePercent   float64             `json:"service_fee_percent,omitempty"`
+	ServiceFee          *instantswap.Amount `json:"service_fee,omitempty"`
+	AffiliateFeePercent float64             `json:"affiliate_fee_percent,omitempty"`
+}
+
+func newFeesView(fees instantswap.Fees) *feesView {
+	if fees.IsZero() {
+		return nil
+	}
+	view := &feesView{
+		ServiceFeePercent:   fees.ServiceFeePercent,
+		AffiliateFeePercent: fees.AffiliateFeePercent,
+	}
+	if !fees.NetworkFee.IsZero() {
+		view.NetworkFee = &fees.NetworkFee
+	}
+	if !fees.ServiceFee.IsZero() {
+		view.ServiceFee = &fees.ServiceFee
+	}
+	return view
+}
+
+func (a *app) quote(args []string) error {
+	flags := flag.NewFlagSet("quote", flag.ContinueOnError)
+	exchanges := flags.String("exchange", "", "comma separated exchanges to query, every configured exchange by default")
+	fromNetwork := flags.String("from-network", "", "network of the sent currency")
+	toNetwork := flags.String("to-network", "", "network of the received currency")
+	rate := flags.String("rate", "", "rate `type`, fixed or float, the default type of each exchange if empty")
+	args, err := a.parseFlags(flags, args, 3, 3)
+	if err != nil {
+		return err
+	}
+	amount, err := instantswap.ParseAmount(args[2])
+	if err != nil {
+		return fmt.Errorf("invalid amount: %v", err)
+	}
+	rateType, err := instantswap.ParseRateType(*rate)
+	if err != nil {
+		return err
+	}
+
+	names := exchangeList(*exchanges)
+	if len(names) == 0 {
+		for _, name := range instantswap.ExchangeNames() {
+			if a.conf.Configured(name) {
+				names = append(names, name)
+			}
+		}
+		if len(names) == 0 {
+			return fmt.Errorf("no exchange is configured")
+		}
+	}
+	configs := make(map[string]instantswap.ExchangeConfig, len(names))
+	for _, name := range names {
+		configs[name] = a.exchangeConfig(name)
+	}
+	aggregator := instantswap.NewAggregator(instantswap.AggregatorConfig{
+		Exchanges: names,
+		Configs:   configs,
+		Timeout:   a.timeout,
+	})
+	results := aggregator.Quote(a.ctx, instantswap.ExchangeRateRequest{
+		From:        args[0],
+		FromNetwork: *fromNetwork,
+		To:          args[1],
+		ToNetwork:   *toNetwork,
+		Amount:      amount,
+		RateType:    rateType,
+	})
+
+	views := make([]quoteView, 0, len(results))
+	rows := make([][]string, 0, len(results))
+	for _, res := range results {
+		view := quoteView{
+			Exchange:        res.Exchange,
+			Rate:            res.Info.ExchangeRate,
+			RateType:        res.Info.RateType,
+			EstimatedAmount: res.Info.EstimatedAmount,
+			Min:             res.Info.Min,
+			Max:             res.Info.Max,
+			Signature:       res.Info.Signature,
+			Provider:        res.Info.Provider,
+			Fees:            newFeesView(res.Info.Fees),
+			LatencyMs:       res.Latency.Milliseconds(),
+		}
+		if !res.Info.ValidUntil.IsZero() {
+			view.ValidUntil = res.Info.ValidUntil.UTC().Format(time.RFC3339)
+		}
+		if res.Err != nil {
+			view.Error = res.Err.Error()
+		}
+		views = append(views, view)
+		if res.Err != nil {
+			rows = append(rows, []string{res.Exchange, "-", "-", "-", "-", "-", "-", view.Error})
+			continue
+		}
+		max := "-"
+		if res.Info.Max.Sign() > 0 {
+			max = res.Info.Max.String()
+		}
+		rows = append(rows, []string{res.Exchange, strconv.FormatFloat(res.Info.ExchangeRate, 'f', -1, 64),
+			res.Info.RateType.String(), res.Info.EstimatedAmount.String(), res.Info.Min.String(), max,
+			orDash(res.Info.Provider), "-"})
+	}
+	return a.out.print(views, []string{"EXCHANGE", "RATE", "TYPE", "RECEIVE", "MIN", "MAX", "PROVIDER", "ERROR"}, rows)
+}
+
+type orderView struct {
+	Exchange       string             `json:"exchange"`
+	OrderID        string             `json:"order_id"`
+	DepositAddress string             `json:"deposit_address"`
+	DepositExtraID string             `json:"deposit_extra_id,omitempty"`
+	SendAmount     instantswap.Amount `json:"send_amount"`
+	FromCurrency   string             `json:"from_currency"`
+	ReceiveAmount  instantswap.Amount `json:"receive_amount"`
+	ToCurrency     string             `json:"to_currency"`
+	Destination    string             `json:"destination"`
+	Rate           float64            `json:"rate,omitempty"`
+	Expires        int                `json:"expires,omitempty"`
+}
+
+func (a *app) create(args []string) error {
+	flags := flag.NewFlagSet("create", flag.ContinueOnError)
+	fromNetwork := flags.String("from-network", "", "network of the sent currency")
+	toNetwork := flags.String("to-network", "", "network of the received currency")
+	refund := flags.String("refund", "", "refund `address` used by the exchange if the swap fails")
+	refundExtraID := flags.String("refund-extra-id", "", "memo or tag of the refund address")
+	extraID := flags.String("extra-id", "", "memo or tag of the destination address")
+	signature := flags.String("signature", "", "signature of a previous quote, a new quote is requested if empty")
+	provider := flags.String("provider", "", "provider of a previous quote")
+	rate := flags.String("rate", "", "rate `type`, fixed or float, the default type of the exchange if empty")
+	args, err := a.parseFlags(flags, args, 5, 5)
+	if err != nil {
+		return err
+	}
+	name := args[0]
+	amount, err := instantswap.ParseAmount(args[3])
+	if err != nil {
+		return fmt.Errorf("invalid amount: %v", err)
+	}
+	rateType, err := instantswap.ParseRateType(*rate)
+	if err != nil {
+		return err
+	}
+	exchange, err := a.exchange(name)
+	if err != nil {
+		return err
+	}
+	store, err := a.openStore()
+	if err != nil {
+		return err
+	}
+	if store != nil {
+		defer store.Close()
+	}
+
+	vars := instantswap.CreateOrder{
+		FromCurrency:   args[1],
+		FromNetwork:    *fromNetwork,
+		ToCurrency:     args[2],
+		ToNetwork:      *toNetwork,
+		InvoicedAmount: amount,
+		Destination:    args[4],
+		RefundAddress:  *refund,
+		RefundExtraID:  *refundExtraID,
+		ExtraID:        *extraID,
+		Signature:      *signature,
+		Provider:       *provider,
+		RateType:       rateType,
+	}
+	if vars.ExtraID == "" && instantswap.UsesExtraID(vars.ToCurrency, vars.ToNetwork) {
+		fmt.Fprintf(a.stderr, "instantswap: no -extra-id, %s sent to an exchange or custodial address without its memo is lost\n", instantswap.CanonicalSymbol(vars.ToCurrency))
+	}
+	res, err := order.Create(exchange, &vars, a.requestContext)
+	if err != nil {
+		return err
+	}
+	if store != nil {
+		if err := store.SaveOrder(name, vars, res); err != nil {
+			fmt.Fprintf(a.stderr, "instantswap: order %s not recorded: %v\n", res.UUID, err)
+		}
+	}
+	view := orderView{
+		Exchange:       name,
+		OrderID:        res.UUID,
+		DepositAddress: res.DepositAddress,
+		DepositExtraID: res.ExtraID,
+		SendAmount:     res.InvoicedAmount,
+		FromCurrency:   res.FromCurrency,
+		ReceiveAmount:  res.OrderedAmount,
+		ToCurrency:     res.ToCurrency,
+		Destination:    res.Destination,
+		Rate:           res.ExchangeRate,
+		Expires:        res.Expires,
+	}
+	return a.out.print(view, []string{"ORDER", "SEND", "TO", "EXTRA ID", "RECEIVE", "AT"}, [][]string{{
+		res.UUID,
+		res.InvoicedAmount.String() + " " + strings.ToUpper(res.FromCurrency),
+		res.DepositAddress,
+		orDash(res.ExtraID),
+		res.OrderedAmount.String() + " " + strings.ToUpper(res.ToCurrency),
+		res.Destination,
+	}})
+}
+
+type statusView struct {
+	Exchange       string             `json:"exchange"`
+	OrderID        string             `json:"order_id"`
+	Status         string             `json:"status"`
+	Final          bool               `json:"final"`
+	ExchangeStatus string             `json:"exchange_status"`
+	TxID           string             `json:"tx_id,omitempty"`
+	ReceiveAmount  instantswap.Amount `json:"receive_amount"`
+	Confirmations  string             `json:"confirmations,omitempty"`
+	LastUpdate     string             `json:"last_update,omitempty"`
+	Time           *time.Time         `json:"time,omitempty"`
+	Error          string             `json:"error,omitempty"`
+}
+
+func newStatusView(exchange, orderID string, info instantswap.OrderInfoResult) statusView {
+	return statusView{
+		Exchange:       exchange,
+		OrderID:        orderID,
+		Status:         info.InternalStatus.String(),
+		Final:          info.InternalStatus.IsFinal(),
+		ExchangeStatus: info.Status,
+		TxID:           info.TxID,
+		ReceiveAmount:  info.ReceiveAmount,
+		Confirmations:  info.Confirmations,
+		LastUpdate:     info.LastUpdate,
+	}
+}
+
+// recordStatus adds info to the order store, if any. Orders created without
+// the store are ignored.
+func (a *app) recordStatus(exchange, orderID string, info instantswap.OrderInfoResult) {
+	store, err := a.openStore()
+	if err == nil && store != nil {
+		err = store.AddStatus(exchange, orderID, info)
+		store.Close()
+	}
+	if err != nil && !errors.Is(err, instantswap.ErrOrderNotFound) {
+		fmt.Fprintf(a.stderr, "instantswap: status of %s not recorded: %v\n", orderID, err)
+	}
+}
+
+func (a *app) status(args []string) error {
+	args, err := a.parseFlags(flag.NewFlagSet("status", flag.ContinueOnError), args, 2, -1)
+	if err != nil {
+		return err
+	}
+	exchange, err := a.exchange(args[0])
+	if err != nil {
+		return err
+	}
+	ctx, cancel := a.requestContext()
+	defer cancel()
+	info, err := exchange.OrderInfo(ctx, args[1], args[2:]...)
+	if err != nil {
+		return err
+	}
+	a.recordStatus(args[0], args[1], info)
+	view := newStatusView(args[0], args[1], info)
+	return a.out.print(view, []string{"ORDER", "STATUS", "EXCHANGE STATUS", "TX", "RECEIVE"}, [][]string{{
+		args[1], view.Status, orDash(info.Status), orDash(info.TxID), info.ReceiveAmount.String(),
+	}})
+}
+
+func (a *app) cancel(args []string) error {
+	args, err := a.parseFlags(flag.NewFlagSet("cancel", flag.ContinueOnError), args, 2, 2)
+	if err != nil {
+		return err
+	}
+	exchange, err := a.exchange(args[0])
+	if err != nil {
+		return err
+	}
+	ctx, cancel := a.requestContext()
+	defer cancel()
+	res, err := exchange.CancelOrder(ctx, args[1])
+	if err != nil {
+		return err
+	}
+	return a.out.print(map[string]string{"order_id": args[1], "result": res},
+		[]string{"ORDER", "RESULT"}, [][]string{{args[1], orDash(res)}})
+}
+
+func (a *app) watch(args []string) error {
+	flags := flag.NewFlagSet("watch", flag.ContinueOnError)
+	interval := flags.Duration("interval", 10*time.Second, "polling interval after a status change")
+	args, err := a.parseFlags(flags, args, 2, -1)
+	if err != nil {
+		return err
+	}
+	name, orderID := args[0], args[1]
+	exchange, err := a.exchange(name)
+	if err != nil {
+		return err
+	}
+	store, err := a.openStore()
+	if err != nil {
+		return err
+	}
+	if store != nil {
+		defer store.Close()
+	}
+
+	final := make(chan struct{})
+	onEvent := func(event instantswap.OrderEvent) {
+		view := newStatusView(name, orderID, event.Info)
+		t := event.Time
+		view.Time = &t
+		fields := []string{t.Format(time.RFC3339), view.Status, orDash(view.ExchangeStatus),
+			orDash(view.TxID), view.ReceiveAmount.String()}
+		if event.Err != nil {
+			view.Status = event.NewStatus.String()
+			view.Error = event.Err.Error()
+			fields = []string{t.Format(time.RFC3339), "error:", view.Error}
+		}
+		if err := a.out.line(view, fields...); err != nil {
+			fmt.Fprintf(a.stderr, "instantswap: %v\n", err)
+		}
+		if event.Err == nil && event.NewStatus.IsFinal() {
+			close(final)
+		}
+	}
+	if store != nil {
+		onEvent = instantswap.RecordEvents(store, onEvent, func(err error) {
+			if !errors.Is(err, instantswap.ErrOrderNotFound) {
+				fmt.Fprintf(a.stderr, "instantswap: status of %s not recorded: %v\n", orderID, err)
+			}
+		})
+	}
+	tracker := instantswap.NewOrderTracker(instantswap.TrackerConfig{
+		MinInterval: *interval,
+		OnEvent:     onEvent,
+	})
+	defer tracker.Close()
+	if err := tracker.Track(name, exchange, orderID, args[2:]...); err != nil {
+		return err
+	}
+	select {
+	case <-final:
+		return nil
+	case <-a.ctx.Done():
+		return a.ctx.Err()
+	}
+}
@@ -0,0 +1,224 @@
+// Command instantswap lists the currencies, quotes and trades on the instant
+// exchanges registered in instantswap/index.
+//
+// Usage:
+//
+//	instantswap [flags] <command> [command flags] [args]
+//
+// The api keys are read from the config file, a JSON document such as
+//
+//	{
+//	  "proxy": "socks5://127.0.0.1:9050",
+//	  "exchanges": {
+//	    "changenow": {"api_key": "..."},
+//	    "changelly": {"api_key": "...", "api_secret": "..."}
+//	  }
+//	}
+//
+// or from the environment: INSTANTSWAP_CHANGENOW_API_KEY,
+// INSTANTSWAP_CHANGELLY_API_SECRET, INSTANTSWAP_PROXY and so on.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/crypto-power/instantswap/cmd/internal/config"
+	"github.com/crypto-power/instantswap/instantswap"
+	_ "github.com/crypto-power/instantswap/instantswap/index"
+)
+
+// command is a subcommand of the tool.
+type command struct {
+	usage string
+	help  string
+	run   func(a *app, args []string) error
+}
+
+var commands = map[string]command{
+	"exchanges":  {"", "list the exchanges and their capabilities", (*app).exchanges},
+	"currencies": {"<exchange>", "list the currencies of an exchange", (*app).currencies},
+	"pairs":      {"<exchange> <from>", "list the currencies a currency can be exchanged to", (*app).pairs},
+	"quote":      {"[-exchange name,...] [-rate fixed|float] <from> <to> <amount>", "quote an amount on one or every configured exchange", (*app).quote},
+	"create":     {"[flags] <exchange> <from> <to> <amount> <destination>", "create an order", (*app).create},
+	"status":     {"<exchange> <order id> [extra id...]", "show the status of an order", (*app).status},
+	"cancel":     {"<exchange> <order id>", "cancel an order", (*app).cancel},
+	"watch":      {"[-interval d] <exchange> <order id> [extra id...]", "print the status changes of an order until it is final", (*app).watch},
+}
+
+// errUsage reports invalid arguments, once the usage of the command is
+// printed.
+var errUsage = errors.New("invalid arguments")
+
+// app holds the global settings shared by the commands.
+type app struct {
+	conf      *config.Config
+	transport http.RoundTripper
+	out       *printer
+	stderr    io.Writer
+	// usage is the usage of the running command.
+	usage     string
+	ctx       context.Context
+	timeout   time.Duration
+	debug     bool
+	storePath string
+}
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	code := run(ctx, os.Args[1:], os.Stdout, os.Stderr, os.Getenv)
+	stop()
+	os.Exit(code)
+}
+
+// run executes the command line args and returns the exit code.
+func run(ctx context.Context, args []string, stdout, stderr io.Writer, getenv func(string) string) int {
+	flags := flag.NewFlagSet("instantswap", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	configPath := flags.String("config", "", "config `file`, $INSTANTSWAP_CONFIG or instantswap/config.json in the user config directory by default")
+	jsonOutput := flags.Bool("json", false, "print JSON instead of tables")
+	timeout := flags.Duration("timeout", 30*time.Second, "timeout of each exchange request")
+	debug := flags.Bool("debug", false, "log the requests sent to the exchanges")
+	storePath := flags.String("store", "", "order store `file`, the orders created and the statuses seen are recorded in it")
+	flags.Usage = func() { usage(flags) }
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if flags.NArg() == 0 {
+		flags.Usage()
+		return 2
+	}
+	name := flags.Arg(0)
+	cmd, ok := commands[name]
+	if !ok {
+		fmt.Fprintf(stderr, "unknown command %q\n", name)
+		flags.Usage()
+		return 2
+	}
+
+	path, required := *configPath, true
+	if path == "" {
+		path, required = config.DefaultPath(getenv), false
+	}
+	conf, err := config.Load(path, required, getenv)
+	if err != nil {
+		fmt.Fprintf(stderr, "instantswap: %v\n", err)
+		return 1
+	}
+	transport, err := conf.Transport()
+	if err != nil {
+		fmt.Fprintf(stderr, "instantswap: %v\n", err)
+		return 1
+	}
+	a := &app{
+		conf:      conf,
+		transport: transport,
+		out:       &printer{w: stdout, json: *jsonOutput},
+		stderr:    stderr,
+		usage:     cmd.usage,
+		ctx:       ctx,
+		timeout:   *timeout,
+		debug:     *debug,
+		storePath: *storePath,
+	}
+	err = cmd.run(a, flags.Args()[1:])
+	switch {
+	case errors.Is(err, errUsage):
+		return 2
+	case err != nil:
+		fmt.Fprintf(stderr, "instantswap: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+func usage(flags *flag.FlagSet) {
+	w := flags.Output()
+	fmt.Fprintf(w, "usage: instantswap [flags] <command> [args]\n\ncommands:\n")
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(w, "  %-11s %s\n", name, commands[name].help)
+	}
+	fmt.Fprintf(w, "\nflags:\n")
+	flags.PrintDefaults()
+}
+
+// parseFlags parses the flags of the command and returns its positional
+// arguments, which must be between min and max, max < 0 for no limit. The
+// usage of the command is printed on error.
+func (a *app) parseFlags(flags *flag.FlagSet, args []string, min, max int) ([]string, error) {
+	flags.SetOutput(a.stderr)
+	flags.Usage = func() {
+		fmt.Fprintf(a.stderr, "usage: instantswap %s %s\n", flags.Name(), a.usage)
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return nil, errUsage
+	}
+	args = flags.Args()
+	if len(args) < min || (max >= 0 && len(args) > max) {
+		flags.Usage()
+		return nil, errUsage
+	}
+	return args, nil
+}
+
+// requestContext returns the context of a single exchange request.
+func (a *app) requestContext() (context.Context, context.CancelFunc) {
+	if a.timeout <= 0 {
+		return context.WithCancel(a.ctx)
+	}
+	return context.WithTimeout(a.ctx, a.timeout)
+}
+
+// exchangeConfig returns the ExchangeConfig of the exchange name.
+func (a *app) exchangeConfig(name string) instantswap.ExchangeConfig {
+	conf := a.conf.ExchangeConfig(name, a.transport)
+	conf.Debug = a.debug
+	return conf
+}
+
+// exchange creates the exchange name, failing when its credentials are
+// missing.
+func (a *app) exchange(name string) (instantswap.IDExchangeCtx, error) {
+	if !a.conf.Configured(name) {
+		variables := config.EnvPrefix(name) + "API_KEY"
+		if capabilities, _ := instantswap.ExchangeCapabilities(name); capabilities.APISecret {
+			variables += " and " + config.EnvPrefix(name) + "API_SECRET"
+		}
+		return nil, fmt.Errorf("%s requires api credentials, set them in the config file or in %s", name, variables)
+	}
+	return instantswap.NewExchangeCtx(name, a.exchangeConfig(name))
+}
+
+// openStore opens the order store, nil when -store is not set.
+func (a *app) openStore() (instantswap.OrderStore, error) {
+	if a.storePath == "" {
+		return nil, nil
+	}
+	return instantswap.OpenFileOrderStore(a.storePath)
+}
+
+// exchangeList splits the comma separated exchange names of list.
+func exchangeList(list string) []string {
+	var names []string
+	for _, name := range strings.Split(list, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
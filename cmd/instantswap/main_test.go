@@ -0,0 +1,128 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/crypto-power/instantswap/instantswap"
+	"github.com/crypto-power/instantswap/instantswap/exchangetest"
+)
+
+func TestCommands(t *testing.T) {
+	server := exchangetest.NewServer(t, exchangetest.Fixture{
+		Method: "GET", Path: "/v1/currencies", Query: "active=true",
+		Response: `[{"ticker":"btc","name":"Bitcoin"},{"ticker":"dcr","name":"Decred"}]`,
+	}, exchangetest.Fixture{
+		Method: "GET", Path: "/v1/exchange-range/btc_dcr",
+		Response: `{"minAmount":0.0006,"maxAmount":null}`,
+	}, exchangetest.Fixture{
+		Method: "GET", Path: "/v1/exchange-amount/0.1/btc_dcr",
+		Response: `{"estimatedAmount":183.52137104,"serviceCommission":0.5}`,
+	}, exchangetest.Fixture{
+		Method: "GET", Path: "/v1/exchange-range/fixed-rate/btc_dcr",
+		Response: `{"minAmount":0.001,"maxAmount":2.5}`,
+	}, exchangetest.Fixture{
+		Method: "GET", Path: "/v1/exchange-amount/fixed-rate/0.1/btc_dcr",
+		Response: `{"estimatedAmount":180.1,"rateId":"r4t3","validUntil":"2023-05-01T10:20:00.000Z"}`,
+	}, exchangetest.Fixture{
+		Method: "POST", Path: "/v1/transactions/key", Body: `"address":"DsUZxxoHJSty8DCfwfartwTYbuhmVct7tJu"`,
+		Response: `{"payinAddress":"bc1qdeposit","payoutAddress":"DsUZxxoHJSty8DCfwfartwTYbuhmVct7tJu","fromCurrency":"btc","toCurrency":"dcr",
+			"id":"a1b2c3","amount":183.52137104}`,
+	}, exchangetest.Fixture{
+		Method: "GET", Path: "/v1/transactions/a1b2c3/key",
+		Response: `{"status":"finished","amountReceive":183.4,"payoutHash":"dcrtxhash"}`,
+	})
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "config.json")
+	err := os.WriteFile(configPath, []byte(`{"exchanges":{"changenow":{"api_key":"key","base_url":"`+server.URL+`/v1"}}}`), 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	store := filepath.Join(dir, "orders.log")
+
+	exec := func(args ...string) string {
+		t.Helper()
+		var stdout, stderr bytes.Buffer
+		args = append([]string{"-config", configPath, "-store", store}, args...)
+		if code := run(context.Background(), args, &stdout, &stderr, func(string) string { return "" }); code != 0 {
+			t.Fatalf("%v: exit code %d: %s", args, code, stderr.String())
+		}
+		return stdout.String()
+	}
+
+	if out := exec("currencies", "changenow"); !strings.Contains(out, "DCR") || !strings.Contains(out, "Decred") {
+		t.Errorf("unexpected currencies:\n%s", out)
+	}
+	var quotes []quoteView
+	if err := json.Unmarshal([]byte(exec("-json", "quote", "-exchange", "changenow", "btc", "dcr", "0.1")), &quotes); err != nil {
+		t.Fatal(err)
+	}
+	if len(quotes) != 1 || quotes[0].Error != "" || quotes[0].EstimatedAmount.String() != "183.52137104" ||
+		quotes[0].Fees == nil || quotes[0].Fees.ServiceFeePercent != 0.5 || quotes[0].Fees.NetworkFee != nil {
+		t.Errorf("unexpected quotes %+v", quotes)
+	}
+	if err := json.Unmarshal([]byte(exec("-json", "quote", "-exchange", "changenow", "-rate", "fixed", "btc", "dcr", "0.1")), &quotes); err != nil {
+		t.Fatal(err)
+	}
+	if len(quotes) != 1 || quotes[0].RateType != instantswap.RateTypeFixed || quotes[0].Signature != "r4t3" ||
+		quotes[0].ValidUntil != "2023-05-01T10:20:00Z" {
+		t.Errorf("unexpected fixed quotes %+v", quotes)
+	}
+	var order orderView
+	if err := json.Unmarshal([]byte(exec("-json", "create", "-refund", "bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t4", "changenow", "btc", "dcr", "0.1", "DsUZxxoHJSty8DCfwfartwTYbuhmVct7tJu")), &order); err != nil {
+		t.Fatal(err)
+	}
+	if order.OrderID != "a1b2c3" || order.DepositAddress != "bc1qdeposit" {
+		t.Errorf("unexpected order %+v", order)
+	}
+	var status statusView
+	if err := json.Unmarshal([]byte(exec("-json", "watch", "changenow", "a1b2c3")), &status); err != nil {
+		t.Fatal(err)
+	}
+	if !status.Final || status.TxID != "dcrtxhash" || status.Status != instantswap.OrderStatusCompleted.String() {
+		t.Errorf("unexpected status %+v", status)
+	}
+
+	orders, err := instantswap.OpenFileOrderStore(store)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer orders.Close()
+	stored, err := orders.Order("changenow", "a1b2c3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.Exchange != "changenow" || stored.Status() != instantswap.OrderStatusCompleted {
+		t.Errorf("unexpected stored order %+v", stored)
+	}
+}
+
+func TestUsage(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	// Keep the config file of the user out of the test.
+	missing := filepath.Join(t.TempDir(), "config.json")
+	getenv := func(key string) string {
+		if key == "INSTANTSWAP_CONFIG" {
+			return missing
+		}
+		return ""
+	}
+	if code := run(context.Background(), []string{"quote", "btc"}, &stdout, &stderr, getenv); code != 2 {
+		t.Errorf("unexpected exit code %d", code)
+	}
+	if !strings.Contains(stderr.String(), "usage: instantswap quote") {
+		t.Errorf("unexpected usage %q", stderr.String())
+	}
+	stderr.Reset()
+	if code := run(context.Background(), []string{"currencies", "changelly"}, &stdout, &stderr, getenv); code != 1 {
+		t.Errorf("unexpected exit code %d", code)
+	}
+	if !strings.Contains(stderr.String(), "INSTANTSWAP_CHANGELLY_API_SECRET") {
+		t.Errorf("unexpected error %q", stderr.String())
+	}
+}
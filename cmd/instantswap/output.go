@@ -0,0 +1,48 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+)
+
+// printer writes the results of the commands as tables or JSON.
+type printer struct {
+	w    io.Writer
+	json bool
+}
+
+// print writes v as indented JSON, or the rows under header as a table.
+func (p *printer) print(v interface{}, header []string, rows [][]string) error {
+	if p.json {
+		enc := json.NewEncoder(p.w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(v)
+	}
+	tw := tabwriter.NewWriter(p.w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, strings.Join(header, "\t"))
+	for _, row := range rows {
+		fmt.Fprintln(tw, strings.Join(row, "\t"))
+	}
+	return tw.Flush()
+}
+
+// line writes v as a single JSON line, or the fields separated by spaces. It is
+// used for the streamed output of watch.
+func (p *printer) line(v interface{}, fields ...string) error {
+	if p.json {
+		return json.NewEncoder(p.w).Encode(v)
+	}
+	_, err := fmt.Fprintln(p.w, strings.Join(fields, "  "))
+	return err
+}
+
+// orDash returns s, or - if it is empty, to keep the table columns aligned.
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/crypto-power/instantswap/instantswap"
+)
+
+// apiError is the error returned to the clients, in an {"error": ...}
+// envelope.
+type apiError struct {
+	status int
+	// Kind identifies the error, invalid_request, pair_unavailable...
+	Kind    string `json:"kind"`
+	Message string `json:"message"`
+	// Exchange and Code are set for the errors returned by an exchange, Code
+	// being the error code of the exchange api.
+	Exchange string `json:"exchange,omitempty"`
+	Code     string `json:"code,omitempty"`
+	// RetryAfter is the delay in seconds before retrying a rate limited
+	// request, if known.
+	RetryAfter int `json:"retry_after,omitempty"`
+}
+
+func (e *apiError) Error() string {
+	return e.Kind + ": " + e.Message
+}
+
+func invalidRequest(message string) *apiError {
+	return &apiError{status: http.StatusBadRequest, Kind: "invalid_request", Message: message}
+}
+
+var (
+	errUnauthorized    = &apiError{status: http.StatusUnauthorized, Kind: "unauthorized", Message: "missing or invalid api key"}
+	errUnknownExchange = &apiError{status: http.StatusNotFound, Kind: "unknown_exchange", Message: "exchange not available"}
+	errRouteNotFound   = &apiError{status: http.StatusNotFound, Kind: "not_found", Message: "no such endpoint"}
+	errMethod          = &apiError{status: http.StatusMethodNotAllowed, Kind: "method_not_allowed", Message: "method not allowed"}
+)
+
+// kindStatus is the http status of the errors of each kind. The unauthorized
+// errors of the exchanges come from the keys of the gateway, not the client,
+// they are reported as a bad gateway.
+var kindStatus = map[instantswap.ErrorKind]int{
+	instantswap.KindRateLimited:      http.StatusTooManyRequests,
+	instantswap.KindAmountOutOfRange: http.StatusUnprocessableEntity,
+	instantswap.KindPairUnavailable:  http.StatusUnprocessableEntity,
+	instantswap.KindInvalidAddress:   http.StatusUnprocessableEntity,
+	instantswap.KindNotFound:         http.StatusNotFound,
+	instantswap.KindNotSupported:     http.StatusNotImplemented,
+	instantswap.KindUnauthorized:     http.StatusBadGateway,
+	instantswap.KindNetwork:          http.StatusBadGateway,
+	instantswap.KindUnknown:          http.StatusBadGateway,
+}
+
+// exchangeError converts an error of an exchange to the error returned to the
+// client. Only the message sent by the exchange api is passed on: the other
+// errors may contain the request urls and with them the api keys.
+func exchangeError(err error) *apiError {
+	var e *instantswap.Error
+	if !errors.As(err, &e) {
+		e = &instantswap.Error{Kind: instantswap.KindUnknown, Err: err}
+		if errors.Is(err, instantswap.ErrAmountOutOfRange) {
+			e.Kind = instantswap.KindAmountOutOfRange
+			e.Message = err.Error()
+		}
+	}
+	res := &apiError{
+		status:   kindStatus[e.Kind],
+		Kind:     strings.ReplaceAll(e.Kind.String(), " ", "_"),
+		Message:  e.Message,
+		Exchange: e.Exchange,
+		Code:     e.Code,
+	}
+	if res.Kind == "unknown" {
+		res.Kind = "exchange_error"
+	}
+	if e.Kind == instantswap.KindUnauthorized {
+		res.Message = ""
+	}
+	if e.RetryAfter > 0 {
+		res.RetryAfter = int(math.Ceil(e.RetryAfter.Seconds()))
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		res.status = http.StatusGatewayTimeout
+		res.Kind = "timeout"
+	}
+	if res.Message == "" {
+		res.Message = "the exchange request failed"
+		if e.Kind != instantswap.KindUnknown {
+			res.Message += ": " + e.Kind.String()
+		}
+	}
+	return res
+}
+
+// writeError writes err, an *apiError or an exchange error, to w.
+func writeError(w http.ResponseWriter, err error) {
+	var e *apiError
+	if !errors.As(err, &e) {
+		e = exchangeError(err)
+	}
+	if e.RetryAfter > 0 {
+		w.Header().Set("Retry-After", strconv.Itoa(e.RetryAfter))
+	}
+	writeJSON(w, e.status, struct {
+		Error *apiError `json:"error"`
+	}{e})
+}
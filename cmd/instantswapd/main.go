@@ -0,0 +1,156 @@
+// Command instantswapd serves the exchanges registered in instantswap/index
+// over a REST api, so the clients trade without holding the exchange api keys.
+//
+// The exchange keys are read from the config file of the instantswap command,
+// see cmd/instantswap. The keys of the clients are read from the -client-keys
+// file, one per line, and from INSTANTSWAPD_CLIENT_KEYS, comma separated. The
+// clients send them as a bearer token or in the X-API-Key header.
+//
+// Endpoints:
+//
+//	GET  /v1/exchanges
+//	GET  /v1/exchanges/{exchange}/currencies
+//	GET  /v1/exchanges/{exchange}/pairs/{from}
+//	GET  /v1/quotes?from=btc&to=dcr&amount=0.1[&exchange=a,b][&from_network=][&to_network=][&rate_type=fixed|float]
+//	POST /v1/exchanges/{exchange}/orders
+//	GET  /v1/exchanges/{exchange}/orders/{order id}[?extra_id=]
+//	GET  /healthz
+//
+// Errors are returned as {"error": {"kind": ..., "message": ...}}.
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/crypto-power/instantswap/cmd/internal/config"
+	"github.com/crypto-power/instantswap/instantswap"
+	_ "github.com/crypto-power/instantswap/instantswap/index"
+)
+
+func main() {
+	listen := flag.String("listen", "127.0.0.1:8080", "`address` to listen on")
+	configPath := flag.String("config", "", "exchanges config `file`, $INSTANTSWAP_CONFIG or instantswap/config.json in the user config directory by default")
+	exchanges := flag.String("exchanges", "", "comma separated exchanges to serve, every exchange whose credentials are set by default")
+	clientKeysPath := flag.String("client-keys", "", "`file` of the api keys of the clients, one per line")
+	timeout := flag.Duration("timeout", 30*time.Second, "timeout of each exchange request")
+	cacheTTL := flag.Duration("cache-ttl", time.Hour, "how long the currency and pair lists are cached, 0 disables the cache")
+	storePath := flag.String("store", "", "order store `file`, the orders created and the statuses seen are recorded in it")
+	tlsCert := flag.String("tls-cert", "", "TLS certificate `file`, plain http is served without it")
+	tlsKey := flag.String("tls-key", "", "TLS key `file`")
+	debug := flag.Bool("debug", false, "log the requests sent to the exchanges")
+	flag.Parse()
+
+	logger := log.New(os.Stderr, "instantswapd: ", log.LstdFlags)
+	if err := run(logger, *listen, *configPath, *exchanges, *clientKeysPath, *timeout, *cacheTTL, *storePath, *tlsCert, *tlsKey, *debug); err != nil {
+		logger.Fatal(err)
+	}
+}
+
+func run(logger *log.Logger, listen, configPath, exchanges, clientKeysPath string, timeout, cacheTTL time.Duration,
+	storePath, tlsCert, tlsKey string, debug bool) error {
+	required := configPath != ""
+	if !required {
+		configPath = config.DefaultPath(os.Getenv)
+	}
+	conf, err := config.Load(configPath, required, os.Getenv)
+	if err != nil {
+		return err
+	}
+	clientKeys, err := loadClientKeys(clientKeysPath, os.Getenv("INSTANTSWAPD_CLIENT_KEYS"))
+	if err != nil {
+		return err
+	}
+	if len(clientKeys) == 0 {
+		return fmt.Errorf("no client key, set -client-keys or INSTANTSWAPD_CLIENT_KEYS")
+	}
+	var store instantswap.OrderStore
+	if storePath != "" {
+		fileStore, err := instantswap.OpenFileOrderStore(storePath)
+		if err != nil {
+			return err
+		}
+		defer fileStore.Close()
+		store = fileStore
+	}
+	var names []string
+	for _, name := range strings.Split(exchanges, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+	handler, err := newServer(conf, names, clientKeys, timeout, cacheTTL, debug, store, logger)
+	if err != nil {
+		return err
+	}
+	defer handler.close()
+	if len(handler.names) == 0 {
+		return fmt.Errorf("no exchange is configured")
+	}
+
+	srv := &http.Server{
+		Addr:              listen,
+		Handler:           handler,
+		ReadHeaderTimeout: 10 * time.Second,
+		ErrorLog:          logger,
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	errc := make(chan error, 1)
+	go func() {
+		logger.Printf("serving %s on %s", strings.Join(handler.names, ", "), listen)
+		if tlsCert != "" || tlsKey != "" {
+			errc <- srv.ListenAndServeTLS(tlsCert, tlsKey)
+		} else {
+			errc <- srv.ListenAndServe()
+		}
+	}()
+	select {
+	case err := <-errc:
+		return err
+	case <-ctx.Done():
+	}
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), timeout+5*time.Second)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// loadClientKeys returns the keys of the file at path, one per line with #
+// comments, and the comma separated keys of env.
+func loadClientKeys(path, env string) ([]string, error) {
+	var keys []string
+	for _, key := range strings.Split(env, ",") {
+		if key = strings.TrimSpace(key); key != "" {
+			keys = append(keys, key)
+		}
+	}
+	if path == "" {
+		return keys, nil
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line != "" && !strings.HasPrefix(line, "#") {
+			keys = append(keys, line)
+		}
+	}
+	return keys, scanner.Err()
+}
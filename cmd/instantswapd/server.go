@@ -0,0 +1,635 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/crypto-power/instantswap/cmd/internal/config"
+	"github.com/crypto-power/instantswap/cmd/internal/order"
+	"github.com/crypto-power/instantswap/instantswap"
+)
+
+const maxBodySize = 1 << 16
+
+var symbolPattern = regexp.MustCompile(`^[A-Za-z0-9_.-]{1,32}$`)
+
+// server serves the REST api over the exchanges whose credentials are
+// configured.
+type server struct {
+	conf       *config.Config
+	transport  http.RoundTripper
+	clientKeys [][sha256.Size]byte
+	timeout    time.Duration
+	debug      bool
+	// store records the orders created through the gateway, if set.
+	store     instantswap.OrderStore
+	log       *log.Logger
+	exchanges map[string]instantswap.IDExchangeCtx
+	// caches are the exchanges wrapped with a cache, closed by close.
+	caches []*instantswap.CachedExchange
+	// aggregator quotes the exchanges, with their caches.
+	aggregator *instantswap.Aggregator
+	names      []string
+	routes     []route
+}
+
+// route is an endpoint of the api. The "*" segments of pattern match any
+// value, passed to handle in order.
+type route struct {
+	method  string
+	pattern []string
+	handle  func(w http.ResponseWriter, r *http.Request, params []string) error
+}
+
+// newServer creates the server of the exchanges names, every exchange whose
+// credentials are configured if empty. The currency and pair lists are
+// cached for cacheTTL, not cached if it is 0.
+func newServer(conf *config.Config, names, clientKeys []string, timeout, cacheTTL time.Duration, debug bool,
+	store instantswap.OrderStore, logger *log.Logger) (*server, error) {
+	transport, err := conf.Transport()
+	if err != nil {
+		return nil, err
+	}
+	s := &server{
+		conf:      conf,
+		transport: transport,
+		timeout:   timeout,
+		debug:     debug,
+		store:     store,
+		log:       logger,
+		exchanges: make(map[string]instantswap.IDExchangeCtx),
+		aggregator: instantswap.NewAggregator(instantswap.AggregatorConfig{
+			Timeout: timeout,
+			Empty:   true,
+		}),
+	}
+	for _, key := range clientKeys {
+		s.clientKeys = append(s.clientKeys, sha256.Sum256([]byte(key)))
+	}
+	if len(names) == 0 {
+		for _, name := range instantswap.ExchangeNames() {
+			if conf.Configured(name) {
+				names = append(names, name)
+			}
+		}
+	}
+	for _, name := range names {
+		if !conf.Configured(name) {
+			return nil, fmt.Errorf("%s requires api credentials, set them in the config file or in %sAPI_KEY",
+				name, config.EnvPrefix(name))
+		}
+		exchange, err := instantswap.NewExchangeCtx(name, s.exchangeConfig(name))
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", name, err)
+		}
+		if cacheTTL > 0 {
+			cache := instantswap.NewCachedExchange(exchange, instantswap.CacheConfig{
+				CurrenciesTTL: cacheTTL,
+				RefreshAhead:  cacheTTL / 10,
+			})
+			s.caches = append(s.caches, cache)
+			exchange = cache
+		}
+		s.exchanges[name] = exchange
+		s.aggregator.Add(name, exchange)
+		s.names = append(s.names, name)
+	}
+	s.routes = []route{
+		{"GET", []string{"v1", "exchanges"}, s.listExchanges},
+		{"GET", []string{"v1", "exchanges", "*", "currencies"}, s.currencies},
+		{"GET", []string{"v1", "exchanges", "*", "pairs", "*"}, s.pairs},
+		{"GET", []string{"v1", "quotes"}, s.quotes},
+		{"POST", []string{"v1", "exchanges", "*", "orders"}, s.createOrder},
+		{"GET", []string{"v1", "exchanges", "*", "orders", "*"}, s.orderStatus},
+	}
+	return s, nil
+}
+
+// close stops the background refreshes of the caches.
+func (s *server) close() {
+	for _, cache := range s.caches {
+		cache.Close()
+	}
+}
+
+func (s *server) exchangeConfig(name string) instantswap.ExchangeConfig {
+	conf := s.conf.ExchangeConfig(name, s.transport)
+	conf.Debug = s.debug
+	return conf
+}
+
+func (s *server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path == "/healthz" {
+		writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+		return
+	}
+	if !s.authorized(r) {
+		writeError(w, errUnauthorized)
+		return
+	}
+	segments := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	found := false
+	for _, rt := range s.routes {
+		params, ok := rt.match(segments)
+		if !ok {
+			continue
+		}
+		found = true
+		if rt.method != r.Method {
+			continue
+		}
+		if err := rt.handle(w, r, params); err != nil {
+			s.fail(w, r, err)
+		}
+		return
+	}
+	if found {
+		writeError(w, errMethod)
+		return
+	}
+	writeError(w, errRouteNotFound)
+}
+
+func (rt *route) match(segments []string) ([]string, bool) {
+	if len(segments) != len(rt.pattern) {
+		return nil, false
+	}
+	var params []string
+	for i, segment := range rt.pattern {
+		switch {
+		case segment == "*" && segments[i] != "":
+			params = append(params, segments[i])
+		case segment != segments[i]:
+			return nil, false
+		}
+	}
+	return params, true
+}
+
+// authorized reports whether the request carries a client key, as a bearer
+// token or in the X-API-Key header.
+func (s *server) authorized(r *http.Request) bool {
+	key := r.Header.Get("X-API-Key")
+	if auth := r.Header.Get("Authorization"); key == "" && strings.HasPrefix(auth, "Bearer ") {
+		key = strings.TrimPrefix(auth, "Bearer ")
+	}
+	if key == "" {
+		return false
+	}
+	sum := sha256.Sum256([]byte(key))
+	ok := 0
+	for i := range s.clientKeys {
+		ok |= subtle.ConstantTimeCompare(sum[:], s.clientKeys[i][:])
+	}
+	return ok == 1
+}
+
+// fail writes err to the client and logs the exchange errors, whose details
+// are not sent to the client.
+func (s *server) fail(w http.ResponseWriter, r *http.Request, err error) {
+	var e *apiError
+	if !errors.As(err, &e) {
+		s.log.Printf("%s %s: %v", r.Method, r.URL.Path, err)
+	}
+	writeError(w, err)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func (s *server) exchange(name string) (instantswap.IDExchangeCtx, error) {
+	exchange, ok := s.exchanges[name]
+	if !ok {
+		return nil, errUnknownExchange
+	}
+	return exchange, nil
+}
+
+// requestContext returns the context of a single exchange request.
+func (s *server) requestContext(r *http.Request) (context.Context, context.CancelFunc) {
+	if s.timeout <= 0 {
+		return context.WithCancel(r.Context())
+	}
+	return context.WithTimeout(r.Context(), s.timeout)
+}
+
+func checkSymbol(field, value string) error {
+	if !symbolPattern.MatchString(value) {
+		return invalidRequest(fmt.Sprintf("invalid %s %q", field, value))
+	}
+	return nil
+}
+
+// checkOptional checks an optional field holding an address, memo or
+// signature: printable, without spaces and at most 256 bytes long.
+func checkOptional(field, value string) error {
+	if len(value) > 256 || strings.IndexFunc(value, func(r rune) bool {
+		return unicode.IsSpace(r) || !unicode.IsPrint(r)
+	}) >= 0 {
+		return invalidRequest("invalid " + field)
+	}
+	return nil
+}
+
+// parseAmount parses a positive amount of the currency symbol on network,
+// amounts with more decimals than the currency are rejected. The decimals of
+// a token are unknown without its network, its amounts are not checked.
+func parseAmount(field, value, symbol, network string) (instantswap.Amount, error) {
+	amount, err := instantswap.ParseAmount(value)
+	if err != nil || amount.Sign() <= 0 {
+		return instantswap.Amount{}, invalidRequest(fmt.Sprintf("invalid %s %q", field, value))
+	}
+	if decimals, ok := instantswap.CurrencyDecimals(symbol, network); ok && amount.Truncate(decimals).Cmp(amount) != 0 {
+		return instantswap.Amount{}, invalidRequest(fmt.Sprintf("invalid %s %q, %s has %d decimals",
+			field, value, symbol, decimals))
+	}
+	return amount, nil
+}
+
+func parseRateType(field, value string) (instantswap.RateType, error) {
+	rateType, err := instantswap.ParseRateType(value)
+	if err != nil {
+		return rateType, invalidRequest(fmt.Sprintf("invalid %s %q, expected fixed or float", field, value))
+	}
+	return rateType, nil
+}
+
+type exchangeResponse struct {
+	Name     string   `json:"name"`
+	Features []string `json:"features"`
+}
+
+func (s *server) listExchanges(w http.ResponseWriter, r *http.Request, _ []string) error {
+	res := make([]exchangeResponse, 0, len(s.names))
+	for _, name := range s.names {
+		c, _ := instantswap.ExchangeCapabilities(name)
+		features := []string{}
+		for _, feature := range []struct {
+			name      string
+			supported bool
+		}{
+			{"fixed_rate", c.FixedRate},
+			{"floating_rate", c.FloatingRate},
+			{"pair_listing", c.PairListing},
+			{"limits", c.Limits},
+			{"networks", c.Networks},
+			{"refund_address", c.RefundAddress},
+			{"extra_id", c.ExtraID},
+		} {
+			if feature.supported {
+				features = append(features, feature.name)
+			}
+		}
+		res = append(res, exchangeResponse{Name: name, Features: features})
+	}
+	writeJSON(w, http.StatusOK, res)
+	return nil
+}
+
+type currencyResponse struct {
+	Symbol   string   `json:"symbol"`
+	Name     string   `json:"name"`
+	Networks []string `json:"networks,omitempty"`
+}
+
+func writeCurrencies(w http.ResponseWriter, currencies []instantswap.Currency) {
+	res := make([]currencyResponse, 0, len(currencies))
+	for _, c := range currencies {
+		res = append(res, currencyResponse{Symbol: c.Symbol, Name: c.Name, Networks: c.Networks})
+	}
+	writeJSON(w, http.StatusOK, res)
+}
+
+func (s *server) currencies(w http.ResponseWriter, r *http.Request, params []string) error {
+	exchange, err := s.exchange(params[0])
+	if err != nil {
+		return err
+	}
+	ctx, cancel := s.requestContext(r)
+	defer cancel()
+	currencies, err := exchange.GetCurrencies(ctx)
+	if err != nil {
+		return err
+	}
+	writeCurrencies(w, currencies)
+	return nil
+}
+
+func (s *server) pairs(w http.ResponseWriter, r *http.Request, params []string) error {
+	exchange, err := s.exchange(params[0])
+	if err != nil {
+		return err
+	}
+	if err := checkSymbol("currency", params[1]); err != nil {
+		return err
+	}
+	ctx, cancel := s.requestContext(r)
+	defer cancel()
+	currencies, err := exchange.GetCurrenciesToPair(ctx, params[1])
+	if err != nil {
+		return err
+	}
+	writeCurrencies(w, currencies)
+	return nil
+}
+
+type quoteResponse struct {
+	Exchange        string               `json:"exchange"`
+	Rate            float64              `json:"rate,omitempty"`
+	RateType        instantswap.RateType `json:"rate_type,omitempty"`
+	ValidUntil      *time.Time           `json:"valid_until,omitempty"`
+	EstimatedAmount *instantswap.Amount  `json:"estimated_amount,omitempty"`
+	Min             *instantswap.Amount  `json:"min,omitempty"`
+	Max             *instantswap.Amount  `json:"max,omitempty"`
+	Signature       string               `json:"signature,omitempty"`
+	Provider        string               `json:"provider,omitempty"`
+	Fees            *feesResponse        `json:"fees,omitempty"`
+	Error           *apiError            `json:"error,omitempty"`
+}
+
+// feesResponse is the fee breakdown of a quote, without the fees the exchange
+// did not report.
+type feesResponse struct {
+	NetworkFee          *instantswap.Amount `json:"network_fee,omitempty"`
+	ServiceFeePercent   float64             `json:"service_fee_percent,omitempty"`
+	ServiceFee          *instantswap.Amount `json:"service_fee,omitempty"`
+	AffiliateFeePercent float64             `json:"affiliate_fee_percent,omitempty"`
+}
+
+func newFeesResponse(fees instantswap.Fees) *feesResponse {
+	if fees.IsZero() {
+		return nil
+	}
+	res := &feesResponse{
+		ServiceFeePercent:   fees.ServiceFeePercent,
+		AffiliateFeePercent: fees.AffiliateFeePercent,
+	}
+	if !fees.NetworkFee.IsZero() {
+		res.NetworkFee = &fees.NetworkFee
+	}
+	if !fees.ServiceFee.IsZero() {
+		res.ServiceFee = &fees.ServiceFee
+	}
+	return res
+}
+
+// quotes returns the quotes of the requested exchanges, every exchange by
+// default, best first.
+func (s *server) quotes(w http.ResponseWriter, r *http.Request, _ []string) error {
+	query := r.URL.Query()
+	from, to := query.Get("from"), query.Get("to")
+	if err := checkSymbol("from", from); err != nil {
+		return err
+	}
+	if err := checkSymbol("to", to); err != nil {
+		return err
+	}
+	fromNetwork, toNetwork := query.Get("from_network"), query.Get("to_network")
+	for field, value := range map[string]string{"from_network": fromNetwork, "to_network": toNetwork} {
+		if err := checkOptional(field, value); err != nil {
+			return err
+		}
+	}
+	amount, err := parseAmount("amount", query.Get("amount"), from, fromNetwork)
+	if err != nil {
+		return err
+	}
+	rateType, err := parseRateType("rate_type", query.Get("rate_type"))
+	if err != nil {
+		return err
+	}
+	names := s.names
+	if list := query.Get("exchange"); list != "" {
+		names = nil
+		for _, name := range strings.Split(list, ",") {
+			if _, ok := s.exchanges[name]; !ok {
+				return errUnknownExchange
+			}
+			names = append(names, name)
+		}
+	}
+
+	results := s.aggregator.QuoteExchanges(r.Context(), names, instantswap.ExchangeRateRequest{
+		From:        from,
+		FromNetwork: fromNetwork,
+		To:          to,
+		ToNetwork:   toNetwork,
+		Amount:      amount,
+		RateType:    rateType,
+	})
+	res := make([]quoteResponse, 0, len(results))
+	for _, result := range results {
+		quote := quoteResponse{Exchange: result.Exchange}
+		if result.Err != nil {
+			s.log.Printf("quote %s: %v", result.Exchange, result.Err)
+			quote.Error = exchangeError(result.Err)
+			if result.TimedOut {
+				quote.Error.Kind = "timeout"
+			}
+			res = append(res, quote)
+			continue
+		}
+		info := result.Info
+		quote.Rate = info.ExchangeRate
+		quote.RateType = info.RateType
+		if !info.ValidUntil.IsZero() {
+			quote.ValidUntil = &info.ValidUntil
+		}
+		quote.EstimatedAmount = &info.EstimatedAmount
+		quote.Min = &info.Min
+		quote.Max = &info.Max
+		quote.Signature = info.Signature
+		quote.Provider = info.Provider
+		quote.Fees = newFeesResponse(info.Fees)
+		res = append(res, quote)
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"quotes": res})
+	return nil
+}
+
+type orderRequest struct {
+	From          string `json:"from"`
+	FromNetwork   string `json:"from_network"`
+	To            string `json:"to"`
+	ToNetwork     string `json:"to_network"`
+	Amount        string `json:"amount"`
+	Destination   string `json:"destination"`
+	ExtraID       string `json:"extra_id"`
+	RefundAddress string `json:"refund_address"`
+	RefundExtraID string `json:"refund_extra_id"`
+	Signature     string `json:"signature"`
+	Provider      string `json:"provider"`
+	RateType      string `json:"rate_type"`
+}
+
+type orderResponse struct {
+	Exchange       string             `json:"exchange"`
+	OrderID        string             `json:"order_id"`
+	DepositAddress string             `json:"deposit_address"`
+	DepositExtraID string             `json:"deposit_extra_id,omitempty"`
+	SendAmount     instantswap.Amount `json:"send_amount"`
+	From           string             `json:"from"`
+	ReceiveAmount  instantswap.Amount `json:"receive_amount"`
+	To             string             `json:"to"`
+	Destination    string             `json:"destination"`
+	Rate           float64            `json:"rate,omitempty"`
+	Expires        int                `json:"expires,omitempty"`
+}
+
+func (s *server) createOrder(w http.ResponseWriter, r *http.Request, params []string) error {
+	name := params[0]
+	exchange, err := s.exchange(name)
+	if err != nil {
+		return err
+	}
+	var req orderRequest
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBodySize))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&req); err != nil {
+		return invalidRequest("invalid body: " + err.Error())
+	}
+	if err := checkSymbol("from", req.From); err != nil {
+		return err
+	}
+	if err := checkSymbol("to", req.To); err != nil {
+		return err
+	}
+	amount, err := parseAmount("amount", req.Amount, req.From, req.FromNetwork)
+	if err != nil {
+		return err
+	}
+	if req.Destination == "" {
+		return invalidRequest("missing destination")
+	}
+	rateType, err := parseRateType("rate_type", req.RateType)
+	if err != nil {
+		return err
+	}
+	for _, field := range []struct{ name, value string }{
+		{"from_network", req.FromNetwork},
+		{"to_network", req.ToNetwork},
+		{"destination", req.Destination},
+		{"extra_id", req.ExtraID},
+		{"refund_address", req.RefundAddress},
+		{"refund_extra_id", req.RefundExtraID},
+		{"signature", req.Signature},
+		{"provider", req.Provider},
+	} {
+		if err := checkOptional(field.name, field.value); err != nil {
+			return err
+		}
+	}
+
+	vars := instantswap.CreateOrder{
+		FromCurrency:   req.From,
+		FromNetwork:    req.FromNetwork,
+		ToCurrency:     req.To,
+		ToNetwork:      req.ToNetwork,
+		InvoicedAmount: amount,
+		Destination:    req.Destination,
+		ExtraID:        req.ExtraID,
+		RefundAddress:  req.RefundAddress,
+		RefundExtraID:  req.RefundExtraID,
+		Signature:      req.Signature,
+		Provider:       req.Provider,
+		RateType:       rateType,
+	}
+	res, err := order.Create(exchange, &vars, func() (context.Context, context.CancelFunc) {
+		return s.requestContext(r)
+	})
+	if err != nil {
+		return err
+	}
+	if s.store != nil {
+		if err := s.store.SaveOrder(name, vars, res); err != nil {
+			s.log.Printf("order %s of %s not recorded: %v", res.UUID, name, err)
+		}
+	}
+	writeJSON(w, http.StatusCreated, orderResponse{
+		Exchange:       name,
+		OrderID:        res.UUID,
+		DepositAddress: res.DepositAddress,
+		DepositExtraID: res.ExtraID,
+		SendAmount:     res.InvoicedAmount,
+		From:           res.FromCurrency,
+		ReceiveAmount:  res.OrderedAmount,
+		To:             res.ToCurrency,
+		Destination:    res.Destination,
+		Rate:           res.ExchangeRate,
+		Expires:        res.Expires,
+	})
+	return nil
+}
+
+type statusResponse struct {
+	Exchange       string             `json:"exchange"`
+	OrderID        string             `json:"order_id"`
+	Status         string             `json:"status"`
+	Final          bool               `json:"final"`
+	ExchangeStatus string             `json:"exchange_status"`
+	TxID           string             `json:"tx_id,omitempty"`
+	ReceiveAmount  instantswap.Amount `json:"receive_amount"`
+	Confirmations  string             `json:"confirmations,omitempty"`
+	LastUpdate     string             `json:"last_update,omitempty"`
+}
+
+// statusCode returns the status as a lower case identifier, waiting_for_deposit
+// for OrderStatusWaitingForDeposit.
+func statusCode(status instantswap.Status) string {
+	return strings.ReplaceAll(strings.ToLower(status.String()), " ", "_")
+}
+
+// orderStatus returns the status of an order, the extra_id query parameters
+// are passed to OrderInfo.
+func (s *server) orderStatus(w http.ResponseWriter, r *http.Request, params []string) error {
+	name, orderID := params[0], params[1]
+	exchange, err := s.exchange(name)
+	if err != nil {
+		return err
+	}
+	if err := checkOptional("order id", orderID); err != nil {
+		return err
+	}
+	extraIDs := r.URL.Query()["extra_id"]
+	for _, id := range extraIDs {
+		if err := checkOptional("extra_id", id); err != nil {
+			return err
+		}
+	}
+	ctx, cancel := s.requestContext(r)
+	defer cancel()
+	info, err := exchange.OrderInfo(ctx, orderID, extraIDs...)
+	if err != nil {
+		return err
+	}
+	if s.store != nil {
+		if err := s.store.AddStatus(name, orderID, info); err != nil && !errors.Is(err, instantswap.ErrOrderNotFound) {
+			s.log.Printf("status of %s of %s not recorded: %v", orderID, name, err)
+		}
+	}
+	writeJSON(w, http.StatusOK, statusResponse{
+		Exchange:       name,
+		OrderID:        orderID,
+		Status:         statusCode(info.InternalStatus),
+		Final:          info.InternalStatus.IsFinal(),
+		ExchangeStatus: info.Status,
+		TxID:           info.TxID,
+		ReceiveAmount:  info.ReceiveAmount,
+		Confirmations:  info.Confirmations,
+		LastUpdate:     info.LastUpdate,
+	})
+	return nil
+}
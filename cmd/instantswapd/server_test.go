@@ -0,0 +1,161 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/crypto-power/instantswap/cmd/internal/config"
+	"github.com/crypto-power/instantswap/instantswap/exchangetest"
+)
+
+func newTestServer(t *testing.T, fixtures ...exchangetest.Fixture) *httptest.Server {
+	exchange := exchangetest.NewServer(t, fixtures...)
+	conf := &config.Config{Exchanges: map[string]config.Exchange{
+		"changenow": {APIKey: "secretkey", BaseURL: exchange.URL + "/v1"},
+	}}
+	s, err := newServer(conf, []string{"changenow"}, []string{"client"}, 0, 0, false, nil, log.New(io.Discard, "", 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := httptest.NewServer(s)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func do(t *testing.T, server *httptest.Server, method, path, body string, res interface{}) int {
+	t.Helper()
+	req, err := http.NewRequest(method, server.URL+path, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Authorization", "Bearer client")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if err := json.NewDecoder(resp.Body).Decode(res); err != nil {
+		t.Fatal(err)
+	}
+	return resp.StatusCode
+}
+
+type errorResponse struct {
+	Error apiError `json:"error"`
+}
+
+func TestServer(t *testing.T) {
+	server := newTestServer(t, exchangetest.Fixture{
+		Method: "GET", Path: "/v1/currencies", Query: "active=true",
+		Response: `[{"ticker":"btc","name":"Bitcoin"},{"ticker":"dcr","name":"Decred"}]`,
+	}, exchangetest.Fixture{
+		Method: "GET", Path: "/v1/exchange-range/btc_dcr",
+		Response: `{"minAmount":0.0006,"maxAmount":null}`,
+	}, exchangetest.Fixture{
+		Method: "GET", Path: "/v1/exchange-amount/0.1/btc_dcr",
+		Response: `{"estimatedAmount":183.52137104,"networkFee":0.05}`,
+	}, exchangetest.Fixture{
+		Method: "POST", Path: "/v1/transactions/secretkey", Body: `"address":"DsUZxxoHJSty8DCfwfartwTYbuhmVct7tJu"`,
+		Response: `{"payinAddress":"bc1qdeposit","payoutAddress":"DsUZxxoHJSty8DCfwfartwTYbuhmVct7tJu","fromCurrency":"btc","toCurrency":"dcr",
+			"id":"a1b2c3","amount":183.52137104}`,
+	}, exchangetest.Fixture{
+		Method: "GET", Path: "/v1/transactions/a1b2c3/secretkey",
+		Response: `{"status":"finished","amountReceive":183.4,"payoutHash":"dcrtxhash"}`,
+	})
+
+	var exchanges []exchangeResponse
+	if status := do(t, server, "GET", "/v1/exchanges", "", &exchanges); status != http.StatusOK ||
+		len(exchanges) != 1 || exchanges[0].Name != "changenow" {
+		t.Errorf("unexpected exchanges %d %+v", status, exchanges)
+	}
+	var currencies []currencyResponse
+	if status := do(t, server, "GET", "/v1/exchanges/changenow/currencies", "", &currencies); status != http.StatusOK ||
+		len(currencies) != 2 {
+		t.Errorf("unexpected currencies %d %+v", status, currencies)
+	}
+	var quotes struct{ Quotes []quoteResponse }
+	if status := do(t, server, "GET", "/v1/quotes?from=btc&to=dcr&amount=0.1", "", &quotes); status != http.StatusOK ||
+		len(quotes.Quotes) != 1 || quotes.Quotes[0].EstimatedAmount.String() != "183.52137104" {
+		t.Errorf("unexpected quotes %d %+v", status, quotes)
+	} else if fees := quotes.Quotes[0].Fees; fees == nil || fees.NetworkFee == nil || fees.NetworkFee.String() != "0.05" || fees.ServiceFee != nil {
+		t.Errorf("unexpected fees %+v", fees)
+	}
+	var order orderResponse
+	body := `{"from":"btc","to":"dcr","amount":"0.1","destination":"DsUZxxoHJSty8DCfwfartwTYbuhmVct7tJu","refund_address":"bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t4"}`
+	if status := do(t, server, "POST", "/v1/exchanges/changenow/orders", body, &order); status != http.StatusCreated ||
+		order.OrderID != "a1b2c3" || order.DepositAddress != "bc1qdeposit" {
+		t.Errorf("unexpected order %d %+v", status, order)
+	}
+	var info statusResponse
+	if status := do(t, server, "GET", "/v1/exchanges/changenow/orders/a1b2c3", "", &info); status != http.StatusOK ||
+		info.Status != "completed" || !info.Final || info.TxID != "dcrtxhash" {
+		t.Errorf("unexpected status %d %+v", status, info)
+	}
+}
+
+func TestServerErrors(t *testing.T) {
+	server := newTestServer(t, exchangetest.Fixture{
+		Method: "GET", Path: "/v1/exchange-range/btc_xyz",
+		Status: http.StatusBadRequest, Response: `{"error":"pair_is_inactive","message":"Pair is inactive"}`,
+	}, exchangetest.Fixture{
+		Method: "GET", Path: "/v1/exchange-range/btc_dcr",
+		Response: `{"minAmount":0.0006,"maxAmount":null}`,
+	}, exchangetest.Fixture{
+		Method: "GET", Path: "/v1/exchange-amount/1/btc_dcr",
+		Response: `{"estimatedAmount":1835.2137104,"networkFee":0.05}`,
+	}, exchangetest.Fixture{
+		Method: "GET", Path: "/v1/transactions/missing/secretkey",
+		Status: http.StatusBadGateway, Response: `bad gateway`,
+	})
+
+	tests := []struct {
+		name, method, path, body string
+		status                   int
+		kind                     string
+	}{
+		{"unknown exchange", "GET", "/v1/exchanges/changelly/currencies", "", http.StatusNotFound, "unknown_exchange"},
+		{"unknown route", "GET", "/v1/orders", "", http.StatusNotFound, "not_found"},
+		{"method", "DELETE", "/v1/exchanges", "", http.StatusMethodNotAllowed, "method_not_allowed"},
+		{"amount", "GET", "/v1/quotes?from=btc&to=dcr&amount=-1", "", http.StatusBadRequest, "invalid_request"},
+		{"exponent", "GET", "/v1/quotes?from=btc&to=dcr&amount=1e-300000000", "", http.StatusBadRequest, "invalid_request"},
+		{"precision", "GET", "/v1/quotes?from=btc&to=dcr&amount=0.123456789", "", http.StatusBadRequest, "invalid_request"},
+		{"symbol", "GET", "/v1/quotes?from=b%20tc&to=dcr&amount=1", "", http.StatusBadRequest, "invalid_request"},
+		{"rate type", "GET", "/v1/quotes?from=btc&to=dcr&amount=1&rate_type=floating", "", http.StatusBadRequest, "invalid_request"},
+		{"body", "POST", "/v1/exchanges/changenow/orders", `{"from":"btc","to":"dcr","amount":"1","api_key":"x"}`,
+			http.StatusBadRequest, "invalid_request"},
+		{"order precision", "POST", "/v1/exchanges/changenow/orders", `{"from":"usdt","from_network":"erc20","to":"dcr","amount":"1.0000001","destination":"x"}`,
+			http.StatusBadRequest, "invalid_request"},
+		{"destination", "POST", "/v1/exchanges/changenow/orders", `{"from":"btc","to":"dcr","amount":"1"}`,
+			http.StatusBadRequest, "invalid_request"},
+		{"address", "POST", "/v1/exchanges/changenow/orders", `{"from":"btc","to":"dcr","amount":"1","destination":"Dsx"}`,
+			http.StatusUnprocessableEntity, "invalid_address"},
+		{"pair", "POST", "/v1/exchanges/changenow/orders", `{"from":"btc","to":"xyz","amount":"1","destination":"x"}`,
+			http.StatusUnprocessableEntity, "pair_unavailable"},
+		{"upstream", "GET", "/v1/exchanges/changenow/orders/missing", "", http.StatusBadGateway, "network_error"},
+	}
+	for _, test := range tests {
+		var res errorResponse
+		if status := do(t, server, test.method, test.path, test.body, &res); status != test.status || res.Error.Kind != test.kind {
+			t.Errorf("%s: unexpected error %d %+v", test.name, status, res.Error)
+		}
+		if strings.Contains(res.Error.Message, "secretkey") {
+			t.Errorf("%s: api key leaked in %q", test.name, res.Error.Message)
+		}
+	}
+
+	req, _ := http.NewRequest("GET", server.URL+"/v1/exchanges", nil)
+	req.Header.Set("X-API-Key", "wrong")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("unexpected status %d with a wrong key", resp.StatusCode)
+	}
+}
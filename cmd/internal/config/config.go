@@ -0,0 +1,142 @@
+// Package config loads the exchange settings of the instantswap commands
+// from a JSON file and the environment.
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/crypto-power/instantswap/instantswap"
+)
+
+// Config is the content of the config file, overridden by the environment.
+type Config struct {
+	// Proxy is the url of the proxy the requests are sent through, for
+	// example socks5://127.0.0.1:9050 to use Tor.
+	Proxy     string              `json:"proxy,omitempty"`
+	Exchanges map[string]Exchange `json:"exchanges,omitempty"`
+}
+
+// Exchange holds the settings of one exchange.
+type Exchange struct {
+	APIKey      string `json:"api_key,omitempty"`
+	APISecret   string `json:"api_secret,omitempty"`
+	AffiliateID string `json:"affiliate_id,omitempty"`
+	UserID      string `json:"user_id,omitempty"`
+	BaseURL     string `json:"base_url,omitempty"`
+	Onion       bool   `json:"onion,omitempty"`
+}
+
+// DefaultPath returns the config file read when none is given.
+func DefaultPath(getenv func(string) string) string {
+	if path := getenv("INSTANTSWAP_CONFIG"); path != "" {
+		return path
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "instantswap", "config.json")
+}
+
+// Load reads the config file at path, a missing file is only an error
+// when required is set. The settings found in the environment take
+// precedence over the file.
+func Load(path string, required bool, getenv func(string) string) (*Config, error) {
+	conf := &Config{}
+	if path != "" {
+		data, err := os.ReadFile(path)
+		switch {
+		case err == nil:
+			if err := json.Unmarshal(data, conf); err != nil {
+				return nil, fmt.Errorf("config %s: %v", path, err)
+			}
+		case !errors.Is(err, os.ErrNotExist) || required:
+			return nil, err
+		}
+	}
+	if conf.Exchanges == nil {
+		conf.Exchanges = make(map[string]Exchange)
+	}
+	if proxy := getenv("INSTANTSWAP_PROXY"); proxy != "" {
+		conf.Proxy = proxy
+	}
+	for _, name := range instantswap.ExchangeNames() {
+		section := conf.Exchanges[name]
+		prefix := EnvPrefix(name)
+		for suffix, field := range map[string]*string{
+			"API_KEY":      &section.APIKey,
+			"API_SECRET":   &section.APISecret,
+			"AFFILIATE_ID": &section.AffiliateID,
+			"USER_ID":      &section.UserID,
+			"BASE_URL":     &section.BaseURL,
+		} {
+			if value := getenv(prefix + suffix); value != "" {
+				*field = value
+			}
+		}
+		if section != (Exchange{}) {
+			conf.Exchanges[name] = section
+		}
+	}
+	return conf, nil
+}
+
+// EnvPrefix returns the prefix of the environment variables of an exchange,
+// INSTANTSWAP_CHANGENOW_ for changenow.
+func EnvPrefix(name string) string {
+	return "INSTANTSWAP_" + strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z':
+			return r - 'a' + 'A'
+		case r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			return r
+		default:
+			return '_'
+		}
+	}, name) + "_"
+}
+
+// Transport returns the RoundTripper using the configured proxy, nil without
+// proxy.
+func (c *Config) Transport() (http.RoundTripper, error) {
+	if c.Proxy == "" {
+		return nil, nil
+	}
+	proxyURL, err := url.Parse(c.Proxy)
+	if err != nil {
+		return nil, fmt.Errorf("invalid proxy: %v", err)
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = http.ProxyURL(proxyURL)
+	return transport, nil
+}
+
+// Configured reports whether the credentials required by the exchange are
+// set.
+func (c *Config) Configured(name string) bool {
+	capabilities, _ := instantswap.ExchangeCapabilities(name)
+	section := c.Exchanges[name]
+	return (!capabilities.APIKey || section.APIKey != "") &&
+		(!capabilities.APISecret || section.APISecret != "")
+}
+
+// ExchangeConfig returns the ExchangeConfig of the exchange name.
+func (c *Config) ExchangeConfig(name string, transport http.RoundTripper) instantswap.ExchangeConfig {
+	section := c.Exchanges[name]
+	return instantswap.ExchangeConfig{
+		ApiKey:      section.APIKey,
+		ApiSecret:   section.APISecret,
+		AffiliateId: section.AffiliateID,
+		UserId:      section.UserID,
+		BaseURL:     section.BaseURL,
+		Onion:       section.Onion,
+		Transport:   transport,
+	}
+}
@@ -0,0 +1,49 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	_ "github.com/crypto-power/instantswap/instantswap/index"
+)
+
+func TestLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	err := os.WriteFile(path, []byte(`{"exchanges":{"changenow":{"api_key":"file"},"godex":{"api_key":"file"}}}`), 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	env := map[string]string{
+		"INSTANTSWAP_CHANGENOW_API_KEY":   "env",
+		"INSTANTSWAP_CHANGELLY_API_KEY":   "key",
+		"INSTANTSWAP_PROXY":               "socks5://127.0.0.1:9050",
+		"INSTANTSWAP_FIXEDFLOAT_BASE_URL": "http://localhost/",
+	}
+	conf, err := Load(path, true, func(key string) string { return env[key] })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if key := conf.Exchanges["changenow"].APIKey; key != "env" {
+		t.Errorf("environment does not override the file, api key %q", key)
+	}
+	if key := conf.Exchanges["godex"].APIKey; key != "file" {
+		t.Errorf("unexpected godex api key %q", key)
+	}
+	if url := conf.Exchanges["fixedfloat"].BaseURL; url != "http://localhost/" {
+		t.Errorf("unexpected fixedfloat base url %q", url)
+	}
+	if conf.Proxy != env["INSTANTSWAP_PROXY"] {
+		t.Errorf("unexpected proxy %q", conf.Proxy)
+	}
+	if !conf.Configured("changenow") || conf.Configured("changelly") || !conf.Configured("flypme") {
+		t.Errorf("unexpected configured exchanges")
+	}
+
+	if _, err := Load(filepath.Join(t.TempDir(), "missing.json"), true, os.Getenv); err == nil {
+		t.Errorf("missing config file accepted")
+	}
+	if _, err := Load(filepath.Join(t.TempDir(), "missing.json"), false, os.Getenv); err != nil {
+		t.Errorf("missing default config file: %v", err)
+	}
+}
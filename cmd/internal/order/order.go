@@ -0,0 +1,40 @@
+// Package order creates the orders of the instantswap commands.
+package order
+
+import (
+	"context"
+
+	"github.com/crypto-power/instantswap/instantswap"
+)
+
+// Create creates order on exchange. An order without signature is quoted
+// first, as some exchanges only create orders for a quote they issued, and
+// its Signature and Provider are set from the quote. newContext returns the
+// context of each request. The exchange validates the order, a mistyped
+// address returns an instantswap.ErrInvalidAddress error.
+func Create(exchange instantswap.IDExchangeCtx, order *instantswap.CreateOrder,
+	newContext func() (context.Context, context.CancelFunc)) (instantswap.CreateResultInfo, error) {
+	if order.Signature == "" {
+		ctx, cancel := newContext()
+		rate, err := exchange.GetExchangeRateInfo(ctx, instantswap.ExchangeRateRequest{
+			From:        order.FromCurrency,
+			FromNetwork: order.FromNetwork,
+			To:          order.ToCurrency,
+			ToNetwork:   order.ToNetwork,
+			Amount:      order.InvoicedAmount,
+			RateType:    order.RateType,
+		})
+		cancel()
+		if err != nil {
+			return instantswap.CreateResultInfo{}, err
+		}
+		order.Signature = rate.Signature
+		if order.Provider == "" {
+			order.Provider = rate.Provider
+		}
+	}
+
+	ctx, cancel := newContext()
+	defer cancel()
+	return exchange.CreateOrder(instantswap.NotIdempotent(ctx), *order)
+}
@@ -1,18 +1,13 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"os"
 
-	_ "github.com/crypto-power/instantswap/exchange/changelly"
-	_ "github.com/crypto-power/instantswap/exchange/changenow"
-	_ "github.com/crypto-power/instantswap/exchange/coinswitch"
-	_ "github.com/crypto-power/instantswap/exchange/flypme"
-	_ "github.com/crypto-power/instantswap/exchange/godex"
-	_ "github.com/crypto-power/instantswap/exchange/simpleswap"
-	_ "github.com/crypto-power/instantswap/exchange/swapzone"
 	"github.com/crypto-power/instantswap/instantswap"
+	_ "github.com/crypto-power/instantswap/instantswap/index"
 )
 
 // using this code:
@@ -30,7 +25,7 @@ func init() {
 }
 
 func main() {
-	exchange, err := instantswap.NewExchange(exchange, instantswap.ExchangeConfig{
+	exchange, err := instantswap.NewExchangeCtx(exchange, instantswap.ExchangeConfig{
 		Debug:     false,
 		ApiKey:    apiKey,
 		ApiSecret: apiSecret,
@@ -39,25 +34,26 @@ func main() {
 		fmt.Println(err)
 		os.Exit(1)
 	}
-	currencies, err := exchange.GetCurrencies()
+	ctx := context.Background()
+	currencies, err := exchange.GetCurrencies(ctx)
 	fmt.Println("currencies length: ", len(currencies), err)
-	currencies, err = exchange.GetCurrenciesToPair("btc")
+	currencies, err = exchange.GetCurrenciesToPair(ctx, "btc")
 	fmt.Println("GetCurrenciesToPair length: ", len(currencies), err)
-	res, err := exchange.GetExchangeRateInfo(instantswap.ExchangeRateRequest{
+	res, err := exchange.GetExchangeRateInfo(ctx, instantswap.ExchangeRateRequest{
 		From:   "BTC",
 		To:     "DCR",
-		Amount: 5,
+		Amount: instantswap.MustParseAmount("0.5"),
 	})
 	fmt.Printf("%+v \n %v \n", res, err)
-	order, err := exchange.CreateOrder(instantswap.CreateOrder{
+	order, err := exchange.CreateOrder(ctx, instantswap.CreateOrder{
 		RefundAddress:   "your_btc_address", // if the trading fail, the exchange will refund here
 		Destination:     "your_dcr_address", // your received dcr address
 		FromCurrency:    "BTC",
-		OrderedAmount:   0, // use OrderedAmount or InvoicedAmount
-		InvoicedAmount:  0.5,
+		InvoicedAmount:  instantswap.MustParseAmount("0.5"), // use OrderedAmount or InvoicedAmount
 		ToCurrency:      "DCR",
 		ExtraID:         "",
 		Signature:       res.Signature,
+		Provider:        res.Provider,
 		UserReferenceID: "",
 		RefundExtraID:   "",
 	})
@@ -66,7 +62,7 @@ func main() {
 	// the exchange will return the rate of exchange is: order.ExchangeRate
 	// you will send btc to order.DepositAddress
 	// use OrderInfo to get order status
-	orderInfo, err := exchange.OrderInfo(order.UUID)
+	orderInfo, err := exchange.OrderInfo(ctx, order.UUID)
 	fmt.Println(orderInfo, err)
 
 	fmt.Println(orderInfo.InternalStatus.String())
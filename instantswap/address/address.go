@@ -0,0 +1,250 @@
+// Package address validates the addresses of the currencies traded by the
+// exchanges, so that a mistyped destination or refund address is rejected
+// before an order is created. The validators accept the mainnet addresses
+// only, the exchanges do not trade the test networks.
+package address
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ErrInvalid is wrapped by the errors of the validators.
+var ErrInvalid = errors.New("invalid address")
+
+func invalid(format string, args ...interface{}) error {
+	return fmt.Errorf("%w: %s", ErrInvalid, fmt.Sprintf(format, args...))
+}
+
+// base58Check validates a base58check address of 20 bytes hashes with one
+// of the version bytes.
+func base58Check(address string, versions ...byte) error {
+	payload, err := base58CheckDecode(address, doubleSHA256)
+	if err != nil {
+		return invalid("%v", err)
+	}
+	if len(payload) != 21 || bytes.IndexByte(versions, payload[0]) < 0 {
+		return invalid("not a mainnet address")
+	}
+	return nil
+}
+
+// segwit validates a segwit address of prefix hrp.
+func segwit(hrp, address string) error {
+	if _, _, err := segwitDecode(hrp, address); err != nil {
+		return invalid("%v", err)
+	}
+	return nil
+}
+
+// hasBech32Prefix reports whether address starts with hrp and the bech32
+// separator, in any case.
+func hasBech32Prefix(address, hrp string) bool {
+	return len(address) > len(hrp) && strings.EqualFold(address[:len(hrp)+1], hrp+"1")
+}
+
+// Bitcoin validates the P2PKH, P2SH and segwit addresses of bitcoin.
+func Bitcoin(address string) error {
+	if hasBech32Prefix(address, "bc") {
+		return segwit("bc", address)
+	}
+	return base58Check(address, 0x00, 0x05)
+}
+
+// Litecoin validates the P2PKH, P2SH, legacy P2SH and segwit addresses of
+// litecoin.
+func Litecoin(address string) error {
+	if hasBech32Prefix(address, "ltc") {
+		return segwit("ltc", address)
+	}
+	return base58Check(address, 0x30, 0x32, 0x05)
+}
+
+// Dogecoin validates the P2PKH and P2SH addresses of dogecoin.
+func Dogecoin(address string) error {
+	return base58Check(address, 0x1e, 0x16)
+}
+
+// Dash validates the P2PKH and P2SH addresses of dash.
+func Dash(address string) error {
+	return base58Check(address, 0x4c, 0x10)
+}
+
+// decredNetIDs are the 2 bytes prefixes of the mainnet addresses of decred
+// and the length of their payload.
+var decredNetIDs = map[[2]byte]int{
+	{0x07, 0x3f}: 20, // Ds, P2PKH
+	{0x07, 0x1a}: 20, // Dc, P2SH
+	{0x07, 0x1f}: 20, // De, P2PKH ed25519
+	{0x07, 0x01}: 20, // DS, P2PKH schnorr
+	{0x13, 0x86}: 33, // Dk, P2PK
+}
+
+// Decred validates the addresses of decred, checksummed with BLAKE-256.
+func Decred(address string) error {
+	payload, err := base58CheckDecode(address, doubleBLAKE256)
+	if err != nil {
+		return invalid("%v", err)
+	}
+	if len(payload) < 2 {
+		return invalid("too short")
+	}
+	length, ok := decredNetIDs[[2]byte{payload[0], payload[1]}]
+	if !ok || len(payload)-2 != length {
+		return invalid("not a mainnet address")
+	}
+	return nil
+}
+
+// Ethereum validates the addresses of ethereum and the EVM networks. The
+// EIP-55 checksum is verified when the address is mixed case.
+func Ethereum(address string) error {
+	if !strings.HasPrefix(address, "0x") && !strings.HasPrefix(address, "0X") {
+		return invalid("missing 0x prefix")
+	}
+	hexAddress := address[2:]
+	if len(hexAddress) != 40 {
+		return invalid("%d hex digits, expected 40", len(hexAddress))
+	}
+	if _, err := hex.DecodeString(hexAddress); err != nil {
+		return invalid("not hexadecimal")
+	}
+	lower := strings.ToLower(hexAddress)
+	if hexAddress == lower || hexAddress == strings.ToUpper(hexAddress) {
+		return nil
+	}
+	hash := keccak256([]byte(lower))
+	for i := 0; i < len(hexAddress); i++ {
+		c := hexAddress[i]
+		if c < 'A' {
+			continue
+		}
+		nibble := hash[i/2] >> 4
+		if i%2 == 1 {
+			nibble = hash[i/2] & 0x0f
+		}
+		if (nibble >= 8) != (c <= 'F') {
+			return invalid("bad EIP-55 checksum")
+		}
+	}
+	return nil
+}
+
+// The network bytes of the monero mainnet addresses.
+const (
+	moneroStandard   = 18
+	moneroIntegrated = 19
+	moneroSubaddress = 42
+)
+
+// Monero validates the standard, integrated and subaddresses of monero.
+func Monero(address string) error {
+	b, err := moneroBase58Decode(address)
+	if err != nil {
+		return invalid("%v", err)
+	}
+	if len(b) < 5 {
+		return invalid("too short")
+	}
+	payload, checksum := b[:len(b)-4], b[len(b)-4:]
+	if h := keccak256(payload); !bytes.Equal(h[:4], checksum) {
+		return invalid("%v", errChecksum)
+	}
+	// The network byte, the public spend and view keys, and the payment id
+	// of the integrated addresses.
+	switch {
+	case len(payload) == 65 && (payload[0] == moneroStandard || payload[0] == moneroSubaddress):
+	case len(payload) == 73 && payload[0] == moneroIntegrated:
+	default:
+		return invalid("not a mainnet address")
+	}
+	return nil
+}
+
+// Zcash validates the transparent, sapling, unified and TEX addresses of
+// zcash.
+func Zcash(address string) error {
+	if strings.HasPrefix(address, "t") {
+		payload, err := base58CheckDecode(address, doubleSHA256)
+		if err != nil {
+			return invalid("%v", err)
+		}
+		if len(payload) != 22 || payload[0] != 0x1c || payload[1] != 0xb8 && payload[1] != 0xbd {
+			return invalid("not a mainnet address")
+		}
+		return nil
+	}
+	// Unified addresses are much longer than the 90 characters of BIP 173.
+	hrp, data, constant, err := bech32Decode(address, 1024)
+	if err != nil {
+		return invalid("%v", err)
+	}
+	b, err := convertBits(data)
+	if err != nil {
+		return invalid("%v", err)
+	}
+	switch {
+	case hrp == "zs" && constant == bech32Const && len(b) == 43:
+	case hrp == "u" && constant == bech32mConst && len(b) >= 48:
+	case hrp == "tex" && constant == bech32mConst && len(b) == 20:
+	default:
+		return invalid("not a mainnet address")
+	}
+	return nil
+}
+
+// Aptos validates the account addresses of aptos, 0x and up to 64 hex
+// digits.
+func Aptos(address string) error {
+	if !strings.HasPrefix(address, "0x") {
+		return invalid("missing 0x prefix")
+	}
+	digits := address[2:]
+	if len(digits) == 0 || len(digits) > 64 {
+		return invalid("%d hex digits, expected at most 64", len(digits))
+	}
+	if strings.Trim(digits, "0123456789abcdefABCDEF") != "" {
+		return invalid("not hexadecimal")
+	}
+	return nil
+}
+
+// Tron validates the base58 addresses of tron.
+func Tron(address string) error {
+	return base58Check(address, 0x41)
+}
+
+// Solana validates the addresses of solana, base58 public keys.
+func Solana(address string) error {
+	b, err := base58Decode(address)
+	if err != nil {
+		return invalid("%v", err)
+	}
+	if len(b) != 32 {
+		return invalid("%d bytes, expected 32", len(b))
+	}
+	return nil
+}
+
+// DestinationTag validates a ripple destination tag, an unsigned 32 bits
+// integer.
+func DestinationTag(tag string) error {
+	if _, err := strconv.ParseUint(tag, 10, 32); err != nil {
+		return fmt.Errorf("%w: destination tag %q is not a 32 bits integer", ErrInvalid, tag)
+	}
+	return nil
+}
+
+// Memo returns a validator of the text memos of at most maxLength bytes.
+func Memo(maxLength int) func(string) error {
+	return func(memo string) error {
+		if len(memo) > maxLength {
+			return fmt.Errorf("%w: memo longer than %d bytes", ErrInvalid, maxLength)
+		}
+		return nil
+	}
+}
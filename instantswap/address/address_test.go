@@ -0,0 +1,107 @@
+package address
+
+import (
+	"encoding/hex"
+	"errors"
+	"testing"
+)
+
+func TestHashes(t *testing.T) {
+	tests := []struct {
+		name string
+		sum  [32]byte
+		hash string
+	}{
+		{"keccak256 empty", keccak256(nil), "c5d2460186f7233c927e7db2dcc703c0e500b653ca82273b7bfad8045d85a470"},
+		{"blake256 empty", blake256(nil), "716f6e863f744b9ac22c97ec7b76ea5f5908bc5b2f67c61510bfc4751384ea7a"},
+		{"blake256 1 byte", blake256([]byte{0}), "0ce8d4ef4dd7cd8d62dfded9d4edb0a774ae6a41929a74da23109e8f11139c87"},
+		{"blake256 72 bytes", blake256(make([]byte, 72)), "d419bad32d504fb7d44d460c42c5593fe544fa4c135dec31e21bd9abdcc22d41"},
+	}
+	for _, test := range tests {
+		if got := hex.EncodeToString(test.sum[:]); got != test.hash {
+			t.Errorf("%s: got %s, expected %s", test.name, got, test.hash)
+		}
+	}
+}
+
+func TestValidators(t *testing.T) {
+	tests := []struct {
+		name     string
+		validate func(string) error
+		address  string
+		valid    bool
+	}{
+		{"btc p2pkh", Bitcoin, "1BvBMSEYstWetqTFn5Au4m4GFg7xJaNVN2", true},
+		{"btc p2sh", Bitcoin, "3J98t1WpEZ73CNmQviecrnyiWrnqRhWNLy", true},
+		{"btc p2wpkh", Bitcoin, "bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t4", true},
+		{"btc p2wpkh upper case", Bitcoin, "BC1QW508D6QEJXTDG4Y5R3ZARVARY0C5XW7KV8F3T4", true},
+		{"btc p2tr", Bitcoin, "bc1p0xlxvlhemja6c4dqv22uapctqupfhlxm9h8z3k2e72q4k9hcz7vqzk5jj0", true},
+		{"btc checksum", Bitcoin, "1BvBMSEYstWetqTFn5Au4m4GFg7xJaNVN3", false},
+		{"btc bech32 checksum", Bitcoin, "bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t5", false},
+		{"btc v1 bech32", Bitcoin, "bc1pw508d6qejxtdg4y5r3zarvary0c5xw7kw508d6qejxtdg4y5r3zarvary0c5xw7k7grplx", false},
+		{"btc testnet", Bitcoin, "tb1qw508d6qejxtdg4y5r3zarvary0c5xw7kxpjzsx", false},
+		{"btc mixed case", Bitcoin, "bc1qW508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t4", false},
+		{"ltc p2pkh", Litecoin, "LVg2kJoFNg45Nbpy53h7Fe1wKyeXVRhMH9", true},
+		{"ltc p2sh", Litecoin, "3J98t1WpEZ73CNmQviecrnyiWrnqRhWNLy", true},
+		{"ltc bitcoin segwit", Litecoin, "bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t4", false},
+		{"ltc btc p2pkh", Litecoin, "1BvBMSEYstWetqTFn5Au4m4GFg7xJaNVN2", false},
+		{"doge p2pkh", Dogecoin, "DH5yaieqoZN36fDVciNyRueRGvGLR3mr7L", true},
+		{"doge btc p2pkh", Dogecoin, "1BvBMSEYstWetqTFn5Au4m4GFg7xJaNVN2", false},
+		{"dcr p2pkh", Decred, "DsUZxxoHJSty8DCfwfartwTYbuhmVct7tJu", true},
+		{"dcr p2sh", Decred, "DcuQKx8BES9wU7C6Q5VmLBjw436r27hayjS", true},
+		{"dcr checksum", Decred, "DsUZxxoHJSty8DCfwfartwTYbuhmVct7tJv", false},
+		{"dcr btc address", Decred, "1BvBMSEYstWetqTFn5Au4m4GFg7xJaNVN2", false},
+		{"dash p2pkh", Dash, "XpESxaUmonkq8RaLLp46Brx2K39ggQe226", true},
+		{"eth checksum", Ethereum, "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed", true},
+		{"eth checksum 2", Ethereum, "0xfB6916095ca1df60bB79Ce92cE3Ea74c37c5d359", true},
+		{"eth checksum 3", Ethereum, "0xD1220A0cf47c7B9Be7A2E6BA89F429762e7b9aDb", true},
+		{"eth lower case", Ethereum, "0x5aaeb6053f3e94c9b9a09f33669435e7ef1beaed", true},
+		{"eth bad checksum", Ethereum, "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAeD", false},
+		{"eth short", Ethereum, "0x5aaeb6053f3e94c9b9a09f33669435e7ef1bea", false},
+		{"eth no prefix", Ethereum, "5aaeb6053f3e94c9b9a09f33669435e7ef1beaed", false},
+		{"xmr standard", Monero, "44AFFq5kSiGBoZ4NMDwYtN18obc8AemS33DBLWs3H7otXft3XjrpDtQGv7SqSsaBYBb98uNbr2VBBEt7f2wfn3RVGQBEP3A", true},
+		{"xmr subaddress", Monero, "888tNkZrPN6JsEgekjMnABU4TBzc2Dt29EPAvkRxbANsAnjyPbb3iQ1YBRk1UXcdRsiKc9dhwMVgN5S9cQUiyoogDavup3H", true},
+		{"xmr integrated", Monero, "4LL9oSLmtpccfufTMvppY6JwXNouMBzSkbLYfpAV5Usx3skxNgYeYTRj5UzqtReoS44qo9mtmXCqY45DJ852K5Jv2bYXZKKQePHES9khPK", true},
+		{"xmr checksum", Monero, "44AFFq5kSiGBoZ4NMDwYtN18obc8AemS33DBLWs3H7otXft3XjrpDtQGv7SqSsaBYBb98uNbr2VBBEt7f2wfn3RVGQBEP3B", false},
+		{"xmr short", Monero, "44AFFq5kSiGBoZ4NMDwYtN18obc8AemS33DBLWs3H7otXft3XjrpDtQGv7SqSsaBYBb98uNbr2VBBEt7f2wfn3RVGQ", false},
+		{"zec t1", Zcash, "t1Hsc1LR8yKnbbe3twRp88p6vFfC5t7DLbs", true},
+		{"zec t3", Zcash, "t3Vz22vK5z2LcKEdg16Yv4FFneEL1zg9ojd", true},
+		{"zec sapling", Zcash, "zs1z7rejlpsa98s2rrrfkwmaxu53e4ue0ulcrw0h4x5g8jl04tak0d3mm47vdtahatqrlkngh9slya", true},
+		{"zec sapling checksum", Zcash, "zs1z7rejlpsa98s2rrrfkwmaxu53e4ue0ulcrw0h4x5g8jl04tak0d3mm47vdtahatqrlkngh9slyb", false},
+		{"zec btc address", Zcash, "1BvBMSEYstWetqTFn5Au4m4GFg7xJaNVN2", false},
+		{"apt", Aptos, "0x1", true},
+		{"apt long", Aptos, "0xeeff357ea5c1a4e7bc11b2b17ff2dc2dcca69750bfef1e1ebcaccf8c8018175b", true},
+		{"apt too long", Aptos, "0xeeff357ea5c1a4e7bc11b2b17ff2dc2dcca69750bfef1e1ebcaccf8c8018175b0", false},
+		{"apt not hex", Aptos, "0xzz", false},
+		{"trx", Tron, "TR7NHqjeKQxGTCi8q8ZY4pL8otSzgjLj6t", true},
+		{"trx btc address", Tron, "1BvBMSEYstWetqTFn5Au4m4GFg7xJaNVN2", false},
+		{"sol", Solana, "EPjFWWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1", true},
+		{"sol not base58", Solana, "0PjFWWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1", false},
+	}
+	for _, test := range tests {
+		err := test.validate(test.address)
+		if test.valid && err != nil {
+			t.Errorf("%s: %v", test.name, err)
+		}
+		if !test.valid && !errors.Is(err, ErrInvalid) {
+			t.Errorf("%s: expected ErrInvalid, got %v", test.name, err)
+		}
+	}
+}
+
+func TestMemos(t *testing.T) {
+	if err := DestinationTag("4294967295"); err != nil {
+		t.Error(err)
+	}
+	for _, tag := range []string{"4294967296", "-1", "tag"} {
+		if err := DestinationTag(tag); !errors.Is(err, ErrInvalid) {
+			t.Errorf("destination tag %q: expected ErrInvalid, got %v", tag, err)
+		}
+	}
+	if err := Memo(4)("memo"); err != nil {
+		t.Error(err)
+	}
+	if err := Memo(4)("memo!"); !errors.Is(err, ErrInvalid) {
+		t.Errorf("expected ErrInvalid, got %v", err)
+	}
+}
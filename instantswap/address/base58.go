@@ -0,0 +1,111 @@
+package address
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"math/bits"
+	"strings"
+)
+
+const base58Alphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+
+var errChecksum = errors.New("bad checksum")
+
+// base58Decode decodes the bitcoin base58 encoding, the leading 1s being
+// zero bytes.
+func base58Decode(s string) ([]byte, error) {
+	if s == "" {
+		return nil, errors.New("empty string")
+	}
+	var out []byte
+	for _, c := range []byte(s) {
+		carry := strings.IndexByte(base58Alphabet, c)
+		if carry < 0 {
+			return nil, errors.New("invalid base58 character " + string(c))
+		}
+		for i := len(out) - 1; i >= 0; i-- {
+			carry += int(out[i]) * 58
+			out[i] = byte(carry)
+			carry >>= 8
+		}
+		for ; carry > 0; carry >>= 8 {
+			out = append([]byte{byte(carry)}, out...)
+		}
+	}
+	zeros := len(s) - len(strings.TrimLeft(s, "1"))
+	return append(make([]byte, zeros), out...), nil
+}
+
+// base58CheckDecode decodes a base58 string ending with the 4 bytes checksum
+// computed by sum, returning the payload without the checksum.
+func base58CheckDecode(s string, sum func([]byte) [32]byte) ([]byte, error) {
+	b, err := base58Decode(s)
+	if err != nil {
+		return nil, err
+	}
+	if len(b) < 5 {
+		return nil, errors.New("too short")
+	}
+	payload, checksum := b[:len(b)-4], b[len(b)-4:]
+	if h := sum(payload); !bytes.Equal(h[:4], checksum) {
+		return nil, errChecksum
+	}
+	return payload, nil
+}
+
+func doubleSHA256(b []byte) [32]byte {
+	h := sha256.Sum256(b)
+	return sha256.Sum256(h[:])
+}
+
+func doubleBLAKE256(b []byte) [32]byte {
+	h := blake256(b)
+	return blake256(h[:])
+}
+
+// moneroBlockSizes are the number of characters of the encoded blocks of 0
+// to 8 bytes in the monero base58 encoding.
+var moneroBlockSizes = [9]int{0, 2, 3, 5, 6, 7, 9, 10, 11}
+
+// moneroBase58Decode decodes the monero base58 encoding, which encodes blocks
+// of 8 bytes in 11 characters.
+func moneroBase58Decode(s string) ([]byte, error) {
+	var out []byte
+	for len(s) > 0 {
+		n := 11
+		if len(s) < n {
+			n = len(s)
+		}
+		size := -1
+		for i, l := range moneroBlockSizes {
+			if l == n {
+				size = i
+			}
+		}
+		if size <= 0 {
+			return nil, errors.New("invalid length")
+		}
+		var v uint64
+		for _, c := range []byte(s[:n]) {
+			d := strings.IndexByte(base58Alphabet, c)
+			if d < 0 {
+				return nil, errors.New("invalid base58 character " + string(c))
+			}
+			hi, lo := bits.Mul64(v, 58)
+			if hi != 0 || lo+uint64(d) < lo {
+				return nil, errors.New("block overflow")
+			}
+			v = lo + uint64(d)
+		}
+		if size < 8 && v>>(8*size) != 0 {
+			return nil, errors.New("block overflow")
+		}
+		var block [8]byte
+		binary.BigEndian.PutUint64(block[:], v)
+		out = append(out, block[8-size:]...)
+		s = s[n:]
+	}
+	return out, nil
+}
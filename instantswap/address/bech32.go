@@ -0,0 +1,118 @@
+package address
+
+import (
+	"errors"
+	"strings"
+)
+
+const bech32Charset = "qpzry9x8gf2tvdw0s3jn54khce6mua7l"
+
+// The checksum constants of bech32 and bech32m, BIP 173 and BIP 350.
+const (
+	bech32Const  = 1
+	bech32mConst = 0x2bc830a3
+)
+
+func bech32Polymod(values []byte) uint32 {
+	gen := [5]uint32{0x3b6a57b2, 0x26508e6d, 0x1ea119fa, 0x3d4233dd, 0x2a1462b3}
+	chk := uint32(1)
+	for _, v := range values {
+		top := chk >> 25
+		chk = (chk&0x1ffffff)<<5 ^ uint32(v)
+		for i := 0; i < 5; i++ {
+			if (top>>i)&1 == 1 {
+				chk ^= gen[i]
+			}
+		}
+	}
+	return chk
+}
+
+// bech32Decode decodes a bech32 or bech32m string of at most maxLength
+// characters, returning its human readable part, the 5 bits values of its
+// data part without the checksum and the checksum constant.
+func bech32Decode(s string, maxLength int) (hrp string, data []byte, constant uint32, err error) {
+	if len(s) > maxLength {
+		return "", nil, 0, errors.New("too long")
+	}
+	if strings.ToLower(s) != s && strings.ToUpper(s) != s {
+		return "", nil, 0, errors.New("mixed case")
+	}
+	s = strings.ToLower(s)
+	sep := strings.LastIndexByte(s, '1')
+	if sep < 1 || sep+7 > len(s) {
+		return "", nil, 0, errors.New("invalid separator position")
+	}
+	hrp = s[:sep]
+	values := make([]byte, 0, len(hrp)*2+1+len(s)-sep-1)
+	for _, c := range []byte(hrp) {
+		if c < 33 || c > 126 {
+			return "", nil, 0, errors.New("invalid character in prefix")
+		}
+		values = append(values, c>>5)
+	}
+	values = append(values, 0)
+	for _, c := range []byte(hrp) {
+		values = append(values, c&31)
+	}
+	for _, c := range []byte(s[sep+1:]) {
+		d := strings.IndexByte(bech32Charset, c)
+		if d < 0 {
+			return "", nil, 0, errors.New("invalid bech32 character " + string(c))
+		}
+		data = append(data, byte(d))
+	}
+	values = append(values, data...)
+	constant = bech32Polymod(values)
+	if constant != bech32Const && constant != bech32mConst {
+		return "", nil, 0, errChecksum
+	}
+	return hrp, data[:len(data)-6], constant, nil
+}
+
+// convertBits regroups the 5 bits values of a bech32 data part in bytes, the
+// padding bits must be zeros.
+func convertBits(data []byte) ([]byte, error) {
+	var acc, bits uint
+	out := make([]byte, 0, len(data)*5/8)
+	for _, v := range data {
+		acc = acc<<5 | uint(v)
+		bits += 5
+		if bits >= 8 {
+			bits -= 8
+			out = append(out, byte(acc>>bits))
+		}
+	}
+	if bits >= 5 || acc&(1<<bits-1) != 0 {
+		return nil, errors.New("invalid padding")
+	}
+	return out, nil
+}
+
+// segwitDecode decodes a segwit address of prefix hrp, BIP 173 and BIP 350.
+func segwitDecode(hrp, s string) (version byte, program []byte, err error) {
+	prefix, data, constant, err := bech32Decode(s, 90)
+	if err != nil {
+		return 0, nil, err
+	}
+	if prefix != hrp {
+		return 0, nil, errors.New("unexpected prefix " + prefix)
+	}
+	if len(data) < 1 || data[0] > 16 {
+		return 0, nil, errors.New("invalid witness version")
+	}
+	version = data[0]
+	program, err = convertBits(data[1:])
+	if err != nil {
+		return 0, nil, err
+	}
+	switch {
+	case len(program) < 2 || len(program) > 40:
+		return 0, nil, errors.New("invalid program length")
+	case version == 0 && len(program) != 20 && len(program) != 32:
+		return 0, nil, errors.New("invalid program length")
+	case version == 0 && constant != bech32Const, version != 0 && constant != bech32mConst:
+		return 0, nil, errors.New("wrong checksum variant")
+	}
+	return version, program, nil
+}
@@ -0,0 +1,110 @@
+package address
+
+import (
+	"encoding/binary"
+	"math/bits"
+)
+
+var blake256IV = [8]uint32{
+	0x6A09E667, 0xBB67AE85, 0x3C6EF372, 0xA54FF53A,
+	0x510E527F, 0x9B05688C, 0x1F83D9AB, 0x5BE0CD19,
+}
+
+var blake256C = [16]uint32{
+	0x243F6A88, 0x85A308D3, 0x13198A2E, 0x03707344,
+	0xA4093822, 0x299F31D0, 0x082EFA98, 0xEC4E6C89,
+	0x452821E6, 0x38D01377, 0xBE5466CF, 0x34E90C6C,
+	0xC0AC29B7, 0xC97C50DD, 0x3F84D5B5, 0xB5470917,
+}
+
+var blakeSigma = [10][16]uint8{
+	{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15},
+	{14, 10, 4, 8, 9, 15, 13, 6, 1, 12, 0, 2, 11, 7, 5, 3},
+	{11, 8, 12, 0, 5, 2, 15, 13, 10, 14, 3, 6, 7, 1, 9, 4},
+	{7, 9, 3, 1, 13, 12, 11, 14, 2, 6, 5, 10, 4, 0, 15, 8},
+	{9, 0, 5, 7, 2, 4, 10, 15, 14, 1, 11, 12, 6, 8, 3, 13},
+	{2, 12, 6, 10, 0, 11, 8, 3, 4, 13, 7, 5, 15, 14, 1, 9},
+	{12, 5, 1, 15, 14, 13, 4, 10, 0, 7, 6, 3, 9, 2, 8, 11},
+	{13, 11, 7, 14, 12, 1, 3, 9, 5, 0, 15, 4, 8, 6, 2, 10},
+	{6, 15, 14, 9, 11, 3, 0, 8, 12, 2, 13, 7, 1, 4, 10, 5},
+	{10, 2, 8, 4, 7, 6, 1, 5, 15, 11, 9, 14, 3, 12, 13, 0},
+}
+
+// blake256Block compresses a 64 bytes block, t being the number of message
+// bits hashed at the end of the block.
+func blake256Block(h *[8]uint32, block []byte, t uint64) {
+	var m [16]uint32
+	for i := range m {
+		m[i] = binary.BigEndian.Uint32(block[i*4:])
+	}
+	var v [16]uint32
+	copy(v[:8], h[:])
+	copy(v[8:], blake256C[:8])
+	v[12] ^= uint32(t)
+	v[13] ^= uint32(t)
+	v[14] ^= uint32(t >> 32)
+	v[15] ^= uint32(t >> 32)
+
+	g := func(r, i, a, b, c, d int) {
+		s := &blakeSigma[r%10]
+		x, y := s[2*i], s[2*i+1]
+		v[a] += v[b] + (m[x] ^ blake256C[y])
+		v[d] = bits.RotateLeft32(v[d]^v[a], -16)
+		v[c] += v[d]
+		v[b] = bits.RotateLeft32(v[b]^v[c], -12)
+		v[a] += v[b] + (m[y] ^ blake256C[x])
+		v[d] = bits.RotateLeft32(v[d]^v[a], -8)
+		v[c] += v[d]
+		v[b] = bits.RotateLeft32(v[b]^v[c], -7)
+	}
+	for r := 0; r < 14; r++ {
+		g(r, 0, 0, 4, 8, 12)
+		g(r, 1, 1, 5, 9, 13)
+		g(r, 2, 2, 6, 10, 14)
+		g(r, 3, 3, 7, 11, 15)
+		g(r, 4, 0, 5, 10, 15)
+		g(r, 5, 1, 6, 11, 12)
+		g(r, 6, 2, 7, 8, 13)
+		g(r, 7, 3, 4, 9, 14)
+	}
+	for i := range h {
+		h[i] ^= v[i] ^ v[i+8]
+	}
+}
+
+// blake256 is the BLAKE-256 hash used by Decred.
+func blake256(data []byte) [32]byte {
+	h := blake256IV
+	length := uint64(len(data)) * 8
+	var t uint64
+	for len(data) >= 64 {
+		t += 512
+		blake256Block(&h, data[:64], t)
+		data = data[64:]
+	}
+	// The padding is a 1 bit, zeros, a 1 bit and the length. A block holding
+	// no message bit is compressed with a null counter.
+	var last [128]byte
+	n := copy(last[:], data)
+	last[n] = 0x80
+	counter := length
+	if n == 0 {
+		counter = 0
+	}
+	if n < 56 {
+		last[55] |= 0x01
+		binary.BigEndian.PutUint64(last[56:], length)
+		blake256Block(&h, last[:64], counter)
+	} else {
+		last[119] |= 0x01
+		binary.BigEndian.PutUint64(last[120:], length)
+		blake256Block(&h, last[:64], counter)
+		blake256Block(&h, last[64:], 0)
+	}
+
+	var sum [32]byte
+	for i, w := range h {
+		binary.BigEndian.PutUint32(sum[i*4:], w)
+	}
+	return sum
+}
@@ -0,0 +1,218 @@
+package instantswap
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// AggregatorConfig configures an Aggregator.
+type AggregatorConfig struct {
+	// Exchanges lists the exchanges to query, all registered exchanges are
+	// used when it is empty.
+	Exchanges []string
+	// Configs holds the config of each exchange by name. Exchanges without
+	// an entry are created with an empty ExchangeConfig.
+	Configs map[string]ExchangeConfig
+	// Timeout bounds the time spent waiting on each exchange. Zero means
+	// only the caller's context applies.
+	Timeout time.Duration
+	// Empty creates the aggregator without any exchange, Exchanges and
+	// Configs are ignored. The exchanges are then added with Add.
+	Empty bool
+}
+
+// QuoteResult is the answer of one exchange to an aggregated quote request.
+type QuoteResult struct {
+	Exchange string
+	Info     ExchangeRateInfo
+	// Err is set when the exchange could not be created, the request failed
+	// or the amount is outside of the exchange limits (ErrAmountOutOfRange).
+	Err error
+	// TimedOut reports that the exchange did not answer before the timeout.
+	TimedOut bool
+	Latency  time.Duration
+}
+
+// Aggregator fans out quote requests to several exchanges concurrently.
+type Aggregator struct {
+	mux       sync.RWMutex
+	timeout   time.Duration
+	exchanges map[string]IDExchangeCtx
+	failed    map[string]error
+}
+
+// NewAggregator creates an Aggregator over the configured exchanges.
+// Exchanges which can not be created, because of a missing api key for
+// example, are reported with their error in every Quote result.
+func NewAggregator(conf AggregatorConfig) *Aggregator {
+	a := &Aggregator{
+		timeout:   conf.Timeout,
+		exchanges: make(map[string]IDExchangeCtx),
+		failed:    make(map[string]error),
+	}
+	if conf.Empty {
+		return a
+	}
+	names := conf.Exchanges
+	if len(names) == 0 {
+		names = ExchangeNames()
+	}
+	for _, name := range names {
+		exchange, err := NewExchangeCtx(name, conf.Configs[name])
+		if err != nil {
+			a.failed[name] = err
+			continue
+		}
+		a.exchanges[name] = exchange
+	}
+	return a
+}
+
+// Add adds or replaces an exchange queried by the aggregator.
+func (a *Aggregator) Add(name string, exchange IDExchangeCtx) {
+	a.mux.Lock()
+	defer a.mux.Unlock()
+	delete(a.failed, name)
+	a.exchanges[name] = exchange
+}
+
+// Quote requests a rate from every exchange concurrently and returns the
+// results ranked from the best estimated amount to the worst, or from the
+// smallest deposit for a DirectionTo request. Results with an error are placed
+// after the successful ones, ordered by exchange name.
+func (a *Aggregator) Quote(ctx context.Context, vars ExchangeRateRequest) []QuoteResult {
+	return a.quoteExchanges(ctx, nil, vars)
+}
+
+// QuoteExchanges is Quote limited to the exchanges names. Names which are not
+// known by the aggregator are reported with an error.
+func (a *Aggregator) QuoteExchanges(ctx context.Context, names []string, vars ExchangeRateRequest) []QuoteResult {
+	selected := make(map[string]bool, len(names))
+	for _, name := range names {
+		selected[name] = true
+	}
+	return a.quoteExchanges(ctx, selected, vars)
+}
+
+// quoteExchanges quotes the exchanges of selected, every exchange when it is
+// nil.
+func (a *Aggregator) quoteExchanges(ctx context.Context, selected map[string]bool, vars ExchangeRateRequest) []QuoteResult {
+	a.mux.RLock()
+	results := make([]QuoteResult, 0, len(a.exchanges)+len(a.failed))
+	for name, err := range a.failed {
+		if selected == nil || selected[name] {
+			results = append(results, QuoteResult{Exchange: name, Err: err})
+		}
+	}
+	for name := range selected {
+		if _, ok := a.exchanges[name]; !ok && a.failed[name] == nil {
+			results = append(results, QuoteResult{Exchange: name,
+				Err: fmt.Errorf("[%s] exchange is not in the aggregator", name)})
+		}
+	}
+	var wg sync.WaitGroup
+	var resMux sync.Mutex
+	for name, exchange := range a.exchanges {
+		if selected != nil && !selected[name] {
+			continue
+		}
+		wg.Add(1)
+		go func(name string, exchange IDExchangeCtx) {
+			defer wg.Done()
+			res := a.quote(ctx, name, exchange, vars)
+			resMux.Lock()
+			results = append(results, res)
+			resMux.Unlock()
+		}(name, exchange)
+	}
+	a.mux.RUnlock()
+	wg.Wait()
+
+	sort.SliceStable(results, func(i, j int) bool {
+		ri, rj := results[i], results[j]
+		if (ri.Err == nil) != (rj.Err == nil) {
+			return ri.Err == nil
+		}
+		if ri.Err == nil && vars.Direction == DirectionTo {
+			di, dj := ri.depositAmount(vars.Amount), rj.depositAmount(vars.Amount)
+			if di.IsZero() != dj.IsZero() {
+				return dj.IsZero()
+			}
+			if c := di.Cmp(dj); c != 0 {
+				return c < 0
+			}
+		} else if ri.Err == nil {
+			ai, aj := estimatedOutput(vars.Amount, ri.Info), estimatedOutput(vars.Amount, rj.Info)
+			if c := ai.Cmp(aj); c != 0 {
+				return c > 0
+			}
+		}
+		return ri.Exchange < rj.Exchange
+	})
+	return results
+}
+
+func (a *Aggregator) quote(ctx context.Context, name string, exchange IDExchangeCtx, vars ExchangeRateRequest) QuoteResult {
+	if a.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, a.timeout)
+		defer cancel()
+	}
+	start := time.Now()
+	info, err := exchange.GetExchangeRateInfo(ctx, vars)
+	res := QuoteResult{
+		Exchange: name,
+		Info:     info,
+		Err:      err,
+		Latency:  time.Since(start),
+	}
+	if err != nil {
+		res.TimedOut = errors.Is(err, context.DeadlineExceeded) || ctx.Err() == context.DeadlineExceeded
+		return res
+	}
+	deposit := vars.Amount
+	if vars.Direction == DirectionTo {
+		deposit = res.depositAmount(vars.Amount)
+	}
+	if deposit.Cmp(info.Min) < 0 || (info.Max.Sign() > 0 && deposit.Cmp(info.Max) > 0) {
+		res.Err = fmt.Errorf("%w: %s accepts [%v, %v], got %v", ErrAmountOutOfRange,
+			name, info.Min, info.Max, deposit)
+	}
+	return res
+}
+
+// estimatedOutput returns the amount received for sending amount at the quote
+// info. It is computed from the exchange rate and the network fee when the
+// exchange does not estimate it.
+func estimatedOutput(amount Amount, info ExchangeRateInfo) Amount {
+	if info.EstimatedAmount.Sign() > 0 {
+		return info.EstimatedAmount
+	}
+	return amount.MulFloat(info.ExchangeRate).Sub(info.Fees.NetworkFee)
+}
+
+// depositAmount returns the deposit of a reverse quote of amount, computed
+// from the exchange rate when the exchange does not estimate it.
+func (r QuoteResult) depositAmount(amount Amount) Amount {
+	if r.Info.DepositAmount.Sign() > 0 {
+		return r.Info.DepositAmount
+	}
+	if r.Info.ExchangeRate <= 0 {
+		return Amount{}
+	}
+	return amount.MulFloat(1 / r.Info.ExchangeRate)
+}
+
+// BestQuote returns the first successful result of ranked quote results.
+func BestQuote(results []QuoteResult) (QuoteResult, bool) {
+	for _, res := range results {
+		if res.Err == nil {
+			return res, true
+		}
+	}
+	return QuoteResult{}, false
+}
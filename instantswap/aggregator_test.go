@@ -0,0 +1,101 @@
+package instantswap
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+type fakeExchange struct {
+	IDExchangeCtx
+	info  ExchangeRateInfo
+	err   error
+	delay time.Duration
+}
+
+func (f *fakeExchange) GetExchangeRateInfo(ctx context.Context, vars ExchangeRateRequest) (ExchangeRateInfo, error) {
+	select {
+	case <-time.After(f.delay):
+	case <-ctx.Done():
+		return ExchangeRateInfo{}, ctx.Err()
+	}
+	return f.info, f.err
+}
+
+func TestAggregatorQuote(t *testing.T) {
+	a := NewAggregator(AggregatorConfig{
+		Exchanges: []string{"unregistered"},
+		Timeout:   100 * time.Millisecond,
+	})
+	a.Add("low", &fakeExchange{info: ExchangeRateInfo{EstimatedAmount: AmountFromFloat(9), Min: AmountFromFloat(0.1)}})
+	a.Add("high", &fakeExchange{info: ExchangeRateInfo{EstimatedAmount: AmountFromFloat(11), Min: AmountFromFloat(0.1)}})
+	a.Add("rate", &fakeExchange{info: ExchangeRateInfo{ExchangeRate: 10}})
+	a.Add("limited", &fakeExchange{info: ExchangeRateInfo{EstimatedAmount: AmountFromFloat(20), Min: AmountFromFloat(2)}})
+	a.Add("failing", &fakeExchange{err: errors.New("boom")})
+	a.Add("slow", &fakeExchange{delay: time.Second})
+
+	results := a.Quote(context.Background(), ExchangeRateRequest{From: "BTC", To: "DCR", Amount: AmountFromFloat(1)})
+	expected := []string{"high", "rate", "low", "failing", "limited", "slow", "unregistered"}
+	if len(results) != len(expected) {
+		t.Fatalf("got %d results, expected %d", len(results), len(expected))
+	}
+	for i, name := range expected {
+		if results[i].Exchange != name {
+			t.Errorf("result %d: got exchange %s, expected %s", i, results[i].Exchange, name)
+		}
+	}
+	for _, res := range results {
+		switch res.Exchange {
+		case "limited":
+			if !errors.Is(res.Err, ErrAmountOutOfRange) {
+				t.Errorf("limited: expected out of range error, got %v", res.Err)
+			}
+		case "slow":
+			if !res.TimedOut {
+				t.Errorf("slow: expected timeout, got %v", res.Err)
+			}
+		}
+	}
+	best, ok := BestQuote(results)
+	if !ok || best.Exchange != "high" {
+		t.Errorf("unexpected best quote: %+v", best)
+	}
+}
+
+func TestAggregatorQuoteExchanges(t *testing.T) {
+	a := NewAggregator(AggregatorConfig{Empty: true})
+	a.Add("low", &fakeExchange{info: ExchangeRateInfo{EstimatedAmount: AmountFromFloat(9)}})
+	a.Add("high", &fakeExchange{info: ExchangeRateInfo{EstimatedAmount: AmountFromFloat(11)}})
+	a.Add("other", &fakeExchange{info: ExchangeRateInfo{EstimatedAmount: AmountFromFloat(12)}})
+
+	results := a.QuoteExchanges(context.Background(), []string{"low", "high", "missing"},
+		ExchangeRateRequest{From: "BTC", To: "DCR", Amount: AmountFromFloat(1)})
+	expected := []string{"high", "low", "missing"}
+	if len(results) != len(expected) {
+		t.Fatalf("got %d results, expected %d", len(results), len(expected))
+	}
+	for i, name := range expected {
+		if results[i].Exchange != name {
+			t.Errorf("result %d: got exchange %s, expected %s", i, results[i].Exchange, name)
+		}
+	}
+	if results[2].Err == nil {
+		t.Errorf("missing: expected an error")
+	}
+	if results := a.Quote(context.Background(), ExchangeRateRequest{Amount: AmountFromFloat(1)}); len(results) != 3 {
+		t.Errorf("got %d results, expected 3", len(results))
+	}
+}
+
+func TestAggregatorQuoteNetworkFee(t *testing.T) {
+	a := NewAggregator(AggregatorConfig{Empty: true})
+	a.Add("estimated", &fakeExchange{info: ExchangeRateInfo{EstimatedAmount: AmountFromFloat(9)}})
+	// 1 * 10 - 2 of network fee is less than the 9 estimated above.
+	a.Add("rate", &fakeExchange{info: ExchangeRateInfo{ExchangeRate: 10, Fees: Fees{NetworkFee: AmountFromFloat(2)}}})
+
+	results := a.Quote(context.Background(), ExchangeRateRequest{From: "BTC", To: "DCR", Amount: AmountFromFloat(1)})
+	if len(results) != 2 || results[0].Exchange != "estimated" || results[1].Exchange != "rate" {
+		t.Errorf("unexpected ranking %+v", results)
+	}
+}
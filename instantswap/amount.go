@@ -0,0 +1,379 @@
+package instantswap
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/crypto-power/instantswap/blockexplorer/global/interfaces/idaemon"
+)
+
+// Amount is an exact decimal amount. It is stored as an integer coefficient
+// scaled by 10^-scale so amounts of 18 decimals tokens or 12 decimals XMR
+// are not rounded. Amount marshals to a JSON string and unmarshals from a
+// JSON string or number without losing precision. The zero value is 0.
+type Amount struct {
+	coef  *big.Int
+	scale int
+}
+
+var bigTen = big.NewInt(10)
+
+// MaxAmountScale bounds the exponent and the number of decimals accepted by
+// ParseAmount. The arithmetic on amounts rescales the coefficients by powers
+// of ten, an unbounded exponent read from an API response would make them
+// compute huge numbers.
+const MaxAmountScale = 64
+
+func pow10(n int) *big.Int {
+	return new(big.Int).Exp(bigTen, big.NewInt(int64(n)), nil)
+}
+
+// newAmount returns coef*10^-scale with the trailing zeros of coef removed.
+func newAmount(coef *big.Int, scale int) Amount {
+	if coef.Sign() == 0 {
+		return Amount{}
+	}
+	coef = new(big.Int).Set(coef)
+	rem := new(big.Int)
+	for scale > 0 {
+		q, r := new(big.Int).QuoRem(coef, bigTen, rem)
+		if r.Sign() != 0 {
+			break
+		}
+		coef = q
+		scale--
+	}
+	if scale < 0 {
+		coef.Mul(coef, pow10(-scale))
+		scale = 0
+	}
+	return Amount{coef: coef, scale: scale}
+}
+
+// ParseAmount parses a decimal string like "0.00012", "-3" or "1.5e-7". The
+// exponent and the resulting number of decimals must not exceed
+// MaxAmountScale in absolute value.
+func ParseAmount(s string) (Amount, error) {
+	str := strings.TrimSpace(s)
+	mantissa, exp := str, 0
+	if i := strings.IndexAny(str, "eE"); i >= 0 {
+		var err error
+		exp, err = strconv.Atoi(str[i+1:])
+		if err != nil {
+			return Amount{}, fmt.Errorf("invalid amount %q", s)
+		}
+		mantissa = str[:i]
+	}
+	neg := strings.HasPrefix(mantissa, "-")
+	if neg || strings.HasPrefix(mantissa, "+") {
+		mantissa = mantissa[1:]
+	}
+	intPart, fracPart := mantissa, ""
+	if i := strings.IndexByte(mantissa, '.'); i >= 0 {
+		intPart, fracPart = mantissa[:i], mantissa[i+1:]
+	}
+	digits := intPart + fracPart
+	if digits == "" || strings.Trim(digits, "0123456789") != "" {
+		return Amount{}, fmt.Errorf("invalid amount %q", s)
+	}
+	scale := len(fracPart) - exp
+	if exp > MaxAmountScale || exp < -MaxAmountScale || scale > MaxAmountScale || scale < -MaxAmountScale {
+		return Amount{}, fmt.Errorf("amount %q exceeds %d digits of scale", s, MaxAmountScale)
+	}
+	coef, _ := new(big.Int).SetString(digits, 10)
+	if neg {
+		coef.Neg(coef)
+	}
+	return newAmount(coef, scale), nil
+}
+
+// MustParseAmount is like ParseAmount but panics on error.
+func MustParseAmount(s string) Amount {
+	a, err := ParseAmount(s)
+	if err != nil {
+		panic(err)
+	}
+	return a
+}
+
+// AmountFromString returns the amount of str, or 0 if str is not a valid
+// amount.
+func AmountFromString(str string) Amount {
+	a, _ := ParseAmount(str)
+	return a
+}
+
+// AmountFromFloat converts f using its shortest decimal representation.
+// NaN and infinities convert to 0.
+func AmountFromFloat(f float64) Amount {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return Amount{}
+	}
+	return AmountFromString(strconv.FormatFloat(f, 'f', -1, 64))
+}
+
+// AmountFromUnits returns the amount of units base units of a currency with
+// the given number of decimals.
+func AmountFromUnits(units *big.Int, decimals int) Amount {
+	return newAmount(units, decimals)
+}
+
+// AmountFromDaemon converts a blockexplorer idaemon.Amount, which is counted
+// in 1e-8 units.
+func AmountFromDaemon(a idaemon.Amount) Amount {
+	return AmountFromUnits(big.NewInt(int64(a)), 8)
+}
+
+func (a Amount) bigCoef() *big.Int {
+	if a.coef == nil {
+		return new(big.Int)
+	}
+	return a.coef
+}
+
+// rescale returns the coefficient of a expressed with scale decimals, digits
+// beyond scale are truncated.
+func (a Amount) rescale(scale int) *big.Int {
+	coef := new(big.Int).Set(a.bigCoef())
+	if scale >= a.scale {
+		return coef.Mul(coef, pow10(scale-a.scale))
+	}
+	return coef.Quo(coef, pow10(a.scale-scale))
+}
+
+// String returns the exact decimal representation of the amount.
+func (a Amount) String() string {
+	if a.coef == nil || a.coef.Sign() == 0 {
+		return "0"
+	}
+	digits := new(big.Int).Abs(a.coef).String()
+	if len(digits) <= a.scale {
+		digits = strings.Repeat("0", a.scale-len(digits)+1) + digits
+	}
+	var sign string
+	if a.coef.Sign() < 0 {
+		sign = "-"
+	}
+	if a.scale == 0 {
+		return sign + digits
+	}
+	point := len(digits) - a.scale
+	return sign + digits[:point] + "." + digits[point:]
+}
+
+// Float64 returns the nearest float64 value of the amount.
+func (a Amount) Float64() float64 {
+	f, _ := strconv.ParseFloat(a.String(), 64)
+	return f
+}
+
+// Sign returns -1, 0 or 1 depending on the sign of the amount.
+func (a Amount) Sign() int {
+	return a.bigCoef().Sign()
+}
+
+// IsZero reports whether the amount is 0.
+func (a Amount) IsZero() bool {
+	return a.Sign() == 0
+}
+
+// Cmp compares a and b and returns -1, 0 or 1.
+func (a Amount) Cmp(b Amount) int {
+	scale := a.scale
+	if b.scale > scale {
+		scale = b.scale
+	}
+	return a.rescale(scale).Cmp(b.rescale(scale))
+}
+
+// Add returns a+b.
+func (a Amount) Add(b Amount) Amount {
+	scale := a.scale
+	if b.scale > scale {
+		scale = b.scale
+	}
+	return newAmount(new(big.Int).Add(a.rescale(scale), b.rescale(scale)), scale)
+}
+
+// Sub returns a-b.
+func (a Amount) Sub(b Amount) Amount {
+	scale := a.scale
+	if b.scale > scale {
+		scale = b.scale
+	}
+	return newAmount(new(big.Int).Sub(a.rescale(scale), b.rescale(scale)), scale)
+}
+
+// Mul returns a*b.
+func (a Amount) Mul(b Amount) Amount {
+	return newAmount(new(big.Int).Mul(a.bigCoef(), b.bigCoef()), a.scale+b.scale)
+}
+
+// MulFloat returns a multiplied by f, f is converted with AmountFromFloat.
+// It is meant to apply exchange rates.
+func (a Amount) MulFloat(f float64) Amount {
+	return a.Mul(AmountFromFloat(f))
+}
+
+// Quo returns a/b truncated to decimals. It returns 0 when b is 0.
+func (a Amount) Quo(b Amount, decimals int) Amount {
+	if b.IsZero() {
+		return Amount{}
+	}
+	// a/b = (ca*10^-sa) / (cb*10^-sb), scaled to get decimals digits.
+	num := new(big.Int).Mul(a.bigCoef(), pow10(decimals+b.scale))
+	den := new(big.Int).Mul(b.bigCoef(), pow10(a.scale))
+	return newAmount(num.Quo(num, den), decimals)
+}
+
+// ExchangeRate returns the rate of a swap of deposit for received, the units
+// of received per unit of deposit as in ExchangeRateInfo.ExchangeRate. It is
+// 0 when deposit is not positive, NaN and infinite rates cannot be encoded in
+// JSON.
+func ExchangeRate(received, deposit Amount) float64 {
+	if deposit.Sign() <= 0 {
+		return 0
+	}
+	return received.Float64() / deposit.Float64()
+}
+
+// Truncate returns a with the digits beyond decimals removed.
+func (a Amount) Truncate(decimals int) Amount {
+	if a.scale <= decimals {
+		return a
+	}
+	return newAmount(a.rescale(decimals), decimals)
+}
+
+// Units returns the amount in base units of a currency with the given number
+// of decimals, digits beyond decimals are truncated.
+func (a Amount) Units(decimals int) *big.Int {
+	return a.rescale(decimals)
+}
+
+// DaemonAmount converts the amount to a blockexplorer idaemon.Amount, which is
+// counted in 1e-8 units. Digits beyond 8 decimals are truncated.
+func (a Amount) DaemonAmount() (idaemon.Amount, error) {
+	units := a.Units(8)
+	if !units.IsInt64() {
+		return 0, fmt.Errorf("amount %s overflows idaemon.Amount", a)
+	}
+	return idaemon.Amount(units.Int64()), nil
+}
+
+// MarshalJSON implements json.Marshaler, the amount is encoded as a string.
+func (a Amount) MarshalJSON() ([]byte, error) {
+	return []byte(strconv.Quote(a.String())), nil
+}
+
+// UnmarshalJSON implements json.Unmarshaler. It accepts JSON numbers, strings
+// and null.
+func (a *Amount) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if bytes.Equal(data, []byte("null")) {
+		*a = Amount{}
+		return nil
+	}
+	str := string(data)
+	if strings.HasPrefix(str, `"`) {
+		var err error
+		str, err = strconv.Unquote(str)
+		if err != nil {
+			return err
+		}
+		if strings.TrimSpace(str) == "" {
+			*a = Amount{}
+			return nil
+		}
+	}
+	amount, err := ParseAmount(str)
+	if err != nil {
+		return err
+	}
+	*a = amount
+	return nil
+}
+
+// decimalsKey is the currency of a decimals entry, on network or on every
+// network when it is empty.
+type decimalsKey struct {
+	symbol, network string
+}
+
+var currencyDecimals = struct {
+	sync.RWMutex
+	decimals map[decimalsKey]int
+}{
+	decimals: map[decimalsKey]int{
+		{"btc", ""}:   8,
+		{"bch", ""}:   8,
+		{"ltc", ""}:   8,
+		{"doge", ""}:  8,
+		{"dash", ""}:  8,
+		{"dcr", ""}:   8,
+		{"zec", ""}:   8,
+		{"apt", ""}:   8,
+		{"xmr", ""}:   12,
+		{"eth", ""}:   18,
+		{"bnb", ""}:   18,
+		{"matic", ""}: 18,
+		{"dai", ""}:   18,
+		{"trx", ""}:   6,
+		// The tokens issued on several networks do not have the same
+		// decimals on each.
+		{"usdt", NetworkEthereum}: 6,
+		{"usdt", NetworkTron}:     6,
+		{"usdt", NetworkBSC}:      18,
+		{"usdt", NetworkPolygon}:  6,
+		{"usdt", NetworkSolana}:   6,
+		{"usdc", NetworkEthereum}: 6,
+		{"usdc", NetworkBSC}:      18,
+		{"usdc", NetworkPolygon}:  6,
+		{"usdc", NetworkSolana}:   6,
+	},
+}
+
+// CurrencyDecimals returns the number of decimals of the currency symbol on
+// network and whether they are known. The network may be empty for the
+// currencies having the same decimals on every network, the decimals of a
+// token are unknown without its network.
+func CurrencyDecimals(symbol, network string) (int, bool) {
+	symbol = strings.ToLower(strings.TrimSpace(symbol))
+	network = CanonicalNetwork(symbol, network)
+	currencyDecimals.RLock()
+	defer currencyDecimals.RUnlock()
+	if network != "" {
+		if decimals, ok := currencyDecimals.decimals[decimalsKey{symbol, network}]; ok {
+			return decimals, true
+		}
+	}
+	decimals, ok := currencyDecimals.decimals[decimalsKey{symbol, ""}]
+	return decimals, ok
+}
+
+// RegisterCurrencyDecimals sets the number of decimals of a currency on
+// network, or on every network when it is empty. It is used for tokens which
+// are not known by default.
+func RegisterCurrencyDecimals(symbol, network string, decimals int) {
+	symbol = strings.ToLower(strings.TrimSpace(symbol))
+	network = CanonicalNetwork(symbol, network)
+	currencyDecimals.Lock()
+	defer currencyDecimals.Unlock()
+	currencyDecimals.decimals[decimalsKey{symbol, network}] = decimals
+}
+
+// TruncateFor truncates the amount to the precision of the currency symbol
+// on network, amounts of currencies whose decimals are unknown are returned
+// unchanged.
+func (a Amount) TruncateFor(symbol, network string) Amount {
+	decimals, ok := CurrencyDecimals(symbol, network)
+	if !ok {
+		return a
+	}
+	return a.Truncate(decimals)
+}
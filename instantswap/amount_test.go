@@ -0,0 +1,155 @@
+package instantswap
+
+import (
+	"encoding/json"
+	"math/big"
+	"strings"
+	"testing"
+)
+
+func TestParseAmount(t *testing.T) {
+	tests := []struct {
+		in, out string
+	}{
+		{"0", "0"},
+		{"1.50", "1.5"},
+		{"-0.000001", "-0.000001"},
+		{"+12", "12"},
+		{".5", "0.5"},
+		{"1.5e-7", "0.00000015"},
+		{"2E3", "2000"},
+		{"123456789.123456789012345678", "123456789.123456789012345678"},
+	}
+	for _, test := range tests {
+		a, err := ParseAmount(test.in)
+		if err != nil {
+			t.Errorf("ParseAmount(%q): %v", test.in, err)
+			continue
+		}
+		if a.String() != test.out {
+			t.Errorf("ParseAmount(%q) = %s, expected %s", test.in, a, test.out)
+		}
+	}
+	for _, in := range []string{"", "-", "1.2.3", "abc", "1e", "--1", "1e1.5"} {
+		if _, err := ParseAmount(in); err == nil {
+			t.Errorf("ParseAmount(%q): expected error", in)
+		}
+	}
+}
+
+func TestParseAmountScaleLimit(t *testing.T) {
+	for _, in := range []string{"1e-64", "1e64", "0." + strings.Repeat("0", 63) + "1"} {
+		if _, err := ParseAmount(in); err != nil {
+			t.Errorf("ParseAmount(%q): %v", in, err)
+		}
+	}
+	huge := []string{
+		"1e-300000000",
+		"1e300000000",
+		"1e-65",
+		"1e65",
+		"0.5e-64",
+		"0." + strings.Repeat("0", 64) + "1",
+		"1e-9223372036854775807",
+	}
+	for _, in := range huge {
+		if _, err := ParseAmount(in); err == nil {
+			t.Errorf("ParseAmount(%q): expected error", in)
+		}
+	}
+	var a Amount
+	if err := json.Unmarshal([]byte(`1e-300000000`), &a); err == nil {
+		t.Errorf("expected error unmarshalling a huge negative exponent")
+	}
+	if err := json.Unmarshal([]byte(`"1e300000000"`), &a); err == nil {
+		t.Errorf("expected error unmarshalling a huge positive exponent")
+	}
+}
+
+func TestAmountJSON(t *testing.T) {
+	var v struct {
+		A Amount `json:"a"`
+		B Amount `json:"b"`
+		C Amount `json:"c"`
+		D Amount `json:"d"`
+	}
+	data := `{"a":"0.123456789012345678","b":1.000000000001,"c":null,"d":""}`
+	if err := json.Unmarshal([]byte(data), &v); err != nil {
+		t.Fatal(err)
+	}
+	if v.A.String() != "0.123456789012345678" || v.B.String() != "1.000000000001" || !v.C.IsZero() || !v.D.IsZero() {
+		t.Fatalf("unexpected amounts: %v %v %v %v", v.A, v.B, v.C, v.D)
+	}
+	out, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `{"a":"0.123456789012345678","b":"1.000000000001","c":"0","d":"0"}`
+	if string(out) != expected {
+		t.Errorf("got %s, expected %s", out, expected)
+	}
+}
+
+func TestAmountArithmetic(t *testing.T) {
+	a := MustParseAmount("1.5")
+	b := MustParseAmount("0.25")
+	if s := a.Add(b).String(); s != "1.75" {
+		t.Errorf("Add: got %s", s)
+	}
+	if s := b.Sub(a).String(); s != "-1.25" {
+		t.Errorf("Sub: got %s", s)
+	}
+	if s := a.Mul(b).String(); s != "0.375" {
+		t.Errorf("Mul: got %s", s)
+	}
+	if s := MustParseAmount("1").Quo(MustParseAmount("3"), 8).String(); s != "0.33333333" {
+		t.Errorf("Quo: got %s", s)
+	}
+	if r := ExchangeRate(MustParseAmount("180.5"), MustParseAmount("0.1")); r != 1805 {
+		t.Errorf("ExchangeRate: got %v", r)
+	}
+	if r := ExchangeRate(MustParseAmount("180.5"), Amount{}); r != 0 {
+		t.Errorf("ExchangeRate of no deposit: got %v", r)
+	}
+	if a.Cmp(b) != 1 || b.Cmp(a) != -1 || a.Cmp(MustParseAmount("1.500")) != 0 {
+		t.Error("unexpected Cmp result")
+	}
+	for _, test := range []struct {
+		symbol, network, expected string
+	}{
+		{"BTC", "", "1.12345678"},
+		{"BTC", "Mainnet", "1.12345678"},
+		{"unknown", "", "1.123456789123456789"},
+		{"USDT", "erc20", "1.123456"},
+		{"usdt", "BEP20", "1.123456789123456789"},
+		// The decimals of a token are unknown without its network.
+		{"usdt", "", "1.123456789123456789"},
+		{"usdt", "unknown", "1.123456789123456789"},
+	} {
+		if s := MustParseAmount("1.123456789123456789").TruncateFor(test.symbol, test.network).String(); s != test.expected {
+			t.Errorf("TruncateFor %s on %q: got %s, expected %s", test.symbol, test.network, s, test.expected)
+		}
+	}
+}
+
+func TestAmountUnits(t *testing.T) {
+	a := MustParseAmount("1.000000000000000001")
+	units := a.Units(18)
+	if units.String() != "1000000000000000001" {
+		t.Errorf("Units: got %s", units)
+	}
+	if AmountFromUnits(units, 18).Cmp(a) != 0 {
+		t.Errorf("AmountFromUnits: got %s", AmountFromUnits(units, 18))
+	}
+	d, err := MustParseAmount("0.123456789").DaemonAmount()
+	if err != nil || d != 12345678 {
+		t.Errorf("DaemonAmount: got %d, %v", d, err)
+	}
+	if s := AmountFromDaemon(d).String(); s != "0.12345678" {
+		t.Errorf("AmountFromDaemon: got %s", s)
+	}
+	huge := AmountFromUnits(new(big.Int).Lsh(big.NewInt(1), 80), 0)
+	if _, err := huge.DaemonAmount(); err == nil {
+		t.Error("DaemonAmount: expected overflow error")
+	}
+}
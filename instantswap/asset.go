@@ -0,0 +1,448 @@
+package instantswap
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// The canonical networks. Currency.Networks, the networks of
+// ExchangeRateRequest and CreateOrder use these names for every exchange.
+const (
+	NetworkBitcoin     = "bitcoin"
+	NetworkBitcoinCash = "bitcoincash"
+	NetworkLitecoin    = "litecoin"
+	NetworkDogecoin    = "dogecoin"
+	NetworkDash        = "dash"
+	NetworkDecred      = "decred"
+	NetworkZcash       = "zcash"
+	NetworkMonero      = "monero"
+	NetworkLightning   = "lightning"
+	NetworkEthereum    = "ethereum"
+	NetworkBSC         = "bsc"
+	NetworkTron        = "tron"
+	NetworkPolygon     = "polygon"
+	NetworkSolana      = "solana"
+	NetworkArbitrum    = "arbitrum"
+	NetworkOptimism    = "optimism"
+	NetworkAvalanche   = "avalanche"
+	NetworkAptos       = "aptos"
+	NetworkRipple      = "ripple"
+	NetworkStellar     = "stellar"
+	NetworkEOS         = "eos"
+	NetworkCosmos      = "cosmos"
+	NetworkHedera      = "hedera"
+	NetworkTON         = "ton"
+)
+
+// Asset is a currency on a network, named the same for every exchange: the
+// symbol is upper case and the network is canonical.
+type Asset struct {
+	Symbol  string
+	Network string
+	// Contract is the address of the token contract, empty for the coin of
+	// the network.
+	Contract string
+}
+
+// networkAliases are the names given to the canonical networks by the
+// exchanges, lower case.
+var networkAliases = map[string]string{
+	"btc":                 NetworkBitcoin,
+	"bch":                 NetworkBitcoinCash,
+	"bitcoin cash":        NetworkBitcoinCash,
+	"ltc":                 NetworkLitecoin,
+	"doge":                NetworkDogecoin,
+	"dcr":                 NetworkDecred,
+	"zec":                 NetworkZcash,
+	"xmr":                 NetworkMonero,
+	"ln":                  NetworkLightning,
+	"lightning network":   NetworkLightning,
+	"eth":                 NetworkEthereum,
+	"erc20":               NetworkEthereum,
+	"erc-20":              NetworkEthereum,
+	"bep20":               NetworkBSC,
+	"bep-20":              NetworkBSC,
+	"bnb":                 NetworkBSC,
+	"bnb smart chain":     NetworkBSC,
+	"binance smart chain": NetworkBSC,
+	"trx":                 NetworkTron,
+	"trc20":               NetworkTron,
+	"trc-20":              NetworkTron,
+	"matic":               NetworkPolygon,
+	"polygon pos":         NetworkPolygon,
+	"sol":                 NetworkSolana,
+	"spl":                 NetworkSolana,
+	"arb":                 NetworkArbitrum,
+	"arbitrum one":        NetworkArbitrum,
+	"op":                  NetworkOptimism,
+	"avax":                NetworkAvalanche,
+	"avaxc":               NetworkAvalanche,
+	"avax c-chain":        NetworkAvalanche,
+	"avalanche c-chain":   NetworkAvalanche,
+	"apt":                 NetworkAptos,
+	"xrp":                 NetworkRipple,
+	"xrp ledger":          NetworkRipple,
+	"xlm":                 NetworkStellar,
+	"atom":                NetworkCosmos,
+	"cosmos hub":          NetworkCosmos,
+	"hbar":                NetworkHedera,
+	"toncoin":             NetworkTON,
+}
+
+var assets = struct {
+	sync.RWMutex
+	// symbols are the assets of each symbol, the coin of its own network
+	// first.
+	symbols  map[string][]Asset
+	mappings map[string]AssetMapping
+}{
+	symbols: map[string][]Asset{
+		"BTC":   {{Symbol: "BTC", Network: NetworkBitcoin}},
+		"BCH":   {{Symbol: "BCH", Network: NetworkBitcoinCash}},
+		"LTC":   {{Symbol: "LTC", Network: NetworkLitecoin}},
+		"DOGE":  {{Symbol: "DOGE", Network: NetworkDogecoin}},
+		"DASH":  {{Symbol: "DASH", Network: NetworkDash}},
+		"DCR":   {{Symbol: "DCR", Network: NetworkDecred}},
+		"ZEC":   {{Symbol: "ZEC", Network: NetworkZcash}},
+		"XMR":   {{Symbol: "XMR", Network: NetworkMonero}},
+		"ETH":   {{Symbol: "ETH", Network: NetworkEthereum}},
+		"BNB":   {{Symbol: "BNB", Network: NetworkBSC}},
+		"TRX":   {{Symbol: "TRX", Network: NetworkTron}},
+		"MATIC": {{Symbol: "MATIC", Network: NetworkPolygon}},
+		"SOL":   {{Symbol: "SOL", Network: NetworkSolana}},
+		"AVAX":  {{Symbol: "AVAX", Network: NetworkAvalanche}},
+		"APT":   {{Symbol: "APT", Network: NetworkAptos}},
+		"XRP":   {{Symbol: "XRP", Network: NetworkRipple}},
+		"XLM":   {{Symbol: "XLM", Network: NetworkStellar}},
+		"EOS":   {{Symbol: "EOS", Network: NetworkEOS}},
+		"ATOM":  {{Symbol: "ATOM", Network: NetworkCosmos}},
+		"HBAR":  {{Symbol: "HBAR", Network: NetworkHedera}},
+		"TON":   {{Symbol: "TON", Network: NetworkTON}},
+		"USDT": {
+			{Symbol: "USDT", Network: NetworkEthereum, Contract: "0xdAC17F958D2ee523a2206206994597C13D831ec7"},
+			{Symbol: "USDT", Network: NetworkTron, Contract: "TR7NHqjeKQxGTCi8q8ZY4pL8otSzgjLj6t"},
+			{Symbol: "USDT", Network: NetworkBSC, Contract: "0x55d398326f99059fF775485246999027B3197955"},
+			{Symbol: "USDT", Network: NetworkPolygon, Contract: "0xc2132D05D31c914a87C6611C10748AEb04B58e8F"},
+			{Symbol: "USDT", Network: NetworkSolana, Contract: "Es9vMFrzaCERmJfrF4H2FYD4KCoNkY11McCe8BenwNYB"},
+		},
+		"USDC": {
+			{Symbol: "USDC", Network: NetworkEthereum, Contract: "0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48"},
+			{Symbol: "USDC", Network: NetworkBSC, Contract: "0x8AC76a51cc950d9822D68b83fE1Ad97B32Cd580d"},
+			{Symbol: "USDC", Network: NetworkPolygon, Contract: "0x3c499c542cEF5E3811e1192ce70d8cC03d5c3359"},
+			{Symbol: "USDC", Network: NetworkSolana, Contract: "EPjFWWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1"},
+		},
+	},
+	mappings: make(map[string]AssetMapping),
+}
+
+// CanonicalSymbol returns the canonical form of a currency symbol.
+func CanonicalSymbol(symbol string) string {
+	return strings.ToUpper(strings.TrimSpace(symbol))
+}
+
+// CanonicalNetwork returns the canonical name of the network of symbol named
+// name by an exchange or a user. "mainnet" and the symbol itself name the
+// network of the coin. Unknown networks are returned in lower case.
+func CanonicalNetwork(symbol, name string) string {
+	network := strings.ToLower(strings.TrimSpace(name))
+	if network == "" {
+		return ""
+	}
+	if alias, ok := networkAliases[network]; ok {
+		network = alias
+	}
+	if network == "mainnet" || network == "main" || network == strings.ToLower(strings.TrimSpace(symbol)) {
+		if native, ok := NativeNetwork(symbol); ok {
+			return native
+		}
+	}
+	return network
+}
+
+// NativeNetwork returns the network whose coin is symbol, ok is false for the
+// tokens and the unknown currencies.
+func NativeNetwork(symbol string) (network string, ok bool) {
+	assets.RLock()
+	defer assets.RUnlock()
+	for _, asset := range assets.symbols[CanonicalSymbol(symbol)] {
+		if asset.Contract == "" {
+			return asset.Network, true
+		}
+	}
+	return "", false
+}
+
+// LookupAsset returns the asset of symbol on network, the coin of symbol if
+// network is empty.
+func LookupAsset(symbol, network string) (Asset, bool) {
+	network = CanonicalNetwork(symbol, network)
+	assets.RLock()
+	defer assets.RUnlock()
+	for _, asset := range assets.symbols[CanonicalSymbol(symbol)] {
+		if asset.Network == network || network == "" && asset.Contract == "" {
+			return asset, true
+		}
+	}
+	return Asset{}, false
+}
+
+// Assets returns the known networks of symbol.
+func Assets(symbol string) []Asset {
+	assets.RLock()
+	defer assets.RUnlock()
+	return append([]Asset(nil), assets.symbols[CanonicalSymbol(symbol)]...)
+}
+
+// RegisterAsset adds an asset to the registry, or replaces the asset of the
+// same symbol and network.
+func RegisterAsset(asset Asset) {
+	asset.Symbol = CanonicalSymbol(asset.Symbol)
+	asset.Network = CanonicalNetwork(asset.Symbol, asset.Network)
+	assets.Lock()
+	defer assets.Unlock()
+	list := assets.symbols[asset.Symbol]
+	for i := range list {
+		if list[i].Network == asset.Network {
+			list[i] = asset
+			return
+		}
+	}
+	if asset.Contract == "" {
+		list = append([]Asset{asset}, list...)
+	} else {
+		list = append(list, asset)
+	}
+	assets.symbols[asset.Symbol] = list
+}
+
+// AssetMapping is how an exchange names the assets, registered with
+// RegisterAssetMapping. The registered exchanges translate the canonical
+// symbols and networks of the requests with it, and return canonical
+// currencies.
+type AssetMapping struct {
+	// LowerCase tells whether the exchange expects lower case symbols, upper
+	// case otherwise.
+	LowerCase bool
+	// Native is the name of the network of the coins, such as "Mainnet",
+	// when the exchange does not name it after the coin.
+	Native string
+	// Networks maps the canonical networks to the names of the exchange, the
+	// other networks are sent with their canonical name.
+	Networks map[string]string
+	// CoinNetworks tells whether the exchange names the networks after the
+	// upper case symbol of their coin, "ETH" for NetworkEthereum. Networks
+	// overrides it.
+	CoinNetworks bool
+	// Tickers names the assets the exchange lists under a ticker of their
+	// own instead of a network, "usdterc20" for USDT on NetworkEthereum for
+	// example. The assets are canonical, without contract. An exchange with
+	// tickers only accepts the networks of its tickers and the networks of
+	// the coins.
+	Tickers map[Asset]string
+}
+
+// RegisterAssetMapping sets the asset names of the exchange symbol. It is
+// called by the exchange packages next to RegisterExchangeCtx.
+func RegisterAssetMapping(symbol string, mapping AssetMapping) {
+	assets.Lock()
+	defer assets.Unlock()
+	assets.mappings[symbol] = mapping
+}
+
+// ExchangeAssetMapping returns the asset names of a registered exchange, ok
+// is false when the exchange did not declare them.
+func ExchangeAssetMapping(symbol string) (mapping AssetMapping, ok bool) {
+	assets.RLock()
+	defer assets.RUnlock()
+	mapping, ok = assets.mappings[symbol]
+	return
+}
+
+// Symbol returns the name of symbol for the exchange.
+func (m AssetMapping) Symbol(symbol string) string {
+	symbol = CanonicalSymbol(symbol)
+	if m.LowerCase {
+		return strings.ToLower(symbol)
+	}
+	return symbol
+}
+
+// ticker returns the name of symbol on network for the exchange, the name of
+// Symbol if the exchange has no ticker for it. ok is false when the exchange
+// has tickers, none of them for symbol on network, and network is not the
+// network of the coin symbol.
+func (m AssetMapping) ticker(symbol, network string) (ticker string, ok bool) {
+	network = CanonicalNetwork(symbol, network)
+	if network == "" || m.Tickers == nil {
+		return m.Symbol(symbol), true
+	}
+	if ticker, ok := m.Tickers[Asset{Symbol: CanonicalSymbol(symbol), Network: network}]; ok {
+		return ticker, true
+	}
+	if native, _ := NativeNetwork(symbol); network == native || network == "mainnet" {
+		return m.Symbol(symbol), true
+	}
+	return "", false
+}
+
+// asset returns the canonical symbol named name by the exchange, and its
+// network when name is one of the Tickers.
+func (m AssetMapping) asset(name string) (symbol, network string) {
+	for asset, ticker := range m.Tickers {
+		if strings.EqualFold(ticker, name) {
+			return asset.Symbol, asset.Network
+		}
+	}
+	return CanonicalSymbol(name), ""
+}
+
+// Network returns the name of the network of symbol for the exchange, network
+// being canonical or an alias.
+func (m AssetMapping) Network(symbol, network string) string {
+	network = CanonicalNetwork(symbol, network)
+	if network == "" {
+		return ""
+	}
+	if m.Native != "" {
+		if native, _ := NativeNetwork(symbol); network == native || network == "mainnet" {
+			return m.Native
+		}
+	}
+	if name, ok := m.Networks[network]; ok {
+		return name
+	}
+	if m.CoinNetworks {
+		if coin, ok := networkCoin(network); ok {
+			return coin
+		}
+	}
+	return network
+}
+
+// networkCoin returns the symbol of the coin of network.
+func networkCoin(network string) (symbol string, ok bool) {
+	assets.RLock()
+	defer assets.RUnlock()
+	for symbol, list := range assets.symbols {
+		for _, asset := range list {
+			if asset.Network == network && asset.Contract == "" {
+				return symbol, true
+			}
+		}
+	}
+	return "", false
+}
+
+// CanonicalNetwork returns the canonical network of symbol named name by the
+// exchange.
+func (m AssetMapping) CanonicalNetwork(symbol, name string) string {
+	for network, exchangeName := range m.Networks {
+		if strings.EqualFold(exchangeName, name) {
+			return network
+		}
+	}
+	if m.Native != "" && strings.EqualFold(m.Native, name) {
+		if native, ok := NativeNetwork(symbol); ok {
+			return native
+		}
+	}
+	return CanonicalNetwork(symbol, name)
+}
+
+// canonicalCurrencies converts the currencies returned by the exchange to the
+// canonical symbols and networks.
+func (m AssetMapping) canonicalCurrencies(currencies []Currency) {
+	for i := range currencies {
+		c := &currencies[i]
+		symbol, tickerNetwork := m.asset(c.Symbol)
+		networks := c.Networks[:0]
+		for _, name := range c.Networks {
+			network := m.CanonicalNetwork(c.Symbol, name)
+			if network != "" && !containsString(networks, network) {
+				networks = append(networks, network)
+			}
+		}
+		if tickerNetwork != "" && !containsString(networks, tickerNetwork) {
+			networks = append(networks, tickerNetwork)
+		}
+		c.Networks = networks
+		c.Symbol = symbol
+	}
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+func (e *errorExchange) getCurrencies(ctx context.Context) ([]Currency, error) {
+	res, err := e.exchange.GetCurrencies(ctx)
+	if m, ok := ExchangeAssetMapping(e.name); ok {
+		m.canonicalCurrencies(res)
+	}
+	return res, err
+}
+
+func (e *errorExchange) getCurrenciesToPair(ctx context.Context, from string) ([]Currency, error) {
+	m, ok := ExchangeAssetMapping(e.name)
+	if !ok {
+		return e.exchange.GetCurrenciesToPair(ctx, from)
+	}
+	res, err := e.exchange.GetCurrenciesToPair(ctx, m.Symbol(from))
+	m.canonicalCurrencies(res)
+	return res, err
+}
+
+func (e *errorExchange) queryLimits(ctx context.Context, fromCurr, toCurr string) (QueryLimits, error) {
+	if m, ok := ExchangeAssetMapping(e.name); ok {
+		fromCurr, toCurr = m.Symbol(fromCurr), m.Symbol(toCurr)
+	}
+	return e.exchange.QueryLimits(ctx, fromCurr, toCurr)
+}
+
+// names returns the ticker and the network name of symbol on network for the
+// exchange, or ErrNotSupported if the exchange has no ticker for it.
+func (m AssetMapping) names(symbol, network string) (string, string, error) {
+	ticker, ok := m.ticker(symbol, network)
+	if !ok {
+		return "", "", fmt.Errorf("%w: %s on the %s network", ErrNotSupported,
+			CanonicalSymbol(symbol), CanonicalNetwork(symbol, network))
+	}
+	return ticker, m.Network(symbol, network), nil
+}
+
+// exchangeRateRequest returns vars with the asset names of the exchange.
+func (m AssetMapping) exchangeRateRequest(vars ExchangeRateRequest) (ExchangeRateRequest, error) {
+	from, fromNetwork, err := m.names(vars.From, vars.FromNetwork)
+	if err != nil {
+		return vars, err
+	}
+	to, toNetwork, err := m.names(vars.To, vars.ToNetwork)
+	if err != nil {
+		return vars, err
+	}
+	vars.From, vars.FromNetwork = from, fromNetwork
+	vars.To, vars.ToNetwork = to, toNetwork
+	return vars, nil
+}
+
+// createOrder returns vars with the asset names of the exchange.
+func (m AssetMapping) createOrder(vars CreateOrder) (CreateOrder, error) {
+	from, fromNetwork, err := m.names(vars.FromCurrency, vars.FromNetwork)
+	if err != nil {
+		return vars, err
+	}
+	to, toNetwork, err := m.names(vars.ToCurrency, vars.ToNetwork)
+	if err != nil {
+		return vars, err
+	}
+	vars.FromCurrency, vars.FromNetwork = from, fromNetwork
+	vars.ToCurrency, vars.ToNetwork = to, toNetwork
+	return vars, nil
+}
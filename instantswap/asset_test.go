@@ -0,0 +1,128 @@
+package instantswap
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCanonicalNetwork(t *testing.T) {
+	tests := []struct {
+		symbol, name, network string
+	}{
+		{"USDT", "ERC20", NetworkEthereum},
+		{"usdt", "trc-20", NetworkTron},
+		{"USDT", " BEP20 ", NetworkBSC},
+		{"BTC", "Mainnet", NetworkBitcoin},
+		{"eth", "ETH", NetworkEthereum},
+		{"DCR", "dcr", NetworkDecred},
+		{"XYZ", "Mainnet", "mainnet"},
+		{"USDT", "Ethereum", NetworkEthereum},
+		{"USDT", "zkSync", "zksync"},
+		{"BTC", "", ""},
+	}
+	for _, test := range tests {
+		if got := CanonicalNetwork(test.symbol, test.name); got != test.network {
+			t.Errorf("%s on %q: got %q, expected %q", test.symbol, test.name, got, test.network)
+		}
+	}
+}
+
+func TestLookupAsset(t *testing.T) {
+	if asset, ok := LookupAsset("usdt", "TRC20"); !ok || asset.Network != NetworkTron || asset.Contract != "TR7NHqjeKQxGTCi8q8ZY4pL8otSzgjLj6t" {
+		t.Errorf("unexpected USDT asset %+v, %v", asset, ok)
+	}
+	if asset, ok := LookupAsset("ETH", ""); !ok || asset.Network != NetworkEthereum || asset.Contract != "" {
+		t.Errorf("unexpected ETH asset %+v, %v", asset, ok)
+	}
+	if _, ok := LookupAsset("USDT", ""); ok {
+		t.Error("USDT has no network of its own")
+	}
+
+	RegisterAsset(Asset{Symbol: "tst", Network: "erc20", Contract: "0x01"})
+	RegisterAsset(Asset{Symbol: "TST", Network: "tstchain"})
+	if network, ok := NativeNetwork("tst"); !ok || network != "tstchain" {
+		t.Errorf("got native network %q, %v", network, ok)
+	}
+	if assets := Assets("TST"); len(assets) != 2 || assets[0].Network != "tstchain" || assets[1].Network != NetworkEthereum {
+		t.Errorf("unexpected assets %+v", assets)
+	}
+}
+
+func TestAssetMapping(t *testing.T) {
+	m := AssetMapping{
+		LowerCase: true,
+		Native:    "Mainnet",
+		Networks:  map[string]string{NetworkEthereum: "ERC20"},
+	}
+	if got := m.Symbol("Usdt"); got != "usdt" {
+		t.Errorf("got symbol %q", got)
+	}
+	for _, test := range []struct{ symbol, network, name string }{
+		{"USDT", NetworkEthereum, "ERC20"},
+		{"USDT", "erc-20", "ERC20"},
+		{"ETH", NetworkEthereum, "Mainnet"},
+		{"BTC", "mainnet", "Mainnet"},
+		{"USDT", "TRC20", NetworkTron},
+		{"BTC", "", ""},
+	} {
+		if got := m.Network(test.symbol, test.network); got != test.name {
+			t.Errorf("%s on %s: got %q, expected %q", test.symbol, test.network, got, test.name)
+		}
+	}
+
+	currencies := []Currency{
+		{Symbol: "usdt", Networks: []string{"ERC20", "TRC20", "erc20"}},
+		{Symbol: "eth", Networks: []string{"Mainnet"}},
+	}
+	m.canonicalCurrencies(currencies)
+	if c := currencies[0]; c.Symbol != "USDT" || len(c.Networks) != 2 || c.Networks[0] != NetworkEthereum || c.Networks[1] != NetworkTron {
+		t.Errorf("unexpected currency %+v", c)
+	}
+	if c := currencies[1]; c.Symbol != "ETH" || len(c.Networks) != 1 || c.Networks[0] != NetworkEthereum {
+		t.Errorf("unexpected currency %+v", c)
+	}
+}
+
+func TestAssetMappingTickers(t *testing.T) {
+	m := AssetMapping{
+		LowerCase: true,
+		Tickers: map[Asset]string{
+			{Symbol: "USDT", Network: NetworkEthereum}: "usdterc20",
+			{Symbol: "USDT", Network: NetworkTron}:     "usdttrc20",
+		},
+	}
+	vars, err := m.exchangeRateRequest(ExchangeRateRequest{From: "BTC", To: "USDT", ToNetwork: "TRC20"})
+	if err != nil || vars.From != "btc" || vars.To != "usdttrc20" {
+		t.Errorf("unexpected request %+v, %v", vars, err)
+	}
+	_, err = m.exchangeRateRequest(ExchangeRateRequest{From: "BTC", To: "USDT", ToNetwork: NetworkPolygon})
+	if !errors.Is(err, ErrNotSupported) {
+		t.Errorf("expected ErrNotSupported, got %v", err)
+	}
+
+	currencies := []Currency{{Symbol: "usdttrc20"}, {Symbol: "btc"}}
+	m.canonicalCurrencies(currencies)
+	if c := currencies[0]; c.Symbol != "USDT" || len(c.Networks) != 1 || c.Networks[0] != NetworkTron {
+		t.Errorf("unexpected currency %+v", c)
+	}
+	if c := currencies[1]; c.Symbol != "BTC" || len(c.Networks) != 0 {
+		t.Errorf("unexpected currency %+v", c)
+	}
+}
+
+func TestAssetMappingCoinNetworks(t *testing.T) {
+	m := AssetMapping{CoinNetworks: true, Networks: map[string]string{NetworkBSC: "BSC"}}
+	for _, test := range []struct{ symbol, network, name string }{
+		{"USDT", NetworkTron, "TRX"},
+		{"USDT", "erc20", "ETH"},
+		{"USDT", NetworkBSC, "BSC"},
+		{"ETH", NetworkEthereum, "ETH"},
+	} {
+		if got := m.Network(test.symbol, test.network); got != test.name {
+			t.Errorf("%s on %s: got %q, expected %q", test.symbol, test.network, got, test.name)
+		}
+	}
+	if got := m.CanonicalNetwork("USDT", "TRX"); got != NetworkTron {
+		t.Errorf("got network %q", got)
+	}
+}
@@ -0,0 +1,230 @@
+package instantswap
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+const (
+	defaultCurrenciesTTL = time.Hour
+	defaultLimitsTTL     = 10 * time.Minute
+)
+
+// CacheConfig configures a CachedExchange.
+type CacheConfig struct {
+	// CurrenciesTTL is how long the results of GetCurrencies and
+	// GetCurrenciesToPair are reused, 1 hour by default.
+	CurrenciesTTL time.Duration
+	// LimitsTTL is how long the results of QueryLimits are reused, 10
+	// minutes by default.
+	LimitsTTL time.Duration
+	// RefreshAhead, when set, refreshes in the background the results
+	// requested during the last RefreshAhead of their TTL, so that the
+	// callers rarely wait for the exchange.
+	RefreshAhead time.Duration
+}
+
+// CachedExchange is an IDExchangeCtx reusing the currency lists, pair lists
+// and limits of an exchange, the other methods are not cached. Concurrent
+// misses of a result share one request. Errors are not cached. Close must be called to stop the background refreshes.
+type CachedExchange struct {
+	IDExchangeCtx
+	conf   CacheConfig
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	mux     sync.Mutex
+	entries map[cacheKey]*cacheEntry
+	// loads are the loads in flight, shared by the callers missing the
+	// same key.
+	loads map[cacheKey]*cacheLoad
+	// generation is incremented by the invalidations, a result requested
+	// before is not stored.
+	generation uint64
+}
+
+type cacheKey struct {
+	op       string
+	from, to string
+}
+
+type cacheEntry struct {
+	value      interface{}
+	expires    time.Time
+	refreshing bool
+}
+
+type cacheLoad struct {
+	done  chan struct{}
+	value interface{}
+	err   error
+}
+
+// NewCachedExchange wraps exchange with a cache. A legacy IDExchange is
+// wrapped with WithContext first.
+func NewCachedExchange(exchange IDExchangeCtx, conf CacheConfig) *CachedExchange {
+	if conf.CurrenciesTTL == 0 {
+		conf.CurrenciesTTL = defaultCurrenciesTTL
+	}
+	if conf.LimitsTTL == 0 {
+		conf.LimitsTTL = defaultLimitsTTL
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	return &CachedExchange{
+		IDExchangeCtx: exchange,
+		conf:          conf,
+		ctx:           ctx,
+		cancel:        cancel,
+		entries:       make(map[cacheKey]*cacheEntry),
+		loads:         make(map[cacheKey]*cacheLoad),
+	}
+}
+
+func (c *CachedExchange) GetCurrencies(ctx context.Context) ([]Currency, error) {
+	value, err := c.get(ctx, cacheKey{op: "currencies"}, c.conf.CurrenciesTTL, func(ctx context.Context) (interface{}, error) {
+		return c.IDExchangeCtx.GetCurrencies(ctx)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return copyCurrencies(value.([]Currency)), nil
+}
+
+func (c *CachedExchange) GetCurrenciesToPair(ctx context.Context, from string) ([]Currency, error) {
+	value, err := c.get(ctx, cacheKey{op: "pairs", from: CanonicalSymbol(from)}, c.conf.CurrenciesTTL, func(ctx context.Context) (interface{}, error) {
+		return c.IDExchangeCtx.GetCurrenciesToPair(ctx, from)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return copyCurrencies(value.([]Currency)), nil
+}
+
+// copyCurrencies returns a copy of the cached currencies the caller may
+// modify, their networks included.
+func copyCurrencies(currencies []Currency) []Currency {
+	res := append([]Currency(nil), currencies...)
+	for i := range res {
+		res[i].Networks = append([]string(nil), res[i].Networks...)
+	}
+	return res
+}
+
+func (c *CachedExchange) QueryLimits(ctx context.Context, fromCurr, toCurr string) (QueryLimits, error) {
+	key := cacheKey{op: "limits", from: CanonicalSymbol(fromCurr), to: CanonicalSymbol(toCurr)}
+	value, err := c.get(ctx, key, c.conf.LimitsTTL, func(ctx context.Context) (interface{}, error) {
+		return c.IDExchangeCtx.QueryLimits(ctx, fromCurr, toCurr)
+	})
+	if err != nil {
+		return QueryLimits{}, err
+	}
+	return value.(QueryLimits), nil
+}
+
+// Invalidate drops all the cached results.
+func (c *CachedExchange) Invalidate() {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	c.entries = make(map[cacheKey]*cacheEntry)
+	c.generation++
+}
+
+// InvalidateCurrency drops the cached results involving symbol: the currency
+// list, the pairs of symbol and the limits of its pairs.
+func (c *CachedExchange) InvalidateCurrency(symbol string) {
+	symbol = CanonicalSymbol(symbol)
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	for key := range c.entries {
+		if key.op == "currencies" || key.from == symbol || key.to == symbol {
+			delete(c.entries, key)
+		}
+	}
+	c.generation++
+}
+
+// Close stops the background refreshes and waits for them to return.
+func (c *CachedExchange) Close() {
+	c.cancel()
+	c.wg.Wait()
+}
+
+// get returns the cached value of key, or loads and caches it for ttl. The
+// callers missing a key being loaded wait for that load. A negative ttl
+// disables the cache of the key.
+func (c *CachedExchange) get(ctx context.Context, key cacheKey, ttl time.Duration, load func(context.Context) (interface{}, error)) (interface{}, error) {
+	if ttl < 0 {
+		return load(ctx)
+	}
+	now := time.Now()
+	c.mux.Lock()
+	if e, ok := c.entries[key]; ok && now.Before(e.expires) {
+		if c.conf.RefreshAhead > 0 && !e.refreshing && e.expires.Sub(now) <= c.conf.RefreshAhead && c.ctx.Err() == nil {
+			e.refreshing = true
+			c.wg.Add(1)
+			go c.refresh(key, ttl, load, c.generation)
+		}
+		c.mux.Unlock()
+		return e.value, nil
+	}
+	if l, ok := c.loads[key]; ok {
+		c.mux.Unlock()
+		select {
+		case <-l.done:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		if l.err == nil || !isContextError(l.err) {
+			return l.value, l.err
+		}
+		// The context of the caller which started the load ended, load
+		// with ours.
+		return c.get(ctx, key, ttl, load)
+	}
+	l := &cacheLoad{done: make(chan struct{})}
+	c.loads[key] = l
+	generation := c.generation
+	c.mux.Unlock()
+
+	l.value, l.err = load(ctx)
+	if l.err == nil {
+		c.store(key, ttl, l.value, generation)
+	}
+	c.mux.Lock()
+	delete(c.loads, key)
+	c.mux.Unlock()
+	close(l.done)
+	return l.value, l.err
+}
+
+func isContextError(err error) bool {
+	return errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)
+}
+
+// refresh reloads the value of key in the background. The expiring value is
+// kept if the exchange fails.
+func (c *CachedExchange) refresh(key cacheKey, ttl time.Duration, load func(context.Context) (interface{}, error), generation uint64) {
+	defer c.wg.Done()
+	value, err := load(c.ctx)
+	if err != nil {
+		c.mux.Lock()
+		if e, ok := c.entries[key]; ok {
+			e.refreshing = false
+		}
+		c.mux.Unlock()
+		return
+	}
+	c.store(key, ttl, value, generation)
+}
+
+func (c *CachedExchange) store(key cacheKey, ttl time.Duration, value interface{}, generation uint64) {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	if generation != c.generation {
+		return
+	}
+	c.entries[key] = &cacheEntry{value: value, expires: time.Now().Add(ttl)}
+}
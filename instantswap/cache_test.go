@@ -0,0 +1,155 @@
+package instantswap
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+type catalogExchange struct {
+	IDExchangeCtx
+	mux        sync.Mutex
+	currencies int
+	limits     int
+	err        error
+}
+
+func (c *catalogExchange) GetCurrencies(ctx context.Context) ([]Currency, error) {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	c.currencies++
+	if c.err != nil {
+		return nil, c.err
+	}
+	return []Currency{{Symbol: "BTC", Networks: []string{NetworkBitcoin}}, {Symbol: "DCR"}}, nil
+}
+
+func (c *catalogExchange) QueryLimits(ctx context.Context, fromCurr, toCurr string) (QueryLimits, error) {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	c.limits++
+	return QueryLimits{Min: MustParseAmount("0.01"), Max: MustParseAmount("1")}, nil
+}
+
+func (c *catalogExchange) calls() (currencies, limits int) {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	return c.currencies, c.limits
+}
+
+func TestCachedExchange(t *testing.T) {
+	exchange := &catalogExchange{}
+	cache := NewCachedExchange(exchange, CacheConfig{})
+	defer cache.Close()
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		currencies, err := cache.GetCurrencies(ctx)
+		if err != nil || len(currencies) != 2 {
+			t.Fatalf("got currencies %+v, %v", currencies, err)
+		}
+		currencies[0].Symbol = "changed"
+		currencies[0].Networks[0] = "changed"
+		if _, err := cache.QueryLimits(ctx, "btc", "DCR"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if currencies, limits := exchange.calls(); currencies != 1 || limits != 1 {
+		t.Errorf("%d currency and %d limit requests, expected 1", currencies, limits)
+	}
+	if currencies, _ := cache.GetCurrencies(ctx); currencies[0].Symbol != "BTC" || currencies[0].Networks[0] != NetworkBitcoin {
+		t.Errorf("cached currencies modified: %+v", currencies)
+	}
+
+	cache.InvalidateCurrency("dcr")
+	cache.GetCurrencies(ctx)
+	cache.QueryLimits(ctx, "BTC", "DCR")
+	if currencies, limits := exchange.calls(); currencies != 2 || limits != 2 {
+		t.Errorf("%d currency and %d limit requests after invalidation, expected 2", currencies, limits)
+	}
+
+	cache.Invalidate()
+	exchange.err = errors.New("boom")
+	if _, err := cache.GetCurrencies(ctx); err == nil {
+		t.Error("expected an error")
+	}
+	exchange.err = nil
+	if _, err := cache.GetCurrencies(ctx); err != nil {
+		t.Errorf("error cached: %v", err)
+	}
+}
+
+func TestCachedExchangeExpiry(t *testing.T) {
+	exchange := &catalogExchange{}
+	cache := NewCachedExchange(exchange, CacheConfig{CurrenciesTTL: 50 * time.Millisecond, LimitsTTL: -1})
+	defer cache.Close()
+	ctx := context.Background()
+
+	cache.GetCurrencies(ctx)
+	cache.QueryLimits(ctx, "BTC", "DCR")
+	cache.QueryLimits(ctx, "BTC", "DCR")
+	time.Sleep(60 * time.Millisecond)
+	cache.GetCurrencies(ctx)
+	if currencies, limits := exchange.calls(); currencies != 2 || limits != 2 {
+		t.Errorf("%d currency and %d limit requests, expected 2", currencies, limits)
+	}
+}
+
+func TestCachedExchangeRefreshAhead(t *testing.T) {
+	exchange := &catalogExchange{}
+	cache := NewCachedExchange(exchange, CacheConfig{CurrenciesTTL: time.Minute, RefreshAhead: time.Minute})
+	ctx := context.Background()
+
+	cache.GetCurrencies(ctx)
+	// Within RefreshAhead of the expiry, the cached list is returned and
+	// refreshed in the background.
+	if _, err := cache.GetCurrencies(ctx); err != nil {
+		t.Fatal(err)
+	}
+	cache.Close()
+	if currencies, _ := exchange.calls(); currencies != 2 {
+		t.Errorf("%d currency requests, expected 2", currencies)
+	}
+}
+
+// slowExchange blocks GetCurrencies until release is closed.
+type slowExchange struct {
+	catalogExchange
+	release chan struct{}
+}
+
+func (s *slowExchange) GetCurrencies(ctx context.Context) ([]Currency, error) {
+	<-s.release
+	return s.catalogExchange.GetCurrencies(ctx)
+}
+
+func TestCachedExchangeConcurrentMiss(t *testing.T) {
+	exchange := &slowExchange{release: make(chan struct{})}
+	cache := NewCachedExchange(exchange, CacheConfig{})
+	defer cache.Close()
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 10)
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := cache.GetCurrencies(context.Background())
+			errs <- err
+		}()
+	}
+	time.Sleep(20 * time.Millisecond)
+	close(exchange.release)
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if currencies, _ := exchange.calls(); currencies != 1 {
+		t.Errorf("%d currency requests, expected 1", currencies)
+	}
+}
@@ -0,0 +1,58 @@
+package instantswap
+
+// Capabilities describes the features an exchange supports. Methods of a
+// feature which is not supported return ErrNotSupported.
+type Capabilities struct {
+	// Cancel reports whether CancelOrder is supported.
+	Cancel bool
+	// Update reports whether UpdateOrder is supported.
+	Update bool
+	// FixedRate reports whether orders are created with a rate fixed for
+	// the order lifetime, RateTypeFixed.
+	FixedRate bool
+	// FloatingRate reports whether orders are created with a rate set when
+	// the deposit is received, RateTypeFloat.
+	FloatingRate bool
+	// ReverseQuote reports whether the exchange estimates the deposit of a
+	// DirectionTo quote, it is searched with DirectionFrom quotes otherwise.
+	ReverseQuote bool
+	// Networks reports whether FromNetwork and ToNetwork are sent to the
+	// exchange to select the network of a currency. The exchanges without it
+	// may still list a token on several networks under tickers of their own,
+	// see AssetMapping.Tickers, the networks of their currencies tell which.
+	Networks bool
+	// PairListing reports whether GetCurrenciesToPair is supported.
+	PairListing bool
+	// Limits reports whether QueryLimits is supported.
+	Limits bool
+	// RefundAddress reports whether CreateOrder.RefundAddress is sent to the
+	// exchange.
+	RefundAddress bool
+	// ExtraID reports whether CreateOrder.ExtraID (memo, destination tag) is
+	// sent to the exchange.
+	ExtraID bool
+	// Onion reports whether the exchange publishes an onion address, used
+	// when ExchangeConfig.Onion is set.
+	Onion bool
+	// APIKey and APISecret report whether ExchangeConfig.ApiKey and
+	// ExchangeConfig.ApiSecret are required.
+	APIKey    bool
+	APISecret bool
+}
+
+// RegisterCapabilities sets the capabilities of the exchange symbol. It is
+// called by the exchange packages next to RegisterExchangeCtx.
+func RegisterCapabilities(symbol string, capabilities Capabilities) {
+	driv.mux.Lock()
+	defer driv.mux.Unlock()
+	driv.capabilities[symbol] = capabilities
+}
+
+// ExchangeCapabilities returns the capabilities of a registered exchange
+// without creating it. ok is false when the exchange did not declare them.
+func ExchangeCapabilities(symbol string) (capabilities Capabilities, ok bool) {
+	driv.mux.RLock()
+	defer driv.mux.RUnlock()
+	capabilities, ok = driv.capabilities[symbol]
+	return
+}
@@ -1,6 +1,7 @@
 package instantswap
 
 import (
+	"context"
 	"fmt"
 	"io/ioutil"
 	"log"
@@ -18,19 +19,28 @@ const (
 
 type CustomReqFunc func(r *http.Request, body string) error
 
+// ErrorParseFunc fills Code, Message and Kind of e from the body of an error
+// response of the exchange api.
+type ErrorParseFunc func(e *Error, body []byte)
+
 type Client struct {
 	exchange      string
 	httpClient    *http.Client
 	conf          *ExchangeConfig
 	handleRequest CustomReqFunc
+	parseError    ErrorParseFunc
+	limiter       *RateLimiter
 }
 
-// NewClient return a new HTTP client
+// NewClient return a new HTTP client. The requests of the clients of exchange
+// with the same api key and endpoint share the rate limiter returned by
+// SharedRateLimiter.
 func NewClient(exchange string, conf *ExchangeConfig, handleRequests ...CustomReqFunc) (c *Client) {
 	client := &Client{
 		exchange:   exchange,
 		conf:       conf,
-		httpClient: &http.Client{},
+		httpClient: conf.HTTPClientOrDefault(),
+		limiter:    SharedRateLimiter(exchange, conf),
 	}
 	if len(handleRequests) >= 1 {
 		client.handleRequest = handleRequests[0]
@@ -38,30 +48,21 @@ func NewClient(exchange string, conf *ExchangeConfig, handleRequests ...CustomRe
 	return client
 }
 
-func (c *Client) doTimeoutRequest(timer *time.Timer, req *http.Request) (*http.Response, error) {
-	// Do the request in the background so we can check the timeout
-	type result struct {
-		resp *http.Response
-		err  error
+// SetErrorParser sets the function used to decode the error responses of the
+// exchange api.
+func (c *Client) SetErrorParser(parseError ErrorParseFunc) {
+	c.parseError = parseError
+}
+
+func (c *Client) doRequest(req *http.Request) (*http.Response, error) {
+	if c.conf.Debug {
+		c.dumpRequest(req)
 	}
-	done := make(chan result, 1)
-	go func() {
-		if c.conf.Debug {
-			c.dumpRequest(req)
-		}
-		resp, err := c.httpClient.Do(req)
-		if c.conf.Debug {
-			c.dumpResponse(resp)
-		}
-		done <- result{resp, err}
-	}()
-	// Wait for the read or the timeout
-	select {
-	case r := <-done:
-		return r.resp, r.err
-	case <-timer.C:
-		return nil, fmt.Errorf("timeout on reading data from [%s] api", c.exchange)
+	resp, err := c.httpClient.Do(req)
+	if c.conf.Debug {
+		c.dumpResponse(resp)
 	}
+	return resp, err
 }
 
 func (c Client) dumpRequest(r *http.Request) {
@@ -90,9 +91,54 @@ func (c Client) dumpResponse(r *http.Response) {
 	}
 }
 
-// Do do prepare and process HTTP request to API
-func (c *Client) Do(apibase, method, resource string, payload string, authNeeded bool) (response []byte, err error) {
-	var connectTimer = time.NewTimer(defaultHttpClientTimeout * time.Second)
+// Do do prepare and process HTTP request to API. The request is bound to ctx,
+// if ctx has no deadline the default client timeout is applied to each
+// attempt. Idempotent requests failing with a rate limit or a network error
+// are retried according to ExchangeConfig.Retry.
+func (c *Client) Do(ctx context.Context, apibase, method, resource string, payload string, authNeeded bool) (response []byte, err error) {
+	policy := DefaultRetryPolicy
+	if c.conf.Retry != nil {
+		policy = *c.conf.Retry
+	}
+	if !isIdempotent(ctx, method) {
+		policy.MaxRetries = 0
+	}
+	for attempt := 0; ; attempt++ {
+		response, err = c.do(ctx, apibase, method, resource, payload)
+		delay, retry := policy.delay(attempt, err)
+		if !retry {
+			return response, err
+		}
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < delay {
+			return response, err
+		}
+		if c.conf.Debug {
+			log.Printf("%s: retrying in %v after error: %v", c.exchange, delay, err)
+		}
+		if sleep(ctx, delay) != nil {
+			return response, err
+		}
+	}
+}
+
+// do makes a single attempt of the request once allowed by the rate limiter.
+func (c *Client) do(ctx context.Context, apibase, method, resource string, payload string) (response []byte, err error) {
+	if err = c.limiter.Wait(ctx); err != nil {
+		if err == context.DeadlineExceeded {
+			return nil, &Error{
+				Exchange: c.exchange,
+				Kind:     KindRateLimited,
+				Message:  fmt.Sprintf("rate limit of [%s] api exceeds the deadline", c.exchange),
+				Err:      err,
+			}
+		}
+		return nil, &Error{Exchange: c.exchange, Err: err}
+	}
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, defaultHttpClientTimeout*time.Second)
+		defer cancel()
+	}
 	var rawurl string
 	if strings.HasPrefix(resource, "http") {
 		rawurl = resource
@@ -100,7 +146,10 @@ func (c *Client) Do(apibase, method, resource string, payload string, authNeeded
 		rawurl = fmt.Sprintf("%s%s", apibase, resource)
 	}
 	var req *http.Request
-	req, err = http.NewRequest(method, rawurl, strings.NewReader(payload))
+	req, err = http.NewRequestWithContext(ctx, method, rawurl, strings.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
 	if method == "POST" || method == "PUT" {
 		req.Header.Add("Content-Type", "application/json;charset=utf-8")
 		req.Header.Set("Accept", "application/json")
@@ -112,9 +161,20 @@ func (c *Client) Do(apibase, method, resource string, payload string, authNeeded
 		}
 	}
 
-	resp, err := c.doTimeoutRequest(connectTimer, req)
+	resp, err := c.doRequest(req)
 	if err != nil {
-		return
+		switch ctx.Err() {
+		case context.DeadlineExceeded:
+			return nil, &Error{
+				Exchange: c.exchange,
+				Kind:     KindNetwork,
+				Message:  fmt.Sprintf("timeout on reading data from [%s] api", c.exchange),
+				Err:      ctx.Err(),
+			}
+		case context.Canceled:
+			return nil, &Error{Exchange: c.exchange, Err: ctx.Err()}
+		}
+		return nil, &Error{Exchange: c.exchange, Kind: KindNetwork, Err: err}
 	}
 
 	defer resp.Body.Close()
@@ -132,22 +192,29 @@ func (c *Client) Do(apibase, method, resource string, payload string, authNeeded
 	}
 
 	if err != nil {
-		return response, err
+		return response, &Error{Exchange: c.exchange, Kind: KindNetwork, Err: err}
 	}
 	if resp.StatusCode >= 300 {
-		if resp.StatusCode == http.StatusTooManyRequests {
-			return response, TooManyRequestsError
-		}
-		var errStr string
 		responseStr := string(response)
 		if responseStr != "" {
 			if strings.Contains(strings.ToLower(responseStr), "<body>") {
 				responseStr = utils.GetStringBefore(responseStr, "<body>")
 			}
 		}
-		res := "'" + responseStr + "'"
-		errStr = "\nexchangeclient:error:" + resp.Status + ":" + res
-		err = fmt.Errorf(errStr)
+		e := &Error{
+			Exchange:   c.exchange,
+			HTTPStatus: resp.StatusCode,
+			Kind:       kindFromStatus(resp.StatusCode),
+			Message:    strings.TrimSpace(responseStr),
+			RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()),
+		}
+		if c.parseError != nil {
+			c.parseError(e, response)
+		}
+		if e.Kind == KindUnknown {
+			e.Kind = ClassifyMessage(e.Message)
+		}
+		return response, e
 	}
-	return response, err
+	return response, nil
 }
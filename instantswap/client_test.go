@@ -0,0 +1,70 @@
+package instantswap
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestClientDoContext(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	client := NewClient("test", &ExchangeConfig{RateLimit: &NoRateLimit})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err := client.Do(ctx, server.URL+"/", "GET", "slow", "", false)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected deadline exceeded error, got: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("request was not interrupted by the deadline, took %v", elapsed)
+	}
+
+	ctx, cancel = context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+	_, err = client.Do(ctx, server.URL+"/", "GET", "slow", "", false)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("expected canceled error, got: %v", err)
+	}
+}
+
+type roundTripFunc func(r *http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
+
+func TestClientTransport(t *testing.T) {
+	var host string
+	transport := roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		host = r.URL.Host
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       io.NopCloser(strings.NewReader("ok")),
+			Request:    r,
+		}, nil
+	})
+	client := NewClient("test", &ExchangeConfig{Transport: transport, RateLimit: &NoRateLimit})
+	res, err := client.Do(context.Background(), "http://example.onion/", "GET", "api", "", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(res) != "ok" || host != "example.onion" {
+		t.Errorf("request not sent through the transport: %q %q", res, host)
+	}
+}
@@ -0,0 +1,118 @@
+package instantswap
+
+import "context"
+
+// WithoutContext adapts an IDExchangeCtx to the IDExchange interface so
+// existing callers keep working. Every call runs with context.Background().
+func WithoutContext(exchange IDExchangeCtx) IDExchange {
+	if e, ok := exchange.(*legacyExchange); ok {
+		return e.exchange
+	}
+	return &backgroundExchange{exchange: exchange}
+}
+
+// WithContext adapts a legacy IDExchange to the IDExchangeCtx interface.
+// The legacy methods can not be interrupted, ctx is only checked before the
+// call is made.
+func WithContext(exchange IDExchange) IDExchangeCtx {
+	if e, ok := exchange.(*backgroundExchange); ok {
+		return e.exchange
+	}
+	return &legacyExchange{exchange: exchange}
+}
+
+type backgroundExchange struct {
+	exchange IDExchangeCtx
+}
+
+func (e *backgroundExchange) GetCurrencies() ([]Currency, error) {
+	return e.exchange.GetCurrencies(context.Background())
+}
+
+func (e *backgroundExchange) GetCurrenciesToPair(from string) ([]Currency, error) {
+	return e.exchange.GetCurrenciesToPair(context.Background(), from)
+}
+
+func (e *backgroundExchange) QueryLimits(fromCurr, toCurr string) (QueryLimits, error) {
+	return e.exchange.QueryLimits(context.Background(), fromCurr, toCurr)
+}
+
+func (e *backgroundExchange) CreateOrder(vars CreateOrder) (CreateResultInfo, error) {
+	return e.exchange.CreateOrder(context.Background(), vars)
+}
+
+func (e *backgroundExchange) UpdateOrder(vars interface{}) (UpdateOrderResultInfo, error) {
+	return e.exchange.UpdateOrder(context.Background(), vars)
+}
+
+func (e *backgroundExchange) CancelOrder(orderID string) (string, error) {
+	return e.exchange.CancelOrder(context.Background(), orderID)
+}
+
+func (e *backgroundExchange) OrderInfo(orderID string, extraIds ...string) (OrderInfoResult, error) {
+	return e.exchange.OrderInfo(context.Background(), orderID, extraIds...)
+}
+
+func (e *backgroundExchange) GetExchangeRateInfo(vars ExchangeRateRequest) (ExchangeRateInfo, error) {
+	return e.exchange.GetExchangeRateInfo(context.Background(), vars)
+}
+
+type legacyExchange struct {
+	exchange IDExchange
+}
+
+func (e *legacyExchange) GetCurrencies(ctx context.Context) ([]Currency, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return e.exchange.GetCurrencies()
+}
+
+func (e *legacyExchange) GetCurrenciesToPair(ctx context.Context, from string) ([]Currency, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return e.exchange.GetCurrenciesToPair(from)
+}
+
+func (e *legacyExchange) QueryLimits(ctx context.Context, fromCurr, toCurr string) (QueryLimits, error) {
+	if err := ctx.Err(); err != nil {
+		return QueryLimits{}, err
+	}
+	return e.exchange.QueryLimits(fromCurr, toCurr)
+}
+
+func (e *legacyExchange) CreateOrder(ctx context.Context, vars CreateOrder) (CreateResultInfo, error) {
+	if err := ctx.Err(); err != nil {
+		return CreateResultInfo{}, err
+	}
+	return e.exchange.CreateOrder(vars)
+}
+
+func (e *legacyExchange) UpdateOrder(ctx context.Context, vars interface{}) (UpdateOrderResultInfo, error) {
+	if err := ctx.Err(); err != nil {
+		return UpdateOrderResultInfo{}, err
+	}
+	return e.exchange.UpdateOrder(vars)
+}
+
+func (e *legacyExchange) CancelOrder(ctx context.Context, orderID string) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	return e.exchange.CancelOrder(orderID)
+}
+
+func (e *legacyExchange) OrderInfo(ctx context.Context, orderID string, extraIds ...string) (OrderInfoResult, error) {
+	if err := ctx.Err(); err != nil {
+		return OrderInfoResult{}, err
+	}
+	return e.exchange.OrderInfo(orderID, extraIds...)
+}
+
+func (e *legacyExchange) GetExchangeRateInfo(ctx context.Context, vars ExchangeRateRequest) (ExchangeRateInfo, error) {
+	if err := ctx.Err(); err != nil {
+		return ExchangeRateInfo{}, err
+	}
+	return e.exchange.GetExchangeRateInfo(vars)
+}
@@ -1,7 +1,308 @@
 package instantswap
 
-import "fmt"
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
 
 var (
 	TooManyRequestsError = fmt.Errorf("exchangeclient:error:429 Too Many Requests")
+	// ErrNotSupported is returned by the methods an exchange does not
+	// support, see Capabilities.
+	ErrNotSupported = fmt.Errorf("exchangeclient:error: not supported")
+	// ErrAmountOutOfRange is returned when the amount is outside the limits
+	// accepted by the exchange.
+	ErrAmountOutOfRange = fmt.Errorf("exchangeclient:error: amount out of range")
+	// ErrPairUnavailable is returned when the exchange does not trade the
+	// requested pair.
+	ErrPairUnavailable = fmt.Errorf("exchangeclient:error: pair unavailable")
+	// ErrInvalidAddress is returned when the exchange rejects an address.
+	ErrInvalidAddress = fmt.Errorf("exchangeclient:error: invalid address")
+	// ErrUnauthorized is returned when the api key or secret is rejected.
+	ErrUnauthorized = fmt.Errorf("exchangeclient:error: unauthorized")
+	// ErrNetwork is returned for transient network and server errors.
+	ErrNetwork = fmt.Errorf("exchangeclient:error: network error")
+	// ErrNotFound is returned when the exchange does not know the order.
+	ErrNotFound = fmt.Errorf("exchangeclient:error: not found")
+	// ErrOrderNotTracked is returned by the OrderTracker for unknown orders.
+	ErrOrderNotTracked = fmt.Errorf("exchangeclient:error: order not tracked")
+	// ErrOrderNotFound is returned by an OrderStore for unknown orders.
+	ErrOrderNotFound = fmt.Errorf("exchangeclient:error: order not found")
+)
+
+// ErrorKind classifies the errors returned by the exchanges.
+type ErrorKind int
+
+const (
+	KindUnknown ErrorKind = iota
+	KindRateLimited
+	KindAmountOutOfRange
+	KindPairUnavailable
+	KindInvalidAddress
+	KindUnauthorized
+	KindNetwork
+	KindNotFound
+	KindNotSupported
 )
+
+func (k ErrorKind) String() string {
+	switch k {
+	case KindRateLimited:
+		return "rate limited"
+	case KindAmountOutOfRange:
+		return "amount out of range"
+	case KindPairUnavailable:
+		return "pair unavailable"
+	case KindInvalidAddress:
+		return "invalid address"
+	case KindUnauthorized:
+		return "unauthorized"
+	case KindNetwork:
+		return "network error"
+	case KindNotFound:
+		return "not found"
+	case KindNotSupported:
+		return "not supported"
+	default:
+		return "unknown"
+	}
+}
+
+// sentinel returns the error matched by errors.Is for the kind.
+func (k ErrorKind) sentinel() error {
+	switch k {
+	case KindRateLimited:
+		return TooManyRequestsError
+	case KindAmountOutOfRange:
+		return ErrAmountOutOfRange
+	case KindPairUnavailable:
+		return ErrPairUnavailable
+	case KindInvalidAddress:
+		return ErrInvalidAddress
+	case KindUnauthorized:
+		return ErrUnauthorized
+	case KindNetwork:
+		return ErrNetwork
+	case KindNotFound:
+		return ErrNotFound
+	case KindNotSupported:
+		return ErrNotSupported
+	default:
+		return nil
+	}
+}
+
+// Error is the error returned by the exchanges. errors.Is matches the
+// sentinel error of its Kind, TooManyRequestsError for KindRateLimited for
+// example, and the wrapped Err.
+type Error struct {
+	// Exchange is the name of the exchange.
+	Exchange string
+	// Op is the IDExchangeCtx method which failed, if known.
+	Op string
+	// HTTPStatus is the status of the http response, 0 if the request
+	// failed before a response or the exchange answered with a 200.
+	HTTPStatus int
+	// Code is the error code returned by the exchange api, if any.
+	Code string
+	Kind ErrorKind
+	// Message is the error message of the exchange api or the response body.
+	Message string
+	// RetryAfter is the delay requested by the Retry-After header of the
+	// response, if any.
+	RetryAfter time.Duration
+	Err        error
+}
+
+func (e *Error) Error() string {
+	var b strings.Builder
+	b.WriteString(e.Exchange)
+	b.WriteString(":error:")
+	if e.Op != "" {
+		b.WriteString(" " + e.Op + ":")
+	}
+	if e.HTTPStatus != 0 {
+		fmt.Fprintf(&b, " %d %s:", e.HTTPStatus, http.StatusText(e.HTTPStatus))
+	}
+	if e.Code != "" {
+		b.WriteString(" [" + e.Code + "]")
+	}
+	switch {
+	case e.Message != "":
+		b.WriteString(" " + e.Message)
+	case e.Err != nil:
+		b.WriteString(" " + e.Err.Error())
+	default:
+		b.WriteString(" " + e.Kind.String())
+	}
+	return b.String()
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Is reports whether target is the sentinel error of the kind of e.
+func (e *Error) Is(target error) bool {
+	sentinel := e.Kind.sentinel()
+	return sentinel != nil && target == sentinel
+}
+
+// Temporary reports whether the request may succeed if retried later.
+func (e *Error) Temporary() bool {
+	return e.Kind == KindRateLimited || e.Kind == KindNetwork
+}
+
+// NewError returns an Error of exchange with the code and message returned
+// by the exchange api.
+func NewError(exchange string, kind ErrorKind, code, message string) *Error {
+	return &Error{
+		Exchange: exchange,
+		Kind:     kind,
+		Code:     code,
+		Message:  message,
+	}
+}
+
+// WrapError converts err to an *Error of exchange for the operation op. An
+// *Error is copied with the missing Exchange and Op set, other errors are
+// wrapped and classified from their message.
+func WrapError(exchange, op string, err error) error {
+	if err == nil {
+		return nil
+	}
+	var e *Error
+	if errors.As(err, &e) {
+		c := *e
+		if c.Exchange == "" {
+			c.Exchange = exchange
+		}
+		if c.Op == "" {
+			c.Op = op
+		}
+		return &c
+	}
+	e = &Error{Exchange: exchange, Op: op, Err: err}
+	switch {
+	case errors.Is(err, ErrNotSupported):
+		e.Kind = KindNotSupported
+	case errors.Is(err, context.DeadlineExceeded):
+		e.Kind = KindNetwork
+	case errors.Is(err, context.Canceled):
+	default:
+		e.Kind = ClassifyMessage(err.Error())
+	}
+	return e
+}
+
+// ErrorKindOf returns the kind of err, KindUnknown if it is not an *Error.
+func ErrorKindOf(err error) ErrorKind {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.Kind
+	}
+	return KindUnknown
+}
+
+// IsTemporary reports whether err is a rate limit or a transient network
+// error, the request may succeed if retried later.
+func IsTemporary(err error) bool {
+	var e *Error
+	return errors.As(err, &e) && e.Temporary()
+}
+
+// kindFromStatus classifies an http error status.
+func kindFromStatus(status int) ErrorKind {
+	switch {
+	case status == http.StatusTooManyRequests:
+		return KindRateLimited
+	case status == http.StatusUnauthorized || status == http.StatusForbidden:
+		return KindUnauthorized
+	case status == http.StatusNotFound:
+		return KindNotFound
+	case status == http.StatusRequestTimeout || status >= 500:
+		return KindNetwork
+	default:
+		return KindUnknown
+	}
+}
+
+// errorMessageKinds maps words found in exchange error messages to a kind,
+// the first match wins.
+var errorMessageKinds = []struct {
+	words []string
+	kind  ErrorKind
+}{
+	{[]string{"too many requests", "rate limit"}, KindRateLimited},
+	{[]string{"api key", "apikey", "api_key", "unauthorized", "forbidden", "signature", "not authorized", "authentication"}, KindUnauthorized},
+	{[]string{"address"}, KindInvalidAddress},
+	{[]string{"minimum", "maximum", "min amount", "max amount", "amount is less", "amount is more", "amount too", "out of range", "limit"}, KindAmountOutOfRange},
+	{[]string{"pair", "not available", "unavailable", "disabled", "not supported", "invalid currency", "unknown currency", "invalid coin"}, KindPairUnavailable},
+	{[]string{"not found", "no such", "does not exist"}, KindNotFound},
+	{[]string{"timeout", "temporarily", "try again", "connection"}, KindNetwork},
+}
+
+// ClassifyMessage guesses the kind of an error from the message returned by
+// an exchange. It is used by the adapters of apis without error codes.
+func ClassifyMessage(msg string) ErrorKind {
+	msg = strings.ToLower(msg)
+	for _, m := range errorMessageKinds {
+		for _, word := range m.words {
+			if strings.Contains(msg, word) {
+				return m.kind
+			}
+		}
+	}
+	return KindUnknown
+}
+
+// errorExchange converts the errors of an exchange with WrapError so every
+// error returned by a registered exchange is an *Error with Exchange and Op.
+type errorExchange struct {
+	name     string
+	exchange IDExchangeCtx
+}
+
+func (e *errorExchange) GetCurrencies(ctx context.Context) ([]Currency, error) {
+	res, err := e.getCurrencies(ctx)
+	return res, WrapError(e.name, "GetCurrencies", err)
+}
+
+func (e *errorExchange) GetCurrenciesToPair(ctx context.Context, from string) ([]Currency, error) {
+	res, err := e.getCurrenciesToPair(ctx, from)
+	return res, WrapError(e.name, "GetCurrenciesToPair", err)
+}
+
+func (e *errorExchange) QueryLimits(ctx context.Context, fromCurr, toCurr string) (QueryLimits, error) {
+	res, err := e.queryLimits(ctx, fromCurr, toCurr)
+	return res, WrapError(e.name, "QueryLimits", err)
+}
+
+func (e *errorExchange) CreateOrder(ctx context.Context, vars CreateOrder) (CreateResultInfo, error) {
+	res, err := e.createOrder(ctx, vars)
+	return res, WrapError(e.name, "CreateOrder", err)
+}
+
+func (e *errorExchange) UpdateOrder(ctx context.Context, vars interface{}) (UpdateOrderResultInfo, error) {
+	res, err := e.exchange.UpdateOrder(ctx, vars)
+	return res, WrapError(e.name, "UpdateOrder", err)
+}
+
+func (e *errorExchange) CancelOrder(ctx context.Context, orderID string) (string, error) {
+	res, err := e.exchange.CancelOrder(ctx, orderID)
+	return res, WrapError(e.name, "CancelOrder", err)
+}
+
+func (e *errorExchange) OrderInfo(ctx context.Context, orderID string, extraIds ...string) (OrderInfoResult, error) {
+	res, err := e.exchange.OrderInfo(ctx, orderID, extraIds...)
+	return res, WrapError(e.name, "OrderInfo", err)
+}
+
+func (e *errorExchange) GetExchangeRateInfo(ctx context.Context, vars ExchangeRateRequest) (ExchangeRateInfo, error) {
+	res, err := e.getExchangeRateInfo(ctx, vars)
+	return res, WrapError(e.name, "GetExchangeRateInfo", err)
+}
@@ -0,0 +1,116 @@
+package instantswap
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestErrorIs(t *testing.T) {
+	err := WrapError("test", "QueryLimits", NewError("", KindAmountOutOfRange, "42", "amount is less than minimum"))
+	if !errors.Is(err, ErrAmountOutOfRange) {
+		t.Errorf("expected ErrAmountOutOfRange, got %v", err)
+	}
+	if errors.Is(err, ErrPairUnavailable) {
+		t.Error("unexpected match of ErrPairUnavailable")
+	}
+	var e *Error
+	if !errors.As(err, &e) {
+		t.Fatalf("expected *Error, got %T", err)
+	}
+	if e.Exchange != "test" || e.Op != "QueryLimits" || e.Code != "42" {
+		t.Errorf("unexpected error: %+v", e)
+	}
+	if got, expected := err.Error(), "test:error: QueryLimits: [42] amount is less than minimum"; got != expected {
+		t.Errorf("got %q, expected %q", got, expected)
+	}
+
+	wrapped := fmt.Errorf("quote: %w", err)
+	if ErrorKindOf(wrapped) != KindAmountOutOfRange || IsTemporary(wrapped) {
+		t.Errorf("unexpected kind of wrapped error: %v", ErrorKindOf(wrapped))
+	}
+}
+
+func TestWrapError(t *testing.T) {
+	tests := []struct {
+		err  error
+		kind ErrorKind
+	}{
+		{ErrNotSupported, KindNotSupported},
+		{context.DeadlineExceeded, KindNetwork},
+		{context.Canceled, KindUnknown},
+		{errors.New("Invalid address"), KindInvalidAddress},
+		{errors.New("pair is disabled"), KindPairUnavailable},
+		{errors.New("something else"), KindUnknown},
+	}
+	for _, test := range tests {
+		err := WrapError("test", "CreateOrder", test.err)
+		if kind := ErrorKindOf(err); kind != test.kind {
+			t.Errorf("%v: got kind %v, expected %v", test.err, kind, test.kind)
+		}
+		if !errors.Is(err, test.err) {
+			t.Errorf("%v: wrapped error does not match", test.err)
+		}
+	}
+	if WrapError("test", "CreateOrder", nil) != nil {
+		t.Error("expected nil error")
+	}
+}
+
+func TestClientDoStatusError(t *testing.T) {
+	tests := []struct {
+		status int
+		body   string
+		kind   ErrorKind
+	}{
+		{http.StatusTooManyRequests, "slow down", KindRateLimited},
+		{http.StatusUnauthorized, "", KindUnauthorized},
+		{http.StatusBadGateway, "<html><body>bad gateway</body></html>", KindNetwork},
+		{http.StatusBadRequest, `{"message":"Invalid withdrawal address"}`, KindInvalidAddress},
+		{http.StatusBadRequest, `{"message":"???"}`, KindUnknown},
+	}
+	for _, test := range tests {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(test.status)
+			w.Write([]byte(test.body))
+		}))
+		client := NewClient("test", &ExchangeConfig{Retry: &NoRetry, RateLimit: &NoRateLimit})
+		body, err := client.Do(context.Background(), server.URL+"/", "GET", "", "", false)
+		server.Close()
+
+		var e *Error
+		if !errors.As(err, &e) {
+			t.Fatalf("%d: expected *Error, got %v", test.status, err)
+		}
+		if e.HTTPStatus != test.status || e.Kind != test.kind {
+			t.Errorf("%d: unexpected error %+v", test.status, e)
+		}
+		if string(body) != test.body {
+			t.Errorf("%d: response body not returned", test.status)
+		}
+	}
+	if !IsTemporary(&Error{Kind: KindRateLimited}) || !errors.Is(&Error{Kind: KindRateLimited}, TooManyRequestsError) {
+		t.Error("rate limited error should be temporary and match TooManyRequestsError")
+	}
+}
+
+func TestClientErrorParser(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"code":"pair_is_inactive"}`))
+	}))
+	defer server.Close()
+
+	client := NewClient("test", &ExchangeConfig{RateLimit: &NoRateLimit})
+	client.SetErrorParser(func(e *Error, body []byte) {
+		e.Code = "pair_is_inactive"
+		e.Kind = KindPairUnavailable
+	})
+	_, err := client.Do(context.Background(), server.URL+"/", "GET", "", "", false)
+	if !errors.Is(err, ErrPairUnavailable) {
+		t.Errorf("expected ErrPairUnavailable, got %v", err)
+	}
+}
@@ -1,9 +1,8 @@
 package changelly
 
 import (
+	"context"
 	"encoding/json"
-	"errors"
-	"fmt"
 	"strconv"
 	"strings"
 	"time"
@@ -17,25 +16,47 @@ const (
 )
 
 func init() {
-	instantswap.RegisterExchange(LIBNAME, func(config instantswap.ExchangeConfig) (instantswap.IDExchange, error) {
+	instantswap.RegisterExchangeCtx(LIBNAME, func(config instantswap.ExchangeConfig) (instantswap.IDExchangeCtx, error) {
 		return New(config)
 	})
+	instantswap.RegisterCapabilities(LIBNAME, instantswap.Capabilities{
+		FixedRate:     true,
+		FloatingRate:  true,
+		ReverseQuote:  true,
+		Limits:        true,
+		RefundAddress: true,
+		ExtraID:       true,
+		APIKey:        true,
+		APISecret:     true,
+	})
+	instantswap.RegisterRateLimit(LIBNAME, instantswap.RateLimit{Rate: 1, Burst: 3})
+	instantswap.RegisterAssetMapping(LIBNAME, instantswap.AssetMapping{
+		LowerCase: true,
+		Tickers: map[instantswap.Asset]string{
+			{Symbol: "USDT", Network: instantswap.NetworkEthereum}: "usdt20",
+			{Symbol: "USDT", Network: instantswap.NetworkTron}:     "usdtrx",
+			{Symbol: "USDT", Network: instantswap.NetworkBSC}:      "usdtbsc",
+			{Symbol: "BNB", Network: instantswap.NetworkBSC}:       "bnbbsc",
+		},
+	})
 }
 
 // New return a Changelly api client
 func New(conf instantswap.ExchangeConfig) (*Changelly, error) {
 	client := instantswap.NewClient(LIBNAME, &conf)
+	client.SetErrorParser(parseError)
 	return &Changelly{
-		client: client,
-		conf:   &conf,
+		client:  client,
+		conf:    &conf,
+		apiBase: conf.BaseURLOrDefault(API_BASE),
 	}, nil
 }
 
 // Changelly represent a Changelly client.
 type Changelly struct {
-	client *instantswap.Client
-	conf   *instantswap.ExchangeConfig
-	instantswap.IDExchange
+	client  *instantswap.Client
+	conf    *instantswap.ExchangeConfig
+	apiBase string
 }
 
 // SetDebug set enable/disable http request/response dump.
@@ -48,12 +69,39 @@ func handleErr(r json.RawMessage) (err error) {
 		return err
 	}
 	if errorVal.Message != "" {
-		return fmt.Errorf("%s:error: %s", LIBNAME, errorVal.Message)
+		return instantswap.NewError(LIBNAME, errorKind(errorVal),
+			strconv.FormatInt(errorVal.Code, 10), errorVal.Message)
 	}
 	return nil
 }
 
-func (c *Changelly) GetCurrencies() (currencies []instantswap.Currency, err error) {
+// errorKind classifies a JSON-RPC error of the changelly api.
+func errorKind(e jsonError) instantswap.ErrorKind {
+	// -32603 is the JSON-RPC internal error.
+	if e.Code == -32603 {
+		return instantswap.KindNetwork
+	}
+	return instantswap.ClassifyMessage(e.Message)
+}
+
+// parseError decodes the JSON-RPC error of an error response.
+func parseError(e *instantswap.Error, body []byte) {
+	var response jsonResponse
+	if json.Unmarshal(body, &response) != nil || response.Error == nil {
+		return
+	}
+	var errorVal jsonError
+	if json.Unmarshal(response.Error, &errorVal) != nil || errorVal.Message == "" {
+		return
+	}
+	e.Code = strconv.FormatInt(errorVal.Code, 10)
+	e.Message = errorVal.Message
+	if kind := errorKind(errorVal); kind != instantswap.KindUnknown {
+		e.Kind = kind
+	}
+}
+
+func (c *Changelly) GetCurrencies(ctx context.Context) (currencies []instantswap.Currency, err error) {
 	nonce := strconv.FormatInt(time.Now().Unix(), 10)
 	tmpPayload := jsonRequest{
 		ID:      "queryLimits" + nonce,
@@ -66,14 +114,14 @@ func (c *Changelly) GetCurrencies() (currencies []instantswap.Currency, err erro
 	if err != nil {
 		return nil, err
 	}
-	r, err := c.client.Do(API_BASE, "POST", "", string(payload), true)
+	r, err := c.client.Do(instantswap.Idempotent(ctx), c.apiBase, "POST", "", string(payload), true)
 	if err != nil {
-		err = errors.New(LIBNAME + ":error: " + err.Error())
+		err = instantswap.WrapError(LIBNAME, "GetCurrencies", err)
 		return
 	}
 	var response jsonResponse
 	if err = json.Unmarshal(r, &response); err != nil {
-		err = errors.New(LIBNAME + ":error: " + err.Error())
+		err = instantswap.WrapError(LIBNAME, "GetCurrencies", err)
 		return
 	}
 	if response.Error != nil {
@@ -84,7 +132,7 @@ func (c *Changelly) GetCurrencies() (currencies []instantswap.Currency, err erro
 	}
 	var resCurrencies = []string{}
 	if err = json.Unmarshal(response.Result, &resCurrencies); err != nil {
-		err = errors.New(LIBNAME + ":error: " + err.Error())
+		err = instantswap.WrapError(LIBNAME, "GetCurrencies", err)
 		return
 	}
 	currencies = make([]instantswap.Currency, len(resCurrencies))
@@ -97,38 +145,107 @@ func (c *Changelly) GetCurrencies() (currencies []instantswap.Currency, err erro
 	return currencies, err
 }
 
-func (c *Changelly) GetCurrenciesToPair(from string) (currencies []instantswap.Currency, err error) {
-	return
+func (c *Changelly) GetCurrenciesToPair(ctx context.Context, from string) (currencies []instantswap.Currency, err error) {
+	return currencies, instantswap.ErrNotSupported
 }
 
 // GetExchangeRateInfo get estimate on the amount for the exchange.
-func (c *Changelly) GetExchangeRateInfo(vars instantswap.ExchangeRateRequest) (res instantswap.ExchangeRateInfo, err error) {
-	limits, err := c.QueryLimits(vars.From, vars.To)
+func (c *Changelly) GetExchangeRateInfo(ctx context.Context, vars instantswap.ExchangeRateRequest) (res instantswap.ExchangeRateInfo, err error) {
+	if vars.RateType == instantswap.RateTypeFixed {
+		return c.fixRate(ctx, vars)
+	}
+	if vars.Direction == instantswap.DirectionTo {
+		// Only the fixed rates are quoted for an amount to receive.
+		return res, instantswap.ErrNotSupported
+	}
+	limits, err := c.QueryLimits(ctx, vars.From, vars.To)
 	if err != nil {
-		err = errors.New(err.Error())
 		return
 	}
-	time.Sleep(time.Second * 1)
-	estimate, err := c.EstimateAmount(vars)
+	estimate, err := c.EstimateAmount(ctx, vars)
 	if err != nil {
-		err = errors.New(err.Error())
 		return
 	}
 
-	rate := vars.Amount / estimate.EstimatedAmount
+	rate := instantswap.ExchangeRate(estimate.EstimatedAmount, vars.Amount)
 
 	res = instantswap.ExchangeRateInfo{
 		ExchangeRate:    rate,
 		Min:             limits.Min,
 		Max:             limits.Max,
 		EstimatedAmount: estimate.EstimatedAmount,
+		RateType:        instantswap.RateTypeFloat,
 	}
 	return
 }
 
+// fixRate requests a fixed rate quote, its id is the Signature of the order.
+func (c *Changelly) fixRate(ctx context.Context, vars instantswap.ExchangeRateRequest) (res instantswap.ExchangeRateInfo, err error) {
+	params := []map[string]string{{
+		"from": strings.ToLower(vars.From),
+		"to":   strings.ToLower(vars.To),
+	}}
+	if vars.Direction == instantswap.DirectionTo {
+		params[0]["amountTo"] = vars.Amount.TruncateFor(vars.To, vars.ToNetwork).String()
+	} else {
+		params[0]["amountFrom"] = vars.Amount.TruncateFor(vars.From, vars.FromNetwork).String()
+	}
+	var rates []FixRate
+	if err = c.call(instantswap.Idempotent(ctx), "getFixRateForAmount", params, &rates); err != nil {
+		return
+	}
+	if len(rates) == 0 {
+		err = instantswap.NewError(LIBNAME, instantswap.KindPairUnavailable, "", "no fixed rate for the pair")
+		return
+	}
+	rate := rates[0]
+	res = instantswap.ExchangeRateInfo{
+		Min:             rate.MinFrom,
+		Max:             rate.MaxFrom,
+		ExchangeRate:    rate.Result.Float64(),
+		EstimatedAmount: rate.AmountTo,
+		DepositAmount:   rate.AmountFrom,
+		Signature:       rate.ID,
+		RateType:        instantswap.RateTypeFixed,
+		Fees:            instantswap.Fees{NetworkFee: rate.NetworkFee},
+	}
+	if rate.ExpiredAt > 0 {
+		res.ValidUntil = time.Unix(rate.ExpiredAt, 0)
+	}
+	return
+}
+
+// call sends a JSON-RPC request and decodes its result into result.
+func (c *Changelly) call(ctx context.Context, method string, params, result interface{}) error {
+	nonce := strconv.FormatInt(time.Now().Unix(), 10)
+	payload, err := json.Marshal(jsonRequest{
+		ID:      method + nonce,
+		JSONRPC: "2.0",
+		Method:  method,
+		Params:  params,
+	})
+	if err != nil {
+		return err
+	}
+	r, err := c.client.Do(ctx, c.apiBase, "POST", "", string(payload), true)
+	if err != nil {
+		return err
+	}
+	var response jsonResponse
+	if err = json.Unmarshal(r, &response); err != nil {
+		return err
+	}
+	if response.Error != nil {
+		if err = handleErr(response.Error); err != nil {
+			return err
+		}
+	}
+	return json.Unmarshal(response.Result, result)
+}
+
 // EstimateAmount get estimate on the amount for the exchange.
-func (c *Changelly) EstimateAmount(vars instantswap.ExchangeRateRequest) (res instantswap.EstimateAmount, err error) {
-	amountStr := strconv.FormatFloat(vars.Amount, 'f', 8, 64)
+func (c *Changelly) EstimateAmount(ctx context.Context, vars instantswap.ExchangeRateRequest) (res instantswap.EstimateAmount, err error) {
+	amountStr := vars.Amount.TruncateFor(vars.From, vars.FromNetwork).String()
 	nonce := strconv.FormatInt(time.Now().Unix(), 10)
 	params := map[string]string{"from": strings.ToLower(vars.From), "to": strings.ToLower(vars.To), "amount": amountStr}
 	tmpPayload := jsonRequest{
@@ -140,18 +257,18 @@ func (c *Changelly) EstimateAmount(vars instantswap.ExchangeRateRequest) (res in
 
 	payload, err := json.Marshal(tmpPayload)
 	if err != nil {
-		err = errors.New(LIBNAME + ":error: " + err.Error())
+		err = instantswap.WrapError(LIBNAME, "EstimateAmount", err)
 		return
 	}
 
-	r, err := c.client.Do(API_BASE, "POST", "", string(payload), true)
+	r, err := c.client.Do(instantswap.Idempotent(ctx), c.apiBase, "POST", "", string(payload), true)
 	if err != nil {
-		err = errors.New(LIBNAME + ":error: " + err.Error())
+		err = instantswap.WrapError(LIBNAME, "EstimateAmount", err)
 		return
 	}
 	var response jsonResponse
 	if err = json.Unmarshal(r, &response); err != nil {
-		err = errors.New(LIBNAME + ":error: " + err.Error())
+		err = instantswap.WrapError(LIBNAME, "EstimateAmount", err)
 		return
 	}
 
@@ -164,13 +281,13 @@ func (c *Changelly) EstimateAmount(vars instantswap.ExchangeRateRequest) (res in
 
 	var tmpAmountStr string
 	if err = json.Unmarshal(response.Result, &tmpAmountStr); err != nil {
-		err = errors.New(LIBNAME + ":error: " + err.Error())
+		err = instantswap.WrapError(LIBNAME, "EstimateAmount", err)
 		return
 	}
 
-	exchangeAmount, err := strconv.ParseFloat(tmpAmountStr, 64)
+	exchangeAmount, err := instantswap.ParseAmount(tmpAmountStr)
 	if err != nil {
-		err = errors.New(LIBNAME + ":error: " + err.Error())
+		err = instantswap.WrapError(LIBNAME, "EstimateAmount", err)
 		return
 	}
 
@@ -182,14 +299,12 @@ func (c *Changelly) EstimateAmount(vars instantswap.ExchangeRateRequest) (res in
 }
 
 // QueryRates (list of pairs LTC-BTC, BTC-LTC, etc).
-func (c *Changelly) QueryRates(vars interface{}) (res []instantswap.QueryRate, err error) {
-	//vars not used here
-	err = errors.New(LIBNAME + ":error: not available for this exchange")
-	return
+func (c *Changelly) QueryRates(ctx context.Context, vars interface{}) (res []instantswap.QueryRate, err error) {
+	return res, instantswap.ErrNotSupported
 }
 
 // QueryLimits Get Exchange Rates (from, to).
-func (c *Changelly) QueryLimits(fromCurr, toCurr string) (res instantswap.QueryLimits, err error) {
+func (c *Changelly) QueryLimits(ctx context.Context, fromCurr, toCurr string) (res instantswap.QueryLimits, err error) {
 	nonce := strconv.FormatInt(time.Now().Unix(), 10)
 	params := map[string]string{"from": strings.ToLower(fromCurr), "to": strings.ToLower(toCurr)}
 	tmpPayload := jsonRequest{
@@ -201,17 +316,17 @@ func (c *Changelly) QueryLimits(fromCurr, toCurr string) (res instantswap.QueryL
 
 	payload, err := json.Marshal(tmpPayload)
 	if err != nil {
-		err = errors.New(LIBNAME + ":error: " + err.Error())
+		err = instantswap.WrapError(LIBNAME, "QueryLimits", err)
 		return
 	}
-	r, err := c.client.Do(API_BASE, "POST", "", string(payload), true)
+	r, err := c.client.Do(instantswap.Idempotent(ctx), c.apiBase, "POST", "", string(payload), true)
 	if err != nil {
-		err = errors.New(LIBNAME + ":error: " + err.Error())
+		err = instantswap.WrapError(LIBNAME, "QueryLimits", err)
 		return
 	}
 	var response jsonResponse
 	if err = json.Unmarshal(r, &response); err != nil {
-		err = errors.New(LIBNAME + ":error: " + err.Error())
+		err = instantswap.WrapError(LIBNAME, "QueryLimits", err)
 		return
 	}
 
@@ -224,13 +339,13 @@ func (c *Changelly) QueryLimits(fromCurr, toCurr string) (res instantswap.QueryL
 
 	var tmpMinAmountStr string
 	if err = json.Unmarshal(response.Result, &tmpMinAmountStr); err != nil {
-		err = errors.New(LIBNAME + ":error: " + err.Error())
+		err = instantswap.WrapError(LIBNAME, "QueryLimits", err)
 		return
 	}
 
-	minAmount, err := strconv.ParseFloat(tmpMinAmountStr, 64)
+	minAmount, err := instantswap.ParseAmount(tmpMinAmountStr)
 	if err != nil {
-		err = errors.New(LIBNAME + ":error: " + err.Error())
+		err = instantswap.WrapError(LIBNAME, "QueryLimits", err)
 		return
 	}
 	res = instantswap.QueryLimits{
@@ -240,9 +355,9 @@ func (c *Changelly) QueryLimits(fromCurr, toCurr string) (res instantswap.QueryL
 }
 
 // CreateOrder create an instant exchange order.
-func (c *Changelly) CreateOrder(orderInfo instantswap.CreateOrder) (res instantswap.CreateResultInfo, err error) {
+func (c *Changelly) CreateOrder(ctx context.Context, orderInfo instantswap.CreateOrder) (res instantswap.CreateResultInfo, err error) {
 	nonce := strconv.FormatInt(time.Now().Unix(), 10)
-	amountStr := strconv.FormatFloat(orderInfo.InvoicedAmount, 'f', 8, 64)
+	amountStr := orderInfo.InvoicedAmount.TruncateFor(orderInfo.FromCurrency, orderInfo.FromNetwork).String()
 	params := map[string]string{
 		"from":          strings.ToLower(orderInfo.FromCurrency),
 		"to":            strings.ToLower(orderInfo.ToCurrency),
@@ -252,35 +367,47 @@ func (c *Changelly) CreateOrder(orderInfo instantswap.CreateOrder) (res instants
 		"refundAddress": orderInfo.RefundAddress,
 		"refundExtraId": orderInfo.RefundExtraID,
 	}
+	method := "createTransaction"
+	if orderInfo.RateType == instantswap.RateTypeFixed {
+		if orderInfo.Signature == "" {
+			err = instantswap.NewError(LIBNAME, instantswap.KindUnknown, "", "a fixed rate order requires the Signature of a fixed rate quote")
+			return
+		}
+		method = "createFixTransaction"
+		delete(params, "amount")
+		params["amountFrom"] = amountStr
+		params["rateId"] = orderInfo.Signature
+	}
 	tmpPayload := jsonRequest{
 		ID:      "createOrder" + nonce,
 		JSONRPC: "2.0",
-		Method:  "createTransaction",
+		Method:  method,
 		Params:  params,
 	}
-	if orderInfo.InvoicedAmount == 0.0 {
-		err = errors.New(LIBNAME + ":error:createorder invoiced amount is 0")
+	if orderInfo.InvoicedAmount.IsZero() {
+		err = instantswap.NewError(LIBNAME, instantswap.KindAmountOutOfRange, "", "createorder invoiced amount is 0")
 		return
 	}
 	payload, err := json.Marshal(tmpPayload)
 	if err != nil {
-		err = errors.New(LIBNAME + ":error: " + err.Error())
+		err = instantswap.WrapError(LIBNAME, "CreateOrder", err)
 		return
 	}
 
 	if c.conf.ApiKey == "" {
-		err = errors.New(LIBNAME + ":error: APIKEY is blank")
+		err = instantswap.NewError(LIBNAME, instantswap.KindUnauthorized, "", "APIKEY is blank")
+		return
 	}
 
-	r, err := c.client.Do(API_BASE, "POST", "", string(payload), true)
+	r, err := c.client.Do(ctx, c.apiBase, "POST", "", string(payload), true)
 	if err != nil {
-		err = errors.New(LIBNAME + ":error: " + err.Error())
+		err = instantswap.WrapError(LIBNAME, "CreateOrder", err)
 		return
 	}
 
 	var response jsonResponse
 	if err = json.Unmarshal(r, &response); err != nil {
-		err = errors.New(LIBNAME + ":error: " + err.Error())
+		err = instantswap.WrapError(LIBNAME, "CreateOrder", err)
 		return
 	}
 
@@ -293,7 +420,7 @@ func (c *Changelly) CreateOrder(orderInfo instantswap.CreateOrder) (res instants
 
 	var tmp CreateResult
 	if err = json.Unmarshal(response.Result, &tmp); err != nil {
-		err = errors.New(LIBNAME + ":error: " + err.Error())
+		err = instantswap.WrapError(LIBNAME, "CreateOrder", err)
 		return
 	}
 
@@ -311,19 +438,17 @@ func (c *Changelly) CreateOrder(orderInfo instantswap.CreateOrder) (res instants
 }
 
 // UpdateOrder not available for this exchange.
-func (c *Changelly) UpdateOrder(vars interface{}) (res instantswap.UpdateOrderResultInfo, err error) {
-	err = errors.New(LIBNAME + ":error:update not available for this exchange")
-	return
+func (c *Changelly) UpdateOrder(ctx context.Context, vars interface{}) (res instantswap.UpdateOrderResultInfo, err error) {
+	return res, instantswap.ErrNotSupported
 }
 
 // CancelOrder not available for this exchange.
-func (c *Changelly) CancelOrder(oId string) (res string, err error) {
-	err = errors.New(LIBNAME + ":error:cancel not available for this exchange")
-	return
+func (c *Changelly) CancelOrder(ctx context.Context, oId string) (res string, err error) {
+	return res, instantswap.ErrNotSupported
 }
 
 // OrderInfo get information on orderid/uuid.
-func (c *Changelly) OrderInfo(orderID string, extraIds ...string) (res instantswap.OrderInfoResult, err error) {
+func (c *Changelly) OrderInfo(ctx context.Context, orderID string, extraIds ...string) (res instantswap.OrderInfoResult, err error) {
 	nonce := strconv.FormatInt(time.Now().Unix(), 10)
 	params := map[string]string{"id": orderID}
 	tmpPayload := jsonRequest{
@@ -335,21 +460,22 @@ func (c *Changelly) OrderInfo(orderID string, extraIds ...string) (res instantsw
 
 	payload, err := json.Marshal(tmpPayload)
 	if err != nil {
-		err = errors.New(LIBNAME + ":error: " + err.Error())
+		err = instantswap.WrapError(LIBNAME, "OrderInfo", err)
 		return
 	}
 
 	if c.conf.ApiKey == "" {
-		err = errors.New(LIBNAME + ":error: APIKEY is blank")
+		err = instantswap.NewError(LIBNAME, instantswap.KindUnauthorized, "", "APIKEY is blank")
+		return
 	}
-	r, err := c.client.Do(API_BASE, "POST", "", string(payload), true)
+	r, err := c.client.Do(instantswap.Idempotent(ctx), c.apiBase, "POST", "", string(payload), true)
 	if err != nil {
-		err = errors.New(LIBNAME + ":error: " + err.Error())
+		err = instantswap.WrapError(LIBNAME, "OrderInfo", err)
 		return
 	}
 	var response jsonResponse
 	if err = json.Unmarshal(r, &response); err != nil {
-		err = errors.New(LIBNAME + ":error: " + err.Error())
+		err = instantswap.WrapError(LIBNAME, "OrderInfo", err)
 		return
 	}
 
@@ -362,7 +488,7 @@ func (c *Changelly) OrderInfo(orderID string, extraIds ...string) (res instantsw
 
 	var tmp []OrderInfoResult
 	if err = json.Unmarshal(response.Result, &tmp); err != nil {
-		err = errors.New(LIBNAME + ":error: " + err.Error())
+		err = instantswap.WrapError(LIBNAME, "OrderInfo", err)
 		return
 	}
 	var finalOrderInfo OrderInfoResult
@@ -371,8 +497,8 @@ func (c *Changelly) OrderInfo(orderID string, extraIds ...string) (res instantsw
 			finalOrderInfo = v
 		}
 	}
-	if finalOrderInfo == (OrderInfoResult{}) {
-		err = errors.New(LIBNAME + ":error: order info could not be found")
+	if finalOrderInfo.UUID == "" {
+		err = instantswap.NewError(LIBNAME, instantswap.KindNotFound, "", "order info could not be found")
 		return
 	}
 	res = instantswap.OrderInfoResult{
@@ -392,7 +518,7 @@ func GetLocalStatus(status string) (iStatus instantswap.Status) {
 	case "finished":
 		return instantswap.OrderStatusCompleted
 	case "waiting":
-		return instantswap.OrderStatusExchanging
+		return instantswap.OrderStatusWaitingForDeposit
 	case "confirming":
 		return instantswap.OrderStatusDepositReceived
 	case "refunded":
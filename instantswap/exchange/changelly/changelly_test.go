@@ -0,0 +1,144 @@
+package changelly
+
+import (
+	"testing"
+	"time"
+
+	"github.com/crypto-power/instantswap/instantswap"
+	"github.com/crypto-power/instantswap/instantswap/exchangetest"
+)
+
+func TestChangelly(t *testing.T) {
+	amount := instantswap.MustParseAmount
+	exchangetest.Run(t, exchangetest.Suite{
+		Exchange: LIBNAME,
+		Config:   instantswap.ExchangeConfig{ApiKey: "key", ApiSecret: "secret"},
+		Fixtures: []exchangetest.Fixture{{
+			Method: "POST", Path: "/", Body: `"method":"getCurrencies"`,
+			Response: `{"jsonrpc":"2.0","id":"1","result":["btc","dcr","eth","ltc"]}`,
+		}, {
+			Method: "POST", Path: "/", Body: `"method":"getMinAmount"`,
+			Response: `{"jsonrpc":"2.0","id":"1","result":"0.00142"}`,
+		}, {
+			Method: "POST", Path: "/", Body: `"method":"getExchangeAmount"`,
+			Response: `{"jsonrpc":"2.0","id":"1","result":"181.9871"}`,
+		}, {
+			Method: "POST", Path: "/", Body: `"method":"createTransaction"`,
+			Response: `{"jsonrpc":"2.0","id":"1","result":{"id":"jrcka3x4","apiExtraFee":"0","changellyFee":"0.5",
+				"payinExtraId":null,"payoutExtraId":"","amountExpectedFrom":"0.1","status":"new",
+				"currencyFrom":"btc","currencyTo":"dcr","amountTo":"0","payinAddress":"bc1qdeposit",
+				"payoutAddress":"DsUZxxoHJSty8DCfwfartwTYbuhmVct7tJu","createdAt":"2023-05-01T10:00:00.000Z"}}`,
+		}, {
+			Method: "POST", Path: "/", Body: `"method":"getTransactions"`,
+			Response: `{"jsonrpc":"2.0","id":"1","result":[{"id":"jrcka3x4","status":"sending",
+				"amountTo":"181.9","payinConfirmations":"3","payoutHash":"","currencyFrom":"btc","currencyTo":"dcr"}]}`,
+		}},
+		Currencies: []string{"BTC", "DCR", "LTC"},
+		Limits: &exchangetest.LimitsTest{
+			From: "BTC", To: "DCR",
+			Expected: instantswap.QueryLimits{Min: amount("0.00142")},
+		},
+		Rate: &exchangetest.RateTest{
+			Request: instantswap.ExchangeRateRequest{From: "BTC", To: "DCR", Amount: amount("0.1")},
+			Expected: instantswap.ExchangeRateInfo{
+				Min:             amount("0.00142"),
+				ExchangeRate:    1819.871,
+				EstimatedAmount: amount("181.9871"),
+				DepositAmount:   amount("0.1"),
+				RateType:        instantswap.RateTypeFloat,
+			},
+		},
+		Order: &exchangetest.OrderTest{
+			Request: instantswap.CreateOrder{
+				FromCurrency:   "BTC",
+				ToCurrency:     "DCR",
+				Destination:    "DsUZxxoHJSty8DCfwfartwTYbuhmVct7tJu",
+				RefundAddress:  "bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t4",
+				InvoicedAmount: amount("0.1"),
+			},
+			Expected: instantswap.CreateResultInfo{
+				UUID:           "jrcka3x4",
+				Destination:    "DsUZxxoHJSty8DCfwfartwTYbuhmVct7tJu",
+				FromCurrency:   "BTC",
+				ToCurrency:     "DCR",
+				ChargedFee:     amount("0.5"),
+				DepositAddress: "bc1qdeposit",
+			},
+		},
+		Info: &exchangetest.InfoTest{
+			OrderID: "jrcka3x4",
+			Expected: instantswap.OrderInfoResult{
+				ReceiveAmount:  amount("181.9"),
+				Confirmations:  "3",
+				Status:         "sending",
+				InternalStatus: instantswap.OrderStatusSending,
+			},
+		},
+		StatusFunc: GetLocalStatus,
+		Statuses: map[string]instantswap.Status{
+			"new":        instantswap.OrderStatusNew,
+			"waiting":    instantswap.OrderStatusWaitingForDeposit,
+			"confirming": instantswap.OrderStatusDepositReceived,
+			"exchanging": instantswap.OrderStatusExchanging,
+			"sending":    instantswap.OrderStatusSending,
+			"finished":   instantswap.OrderStatusCompleted,
+			"failed":     instantswap.OrderStatusFailed,
+			"refunded":   instantswap.OrderStatusRefunded,
+			"expired":    instantswap.OrderStatusExpired,
+			"hold":       instantswap.OrderStatusUnknown,
+		},
+	})
+}
+
+func TestChangellyFixed(t *testing.T) {
+	amount := instantswap.MustParseAmount
+	exchangetest.Run(t, exchangetest.Suite{
+		Exchange: LIBNAME,
+		Config:   instantswap.ExchangeConfig{ApiKey: "key", ApiSecret: "secret"},
+		Fixtures: []exchangetest.Fixture{{
+			Method: "POST", Path: "/", Body: `"method":"getFixRateForAmount"`,
+			Response: `{"jsonrpc":"2.0","id":"1","result":[{"id":"f1x3d","result":"1801.5","from":"btc","to":"dcr",
+				"networkFee":"0.1","max":"2.5","maxFrom":"2.5","maxTo":"4503.7","min":"0.0025","minFrom":"0.0025",
+				"minTo":"4.5","amountFrom":"0.1","amountTo":"180.15","expiredAt":1682935230}]}`,
+		}, {
+			Method: "POST", Path: "/", Body: `"rateId":"f1x3d"`,
+			Response: `{"jsonrpc":"2.0","id":"1","result":{"id":"fx2ka9b1","apiExtraFee":"0","changellyFee":"0.5",
+				"payinExtraId":null,"payoutExtraId":"","amountExpectedFrom":"0.1","amountExpectedTo":"180.15",
+				"status":"new","payTill":"2023-05-01T10:20:30.000Z","currencyFrom":"btc","currencyTo":"dcr",
+				"amountTo":"0","payinAddress":"bc1qdeposit","payoutAddress":"DsUZxxoHJSty8DCfwfartwTYbuhmVct7tJu","createdAt":"2023-05-01T10:00:00.000Z"}}`,
+		}},
+		Rate: &exchangetest.RateTest{
+			Request: instantswap.ExchangeRateRequest{From: "BTC", To: "DCR", Amount: amount("0.1"), RateType: instantswap.RateTypeFixed},
+			Expected: instantswap.ExchangeRateInfo{
+				Min:             amount("0.0025"),
+				Max:             amount("2.5"),
+				ExchangeRate:    1801.5,
+				EstimatedAmount: amount("180.15"),
+				DepositAmount:   amount("0.1"),
+				Signature:       "f1x3d",
+				RateType:        instantswap.RateTypeFixed,
+				ValidUntil:      time.Unix(1682935230, 0),
+				Fees:            instantswap.Fees{NetworkFee: amount("0.1")},
+			},
+		},
+		Order: &exchangetest.OrderTest{
+			Request: instantswap.CreateOrder{
+				FromCurrency:   "BTC",
+				ToCurrency:     "DCR",
+				Destination:    "DsUZxxoHJSty8DCfwfartwTYbuhmVct7tJu",
+				RefundAddress:  "bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t4",
+				InvoicedAmount: amount("0.1"),
+				Signature:      "f1x3d",
+				RateType:       instantswap.RateTypeFixed,
+			},
+			Expected: instantswap.CreateResultInfo{
+				UUID:           "fx2ka9b1",
+				Destination:    "DsUZxxoHJSty8DCfwfartwTYbuhmVct7tJu",
+				FromCurrency:   "BTC",
+				ToCurrency:     "DCR",
+				ChargedFee:     amount("0.5"),
+				DepositAddress: "bc1qdeposit",
+			},
+		},
+	})
+}
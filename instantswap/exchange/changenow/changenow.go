@@ -1,10 +1,9 @@
 package changenow
 
 import (
+	"context"
 	"encoding/json"
-	"errors"
 	"fmt"
-	"strconv"
 	"strings"
 	"time"
 
@@ -17,26 +16,80 @@ const (
 )
 
 func init() {
-	instantswap.RegisterExchange(LIBNAME, func(config instantswap.ExchangeConfig) (instantswap.IDExchange, error) {
+	instantswap.RegisterExchangeCtx(LIBNAME, func(config instantswap.ExchangeConfig) (instantswap.IDExchangeCtx, error) {
 		return New(config)
 	})
+	instantswap.RegisterCapabilities(LIBNAME, instantswap.Capabilities{
+		FixedRate:     true,
+		FloatingRate:  true,
+		ReverseQuote:  true,
+		PairListing:   true,
+		Limits:        true,
+		RefundAddress: true,
+		ExtraID:       true,
+		APIKey:        true,
+	})
+	instantswap.RegisterRateLimit(LIBNAME, instantswap.RateLimit{Rate: 2, Burst: 5})
+	instantswap.RegisterAssetMapping(LIBNAME, instantswap.AssetMapping{
+		LowerCase: true,
+		Tickers: map[instantswap.Asset]string{
+			{Symbol: "USDT", Network: instantswap.NetworkEthereum}: "usdterc20",
+			{Symbol: "USDT", Network: instantswap.NetworkTron}:     "usdttrc20",
+			{Symbol: "USDT", Network: instantswap.NetworkBSC}:      "usdtbsc",
+			{Symbol: "USDT", Network: instantswap.NetworkPolygon}:  "usdtmatic",
+			{Symbol: "USDT", Network: instantswap.NetworkSolana}:   "usdtsol",
+			{Symbol: "USDC", Network: instantswap.NetworkEthereum}: "usdc",
+			{Symbol: "USDC", Network: instantswap.NetworkBSC}:      "usdcbsc",
+			{Symbol: "USDC", Network: instantswap.NetworkPolygon}:  "usdcmatic",
+			{Symbol: "USDC", Network: instantswap.NetworkSolana}:   "usdcsol",
+			{Symbol: "BNB", Network: instantswap.NetworkBSC}:       "bnbbsc",
+			{Symbol: "MATIC", Network: instantswap.NetworkPolygon}: "maticmainnet",
+		},
+	})
 }
 
 // New return an ChangeNow client struct with IDExchange implement.
 func New(conf instantswap.ExchangeConfig) (*ChangeNow, error) {
 	if conf.ApiKey == "" {
-		err := fmt.Errorf("APIKEY is blank")
+		err := instantswap.NewError(LIBNAME, instantswap.KindUnauthorized, "", "APIKEY is blank")
 		return nil, err
 	}
 	client := instantswap.NewClient(LIBNAME, &conf)
-	return &ChangeNow{client: client, conf: &conf}, nil
+	client.SetErrorParser(parseError)
+	return &ChangeNow{client: client, conf: &conf, apiBase: conf.BaseURLOrDefault(API_BASE)}, nil
+}
+
+// errorKinds maps the error codes of the changenow api.
+var errorKinds = map[string]instantswap.ErrorKind{
+	"pair_is_inactive":  instantswap.KindPairUnavailable,
+	"deposit_too_small": instantswap.KindAmountOutOfRange,
+	"out_of_range":      instantswap.KindAmountOutOfRange,
+	"not_valid_address": instantswap.KindInvalidAddress,
+	"not_found":         instantswap.KindNotFound,
+	"internal_error":    instantswap.KindNetwork,
+}
+
+func parseError(e *instantswap.Error, body []byte) {
+	var apiErr apiError
+	if json.Unmarshal(body, &apiErr) != nil || apiErr.Error == "" {
+		return
+	}
+	e.Code = apiErr.Error
+	if apiErr.Message != "" {
+		e.Message = apiErr.Message
+	}
+	if kind, ok := errorKinds[apiErr.Error]; ok {
+		e.Kind = kind
+	} else if kind := instantswap.ClassifyMessage(apiErr.Message); kind != instantswap.KindUnknown {
+		e.Kind = kind
+	}
 }
 
 // ChangeNow represent a ChangeNow client.
 type ChangeNow struct {
-	conf   *instantswap.ExchangeConfig
-	client *instantswap.Client
-	instantswap.IDExchange
+	conf    *instantswap.ExchangeConfig
+	client  *instantswap.Client
+	apiBase string
 }
 
 // SetDebug set enable/disable http request/response dump.
@@ -44,8 +97,8 @@ func (c *ChangeNow) SetDebug(enable bool) {
 	c.conf.Debug = enable
 }
 
-func (c *ChangeNow) GetCurrencies() (currencies []instantswap.Currency, err error) {
-	r, err := c.client.Do(API_BASE, "GET", "currencies?active=true", "", false)
+func (c *ChangeNow) GetCurrencies(ctx context.Context) (currencies []instantswap.Currency, err error) {
+	r, err := c.client.Do(ctx, c.apiBase, "GET", "currencies?active=true", "", false)
 	if err != nil {
 		return nil, err
 	}
@@ -66,8 +119,8 @@ func (c *ChangeNow) GetCurrencies() (currencies []instantswap.Currency, err erro
 	return currencies, nil
 }
 
-func (c *ChangeNow) GetCurrenciesToPair(from string) (currencies []instantswap.Currency, err error) {
-	r, err := c.client.Do(API_BASE, "GET",
+func (c *ChangeNow) GetCurrenciesToPair(ctx context.Context, from string) (currencies []instantswap.Currency, err error) {
+	r, err := c.client.Do(ctx, c.apiBase, "GET",
 		fmt.Sprintf("currencies-to/%s", strings.ToLower(from)), "", false)
 	if err != nil {
 		return nil, err
@@ -90,42 +143,108 @@ func (c *ChangeNow) GetCurrenciesToPair(from string) (currencies []instantswap.C
 }
 
 // GetExchangeRateInfo get estimate on the amount for the exchange.
-func (c *ChangeNow) GetExchangeRateInfo(vars instantswap.ExchangeRateRequest) (res instantswap.ExchangeRateInfo, err error) {
-	limits, err := c.QueryLimits(vars.From, vars.To)
+func (c *ChangeNow) GetExchangeRateInfo(ctx context.Context, vars instantswap.ExchangeRateRequest) (res instantswap.ExchangeRateInfo, err error) {
+	if vars.Direction == instantswap.DirectionTo {
+		return c.reverseRate(ctx, vars)
+	}
+	limits, err := c.queryLimits(ctx, vars.From, vars.To, vars.RateType)
 	if err != nil {
-		err = errors.New(LIBNAME + ":error: " + err.Error())
+		err = instantswap.WrapError(LIBNAME, "GetExchangeRateInfo", err)
 		return
 	}
-	time.Sleep(time.Second * 1)
-	estimate, err := c.EstimateAmount(vars)
+	estimate, validUntil, err := c.estimateAmount(ctx, vars)
 	if err != nil {
-		err = errors.New(LIBNAME + ":error: " + err.Error())
+		err = instantswap.WrapError(LIBNAME, "GetExchangeRateInfo", err)
 		return
 	}
-	rate := estimate.EstimatedAmount / vars.Amount
+	rate := instantswap.ExchangeRate(estimate.EstimatedAmount, vars.Amount)
 
 	res = instantswap.ExchangeRateInfo{
 		ExchangeRate:    rate,
 		Min:             limits.Min,
 		Max:             limits.Max,
 		EstimatedAmount: estimate.EstimatedAmount,
+		Signature:       estimate.Signature,
+		RateType:        instantswap.RateTypeFloat,
+		Fees: instantswap.Fees{
+			NetworkFee:        estimate.NetworkFee,
+			ServiceFeePercent: estimate.ServiceCommission,
+		},
+	}
+	if vars.RateType == instantswap.RateTypeFixed {
+		res.RateType = instantswap.RateTypeFixed
+		res.ValidUntil = validUntil
+	}
+
+	return
+}
+
+// reverseRate estimates the deposit of a fixed rate exchange, the floating
+// rate reverse estimates are not offered by the v1 api.
+func (c *ChangeNow) reverseRate(ctx context.Context, vars instantswap.ExchangeRateRequest) (res instantswap.ExchangeRateInfo, err error) {
+	if vars.RateType != instantswap.RateTypeFixed {
+		return res, instantswap.ErrNotSupported
+	}
+	limits, err := c.queryLimits(ctx, vars.From, vars.To, vars.RateType)
+	if err != nil {
+		err = instantswap.WrapError(LIBNAME, "GetExchangeRateInfo", err)
+		return
+	}
+	r, err := c.client.Do(ctx, c.apiBase, "GET",
+		fmt.Sprintf("exchange-deposit/fixed-rate/%s/%s_%s?api_key=%s&useRateId=true",
+			vars.Amount.TruncateFor(vars.To, vars.ToNetwork), vars.From, vars.To, c.conf.ApiKey), "", false)
+	if err != nil {
+		err = instantswap.WrapError(LIBNAME, "GetExchangeRateInfo", err)
+		return
+	}
+	var tmpRes EstimateDeposit
+	if err = json.Unmarshal(r, &tmpRes); err != nil {
+		err = instantswap.WrapError(LIBNAME, "GetExchangeRateInfo", err)
+		return
+	}
+	res = instantswap.ExchangeRateInfo{
+		Min:             limits.Min,
+		Max:             limits.Max,
+		EstimatedAmount: vars.Amount,
+		DepositAmount:   tmpRes.EstimatedDeposit,
+		Signature:       tmpRes.RateID,
+		RateType:        instantswap.RateTypeFixed,
+		ValidUntil:      tmpRes.ValidUntil,
+		Fees:            instantswap.Fees{NetworkFee: tmpRes.NetworkFee},
+	}
+	res.ExchangeRate = instantswap.ExchangeRate(vars.Amount, tmpRes.EstimatedDeposit)
+	return
+}
+
+// ratePath returns the path prefix of the fixed rate endpoints.
+func ratePath(rateType instantswap.RateType) string {
+	if rateType == instantswap.RateTypeFixed {
+		return "fixed-rate/"
 	}
+	return ""
+}
 
+// EstimateAmount get estimate on the amount for the exchange. The Signature
+// of a fixed rate estimate is the rate id of the order.
+func (c *ChangeNow) EstimateAmount(ctx context.Context, vars instantswap.ExchangeRateRequest) (res instantswap.EstimateAmount, err error) {
+	res, _, err = c.estimateAmount(ctx, vars)
 	return
 }
 
-// EstimateAmount get estimate on the amount for the exchange.
-func (c *ChangeNow) EstimateAmount(vars instantswap.ExchangeRateRequest) (res instantswap.EstimateAmount, err error) {
-	amountStr := strconv.FormatFloat(vars.Amount, 'f', 8, 64)
-	r, err := c.client.Do(API_BASE, "GET",
-		fmt.Sprintf("exchange-amount/%s/%s_%s?api_key=%s", amountStr, vars.From, vars.To, c.conf.ApiKey), "", false)
+func (c *ChangeNow) estimateAmount(ctx context.Context, vars instantswap.ExchangeRateRequest) (res instantswap.EstimateAmount, validUntil time.Time, err error) {
+	amountStr := vars.Amount.TruncateFor(vars.From, vars.FromNetwork).String()
+	path := fmt.Sprintf("exchange-amount/%s%s/%s_%s?api_key=%s", ratePath(vars.RateType), amountStr, vars.From, vars.To, c.conf.ApiKey)
+	if vars.RateType == instantswap.RateTypeFixed {
+		path += "&useRateId=true"
+	}
+	r, err := c.client.Do(ctx, c.apiBase, "GET", path, "", false)
 	if err != nil {
-		err = errors.New(LIBNAME + ":error: " + err.Error())
+		err = instantswap.WrapError(LIBNAME, "EstimateAmount", err)
 		return
 	}
 	var tmpRes EstimateAmount
 	if err = json.Unmarshal(r, &tmpRes); err != nil {
-		err = errors.New(LIBNAME + ":error: " + err.Error())
+		err = instantswap.WrapError(LIBNAME, "EstimateAmount", err)
 		return
 	}
 
@@ -135,28 +254,28 @@ func (c *ChangeNow) EstimateAmount(vars instantswap.ExchangeRateRequest) (res in
 		ServiceCommission:        tmpRes.ServiceCommission,
 		TransactionSpeedForecast: tmpRes.TransactionSpeedForecast,
 		WarningMessage:           tmpRes.WarningMessage,
+		Signature:                tmpRes.RateID,
 	}
+	validUntil = tmpRes.ValidUntil
 
 	return
 }
 
 // QueryRates (list of pairs LTC-BTC, BTC-LTC, etc).
-func (c *ChangeNow) QueryRates(vars interface{}) (res []instantswap.QueryRate, err error) {
-	//vars not used here
-	err = errors.New(LIBNAME + ":error: not available for this exchange")
-	return
+func (c *ChangeNow) QueryRates(ctx context.Context, vars interface{}) (res []instantswap.QueryRate, err error) {
+	return res, instantswap.ErrNotSupported
 }
 
 // QueryActiveCurrencies get all active currencies.
-func (c *ChangeNow) QueryActiveCurrencies(vars interface{}) (res []instantswap.ActiveCurr, err error) {
-	r, err := c.client.Do(API_BASE, "GET", "currencies?active=true", "", false)
+func (c *ChangeNow) QueryActiveCurrencies(ctx context.Context, vars interface{}) (res []instantswap.ActiveCurr, err error) {
+	r, err := c.client.Do(ctx, c.apiBase, "GET", "currencies?active=true", "", false)
 	if err != nil {
-		err = errors.New(LIBNAME + ":error: " + err.Error())
+		err = instantswap.WrapError(LIBNAME, "QueryActiveCurrencies", err)
 		return
 	}
 	var tmpArr []ActiveCurr
 	if err = json.Unmarshal(r, &tmpArr); err != nil {
-		err = errors.New(LIBNAME + ":error: " + err.Error())
+		err = instantswap.WrapError(LIBNAME, "QueryActiveCurrencies", err)
 		return
 	}
 
@@ -175,15 +294,23 @@ func (c *ChangeNow) QueryActiveCurrencies(vars interface{}) (res []instantswap.A
 }
 
 // QueryLimits Get Exchange Rates (from, to).
-func (c *ChangeNow) QueryLimits(fromCurr, toCurr string) (res instantswap.QueryLimits, err error) {
-	r, err := c.client.Do(API_BASE, "GET", "exchange-range/"+fromCurr+"_"+toCurr, "", false)
+func (c *ChangeNow) QueryLimits(ctx context.Context, fromCurr, toCurr string) (res instantswap.QueryLimits, err error) {
+	return c.queryLimits(ctx, fromCurr, toCurr, instantswap.RateTypeDefault)
+}
+
+func (c *ChangeNow) queryLimits(ctx context.Context, fromCurr, toCurr string, rateType instantswap.RateType) (res instantswap.QueryLimits, err error) {
+	path := "exchange-range/" + fromCurr + "_" + toCurr
+	if rateType == instantswap.RateTypeFixed {
+		path = "exchange-range/fixed-rate/" + fromCurr + "_" + toCurr + "?api_key=" + c.conf.ApiKey
+	}
+	r, err := c.client.Do(ctx, c.apiBase, "GET", path, "", false)
 	if err != nil {
-		err = errors.New(LIBNAME + ":error: " + err.Error())
+		err = instantswap.WrapError(LIBNAME, "QueryLimits", err)
 		return
 	}
 	var tmp QueryLimits
 	if err = json.Unmarshal(r, &tmp); err != nil {
-		err = errors.New(LIBNAME + ":error: " + err.Error())
+		err = instantswap.WrapError(LIBNAME, "QueryLimits", err)
 		return
 	}
 	res = instantswap.QueryLimits{
@@ -194,31 +321,38 @@ func (c *ChangeNow) QueryLimits(fromCurr, toCurr string) (res instantswap.QueryL
 }
 
 // CreateOrder create an instant exchange order.
-func (c *ChangeNow) CreateOrder(orderInfo instantswap.CreateOrder) (res instantswap.CreateResultInfo, err error) {
+func (c *ChangeNow) CreateOrder(ctx context.Context, orderInfo instantswap.CreateOrder) (res instantswap.CreateResultInfo, err error) {
 	tmpOrderInfo := CreateOrder{
 		FromCurrency:      orderInfo.FromCurrency,
 		ToCurrency:        orderInfo.ToCurrency,
 		ToCurrencyAddress: orderInfo.Destination,
 		RefundAddress:     orderInfo.RefundAddress,
-		InvoicedAmount:    strconv.FormatFloat(orderInfo.InvoicedAmount, 'f', 8, 64),
+		InvoicedAmount:    orderInfo.InvoicedAmount.TruncateFor(orderInfo.FromCurrency, orderInfo.FromNetwork).String(),
 		ExtraID:           orderInfo.ExtraID,
 	}
+	if orderInfo.RateType == instantswap.RateTypeFixed {
+		if orderInfo.Signature == "" {
+			err = instantswap.NewError(LIBNAME, instantswap.KindUnknown, "", "a fixed rate order requires the Signature of a fixed rate quote")
+			return
+		}
+		tmpOrderInfo.RateID = orderInfo.Signature
+	}
 
 	payload, err := json.Marshal(tmpOrderInfo)
 	if err != nil {
-		err = errors.New(LIBNAME + ":error: " + err.Error())
+		err = instantswap.WrapError(LIBNAME, "CreateOrder", err)
 		return
 	}
 
-	r, err := c.client.Do(API_BASE, "POST", "transactions/"+c.conf.ApiKey, string(payload), false)
+	r, err := c.client.Do(ctx, c.apiBase, "POST", "transactions/"+ratePath(orderInfo.RateType)+c.conf.ApiKey, string(payload), false)
 	if err != nil {
-		err = errors.New(LIBNAME + ":error: " + err.Error())
+		err = instantswap.WrapError(LIBNAME, "CreateOrder", err)
 		return
 	}
 
 	var tmp CreateResult
 	if err = json.Unmarshal(r, &tmp); err != nil {
-		err = errors.New(LIBNAME + ":error: " + err.Error())
+		err = instantswap.WrapError(LIBNAME, "CreateOrder", err)
 		return
 	}
 
@@ -236,30 +370,28 @@ func (c *ChangeNow) CreateOrder(orderInfo instantswap.CreateOrder) (res instants
 }
 
 // UpdateOrder not available for this exchange.
-func (c *ChangeNow) UpdateOrder(vars interface{}) (res instantswap.UpdateOrderResultInfo, err error) {
-	err = errors.New(LIBNAME + ":error:update not available for this exchange")
-	return
+func (c *ChangeNow) UpdateOrder(ctx context.Context, vars interface{}) (res instantswap.UpdateOrderResultInfo, err error) {
+	return res, instantswap.ErrNotSupported
 }
 
 // CancelOrder not available for this exchange.
-func (c *ChangeNow) CancelOrder(oId string) (res string, err error) {
-	err = errors.New(LIBNAME + ":error:cancel not available for this exchange")
-	return
+func (c *ChangeNow) CancelOrder(ctx context.Context, oId string) (res string, err error) {
+	return res, instantswap.ErrNotSupported
 }
 
 // OrderInfo get information on orderid/uuid.
-func (c *ChangeNow) OrderInfo(orderID string, extraIds ...string) (res instantswap.OrderInfoResult, err error) {
-	r, err := c.client.Do(API_BASE, "GET", "transactions/"+orderID+"/"+c.conf.ApiKey, "", false)
+func (c *ChangeNow) OrderInfo(ctx context.Context, orderID string, extraIds ...string) (res instantswap.OrderInfoResult, err error) {
+	r, err := c.client.Do(ctx, c.apiBase, "GET", "transactions/"+orderID+"/"+c.conf.ApiKey, "", false)
 	if err != nil {
-		err = errors.New(LIBNAME + ":error: " + err.Error())
+		err = instantswap.WrapError(LIBNAME, "OrderInfo", err)
 		return
 	}
 	var tmp OrderInfoResult
 	if err = json.Unmarshal(r, &tmp); err != nil {
-		err = errors.New(LIBNAME + ":error: " + err.Error())
+		err = instantswap.WrapError(LIBNAME, "OrderInfo", err)
 		return
 	}
-	var amountRecv float64
+	var amountRecv instantswap.Amount
 	if tmp.Status != "finished" {
 		amountRecv = tmp.ExpectedAmountReceive
 	} else {
@@ -0,0 +1,152 @@
+package changenow
+
+import (
+	"testing"
+	"time"
+
+	"github.com/crypto-power/instantswap/instantswap"
+	"github.com/crypto-power/instantswap/instantswap/exchangetest"
+)
+
+func TestChangeNow(t *testing.T) {
+	amount := instantswap.MustParseAmount
+	exchangetest.Run(t, exchangetest.Suite{
+		Exchange: LIBNAME,
+		Config:   instantswap.ExchangeConfig{ApiKey: "key"},
+		Fixtures: []exchangetest.Fixture{{
+			Method: "GET", Path: "/v1/currencies", Query: "active=true",
+			Response: `[{"ticker":"btc","name":"Bitcoin","isFiat":false,"isStable":false},
+				{"ticker":"dcr","name":"Decred"},{"ticker":"usdt","name":"Tether","isStable":true}]`,
+		}, {
+			Method: "GET", Path: "/v1/currencies-to/dcr",
+			Response: `[{"ticker":"btc","name":"Bitcoin"},{"ticker":"ltc","name":"Litecoin"}]`,
+		}, {
+			Method: "GET", Path: "/v1/exchange-range/btc_dcr",
+			Response: `{"minAmount":0.0006,"maxAmount":null}`,
+		}, {
+			Method: "GET", Path: "/v1/exchange-amount/0.1/btc_dcr", Query: "api_key=key",
+			Response: `{"estimatedAmount":183.52137104,"networkFee":0.05,"serviceCommission":0.5,"transactionSpeedForecast":"10-60","warningMessage":null}`,
+		}, {
+			Method: "POST", Path: "/v1/transactions/key", Body: `"amount":"0.1"`,
+			Response: `{"payinAddress":"bc1qdeposit","payoutAddress":"DsUZxxoHJSty8DCfwfartwTYbuhmVct7tJu","payinExtraId":"",
+				"fromCurrency":"btc","toCurrency":"dcr","id":"a1b2c3","amount":183.52137104}`,
+		}, {
+			Method: "GET", Path: "/v1/transactions/a1b2c3/key",
+			Response: `{"status":"finished","amountReceive":183.4,"expectedReceiveAmount":183.52137104,
+				"payoutHash":"dcrtxhash","updatedAt":"2023-05-01T10:00:00.000Z"}`,
+		}},
+		Currencies: []string{"BTC", "DCR", "USDT"},
+		PairFrom:   "DCR",
+		Pairs:      []string{"BTC", "LTC"},
+		Limits: &exchangetest.LimitsTest{
+			From: "btc", To: "dcr",
+			Expected: instantswap.QueryLimits{Min: amount("0.0006")},
+		},
+		Rate: &exchangetest.RateTest{
+			Request: instantswap.ExchangeRateRequest{From: "btc", To: "dcr", Amount: amount("0.1")},
+			Expected: instantswap.ExchangeRateInfo{
+				Min:             amount("0.0006"),
+				ExchangeRate:    1835.2137104,
+				EstimatedAmount: amount("183.52137104"),
+				DepositAmount:   amount("0.1"),
+				RateType:        instantswap.RateTypeFloat,
+				Fees:            instantswap.Fees{NetworkFee: amount("0.05"), ServiceFeePercent: 0.5},
+			},
+		},
+		Order: &exchangetest.OrderTest{
+			Request: instantswap.CreateOrder{
+				FromCurrency:   "btc",
+				ToCurrency:     "dcr",
+				Destination:    "DsUZxxoHJSty8DCfwfartwTYbuhmVct7tJu",
+				RefundAddress:  "bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t4",
+				InvoicedAmount: amount("0.1"),
+			},
+			Expected: instantswap.CreateResultInfo{
+				UUID:           "a1b2c3",
+				Destination:    "DsUZxxoHJSty8DCfwfartwTYbuhmVct7tJu",
+				FromCurrency:   "BTC",
+				ToCurrency:     "DCR",
+				InvoicedAmount: amount("0.1"),
+				OrderedAmount:  amount("183.52137104"),
+				DepositAddress: "bc1qdeposit",
+			},
+		},
+		Info: &exchangetest.InfoTest{
+			OrderID: "a1b2c3",
+			Expected: instantswap.OrderInfoResult{
+				LastUpdate:     "2023-05-01T10:00:00.000Z",
+				ReceiveAmount:  amount("183.4"),
+				TxID:           "dcrtxhash",
+				Status:         "finished",
+				InternalStatus: instantswap.OrderStatusCompleted,
+			},
+		},
+		StatusFunc: GetLocalStatus,
+		Statuses: map[string]instantswap.Status{
+			"new":        instantswap.OrderStatusNew,
+			"waiting":    instantswap.OrderStatusWaitingForDeposit,
+			"confirming": instantswap.OrderStatusDepositReceived,
+			"exchanging": instantswap.OrderStatusExchanging,
+			"sending":    instantswap.OrderStatusSending,
+			"finished":   instantswap.OrderStatusCompleted,
+			"failed":     instantswap.OrderStatusFailed,
+			"refunded":   instantswap.OrderStatusRefunded,
+			"expired":    instantswap.OrderStatusExpired,
+			"Finished":   instantswap.OrderStatusCompleted,
+			"verifying":  instantswap.OrderStatusUnknown,
+		},
+	})
+}
+
+func TestChangeNowFixed(t *testing.T) {
+	amount := instantswap.MustParseAmount
+	exchangetest.Run(t, exchangetest.Suite{
+		Exchange: LIBNAME,
+		Config:   instantswap.ExchangeConfig{ApiKey: "key"},
+		Fixtures: []exchangetest.Fixture{{
+			Method: "GET", Path: "/v1/exchange-range/fixed-rate/btc_dcr", Query: "api_key=key",
+			Response: `{"minAmount":0.001,"maxAmount":2.5}`,
+		}, {
+			Method: "GET", Path: "/v1/exchange-amount/fixed-rate/0.1/btc_dcr", Query: "api_key=key&useRateId=true",
+			Response: `{"estimatedAmount":180.1,"networkFee":0.01,"transactionSpeedForecast":"10-60",
+				"warningMessage":null,"rateId":"r4t3","validUntil":"2023-05-01T10:20:00.000Z"}`,
+		}, {
+			Method: "POST", Path: "/v1/transactions/fixed-rate/key", Body: `"rateId":"r4t3"`,
+			Response: `{"payinAddress":"bc1qdeposit","payoutAddress":"DsUZxxoHJSty8DCfwfartwTYbuhmVct7tJu","payinExtraId":"",
+				"fromCurrency":"btc","toCurrency":"dcr","id":"f1x3d","amount":180.1}`,
+		}},
+		Rate: &exchangetest.RateTest{
+			Request: instantswap.ExchangeRateRequest{From: "btc", To: "dcr", Amount: amount("0.1"), RateType: instantswap.RateTypeFixed},
+			Expected: instantswap.ExchangeRateInfo{
+				Min:             amount("0.001"),
+				Max:             amount("2.5"),
+				ExchangeRate:    1801,
+				EstimatedAmount: amount("180.1"),
+				DepositAmount:   amount("0.1"),
+				Signature:       "r4t3",
+				RateType:        instantswap.RateTypeFixed,
+				ValidUntil:      time.Date(2023, 5, 1, 10, 20, 0, 0, time.UTC),
+				Fees:            instantswap.Fees{NetworkFee: amount("0.01")},
+			},
+		},
+		Order: &exchangetest.OrderTest{
+			Request: instantswap.CreateOrder{
+				FromCurrency:   "btc",
+				ToCurrency:     "dcr",
+				Destination:    "DsUZxxoHJSty8DCfwfartwTYbuhmVct7tJu",
+				InvoicedAmount: amount("0.1"),
+				Signature:      "r4t3",
+				RateType:       instantswap.RateTypeFixed,
+			},
+			Expected: instantswap.CreateResultInfo{
+				UUID:           "f1x3d",
+				Destination:    "DsUZxxoHJSty8DCfwfartwTYbuhmVct7tJu",
+				FromCurrency:   "BTC",
+				ToCurrency:     "DCR",
+				InvoicedAmount: amount("0.1"),
+				OrderedAmount:  amount("180.1"),
+				DepositAddress: "bc1qdeposit",
+			},
+		},
+	})
+}
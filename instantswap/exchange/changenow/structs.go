@@ -2,8 +2,17 @@ package changenow
 
 import (
 	"encoding/json"
+	"time"
+
+	"github.com/crypto-power/instantswap/instantswap"
 )
 
+// apiError is the body of the error responses.
+type apiError struct {
+	Error   string `json:"error"`
+	Message string `json:"message"`
+}
+
 // base json structure
 type jsonResponse struct {
 	Errors json.RawMessage `json:"errors"`
@@ -30,8 +39,8 @@ type ActiveCurr struct {
 }
 
 type QueryLimits struct {
-	Max float64 `json:"maxAmount"`
-	Min float64 `json:"minAmount"`
+	Max instantswap.Amount `json:"maxAmount"`
+	Min instantswap.Amount `json:"minAmount"`
 }
 
 // CREATE
@@ -42,16 +51,17 @@ type CreateOrder struct {
 	RefundAddress     string `json:"refundAddress"`
 	InvoicedAmount    string `json:"amount"`            //amount in "from" currency
 	ExtraID           string `json:"extraID,omitempty"` //optional for some coins
+	RateID            string `json:"rateId,omitempty"`  //fixed rate orders
 }
 
 type CreateResult struct {
-	UUID               string  `json:"id"`
-	DepositAddress     string  `json:"payinAddress"`
-	DestinationAddress string  `json:"payoutAddress"`
-	PayinExtraID       string  `json:"payinExtraId"`
-	FromCurrency       string  `json:"fromCurrency"`
-	InvoicedAmount     float64 `json:"amount"`
-	ToCurrency         string  `json:"toCurrency"`
+	UUID               string             `json:"id"`
+	DepositAddress     string             `json:"payinAddress"`
+	DestinationAddress string             `json:"payoutAddress"`
+	PayinExtraID       string             `json:"payinExtraId"`
+	FromCurrency       string             `json:"fromCurrency"`
+	InvoicedAmount     instantswap.Amount `json:"amount"`
+	ToCurrency         string             `json:"toCurrency"`
 }
 
 //INFO
@@ -62,30 +72,39 @@ type UUID struct {
 }
 
 type OrderInfoResult struct {
-	AmountReceive         float64 `json:"amountReceive"`
-	AmountSend            float64 `json:"amountSend"`
-	ExpectedAmountReceive float64 `json:"expectedReceiveAmount"`
-	ExpectedAmountSend    float64 `json:"expectedSendAmount"`
-	FromCurrency          string  `json:"fromCurrency"`
-	Hash                  string  `json:"hash"`
-	ID                    string  `json:"id"`
-	NetworkFee            float64 `json:"networkFee,string"`
-	PayinAddress          string  `json:"payinAddress"`
-	PayinExtraID          string  `json:"payinExtraId"`
-	PayinHash             string  `json:"payinHash"`
-	PayoutAddress         string  `json:"payoutAddress"`
-	PayoutExtraID         string  `json:"payoutExtraId"`
-	PayoutHash            string  `json:"payoutHash"`
-	Status                string  `json:"status"`
-	ToCurrency            string  `json:"toCurrency"`
-	UpdatedAt             string  `json:"updatedAt"`
+	AmountReceive         instantswap.Amount `json:"amountReceive"`
+	AmountSend            instantswap.Amount `json:"amountSend"`
+	ExpectedAmountReceive instantswap.Amount `json:"expectedReceiveAmount"`
+	ExpectedAmountSend    instantswap.Amount `json:"expectedSendAmount"`
+	FromCurrency          string             `json:"fromCurrency"`
+	Hash                  string             `json:"hash"`
+	ID                    string             `json:"id"`
+	NetworkFee            instantswap.Amount `json:"networkFee"`
+	PayinAddress          string             `json:"payinAddress"`
+	PayinExtraID          string             `json:"payinExtraId"`
+	PayinHash             string             `json:"payinHash"`
+	PayoutAddress         string             `json:"payoutAddress"`
+	PayoutExtraID         string             `json:"payoutExtraId"`
+	PayoutHash            string             `json:"payoutHash"`
+	Status                string             `json:"status"`
+	ToCurrency            string             `json:"toCurrency"`
+	UpdatedAt             string             `json:"updatedAt"`
 }
 type EstimateAmount struct {
-	EstimatedAmount          float64     `json:"estimatedAmount"` //destinationCurrency
-	NetworkFee               float64     `json:"networkFee"`
-	ServiceCommission        float64     `json:"serviceCommission"`
-	TransactionSpeedForecast string      `json:"transactionSpeedForecast"`
-	WarningMessage           interface{} `json:"warningMessage"`
+	EstimatedAmount          instantswap.Amount `json:"estimatedAmount"` //destinationCurrency
+	NetworkFee               instantswap.Amount `json:"networkFee"`
+	ServiceCommission        float64            `json:"serviceCommission"`
+	TransactionSpeedForecast string             `json:"transactionSpeedForecast"`
+	WarningMessage           interface{}        `json:"warningMessage"`
+	RateID                   string             `json:"rateId"`
+	ValidUntil               time.Time          `json:"validUntil"`
+}
+
+type EstimateDeposit struct {
+	EstimatedDeposit instantswap.Amount `json:"estimatedDeposit"` //fromCurrency
+	NetworkFee       instantswap.Amount `json:"networkFee"`
+	RateID           string             `json:"rateId"`
+	ValidUntil       time.Time          `json:"validUntil"`
 }
 
 type Currency struct {
@@ -1,10 +1,11 @@
 package easybit
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
-	"strings"
+	"net/url"
 
 	"github.com/crypto-power/instantswap/instantswap"
 	"github.com/crypto-power/instantswap/instantswap/utils"
@@ -16,32 +17,48 @@ const (
 )
 
 func init() {
-	instantswap.RegisterExchange(LIBNAME, func(config instantswap.ExchangeConfig) (instantswap.IDExchange, error) {
+	instantswap.RegisterExchangeCtx(LIBNAME, func(config instantswap.ExchangeConfig) (instantswap.IDExchangeCtx, error) {
 		return New(config)
 	})
+	instantswap.RegisterCapabilities(LIBNAME, instantswap.Capabilities{
+		FloatingRate: true,
+		Networks:     true,
+		APIKey:       true,
+	})
+	instantswap.RegisterRateLimit(LIBNAME, instantswap.RateLimit{Rate: 2, Burst: 5})
+	instantswap.RegisterAssetMapping(LIBNAME, instantswap.AssetMapping{
+		CoinNetworks: true,
+		Networks: map[string]string{
+			instantswap.NetworkBSC:      "BSC",
+			instantswap.NetworkArbitrum: "ARBITRUM",
+			instantswap.NetworkOptimism: "OPTIMISM",
+		},
+	})
 }
 
 // New return an EasyBit api client
 func New(conf instantswap.ExchangeConfig) (*EasyBit, error) {
 	if conf.ApiKey == "" {
-		err := fmt.Errorf("APIKEY is blank")
+		err := instantswap.NewError(LIBNAME, instantswap.KindUnauthorized, "", "APIKEY is blank")
 		return nil, err
 	}
 	client := instantswap.NewClient(LIBNAME, &conf, func(r *http.Request, body string) error {
 		r.Header.Set("API-KEY", conf.ApiKey)
 		return nil
 	})
+	client.SetErrorParser(parseError)
 	return &EasyBit{
-		client: client,
-		conf:   &conf,
+		client:  client,
+		conf:    &conf,
+		apiBase: conf.BaseURLOrDefault(API_BASE),
 	}, nil
 }
 
 // EasyBit represent a EasyBit client.
 type EasyBit struct {
-	client *instantswap.Client
-	conf   *instantswap.ExchangeConfig
-	instantswap.IDExchange
+	client  *instantswap.Client
+	conf    *instantswap.ExchangeConfig
+	apiBase string
 }
 
 // SetDebug set enable/disable http request/response dump.
@@ -49,8 +66,8 @@ func (c *EasyBit) SetDebug(enable bool) {
 	c.conf.Debug = enable
 }
 
-func (c *EasyBit) GetCurrencies() (currencies []instantswap.Currency, err error) {
-	r, err := c.client.Do(API_BASE, "GET", "currencyList", "", false)
+func (c *EasyBit) GetCurrencies(ctx context.Context) (currencies []instantswap.Currency, err error) {
+	r, err := c.client.Do(ctx, c.apiBase, "GET", "currencyList", "", false)
 	if err != nil {
 		return nil, err
 	}
@@ -62,37 +79,22 @@ func (c *EasyBit) GetCurrencies() (currencies []instantswap.Currency, err error)
 	currencies = make([]instantswap.Currency, len(ebCurrencies))
 	for i, currency := range ebCurrencies {
 		currencies[i] = instantswap.Currency{
-			Name:   currency.Name,
-			Symbol: currency.Currency,
+			Name:     currency.Name,
+			Symbol:   currency.Currency,
+			Networks: currency.networks(),
 		}
 	}
 	return currencies, nil
 }
 
-func (c *EasyBit) GetCurrenciesToPair(from string) (currencies []instantswap.Currency, err error) {
-	r, err := c.client.Do(API_BASE, "GET", "currencyList", "", false)
-	if err != nil {
-		return nil, err
-	}
-	var ebCurrencies []Currency
-	err = parseDataResponse(r, &ebCurrencies)
-	if err != nil {
-		return nil, err
-	}
-	for _, currency := range ebCurrencies {
-		if strings.ToLower(from) != strings.ToLower(currency.Currency) {
-			currencies = append(currencies, instantswap.Currency{
-				Name:   currency.Name,
-				Symbol: currency.Currency,
-			})
-		}
-	}
-	return currencies, nil
+func (c *EasyBit) GetCurrenciesToPair(ctx context.Context, from string) (currencies []instantswap.Currency, err error) {
+	return currencies, instantswap.ErrNotSupported
 }
 
-func (c *EasyBit) GetExchangeRateInfo(vars instantswap.ExchangeRateRequest) (res instantswap.ExchangeRateInfo, err error) {
-	r, err := c.client.Do(API_BASE, "GET",
-		fmt.Sprintf("rate?send=%s&receive=%s&amount=%.8f", vars.From, vars.To, vars.Amount), "", false)
+func (c *EasyBit) GetExchangeRateInfo(ctx context.Context, vars instantswap.ExchangeRateRequest) (res instantswap.ExchangeRateInfo, err error) {
+	r, err := c.client.Do(ctx, c.apiBase, "GET",
+		fmt.Sprintf("rate?send=%s&receive=%s&amount=%s%s", vars.From, vars.To, vars.Amount.TruncateFor(vars.From, vars.FromNetwork),
+			networkParams(vars.FromNetwork, vars.ToNetwork)), "", false)
 	if err != nil {
 		return res, err
 	}
@@ -101,20 +103,34 @@ func (c *EasyBit) GetExchangeRateInfo(vars instantswap.ExchangeRateRequest) (res
 	if err != nil {
 		return res, err
 	}
-	pairInfo, _ := c.pairInfo(vars)
+	pairInfo, _ := c.pairInfo(ctx, vars)
 	return instantswap.ExchangeRateInfo{
-		Min:             utils.StrToFloat(pairInfo.MinimumAmount),
-		Max:             utils.StrToFloat(pairInfo.MaximumAmount),
+		Min:             instantswap.AmountFromString(pairInfo.MinimumAmount),
+		Max:             instantswap.AmountFromString(pairInfo.MaximumAmount),
 		ExchangeRate:    utils.StrToFloat(rate.Rate),
-		EstimatedAmount: utils.StrToFloat(rate.ReceiveAmount),
-		MaxOrder:        0,
+		EstimatedAmount: instantswap.AmountFromString(rate.ReceiveAmount),
+		MaxOrder:        instantswap.Amount{},
 		Signature:       "",
+		Fees:            instantswap.Fees{NetworkFee: instantswap.AmountFromString(rate.NetworkFee)},
 	}, nil
 }
 
-func (c *EasyBit) pairInfo(vars instantswap.ExchangeRateRequest) (PairInfo, error) {
-	r, err := c.client.Do(API_BASE, http.MethodGet,
-		fmt.Sprintf("pairInfo?send=%s&receive=%s", vars.From, vars.To), "", false)
+// networkParams returns the query parameters of the networks which are set.
+func networkParams(sendNetwork, receiveNetwork string) string {
+	var params string
+	if sendNetwork != "" {
+		params += "&sendNetwork=" + url.QueryEscape(sendNetwork)
+	}
+	if receiveNetwork != "" {
+		params += "&receiveNetwork=" + url.QueryEscape(receiveNetwork)
+	}
+	return params
+}
+
+func (c *EasyBit) pairInfo(ctx context.Context, vars instantswap.ExchangeRateRequest) (PairInfo, error) {
+	r, err := c.client.Do(ctx, c.apiBase, http.MethodGet,
+		fmt.Sprintf("pairInfo?send=%s&receive=%s%s", vars.From, vars.To,
+			networkParams(vars.FromNetwork, vars.ToNetwork)), "", false)
 	if err != nil {
 		return PairInfo{}, err
 	}
@@ -123,33 +139,39 @@ func (c *EasyBit) pairInfo(vars instantswap.ExchangeRateRequest) (PairInfo, erro
 	return pair, err
 }
 
-func (c *EasyBit) QueryLimits(fromCurr, toCurr string) (res instantswap.QueryLimits, err error) {
-	return
+func (c *EasyBit) QueryLimits(ctx context.Context, fromCurr, toCurr string) (res instantswap.QueryLimits, err error) {
+	return res, instantswap.ErrNotSupported
 }
-func (c *EasyBit) CreateOrder(vars instantswap.CreateOrder) (res instantswap.CreateResultInfo, err error) {
+func (c *EasyBit) CreateOrder(ctx context.Context, vars instantswap.CreateOrder) (res instantswap.CreateResultInfo, err error) {
 	var orderRequest = map[string]string{
 		"send":           vars.FromCurrency,
 		"receive":        vars.ToCurrency,
-		"amount":         fmt.Sprintf("%.8f", vars.InvoicedAmount),
+		"amount":         vars.InvoicedAmount.TruncateFor(vars.FromCurrency, vars.FromNetwork).String(),
 		"receiveAddress": vars.Destination,
 	}
+	if vars.FromNetwork != "" {
+		orderRequest["sendNetwork"] = vars.FromNetwork
+	}
+	if vars.ToNetwork != "" {
+		orderRequest["receiveNetwork"] = vars.ToNetwork
+	}
 	payload, err := json.Marshal(orderRequest)
 	if err != nil {
 		return res, err
 	}
-	r, err := c.client.Do(API_BASE, http.MethodPost, "order", string(payload), false)
+	r, err := c.client.Do(ctx, c.apiBase, http.MethodPost, "order", string(payload), false)
 	if err != nil {
 		return res, err
 	}
 	var order Order
 	err = parseDataResponse(r, &order)
 	return instantswap.CreateResultInfo{
-		ChargedFee:     0,
+		ChargedFee:     instantswap.Amount{},
 		Destination:    order.ReceiveAddress,
 		ExchangeRate:   0,
 		FromCurrency:   order.Send,
-		InvoicedAmount: utils.StrToFloat(order.SendAmount),
-		OrderedAmount:  utils.StrToFloat(order.ReceiveAmount),
+		InvoicedAmount: instantswap.AmountFromString(order.SendAmount),
+		OrderedAmount:  instantswap.AmountFromString(order.ReceiveAmount),
 		ToCurrency:     order.Receive,
 		UUID:           order.Id,
 		DepositAddress: order.SendAddress,
@@ -160,15 +182,15 @@ func (c *EasyBit) CreateOrder(vars instantswap.CreateOrder) (res instantswap.Cre
 }
 
 // UpdateOrder accepts orderID value and more if needed per lib
-func (c *EasyBit) UpdateOrder(vars interface{}) (res instantswap.UpdateOrderResultInfo, err error) {
-	return
+func (c *EasyBit) UpdateOrder(ctx context.Context, vars interface{}) (res instantswap.UpdateOrderResultInfo, err error) {
+	return res, instantswap.ErrNotSupported
 }
-func (c *EasyBit) CancelOrder(orderID string) (res string, err error) {
-	return
+func (c *EasyBit) CancelOrder(ctx context.Context, orderID string) (res string, err error) {
+	return res, instantswap.ErrNotSupported
 }
 
-func (c *EasyBit) OrderInfo(orderID string, extraIds ...string) (res instantswap.OrderInfoResult, err error) {
-	r, err := c.client.Do(API_BASE, http.MethodGet,
+func (c *EasyBit) OrderInfo(ctx context.Context, orderID string, extraIds ...string) (res instantswap.OrderInfoResult, err error) {
+	r, err := c.client.Do(ctx, c.apiBase, http.MethodGet,
 		fmt.Sprintf("orders?id=%s", orderID), "", false)
 	if err != nil {
 		return res, err
@@ -187,7 +209,7 @@ func (c *EasyBit) OrderInfo(orderID string, extraIds ...string) (res instantswap
 			return instantswap.OrderInfoResult{
 				Expires:        0,
 				LastUpdate:     "",
-				ReceiveAmount:  utils.StrToFloat(order.ReceiveAmount),
+				ReceiveAmount:  instantswap.AmountFromString(order.ReceiveAmount),
 				TxID:           txId,
 				Status:         order.Status,
 				InternalStatus: mapOrderStatus(order.Status),
@@ -195,7 +217,7 @@ func (c *EasyBit) OrderInfo(orderID string, extraIds ...string) (res instantswap
 			}, nil
 		}
 	}
-	return res, fmt.Errorf("order[%s] not found", orderID)
+	return res, instantswap.NewError(LIBNAME, instantswap.KindNotFound, "", fmt.Sprintf("order[%s] not found", orderID))
 }
 
 // "Refund" or "Failed" or "Volatility Protection" or "Action Request" or "Request Overdue"
@@ -2,7 +2,9 @@ package easybit
 
 import (
 	"encoding/json"
-	"fmt"
+	"strconv"
+
+	"github.com/crypto-power/instantswap/instantswap"
 )
 
 type general struct {
@@ -19,11 +21,25 @@ func parseDataResponse(r []byte, obj interface{}) error {
 		return err
 	}
 	if res.Success == 0 {
-		return fmt.Errorf("error[%d]: %s", res.ErrorCode, res.ErrorMessage)
+		return instantswap.NewError(LIBNAME, instantswap.ClassifyMessage(res.ErrorMessage),
+			strconv.Itoa(res.ErrorCode), res.ErrorMessage)
 	}
 	return json.Unmarshal(res.Data, obj)
 }
 
+// parseError decodes the body of an error response.
+func parseError(e *instantswap.Error, body []byte) {
+	var res general
+	if json.Unmarshal(body, &res) != nil || res.ErrorMessage == "" {
+		return
+	}
+	e.Code = strconv.Itoa(res.ErrorCode)
+	e.Message = res.ErrorMessage
+	if kind := instantswap.ClassifyMessage(res.ErrorMessage); kind != instantswap.KindUnknown {
+		e.Kind = kind
+	}
+}
+
 type Currency struct {
 	Currency         string    `json:"currency"`
 	Name             string    `json:"name"`
@@ -32,6 +48,15 @@ type Currency struct {
 	NetworkList      []Network `json:"networkList"`
 }
 
+// networks returns the networks of the currency.
+func (c Currency) networks() []string {
+	var networks []string
+	for _, network := range c.NetworkList {
+		networks = append(networks, network.Network)
+	}
+	return networks
+}
+
 type Network struct {
 	Network              string      `json:"network"`
 	Name                 string      `json:"name"`
@@ -0,0 +1,123 @@
+package easybit
+
+import (
+	"context"
+	"testing"
+
+	"github.com/crypto-power/instantswap/instantswap"
+	"github.com/crypto-power/instantswap/instantswap/exchangetest"
+)
+
+func TestEasyBit(t *testing.T) {
+	amount := instantswap.MustParseAmount
+	exchangetest.Run(t, exchangetest.Suite{
+		Exchange: LIBNAME,
+		Config:   instantswap.ExchangeConfig{ApiKey: "key"},
+		Fixtures: []exchangetest.Fixture{{
+			Method: "GET", Path: "/currencyList",
+			Response: `{"success":1,"data":[
+				{"currency":"BTC","name":"Bitcoin","sendStatusAll":true,"receiveStatusAll":true,"networkList":[]},
+				{"currency":"DCR","name":"Decred","sendStatusAll":true,"receiveStatusAll":true,"networkList":[]},
+				{"currency":"LTC","name":"Litecoin","sendStatusAll":true,"receiveStatusAll":true,"networkList":[]}]}`,
+		}, {
+			Method: "GET", Path: "/rate", Query: "send=BTC&receive=DCR&amount=0.1",
+			Response: `{"success":1,"data":{"rate":"1818.5","sendAmount":"0.1","receiveAmount":"181.85",
+				"networkFee":"0.001","confirmations":2,"processingTime":"5-15"}}`,
+		}, {
+			Method: "GET", Path: "/pairInfo", Query: "send=BTC&receive=DCR",
+			Response: `{"success":1,"data":{"minimumAmount":"0.0004","maximumAmount":"5.5","networkFee":"0.001"}}`,
+		}, {
+			Method: "POST", Path: "/order", Body: `"amount":"0.1"`,
+			Response: `{"success":1,"data":{"id":"eb123","send":"BTC","receive":"DCR","sendAmount":"0.1",
+				"receiveAmount":"181.85","sendAddress":"bc1qdeposit","receiveAddress":"DsUZxxoHJSty8DCfwfartwTYbuhmVct7tJu","createdAt":1682935200}}`,
+		}, {
+			Method: "GET", Path: "/orders", Query: "id=eb123",
+			Response: `{"success":1,"data":[{"id":"eb123","status":"Complete","receiveAmount":"181.8","hashOut":"dcrtxhash"}]}`,
+		}},
+		Currencies: []string{"BTC", "DCR"},
+		Rate: &exchangetest.RateTest{
+			Request: instantswap.ExchangeRateRequest{From: "BTC", To: "DCR", Amount: amount("0.1")},
+			Expected: instantswap.ExchangeRateInfo{
+				Min:             amount("0.0004"),
+				Max:             amount("5.5"),
+				ExchangeRate:    1818.5,
+				EstimatedAmount: amount("181.85"),
+				DepositAmount:   amount("0.1"),
+				RateType:        instantswap.RateTypeFloat,
+				Fees:            instantswap.Fees{NetworkFee: amount("0.001")},
+			},
+		},
+		Order: &exchangetest.OrderTest{
+			Request: instantswap.CreateOrder{
+				FromCurrency:   "BTC",
+				ToCurrency:     "DCR",
+				Destination:    "DsUZxxoHJSty8DCfwfartwTYbuhmVct7tJu",
+				InvoicedAmount: amount("0.1"),
+			},
+			Expected: instantswap.CreateResultInfo{
+				UUID:           "eb123",
+				Destination:    "DsUZxxoHJSty8DCfwfartwTYbuhmVct7tJu",
+				FromCurrency:   "BTC",
+				ToCurrency:     "DCR",
+				InvoicedAmount: amount("0.1"),
+				OrderedAmount:  amount("181.85"),
+				DepositAddress: "bc1qdeposit",
+			},
+		},
+		Info: &exchangetest.InfoTest{
+			OrderID: "eb123",
+			Expected: instantswap.OrderInfoResult{
+				ReceiveAmount:  amount("181.8"),
+				TxID:           "dcrtxhash",
+				Status:         "Complete",
+				InternalStatus: instantswap.OrderStatusCompleted,
+			},
+		},
+		StatusFunc: mapOrderStatus,
+		Statuses: map[string]instantswap.Status{
+			"Awaiting Deposit":      instantswap.OrderStatusWaitingForDeposit,
+			"Confirming Deposit":    instantswap.OrderStatusDepositReceived,
+			"Exchanging":            instantswap.OrderStatusExchanging,
+			"Sending":               instantswap.OrderStatusSending,
+			"Complete":              instantswap.OrderStatusCompleted,
+			"Refund":                instantswap.OrderStatusRefunded,
+			"Failed":                instantswap.OrderStatusFailed,
+			"Volatility Protection": instantswap.OrderStatusExchanging,
+			"Action Request":        instantswap.OrderStatusExchanging,
+			"Request Overdue":       instantswap.OrderStatusExpired,
+			"Unknown":               instantswap.OrderStatusUnknown,
+		},
+	})
+}
+
+func TestEasyBitNetworks(t *testing.T) {
+	server := exchangetest.NewServer(t, exchangetest.Fixture{
+		Method: "GET", Path: "/currencyList",
+		Response: `{"success":1,"data":[{"currency":"USDT","name":"Tether","networkList":[{"network":"ETH"},{"network":"TRX"}]}]}`,
+	}, exchangetest.Fixture{
+		Method: "GET", Path: "/rate", Query: "sendNetwork=BTC&receiveNetwork=TRX",
+		Response: `{"success":1,"data":{"rate":"29000","sendAmount":"0.1","receiveAmount":"2900","networkFee":"1"}}`,
+	}, exchangetest.Fixture{
+		Method: "GET", Path: "/pairInfo", Query: "sendNetwork=BTC&receiveNetwork=TRX",
+		Response: `{"success":1,"data":{"minimumAmount":"0.0004","maximumAmount":"5.5","networkFee":"1"}}`,
+	})
+	exchange, err := instantswap.NewExchangeCtx(LIBNAME, server.Config(instantswap.ExchangeConfig{ApiKey: "key"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	currencies, err := exchange.GetCurrencies(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := currencies[0].Networks; len(n) != 2 || n[0] != instantswap.NetworkEthereum || n[1] != instantswap.NetworkTron {
+		t.Errorf("unexpected USDT networks %v", n)
+	}
+	_, err = exchange.GetExchangeRateInfo(ctx, instantswap.ExchangeRateRequest{
+		From: "BTC", FromNetwork: instantswap.NetworkBitcoin, To: "USDT", ToNetwork: instantswap.NetworkTron,
+		Amount: instantswap.MustParseAmount("0.1"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
@@ -1,62 +1,93 @@
 package exchcx
 
 import (
+	"context"
 	"encoding/json"
-	"fmt"
 	"github.com/crypto-power/instantswap/instantswap"
-	"io"
 	"net/http"
 	"net/url"
+	"strconv"
 	"strings"
 )
 
-const LIBNAME = "exchcx"
+const (
+	API_BASE       = "https://exch.cx/api/"
+	ONION_API_BASE = "http://hszyoqwrcp7cxlxnqmovp6vjvmnwj33g4wviuxqzq47emieaxjaperyd.onion/api/"
+	LIBNAME        = "exchcx"
+)
 
 func init() {
-	instantswap.RegisterExchange(LIBNAME, func(config instantswap.ExchangeConfig) (instantswap.IDExchange, error) {
+	instantswap.RegisterExchangeCtx(LIBNAME, func(config instantswap.ExchangeConfig) (instantswap.IDExchangeCtx, error) {
 		return New(config)
 	})
+	instantswap.RegisterCapabilities(LIBNAME, instantswap.Capabilities{
+		FixedRate:     true,
+		FloatingRate:  true,
+		PairListing:   true,
+		RefundAddress: true,
+		Onion:         true,
+	})
+	instantswap.RegisterRateLimit(LIBNAME, instantswap.RateLimit{Rate: 1, Burst: 3})
+	instantswap.RegisterAssetMapping(LIBNAME, instantswap.AssetMapping{
+		Tickers: map[instantswap.Asset]string{
+			{Symbol: "USDT", Network: instantswap.NetworkEthereum}: "USDT",
+			{Symbol: "USDC", Network: instantswap.NetworkEthereum}: "USDC",
+		},
+	})
 }
 
 // New return a exchCx api client
 func New(conf instantswap.ExchangeConfig) (*ExchCx, error) {
-	return &ExchCx{}, nil
+	apiBase := API_BASE
+	if conf.Onion {
+		apiBase = ONION_API_BASE
+	}
+	client := instantswap.NewClient(LIBNAME, &conf, func(r *http.Request, body string) error {
+		r.Header.Set("X-Requested-With", "XMLHttpRequest")
+		return nil
+	})
+	client.SetErrorParser(parseError)
+	return &ExchCx{client: client, conf: &conf, apiBase: conf.BaseURLOrDefault(apiBase)}, nil
 }
 
 type ExchCx struct {
+	conf    *instantswap.ExchangeConfig
+	client  *instantswap.Client
+	apiBase string
 }
 
-func (e *ExchCx) Do(req *http.Request, resObj any) error {
-	client := &http.Client{}
-	req.Header.Set("X-Requested-With", "XMLHttpRequest")
-	resp, err := client.Do(req)
-	if err != nil {
-		return err
+// SetDebug set enable/disable http request/response dump
+func (e *ExchCx) SetDebug(enable bool) {
+	e.conf.Debug = enable
+}
+
+// parseError decodes the error message of an error response.
+func parseError(e *instantswap.Error, body []byte) {
+	var exchErr Error
+	if json.Unmarshal(body, &exchErr) != nil || exchErr.Error == "" {
+		return
 	}
-	body, err := io.ReadAll(resp.Body)
-	defer resp.Body.Close()
+	e.Message = exchErr.Error
+}
+
+// get requests resource and decodes the response in resObj. The api also
+// reports the errors in the body of successful responses.
+func (e *ExchCx) get(ctx context.Context, resource string, resObj any) error {
+	r, err := e.client.Do(ctx, e.apiBase, http.MethodGet, resource, "", false)
 	if err != nil {
 		return err
 	}
 	var exchErr Error
-	_ = json.Unmarshal(body, &exchErr)
+	_ = json.Unmarshal(r, &exchErr)
 	if exchErr.Error != "" {
-		return fmt.Errorf(exchErr.Error)
+		return instantswap.NewError(LIBNAME, instantswap.ClassifyMessage(exchErr.Error), "", exchErr.Error)
 	}
-	return json.Unmarshal(body, resObj)
-}
-
-func (e *ExchCx) path(path string) string {
-	return fmt.Sprintf("https://exch.cx/api/%s", path)
+	return json.Unmarshal(r, resObj)
 }
 
-func (e *ExchCx) GetCurrencies() (currencies []instantswap.Currency, err error) {
-	req, err := http.NewRequest(http.MethodGet, e.path("volume"), nil)
-	if err != nil {
-		return
-	}
+func (e *ExchCx) GetCurrencies(ctx context.Context) (currencies []instantswap.Currency, err error) {
 	var volumnMap map[string]Volume
-	err = e.Do(req, &volumnMap)
+	err = e.get(ctx, "volume", &volumnMap)
 	if err != nil {
 		return
 	}
@@ -69,14 +100,10 @@ func (e *ExchCx) GetCurrencies() (currencies []instantswap.Currency, err error)
 	return
 }
 
-func (e *ExchCx) GetCurrenciesToPair(from string) (currencies []instantswap.Currency, err error) {
-	req, err := http.NewRequest(http.MethodGet, e.path("rates"), nil)
-	if err != nil {
-		return
-	}
+func (e *ExchCx) GetCurrenciesToPair(ctx context.Context, from string) (currencies []instantswap.Currency, err error) {
 	from = strings.ToUpper(from)
 	var rateMap map[string]Rate
-	err = e.Do(req, &rateMap)
+	err = e.get(ctx, "rates", &rateMap)
 	if err != nil {
 		return
 	}
@@ -91,42 +118,39 @@ func (e *ExchCx) GetCurrenciesToPair(from string) (currencies []instantswap.Curr
 	return
 }
 
-func (e *ExchCx) QueryLimits(fromCurr, toCurr string) (res instantswap.QueryLimits, err error) {
-	return
+func (e *ExchCx) QueryLimits(ctx context.Context, fromCurr, toCurr string) (res instantswap.QueryLimits, err error) {
+	return res, instantswap.ErrNotSupported
 }
 
-func (e *ExchCx) CreateOrder(vars instantswap.CreateOrder) (res instantswap.CreateResultInfo, err error) {
+func (e *ExchCx) CreateOrder(ctx context.Context, vars instantswap.CreateOrder) (res instantswap.CreateResultInfo, err error) {
 	var params = url.Values{}
 	params.Set("from_currency", vars.FromCurrency)
 	params.Set("to_currency", vars.ToCurrency)
 	params.Set("to_address", vars.Destination)
 	params.Set("refund_address", vars.RefundAddress)
-	params.Set("rate_mode", "flat")
+	params.Set("rate_mode", rateMode(vars.RateType))
 	params.Set("fee_option", "s")
-	req, err := http.NewRequest(http.MethodGet, e.path("create?"+params.Encode()), nil)
-	if err != nil {
-		return res, err
-	}
 	var createResponse struct {
 		OrderId string `json:"orderid"`
 	}
-	err = e.Do(req, &createResponse)
+	// The orders are created with a GET request, which must not be retried.
+	err = e.get(instantswap.NotIdempotent(ctx), "create?"+params.Encode(), &createResponse)
 	if err != nil {
 		return res, err
 	}
-	order, err := e.getOrder(createResponse.OrderId)
+	order, err := e.getOrder(ctx, createResponse.OrderId)
 	if err != nil {
 		return res, err
 	}
 	res = instantswap.CreateResultInfo{
-		ChargedFee:     0,
+		ChargedFee:     instantswap.Amount{},
 		Destination:    order.ToAddress,
 		ExchangeRate:   order.Rate,
 		FromCurrency:   order.FromCurrency,
-		InvoicedAmount: 0,
-		OrderedAmount:  0,
+		InvoicedAmount: instantswap.Amount{},
+		OrderedAmount:  instantswap.Amount{},
 		ToCurrency:     order.ToCurrency,
-		UUID:           "",
+		UUID:           order.OrderId,
 		DepositAddress: order.FromAddr,
 		Expires:        0,
 		ExtraID:        "",
@@ -135,37 +159,37 @@ func (e *ExchCx) CreateOrder(vars instantswap.CreateOrder) (res instantswap.Crea
 	return
 }
 
-func (e *ExchCx) UpdateOrder(vars interface{}) (res instantswap.UpdateOrderResultInfo, err error) {
-	return
+func (e *ExchCx) UpdateOrder(ctx context.Context, vars interface{}) (res instantswap.UpdateOrderResultInfo, err error) {
+	return res, instantswap.ErrNotSupported
 }
 
-func (e *ExchCx) CancelOrder(orderID string) (res string, err error) {
-	return
+func (e *ExchCx) CancelOrder(ctx context.Context, orderID string) (res string, err error) {
+	return res, instantswap.ErrNotSupported
 }
 
-func (e *ExchCx) getOrder(orderId string) (*Order, error) {
-	req, err := http.NewRequest(http.MethodGet, e.path("order?orderid="+orderId), nil)
-	if err != nil {
-		return nil, err
-	}
+func (e *ExchCx) getOrder(ctx context.Context, orderId string) (*Order, error) {
 	var order Order
-	err = e.Do(req, &order)
+	err := e.get(ctx, "order?orderid="+url.QueryEscape(orderId), &order)
 	if err != nil {
 		return nil, err
 	}
 	return &order, nil
 }
 
-func (e *ExchCx) OrderInfo(orderID string, extraIds ...string) (res instantswap.OrderInfoResult, err error) {
-	order, err := e.getOrder(orderID)
+func (e *ExchCx) OrderInfo(ctx context.Context, orderID string, extraIds ...string) (res instantswap.OrderInfoResult, err error) {
+	order, err := e.getOrder(ctx, orderID)
 	if err != nil {
 		return
 	}
+	var txID string
+	if order.TransactionIdSent != nil {
+		txID = *order.TransactionIdSent
+	}
 	res = instantswap.OrderInfoResult{
 		Expires:        0,
 		LastUpdate:     "",
-		ReceiveAmount:  *order.ToAmount,
-		TxID:           *order.TransactionIdSent,
+		ReceiveAmount:  order.ToAmount,
+		TxID:           txID,
 		Status:         order.State,
 		InternalStatus: statusMap[order.State],
 		Confirmations:  "",
@@ -173,26 +197,56 @@ func (e *ExchCx) OrderInfo(orderID string, extraIds ...string) (res instantswap.
 	return
 }
 
-func (e *ExchCx) GetExchangeRateInfo(vars instantswap.ExchangeRateRequest) (res instantswap.ExchangeRateInfo, err error) {
-	req, err := http.NewRequest(http.MethodGet, e.path("rates"), nil)
-	if err != nil {
-		return
-	}
+func (e *ExchCx) GetExchangeRateInfo(ctx context.Context, vars instantswap.ExchangeRateRequest) (res instantswap.ExchangeRateInfo, err error) {
 	var rateMap map[string]Rate
-	err = e.Do(req, &rateMap)
+	err = e.get(ctx, "rates", &rateMap)
 	if err != nil {
 		return
 	}
 	var pair = strings.ToUpper(vars.From) + "_" + strings.ToUpper(vars.To)
 	var rate, ok = rateMap[pair]
 	if !ok {
-		err = fmt.Errorf("exchange rate info not found")
+		err = instantswap.NewError(LIBNAME, instantswap.KindPairUnavailable, "", "exchange rate info not found")
 		return
 	}
 	res.ExchangeRate = rate.Rate
+	res.RateType = quotedRateType(vars.RateType)
+	// The orders are created with the slow fee option.
+	res.Fees.NetworkFee = feeAmount(rate.NetworkFee.S)
+	res.Fees.ServiceFeePercent, _ = strconv.ParseFloat(rate.SvcFee, 64)
 	return
 }
 
+// rateMode returns the rate_mode of an order of type rateType, the flat rate
+// unless a floating rate is requested.
+func rateMode(rateType instantswap.RateType) string {
+	if rateType == instantswap.RateTypeFloat {
+		return "dynamic"
+	}
+	return "flat"
+}
+
+// quotedRateType returns the rate type of the orders created for a quote
+// requested with rateType.
+func quotedRateType(rateType instantswap.RateType) instantswap.RateType {
+	if rateType == instantswap.RateTypeFloat {
+		return instantswap.RateTypeFloat
+	}
+	return instantswap.RateTypeFixed
+}
+
+// feeAmount converts a network fee of the rates, a string or a number.
+func feeAmount(v any) instantswap.Amount {
+	switch fee := v.(type) {
+	case string:
+		return instantswap.AmountFromString(fee)
+	case float64:
+		return instantswap.AmountFromFloat(fee)
+	default:
+		return instantswap.Amount{}
+	}
+}
+
 var statusMap = map[string]instantswap.Status{
 	"CREATED":           instantswap.OrderStatusNew,
 	"CANCELLED":         instantswap.OrderStatusCanceled,
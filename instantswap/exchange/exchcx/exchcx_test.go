@@ -0,0 +1,179 @@
+package exchcx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/crypto-power/instantswap/instantswap"
+	"github.com/crypto-power/instantswap/instantswap/exchangetest"
+)
+
+const ratesFixture = `{
+	"BTC_DCR":{"network_fee":{"f":"0.0001","m":"0.0001","s":"0.0001"},"rate":"1815.25","rate_mode":"FLAT","reserve":"1200","svc_fee":"0.5"},
+	"BTC_LTC":{"network_fee":{"f":"0.001","m":"0.001","s":"0.001"},"rate":"350.1","rate_mode":"FLAT","reserve":900,"svc_fee":"0.5"},
+	"DCR_BTC":{"network_fee":{"f":"0.0001","m":"0.0001","s":"0.0001"},"rate":"0.00054","rate_mode":"FLAT","reserve":"2","svc_fee":"0.5"}
+}`
+
+const orderFixture = `{"created":1682935200,"from_addr":"bc1qdeposit","from_amount_received":null,"from_currency":"BTC",
+	"max_input":"1.5","min_input":"0.0005","network_fee":"0.0001","orderid":"ee5c1a2b","rate":"1815.25","rate_mode":"FLAT",
+	"state":"%s","svc_fee":"0.5","to_address":"DsUZxxoHJSty8DCfwfartwTYbuhmVct7tJu","to_amount":"%s","to_currency":"DCR",
+	"transaction_id_received":null,"transaction_id_sent":%s}`
+
+func TestExchCx(t *testing.T) {
+	amount := instantswap.MustParseAmount
+	exchangetest.Run(t, exchangetest.Suite{
+		Exchange: LIBNAME,
+		Fixtures: []exchangetest.Fixture{{
+			Method: "GET", Path: "/api/volume",
+			Response: `{"BTC":{"volume":"1.2"},"DCR":{"volume":"5300"},"LTC":{"volume":"12"}}`,
+		}, {
+			Method: "GET", Path: "/api/rates",
+			Response: ratesFixture,
+		}, {
+			Method: "GET", Path: "/api/create", Query: "rate_mode=flat",
+			Response: `{"orderid":"ee5c1a2b"}`,
+		}, {
+			Method: "GET", Path: "/api/order", Query: "orderid=ee5c1a2b",
+			Response: fmt.Sprintf(orderFixture, "COMPLETE", "181.5", `"dcrtxhash"`),
+		}},
+		Currencies: []string{"BTC", "DCR", "LTC"},
+		PairFrom:   "btc",
+		Pairs:      []string{"DCR", "LTC"},
+		Rate: &exchangetest.RateTest{
+			Request: instantswap.ExchangeRateRequest{From: "btc", To: "dcr", Amount: amount("0.1")},
+			Expected: instantswap.ExchangeRateInfo{
+				ExchangeRate:  1815.25,
+				DepositAmount: amount("0.1"),
+				RateType:      instantswap.RateTypeFixed,
+				Fees:          instantswap.Fees{NetworkFee: amount("0.0001"), ServiceFeePercent: 0.5},
+			},
+		},
+		Order: &exchangetest.OrderTest{
+			Request: instantswap.CreateOrder{
+				FromCurrency:   "BTC",
+				ToCurrency:     "DCR",
+				Destination:    "DsUZxxoHJSty8DCfwfartwTYbuhmVct7tJu",
+				RefundAddress:  "bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t4",
+				InvoicedAmount: amount("0.1"),
+			},
+			Expected: instantswap.CreateResultInfo{
+				UUID:           "ee5c1a2b",
+				Destination:    "DsUZxxoHJSty8DCfwfartwTYbuhmVct7tJu",
+				ExchangeRate:   1815.25,
+				FromCurrency:   "BTC",
+				ToCurrency:     "DCR",
+				DepositAddress: "bc1qdeposit",
+			},
+		},
+		Info: &exchangetest.InfoTest{
+			OrderID: "ee5c1a2b",
+			Expected: instantswap.OrderInfoResult{
+				ReceiveAmount:  amount("181.5"),
+				TxID:           "dcrtxhash",
+				Status:         "COMPLETE",
+				InternalStatus: instantswap.OrderStatusCompleted,
+			},
+		},
+		StatusFunc: func(status string) instantswap.Status { return statusMap[status] },
+		Statuses: map[string]instantswap.Status{
+			"CREATED":           instantswap.OrderStatusNew,
+			"CANCELLED":         instantswap.OrderStatusCanceled,
+			"AWAITING_INPUT":    instantswap.OrderStatusWaitingForDeposit,
+			"CONFIRMING_INPUT":  instantswap.OrderStatusWaitingForDeposit,
+			"EXCHANGING":        instantswap.OrderStatusExchanging,
+			"FUNDED":            instantswap.OrderStatusDepositConfirmed,
+			"BRIDGING":          instantswap.OrderStatusSending,
+			"CONFIRMING_SEND":   instantswap.OrderStatusSending,
+			"COMPLETE":          instantswap.OrderStatusCompleted,
+			"REFUND_REQUEST":    instantswap.OrderStatusRefunded,
+			"REFUNDED":          instantswap.OrderStatusRefunded,
+			"CONFIRMING_REFUND": instantswap.OrderStatusRefunded,
+			"UNKNOWN":           instantswap.OrderStatusUnknown,
+		},
+	})
+}
+
+func TestExchCxPendingOrder(t *testing.T) {
+	server := exchangetest.NewServer(t, exchangetest.Fixture{
+		Method: "GET", Path: "/api/order", Query: "orderid=ee5c1a2b",
+		Response: fmt.Sprintf(orderFixture, "AWAITING_INPUT", "0", "null"),
+	})
+	exchange, err := New(server.Config(instantswap.ExchangeConfig{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	info, err := exchange.OrderInfo(context.Background(), "ee5c1a2b")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.TxID != "" || info.InternalStatus != instantswap.OrderStatusWaitingForDeposit {
+		t.Errorf("unexpected order info: %+v", info)
+	}
+}
+
+func TestExchCxFloatingRate(t *testing.T) {
+	server := exchangetest.NewServer(t, exchangetest.Fixture{
+		Method: "GET", Path: "/api/rates",
+		Response: ratesFixture,
+	}, exchangetest.Fixture{
+		Method: "GET", Path: "/api/create", Query: "rate_mode=dynamic",
+		Response: `{"orderid":"ee5c1a2b"}`,
+	}, exchangetest.Fixture{
+		Method: "GET", Path: "/api/order", Query: "orderid=ee5c1a2b",
+		Response: fmt.Sprintf(orderFixture, "CREATED", "0", "null"),
+	})
+	exchange, err := New(server.Config(instantswap.ExchangeConfig{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rate, err := exchange.GetExchangeRateInfo(context.Background(), instantswap.ExchangeRateRequest{
+		From: "btc", To: "dcr", Amount: instantswap.MustParseAmount("0.1"), RateType: instantswap.RateTypeFloat,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rate.RateType != instantswap.RateTypeFloat {
+		t.Errorf("got rate type %v, expected float", rate.RateType)
+	}
+	_, err = exchange.CreateOrder(context.Background(), instantswap.CreateOrder{
+		FromCurrency:  "BTC",
+		ToCurrency:    "DCR",
+		Destination:   "DsUZxxoHJSty8DCfwfartwTYbuhmVct7tJu",
+		RefundAddress: "bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t4",
+		RateType:      instantswap.RateTypeFloat,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestExchCxErrors(t *testing.T) {
+	server := exchangetest.NewServer(t, exchangetest.Fixture{
+		Method: "GET", Path: "/api/order",
+		Response: `{"error":"order not found"}`,
+	}, exchangetest.Fixture{
+		Method: "GET", Path: "/api/create",
+		Status: 502, Response: `{"error":"service unavailable"}`,
+	})
+	conf := server.Config(instantswap.ExchangeConfig{})
+	conf.Retry = &instantswap.RetryPolicy{MaxRetries: 3, MinBackoff: time.Millisecond, MaxBackoff: time.Millisecond}
+	exchange, err := New(conf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = exchange.OrderInfo(context.Background(), "missing")
+	var exchErr *instantswap.Error
+	if !errors.As(err, &exchErr) || exchErr.Message != "order not found" {
+		t.Errorf("unexpected error %v", err)
+	}
+	_, err = exchange.CreateOrder(context.Background(), instantswap.CreateOrder{FromCurrency: "BTC", ToCurrency: "DCR"})
+	if !errors.As(err, &exchErr) || exchErr.HTTPStatus != 502 || exchErr.Message != "service unavailable" {
+		t.Errorf("unexpected error %v", err)
+	}
+	if n := len(server.Requests()); n != 2 {
+		t.Errorf("got %d requests, the order creation must not be retried", n)
+	}
+}
@@ -1,5 +1,7 @@
 package exchcx
 
+import "github.com/crypto-power/instantswap/instantswap"
+
 type Error struct {
 	Error string `json:"error"`
 }
@@ -33,21 +35,21 @@ type Rate struct {
 }
 
 type Order struct {
-	Created               int      `json:"created"`
-	FromAddr              string   `json:"from_addr"`
-	FromAmountReceived    *float64 `json:"from_amount_received"`
-	FromCurrency          string   `json:"from_currency"`
-	MaxInput              string   `json:"max_input"`
-	MinInput              string   `json:"min_input"`
-	NetworkFee            float64  `json:"network_fee,string"`
-	OrderId               string   `json:"orderid"`
-	Rate                  float64  `json:"rate,string"`
-	RateMode              string   `json:"rate_mode"`
-	State                 string   `json:"state"`
-	SvcFee                string   `json:"svc_fee"`
-	ToAddress             string   `json:"to_address"`
-	ToAmount              *float64 `json:"to_amount"`
-	ToCurrency            string   `json:"to_currency"`
-	TransactionIdReceived *string  `json:"transaction_id_received"`
-	TransactionIdSent     *string  `json:"transaction_id_sent"`
+	Created               int                `json:"created"`
+	FromAddr              string             `json:"from_addr"`
+	FromAmountReceived    instantswap.Amount `json:"from_amount_received"`
+	FromCurrency          string             `json:"from_currency"`
+	MaxInput              string             `json:"max_input"`
+	MinInput              string             `json:"min_input"`
+	NetworkFee            instantswap.Amount `json:"network_fee"`
+	OrderId               string             `json:"orderid"`
+	Rate                  float64            `json:"rate,string"`
+	RateMode              string             `json:"rate_mode"`
+	State                 string             `json:"state"`
+	SvcFee                string             `json:"svc_fee"`
+	ToAddress             string             `json:"to_address"`
+	ToAmount              instantswap.Amount `json:"to_amount"`
+	ToCurrency            string             `json:"to_currency"`
+	TransactionIdReceived *string            `json:"transaction_id_received"`
+	TransactionIdSent     *string            `json:"transaction_id_sent"`
 }
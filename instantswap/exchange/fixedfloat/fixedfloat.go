@@ -1,13 +1,12 @@
 package fixedfloat
 
 import (
+	"context"
 	"crypto/hmac"
 	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
-	"fmt"
 	"net/http"
-	"strings"
 
 	"github.com/crypto-power/instantswap/instantswap"
 )
@@ -19,22 +18,42 @@ const (
 
 // The work on fixedfloat is pending
 func init() {
-	instantswap.RegisterExchange(LIBNAME, func(config instantswap.ExchangeConfig) (instantswap.IDExchange, error) {
+	instantswap.RegisterExchangeCtx(LIBNAME, func(config instantswap.ExchangeConfig) (instantswap.IDExchangeCtx, error) {
 		return New(config)
 	})
+	instantswap.RegisterCapabilities(LIBNAME, instantswap.Capabilities{
+		FixedRate:    true,
+		FloatingRate: true,
+		ReverseQuote: true,
+		APIKey:       true,
+		APISecret:    true,
+	})
+	instantswap.RegisterRateLimit(LIBNAME, instantswap.RateLimit{Rate: 1, Burst: 2})
+	instantswap.RegisterAssetMapping(LIBNAME, instantswap.AssetMapping{
+		Tickers: map[instantswap.Asset]string{
+			{Symbol: "USDT", Network: instantswap.NetworkEthereum}: "USDT",
+			{Symbol: "USDT", Network: instantswap.NetworkTron}:     "USDTTRC",
+			{Symbol: "USDT", Network: instantswap.NetworkBSC}:      "USDTBSC",
+			{Symbol: "USDT", Network: instantswap.NetworkSolana}:   "USDTSOL",
+			{Symbol: "USDC", Network: instantswap.NetworkEthereum}: "USDC",
+			{Symbol: "USDC", Network: instantswap.NetworkBSC}:      "USDCBSC",
+			{Symbol: "USDC", Network: instantswap.NetworkSolana}:   "USDCSOL",
+			{Symbol: "BNB", Network: instantswap.NetworkBSC}:       "BSC",
+		},
+	})
 }
 
 // FixedFloat represent a FixedFloat client.
 type FixedFloat struct {
-	conf   *instantswap.ExchangeConfig
-	client *instantswap.Client
-	instantswap.IDExchange
+	conf    *instantswap.ExchangeConfig
+	client  *instantswap.Client
+	apiBase string
 }
 
 // New return FixedFloat client.
 func New(conf instantswap.ExchangeConfig) (*FixedFloat, error) {
 	if conf.ApiKey == "" || conf.ApiSecret == "" {
-		return nil, fmt.Errorf("%s:error: api key and api secret must be provided", LIBNAME)
+		return nil, instantswap.NewError(LIBNAME, instantswap.KindUnauthorized, "", "api key and api secret must be provided")
 	}
 	client := instantswap.NewClient(LIBNAME, &conf, func(r *http.Request, body string) error {
 		key := []byte(conf.ApiSecret)
@@ -45,7 +64,7 @@ func New(conf instantswap.ExchangeConfig) (*FixedFloat, error) {
 		r.Header.Set("X-API-KEY", conf.ApiKey)
 		return nil
 	})
-	return &FixedFloat{client: client, conf: &conf}, nil
+	return &FixedFloat{client: client, conf: &conf, apiBase: conf.BaseURLOrDefault(API_BASE)}, nil
 }
 
 // SetDebug set enable/disable http request/response dump
@@ -53,9 +72,9 @@ func (c *FixedFloat) SetDebug(enable bool) {
 	c.conf.Debug = enable
 }
 
-func (c *FixedFloat) GetCurrencies() (currencies []instantswap.Currency, err error) {
+func (c *FixedFloat) GetCurrencies(ctx context.Context) (currencies []instantswap.Currency, err error) {
 	var r []byte
-	r, err = c.client.Do(API_BASE, http.MethodPost, "ccies", "", false)
+	r, err = c.client.Do(instantswap.Idempotent(ctx), c.apiBase, http.MethodPost, "ccies", "", false)
 	if err != nil {
 		return nil, err
 	}
@@ -71,23 +90,8 @@ func (c *FixedFloat) GetCurrencies() (currencies []instantswap.Currency, err err
 	return currencies, err
 }
 
-func (c *FixedFloat) GetCurrenciesToPair(from string) (currencies []instantswap.Currency, err error) {
-	var r []byte
-	r, err = c.client.Do(API_BASE, http.MethodPost, "ccies", "", false)
-	if err != nil {
-		return nil, err
-	}
-	var ffCurrs []Currency
-	err = parseResponseData(r, &ffCurrs)
-	for _, ffCurr := range ffCurrs {
-		if strings.ToLower(from) != strings.ToLower(ffCurr.Code) {
-			currencies = append(currencies, instantswap.Currency{
-				Name:   ffCurr.Name,
-				Symbol: ffCurr.Code,
-			})
-		}
-	}
-	return currencies, err
+func (c *FixedFloat) GetCurrenciesToPair(ctx context.Context, from string) (currencies []instantswap.Currency, err error) {
+	return currencies, instantswap.ErrNotSupported
 }
 
 func buildBody(data interface{}) string {
@@ -99,16 +103,20 @@ func buildBody(data interface{}) string {
 }
 
 // GetExchangeRateInfo get estimate on the amount for the exchange.
-func (c *FixedFloat) GetExchangeRateInfo(vars instantswap.ExchangeRateRequest) (res instantswap.ExchangeRateInfo, err error) {
+func (c *FixedFloat) GetExchangeRateInfo(ctx context.Context, vars instantswap.ExchangeRateRequest) (res instantswap.ExchangeRateInfo, err error) {
 	f := PriceReq{
 		FromCcy:   vars.From,
 		ToCcy:     vars.To,
-		Amount:    vars.Amount,
+		Amount:    json.Number(vars.Amount.TruncateFor(vars.From, vars.FromNetwork).String()),
 		Direction: "from",
-		Type:      "fixed",
+		Type:      orderType(vars.RateType),
+	}
+	if vars.Direction == instantswap.DirectionTo {
+		f.Amount = json.Number(vars.Amount.TruncateFor(vars.To, vars.ToNetwork).String())
+		f.Direction = "to"
 	}
 	var r []byte
-	r, err = c.client.Do(API_BASE, http.MethodPost, "price", buildBody(f), false)
+	r, err = c.client.Do(instantswap.Idempotent(ctx), c.apiBase, http.MethodPost, "price", buildBody(f), false)
 	if err != nil {
 		return res, err
 	}
@@ -122,22 +130,44 @@ func (c *FixedFloat) GetExchangeRateInfo(vars instantswap.ExchangeRateRequest) (
 		Max:             priceRes.From.Max,
 		ExchangeRate:    priceRes.From.Rate,
 		EstimatedAmount: priceRes.To.Amount,
-		MaxOrder:        0,
+		DepositAmount:   priceRes.From.Amount,
+		MaxOrder:        instantswap.Amount{},
 		Signature:       "",
+		RateType:        quotedRateType(vars.RateType),
 	}, nil
 }
 
-func (c *FixedFloat) CreateOrder(vars instantswap.CreateOrder) (res instantswap.CreateResultInfo, err error) {
+// orderType returns the order type of the api, fixed unless a floating rate
+// is requested.
+func orderType(rateType instantswap.RateType) string {
+	if rateType == instantswap.RateTypeFloat {
+		return "float"
+	}
+	return "fixed"
+}
+
+func quotedRateType(rateType instantswap.RateType) instantswap.RateType {
+	if rateType == instantswap.RateTypeFloat {
+		return instantswap.RateTypeFloat
+	}
+	return instantswap.RateTypeFixed
+}
+
+func (c *FixedFloat) QueryLimits(ctx context.Context, fromCurr, toCurr string) (res instantswap.QueryLimits, err error) {
+	return res, instantswap.ErrNotSupported
+}
+
+func (c *FixedFloat) CreateOrder(ctx context.Context, vars instantswap.CreateOrder) (res instantswap.CreateResultInfo, err error) {
 	var f = CreateOrderRequest{
 		FromCcy:   vars.FromCurrency,
 		ToCcy:     vars.ToCurrency,
-		Amount:    vars.InvoicedAmount,
+		Amount:    json.Number(vars.InvoicedAmount.TruncateFor(vars.FromCurrency, vars.FromNetwork).String()),
 		Direction: "from",
-		Type:      "fixed",
+		Type:      orderType(vars.RateType),
 		ToAddress: vars.Destination,
 	}
 	var r []byte
-	r, err = c.client.Do(API_BASE, http.MethodPost, "create", buildBody(f), false)
+	r, err = c.client.Do(ctx, c.apiBase, http.MethodPost, "create", buildBody(f), false)
 	if err != nil {
 		return res, err
 	}
@@ -147,9 +177,9 @@ func (c *FixedFloat) CreateOrder(vars instantswap.CreateOrder) (res instantswap.
 		return res, err
 	}
 	return instantswap.CreateResultInfo{
-		ChargedFee:     0,
-		Destination:    orderRes.From.Address,
-		ExchangeRate:   orderRes.From.Amount / orderRes.To.Amount,
+		ChargedFee:     instantswap.Amount{},
+		Destination:    orderRes.To.Address,
+		ExchangeRate:   instantswap.ExchangeRate(orderRes.To.Amount, orderRes.From.Amount),
 		FromCurrency:   orderRes.From.Code,
 		InvoicedAmount: orderRes.From.Amount,
 		OrderedAmount:  orderRes.To.Amount,
@@ -163,17 +193,17 @@ func (c *FixedFloat) CreateOrder(vars instantswap.CreateOrder) (res instantswap.
 }
 
 // UpdateOrder accepts orderID value and more if needed per lib.
-func (c *FixedFloat) UpdateOrder(vars interface{}) (res instantswap.UpdateOrderResultInfo, err error) {
-	return
+func (c *FixedFloat) UpdateOrder(ctx context.Context, vars interface{}) (res instantswap.UpdateOrderResultInfo, err error) {
+	return res, instantswap.ErrNotSupported
 }
-func (c *FixedFloat) CancelOrder(orderID string) (res string, err error) {
-	return
+func (c *FixedFloat) CancelOrder(ctx context.Context, orderID string) (res string, err error) {
+	return res, instantswap.ErrNotSupported
 }
 
 // OrderInfo accepts string of orderID value.
-func (c *FixedFloat) OrderInfo(orderID string, extraIds ...string) (res instantswap.OrderInfoResult, err error) {
+func (c *FixedFloat) OrderInfo(ctx context.Context, orderID string, extraIds ...string) (res instantswap.OrderInfoResult, err error) {
 	if len(extraIds) == 0 {
-		return res, fmt.Errorf("fetching fixedfloat order require order token")
+		return res, instantswap.NewError(LIBNAME, instantswap.KindNotFound, "", "fetching fixedfloat order require order token")
 	}
 	var f = struct {
 		Id    string `json:"id"`
@@ -183,7 +213,7 @@ func (c *FixedFloat) OrderInfo(orderID string, extraIds ...string) (res instants
 		Token: extraIds[0],
 	}
 	var r []byte
-	r, err = c.client.Do(API_BASE, http.MethodPost, "order", buildBody(f), false)
+	r, err = c.client.Do(instantswap.Idempotent(ctx), c.apiBase, http.MethodPost, "order", buildBody(f), false)
 	if err != nil {
 		return res, err
 	}
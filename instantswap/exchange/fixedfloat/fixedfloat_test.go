@@ -0,0 +1,241 @@
+package fixedfloat
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/crypto-power/instantswap/instantswap"
+	"github.com/crypto-power/instantswap/instantswap/exchangetest"
+)
+
+const orderFixture = `{"code":0,"msg":"OK","data":{"id":"TESTID","type":"fixed","email":"","status":"%s",
+	"time":{"reg":1682935200,"start":null,"finish":null,"update":1682935200,"expiration":1682937000,"left":1800},
+	"from":{"code":"BTC","coin":"BTC","network":"BTC","name":"Bitcoin","alias":"btc","amount":"0.1","address":"bc1qdeposit",
+		"tx":{"id":null,"amount":null,"fee":null,"ccyfee":null,"timeReg":null,"timeBlock":null,"confirmations":null}},
+	"to":{"code":"DCR","coin":"DCR","network":"DCR","name":"Decred","alias":"dcr","amount":"181.2","address":"DsUZxxoHJSty8DCfwfartwTYbuhmVct7tJu",
+		"tx":{"id":%s,"amount":null,"fee":null,"ccyfee":null,"timeReg":null,"timeBlock":null,"confirmations":null}},
+	"back":{"tx":{}},"emergency":{"status":[],"choice":"NONE","repeat":"0"},"token":"TOKEN"}}`
+
+func TestFixedFloat(t *testing.T) {
+	amount := instantswap.MustParseAmount
+	exchangetest.Run(t, exchangetest.Suite{
+		Exchange: LIBNAME,
+		Config:   instantswap.ExchangeConfig{ApiKey: "key", ApiSecret: "secret"},
+		Fixtures: []exchangetest.Fixture{{
+			Method: "POST", Path: "/api/v2/ccies",
+			Response: `{"code":0,"msg":"OK","data":[
+				{"code":"BTC","coin":"BTC","network":"BTC","name":"Bitcoin","recv":true,"send":true,"tag":null,"priority":5},
+				{"code":"DCR","coin":"DCR","network":"DCR","name":"Decred","recv":true,"send":true,"tag":null,"priority":3},
+				{"code":"USDTTRC","coin":"USDT","network":"TRX","name":"Tether (TRC20)","recv":1,"send":1,"tag":null,"priority":4}]}`,
+		}, {
+			Method: "POST", Path: "/api/v2/price", Body: `"amount":0.1`,
+			Response: `{"code":0,"msg":"OK","data":{
+				"from":{"code":"BTC","network":"BTC","coin":"BTC","amount":"0.1","rate":"1812","precision":8,"min":"0.0003","max":"2.4","usd":"2900","btc":"0.1"},
+				"to":{"code":"DCR","network":"DCR","coin":"DCR","amount":"181.2","rate":"0.000551","precision":8,"min":"0.5","max":"4400","usd":"2890"},
+				"errors":[]}}`,
+		}, {
+			Method: "POST", Path: "/api/v2/create", Body: `"toAddress":"DsUZxxoHJSty8DCfwfartwTYbuhmVct7tJu"`,
+			Response: fmt.Sprintf(orderFixture, "NEW", "null"),
+		}, {
+			Method: "POST", Path: "/api/v2/order", Body: `"token":"TOKEN"`,
+			Response: fmt.Sprintf(orderFixture, "DONE", `"dcrtxhash"`),
+		}},
+		Currencies: []string{"BTC", "DCR", "USDT"},
+		Rate: &exchangetest.RateTest{
+			Request: instantswap.ExchangeRateRequest{From: "BTC", To: "DCR", Amount: amount("0.1")},
+			Expected: instantswap.ExchangeRateInfo{
+				Min:             amount("0.0003"),
+				Max:             amount("2.4"),
+				ExchangeRate:    1812,
+				EstimatedAmount: amount("181.2"),
+				DepositAmount:   amount("0.1"),
+				RateType:        instantswap.RateTypeFixed,
+			},
+		},
+		Order: &exchangetest.OrderTest{
+			Request: instantswap.CreateOrder{
+				FromCurrency:   "BTC",
+				ToCurrency:     "DCR",
+				Destination:    "DsUZxxoHJSty8DCfwfartwTYbuhmVct7tJu",
+				InvoicedAmount: amount("0.1"),
+			},
+			Expected: instantswap.CreateResultInfo{
+				UUID:           "TESTID",
+				Destination:    "DsUZxxoHJSty8DCfwfartwTYbuhmVct7tJu",
+				ExchangeRate:   1812,
+				FromCurrency:   "BTC",
+				ToCurrency:     "DCR",
+				InvoicedAmount: amount("0.1"),
+				OrderedAmount:  amount("181.2"),
+				DepositAddress: "bc1qdeposit",
+				ExtraID:        "TOKEN",
+			},
+		},
+		Info: &exchangetest.InfoTest{
+			OrderID:  "TESTID",
+			ExtraIDs: []string{"TOKEN"},
+			Expected: instantswap.OrderInfoResult{
+				ReceiveAmount:  amount("181.2"),
+				TxID:           "dcrtxhash",
+				Status:         "DONE",
+				InternalStatus: instantswap.OrderStatusCompleted,
+			},
+		},
+		StatusFunc: GetLocalStatus,
+		Statuses: map[string]instantswap.Status{
+			"NEW":       instantswap.OrderStatusWaitingForDeposit,
+			"PENDING":   instantswap.OrderStatusDepositReceived,
+			"EXCHANGE":  instantswap.OrderStatusExchanging,
+			"WITHDRAW":  instantswap.OrderStatusSending,
+			"DONE":      instantswap.OrderStatusCompleted,
+			"EXPIRED":   instantswap.OrderStatusExpired,
+			"EMERGENCY": instantswap.OrderStatusFailed,
+			"done":      instantswap.OrderStatusUnknown,
+		},
+	})
+}
+
+func TestFixedFloatFloat(t *testing.T) {
+	amount := instantswap.MustParseAmount
+	exchangetest.Run(t, exchangetest.Suite{
+		Exchange: LIBNAME,
+		Config:   instantswap.ExchangeConfig{ApiKey: "key", ApiSecret: "secret"},
+		Fixtures: []exchangetest.Fixture{{
+			Method: "POST", Path: "/api/v2/price", Body: `"type":"float"`,
+			Response: `{"code":0,"msg":"OK","data":{
+				"from":{"code":"BTC","network":"BTC","coin":"BTC","amount":"0.1","rate":"1825","precision":8,"min":"0.0003","max":"2.4","usd":"2900","btc":"0.1"},
+				"to":{"code":"DCR","network":"DCR","coin":"DCR","amount":"182.5","rate":"0.000548","precision":8,"min":"0.5","max":"4400","usd":"2890"},
+				"errors":[]}}`,
+		}, {
+			Method: "POST", Path: "/api/v2/create", Body: `"type":"float"`,
+			Response: fmt.Sprintf(orderFixture, "NEW", "null"),
+		}},
+		Rate: &exchangetest.RateTest{
+			Request: instantswap.ExchangeRateRequest{From: "BTC", To: "DCR", Amount: amount("0.1"), RateType: instantswap.RateTypeFloat},
+			Expected: instantswap.ExchangeRateInfo{
+				Min:             amount("0.0003"),
+				Max:             amount("2.4"),
+				ExchangeRate:    1825,
+				EstimatedAmount: amount("182.5"),
+				DepositAmount:   amount("0.1"),
+				RateType:        instantswap.RateTypeFloat,
+			},
+		},
+		Order: &exchangetest.OrderTest{
+			Request: instantswap.CreateOrder{
+				FromCurrency:   "BTC",
+				ToCurrency:     "DCR",
+				Destination:    "DsUZxxoHJSty8DCfwfartwTYbuhmVct7tJu",
+				InvoicedAmount: amount("0.1"),
+				RateType:       instantswap.RateTypeFloat,
+			},
+			Expected: instantswap.CreateResultInfo{
+				UUID:           "TESTID",
+				Destination:    "DsUZxxoHJSty8DCfwfartwTYbuhmVct7tJu",
+				ExchangeRate:   1812,
+				FromCurrency:   "BTC",
+				ToCurrency:     "DCR",
+				InvoicedAmount: amount("0.1"),
+				OrderedAmount:  amount("181.2"),
+				DepositAddress: "bc1qdeposit",
+				ExtraID:        "TOKEN",
+			},
+		},
+	})
+}
+
+func TestFixedFloatReverse(t *testing.T) {
+	amount := instantswap.MustParseAmount
+	exchangetest.Run(t, exchangetest.Suite{
+		Exchange: LIBNAME,
+		Config:   instantswap.ExchangeConfig{ApiKey: "key", ApiSecret: "secret"},
+		Fixtures: []exchangetest.Fixture{{
+			Method: "POST", Path: "/api/v2/price", Body: `"amount":182.5,"direction":"to"`,
+			Response: `{"code":0,"msg":"OK","data":{
+				"from":{"code":"BTC","network":"BTC","coin":"BTC","amount":"0.10016621","rate":"1822","precision":8,"min":"0.0003","max":"2.4","usd":"2900","btc":"0.1"},
+				"to":{"code":"DCR","network":"DCR","coin":"DCR","amount":"182.5","rate":"0.000548","precision":8,"min":"0.5","max":"4400","usd":"2890"},
+				"errors":[]}}`,
+		}},
+		Rate: &exchangetest.RateTest{
+			Request: instantswap.ExchangeRateRequest{From: "BTC", To: "DCR", Amount: amount("182.5"), Direction: instantswap.DirectionTo},
+			Expected: instantswap.ExchangeRateInfo{
+				Min:             amount("0.0003"),
+				Max:             amount("2.4"),
+				ExchangeRate:    1822,
+				EstimatedAmount: amount("182.5"),
+				DepositAmount:   amount("0.10016621"),
+				RateType:        instantswap.RateTypeFixed,
+			},
+		},
+	})
+}
+
+func TestFixedFloatSignature(t *testing.T) {
+	server := exchangetest.NewServer(t, exchangetest.Fixture{
+		Method: "POST", Path: "/api/v2/ccies",
+		Response: `{"code":0,"msg":"OK","data":[]}`,
+	}, exchangetest.Fixture{
+		Method: "POST", Path: "/api/v2/price",
+		Response: `{"code":301,"msg":"Invalid currency: XYZ","data":null}`,
+	})
+	exchange, err := New(server.Config(instantswap.ExchangeConfig{ApiKey: "key", ApiSecret: "secret"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := exchange.GetCurrencies(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	_, err = exchange.GetExchangeRateInfo(context.Background(), instantswap.ExchangeRateRequest{From: "XYZ", To: "BTC", Amount: instantswap.MustParseAmount("1")})
+	var e *instantswap.Error
+	if !errors.As(err, &e) || e.Code != "301" || e.Kind != instantswap.KindPairUnavailable {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	mac := hmac.New(sha256.New, []byte("secret"))
+	for _, req := range server.Requests() {
+		mac.Reset()
+		mac.Write([]byte(req.Body))
+		if sign := req.Header.Get("X-API-SIGN"); sign != hex.EncodeToString(mac.Sum(nil)) {
+			t.Errorf("%s: invalid signature %q", req.Path, sign)
+		}
+		if key := req.Header.Get("X-API-KEY"); key != "key" {
+			t.Errorf("%s: invalid api key %q", req.Path, key)
+		}
+	}
+}
+
+func TestFixedFloatTickers(t *testing.T) {
+	server := exchangetest.NewServer(t, exchangetest.Fixture{
+		Method: "POST", Path: "/api/v2/price", Body: `"toCcy":"USDTTRC"`,
+		Response: `{"code":0,"msg":"OK","data":{
+			"from":{"code":"BTC","network":"BTC","coin":"BTC","amount":"0.1","rate":"29000","precision":8,"min":"0.0003","max":"2.4"},
+			"to":{"code":"USDTTRC","network":"TRX","coin":"USDT","amount":"2900","rate":"0.0000344","precision":6,"min":"10","max":"100000"},
+			"errors":[]}}`,
+	})
+	exchange, err := instantswap.NewExchangeCtx(LIBNAME, server.Config(instantswap.ExchangeConfig{ApiKey: "key", ApiSecret: "secret"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	rate, err := exchange.GetExchangeRateInfo(ctx, instantswap.ExchangeRateRequest{
+		From: "BTC", To: "USDT", ToNetwork: "TRC20", Amount: instantswap.MustParseAmount("0.1"),
+	})
+	if err != nil || rate.EstimatedAmount.String() != "2900" {
+		t.Fatalf("unexpected rate %+v, %v", rate, err)
+	}
+	// fixedfloat has no ticker for USDT on polygon.
+	_, err = exchange.GetExchangeRateInfo(ctx, instantswap.ExchangeRateRequest{
+		From: "BTC", To: "USDT", ToNetwork: instantswap.NetworkPolygon, Amount: instantswap.MustParseAmount("0.1"),
+	})
+	if !errors.Is(err, instantswap.ErrNotSupported) {
+		t.Errorf("expected ErrNotSupported, got %v", err)
+	}
+	if n := len(server.Requests()); n != 1 {
+		t.Errorf("got %d requests, expected 1", n)
+	}
+}
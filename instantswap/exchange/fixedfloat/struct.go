@@ -3,6 +3,8 @@ package fixedfloat
 import (
 	"encoding/json"
 	"fmt"
+
+	"github.com/crypto-power/instantswap/instantswap"
 )
 
 type response struct {
@@ -20,7 +22,11 @@ func parseResponseData(r []byte, obj interface{}) error {
 	if code, ok := res.Code.(float64); ok && code == 0 {
 		return json.Unmarshal(res.Data, obj)
 	}
-	return fmt.Errorf(res.Msg)
+	var code string
+	if res.Code != nil {
+		code = fmt.Sprint(res.Code)
+	}
+	return instantswap.NewError(LIBNAME, instantswap.ClassifyMessage(res.Msg), code, res.Msg)
 }
 
 type Currency struct {
@@ -38,47 +44,47 @@ type Currency struct {
 
 // {"fromCcy":"BTC","toCcy":"USDTTRC","amount":0.5,"direction":"from","type":"float"}
 type PriceReq struct {
-	FromCcy   string  `json:"fromCcy"`
-	ToCcy     string  `json:"toCcy"`
-	Amount    float64 `json:"amount"`
-	Direction string  `json:"direction"`
-	Type      string  `json:"type"`
+	FromCcy   string      `json:"fromCcy"`
+	ToCcy     string      `json:"toCcy"`
+	Amount    json.Number `json:"amount"`
+	Direction string      `json:"direction"`
+	Type      string      `json:"type"`
 }
 
 type PriceResult struct {
 	From struct {
-		Code      string  `json:"code"`
-		Network   string  `json:"network"`
-		Coin      string  `json:"coin"`
-		Amount    float64 `json:"amount,string"`
-		Rate      float64 `json:"rate,string"`
-		Precision int     `json:"precision"`
-		Min       float64 `json:"min,string"`
-		Max       float64 `json:"max,string"`
-		Usd       float64 `json:"usd,string"`
-		Btc       float64 `json:"btc,string"`
+		Code      string             `json:"code"`
+		Network   string             `json:"network"`
+		Coin      string             `json:"coin"`
+		Amount    instantswap.Amount `json:"amount"`
+		Rate      float64            `json:"rate,string"`
+		Precision int                `json:"precision"`
+		Min       instantswap.Amount `json:"min"`
+		Max       instantswap.Amount `json:"max"`
+		Usd       float64            `json:"usd,string"`
+		Btc       float64            `json:"btc,string"`
 	} `json:"from"`
 	To struct {
-		Code      string  `json:"code"`
-		Network   string  `json:"network"`
-		Coin      string  `json:"coin"`
-		Amount    float64 `json:"amount,string"`
-		Rate      float64 `json:"rate,string"`
-		Precision int     `json:"precision"`
-		Min       float64 `json:"min,string"`
-		Max       float64 `json:"max,string"`
-		Usd       float64 `json:"usd,string"`
+		Code      string             `json:"code"`
+		Network   string             `json:"network"`
+		Coin      string             `json:"coin"`
+		Amount    instantswap.Amount `json:"amount"`
+		Rate      float64            `json:"rate,string"`
+		Precision int                `json:"precision"`
+		Min       instantswap.Amount `json:"min"`
+		Max       instantswap.Amount `json:"max"`
+		Usd       float64            `json:"usd,string"`
 	} `json:"to"`
 	Errors []interface{} `json:"errors"`
 }
 
 type CreateOrderRequest struct {
-	FromCcy   string  `json:"fromCcy"`
-	ToCcy     string  `json:"toCcy"`
-	Amount    float64 `json:"amount"`
-	Direction string  `json:"direction"`
-	Type      string  `json:"type"`
-	ToAddress string  `json:"toAddress"`
+	FromCcy   string      `json:"fromCcy"`
+	ToCcy     string      `json:"toCcy"`
+	Amount    json.Number `json:"amount"`
+	Direction string      `json:"direction"`
+	Type      string      `json:"type"`
+	ToAddress string      `json:"toAddress"`
 }
 
 type OrderResponse struct {
@@ -95,18 +101,18 @@ type OrderResponse struct {
 		Left       int         `json:"left"`
 	} `json:"time"`
 	From struct {
-		Code             string      `json:"code"`
-		Coin             string      `json:"coin"`
-		Network          string      `json:"network"`
-		Name             string      `json:"name"`
-		Alias            string      `json:"alias"`
-		Amount           float64     `json:"amount,string"`
-		Address          string      `json:"address"`
-		AddressAlt       interface{} `json:"addressAlt"`
-		Tag              interface{} `json:"tag"`
-		TagName          interface{} `json:"tagName"`
-		ReqConfirmations int         `json:"reqConfirmations"`
-		MaxConfirmations int         `json:"maxConfirmations"`
+		Code             string             `json:"code"`
+		Coin             string             `json:"coin"`
+		Network          string             `json:"network"`
+		Name             string             `json:"name"`
+		Alias            string             `json:"alias"`
+		Amount           instantswap.Amount `json:"amount"`
+		Address          string             `json:"address"`
+		AddressAlt       interface{}        `json:"addressAlt"`
+		Tag              interface{}        `json:"tag"`
+		TagName          interface{}        `json:"tagName"`
+		ReqConfirmations int                `json:"reqConfirmations"`
+		MaxConfirmations int                `json:"maxConfirmations"`
 		Tx               struct {
 			Id            interface{} `json:"id"`
 			Amount        interface{} `json:"amount"`
@@ -118,15 +124,15 @@ type OrderResponse struct {
 		} `json:"tx"`
 	} `json:"from"`
 	To struct {
-		Code    string      `json:"code"`
-		Coin    string      `json:"coin"`
-		Network string      `json:"network"`
-		Name    string      `json:"name"`
-		Alias   string      `json:"alias"`
-		Amount  float64     `json:"amount,string"`
-		Address string      `json:"address"`
-		Tag     interface{} `json:"tag"`
-		TagName interface{} `json:"tagName"`
+		Code    string             `json:"code"`
+		Coin    string             `json:"coin"`
+		Network string             `json:"network"`
+		Name    string             `json:"name"`
+		Alias   string             `json:"alias"`
+		Amount  instantswap.Amount `json:"amount"`
+		Address string             `json:"address"`
+		Tag     interface{}        `json:"tag"`
+		TagName interface{}        `json:"tagName"`
 		Tx      struct {
 			Id            interface{} `json:"id"`
 			Amount        interface{} `json:"amount"`
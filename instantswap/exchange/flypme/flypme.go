@@ -1,12 +1,11 @@
 package flypme
 
 import (
+	"context"
 	"encoding/json"
-	"errors"
 	"fmt"
 	"strconv"
 	"strings"
-	"time"
 
 	"github.com/crypto-power/instantswap/instantswap"
 )
@@ -17,25 +16,39 @@ const (
 )
 
 func init() {
-	instantswap.RegisterExchange(LIBNAME, func(config instantswap.ExchangeConfig) (instantswap.IDExchange, error) {
+	instantswap.RegisterExchangeCtx(LIBNAME, func(config instantswap.ExchangeConfig) (instantswap.IDExchangeCtx, error) {
 		return New(config)
 	})
+	instantswap.RegisterCapabilities(LIBNAME, instantswap.Capabilities{
+		Cancel:        true,
+		Update:        true,
+		FloatingRate:  true,
+		Limits:        true,
+		RefundAddress: true,
+	})
+	instantswap.RegisterRateLimit(LIBNAME, instantswap.RateLimit{Rate: 1, Burst: 3})
+	instantswap.RegisterAssetMapping(LIBNAME, instantswap.AssetMapping{
+		Tickers: map[instantswap.Asset]string{
+			{Symbol: "USDT", Network: instantswap.NetworkEthereum}: "USDT",
+		},
+	})
 }
 
 // New return a FlypMe struct.
 func New(conf instantswap.ExchangeConfig) (*FlypMe, error) {
 	client := instantswap.NewClient(LIBNAME, &conf)
 	return &FlypMe{
-		client: client,
-		conf:   &conf,
+		client:  client,
+		conf:    &conf,
+		apiBase: conf.BaseURLOrDefault(API_BASE),
 	}, nil
 }
 
 // FlypMe represent a flyp.me exchange client.
 type FlypMe struct {
-	client *instantswap.Client
-	conf   *instantswap.ExchangeConfig
-	instantswap.IDExchange
+	client  *instantswap.Client
+	conf    *instantswap.ExchangeConfig
+	apiBase string
 }
 
 // SetDebug set enable/disable http request/response dump.
@@ -57,14 +70,14 @@ func handleErr(r json.RawMessage) (err error) {
 				errorStr += v[i] + ", "
 			}
 		}
-		err = errors.New(errorStr)
+		err = instantswap.NewError(LIBNAME, instantswap.ClassifyMessage(errorStr), "", errorStr)
 		return
 	}
 	return nil
 }
 
-func (c *FlypMe) GetCurrencies() (currencies []instantswap.Currency, err error) {
-	r, err := c.client.Do(API_BASE, "GET", "currencies", "", false)
+func (c *FlypMe) GetCurrencies(ctx context.Context) (currencies []instantswap.Currency, err error) {
+	r, err := c.client.Do(ctx, c.apiBase, "GET", "currencies", "", false)
 	if err != nil {
 		return nil, err
 	}
@@ -84,90 +97,69 @@ func (c *FlypMe) GetCurrencies() (currencies []instantswap.Currency, err error)
 	return currencies, nil
 }
 
-func (c *FlypMe) GetCurrenciesToPair(from string) (currencies []instantswap.Currency, err error) {
-	r, err := c.client.Do(API_BASE, "GET", "currencies", "", false)
-	if err != nil {
-		return nil, err
-	}
-	var cnCurrencies = make(map[string]Currency)
-	err = json.Unmarshal(r, &cnCurrencies)
-	if err != nil {
-		return nil, err
-	}
-	for _, currency := range cnCurrencies {
-		if strings.ToLower(from) == strings.ToLower(currency.Code) {
-			continue
-		}
-		currencies = append(currencies, instantswap.Currency{
-			Name:     currency.Name,
-			Symbol:   strings.ToLower(currency.Code),
-			IsFiat:   false,
-			IsStable: false,
-		})
-	}
-	return currencies, nil
+func (c *FlypMe) GetCurrenciesToPair(ctx context.Context, from string) (currencies []instantswap.Currency, err error) {
+	return currencies, instantswap.ErrNotSupported
 }
 
 // GetExchangeRateInfo get estimate on the amount for the exchange.
-func (c *FlypMe) GetExchangeRateInfo(vars instantswap.ExchangeRateRequest) (res instantswap.ExchangeRateInfo, err error) {
-	limits, err := c.QueryLimits(vars.From, vars.To)
+func (c *FlypMe) GetExchangeRateInfo(ctx context.Context, vars instantswap.ExchangeRateRequest) (res instantswap.ExchangeRateInfo, err error) {
+	limits, err := c.QueryLimits(ctx, vars.From, vars.To)
 	if err != nil {
-		err = errors.New(LIBNAME + ":error: " + err.Error())
+		err = instantswap.WrapError(LIBNAME, "GetExchangeRateInfo", err)
 		return
 	}
-	time.Sleep(time.Second * 1)
-	exchangeRates, err := c.QueryRates(nil)
+	exchangeRates, err := c.QueryRates(ctx, nil)
 	if err != nil {
-		err = errors.New(LIBNAME + ":error: " + err.Error())
+		err = instantswap.WrapError(LIBNAME, "GetExchangeRateInfo", err)
 		return
 	}
 	var rate instantswap.QueryRate
-	var pair = fmt.Sprintf("%s-%s", vars.From, vars.To)
+	var pair = strings.ToUpper(fmt.Sprintf("%s-%s", vars.From, vars.To))
 	for _, v := range exchangeRates {
 		if v.Name == pair {
 			rate = v
 		}
 	}
 	if rate.Name == "" || rate.Value == "" {
-		err = errors.New(LIBNAME + ":error: rate not found for " + pair + " pair")
+		err = instantswap.NewError(LIBNAME, instantswap.KindPairUnavailable, "", "rate not found for "+pair+" pair")
 		return
 	}
 	exchangeRate, err := strconv.ParseFloat(rate.Value, 64)
 	if err != nil {
-		err = errors.New(LIBNAME + ":error: " + err.Error())
+		err = instantswap.WrapError(LIBNAME, "GetExchangeRateInfo", err)
 		return
 	}
 
+	rateAmount := instantswap.AmountFromString(rate.Value)
+
 	res = instantswap.ExchangeRateInfo{
 		ExchangeRate:    exchangeRate,
-		Min:             limits.Min / exchangeRate,
-		Max:             limits.Max / exchangeRate,
-		EstimatedAmount: vars.Amount * exchangeRate,
+		Min:             limits.Min.Quo(rateAmount, 8),
+		Max:             limits.Max.Quo(rateAmount, 8),
+		EstimatedAmount: vars.Amount.Mul(rateAmount),
 	}
 
 	return
 }
 
 // EstimateAmount get estimate on the amount for the exchange.
-func (c *FlypMe) EstimateAmount(vars interface{}) (res instantswap.EstimateAmount, err error) {
-	//vars not used here
-	err = errors.New(LIBNAME + ":error: not available for this exchange")
-	return
+func (c *FlypMe) EstimateAmount(ctx context.Context, vars interface{}) (res instantswap.EstimateAmount, err error) {
+	return res, instantswap.ErrNotSupported
 }
 
 // QueryRates (list of pairs LTC-BTC, BTC-LTC, etc).
-func (c *FlypMe) QueryRates(vars interface{}) (res []instantswap.QueryRate, err error) {
+func (c *FlypMe) QueryRates(ctx context.Context, vars interface{}) (res []instantswap.QueryRate, err error) {
 	//vars not used here
-	r, err := c.client.Do(API_BASE, "GET", "data/exchange_rates", "", false)
+	r, err := c.client.Do(ctx, c.apiBase, "GET", "data/exchange_rates", "", false)
 	if err != nil {
-		err = errors.New(LIBNAME + ":error: " + err.Error())
+		err = instantswap.WrapError(LIBNAME, "QueryRates", err)
 		return
 	}
 
 	tmpArr := []instantswap.QueryRate{}
 	var rateList map[string]string
 	if err = json.Unmarshal(r, &rateList); err != nil {
-		err = errors.New(LIBNAME + ":error: " + err.Error())
+		err = instantswap.WrapError(LIBNAME, "QueryRates", err)
 		return
 	}
 	for k, v := range rateList {
@@ -180,18 +172,18 @@ func (c *FlypMe) QueryRates(vars interface{}) (res []instantswap.QueryRate, err
 }
 
 // QueryActiveCurrencies returns Flypme's supported currencies
-func (c *FlypMe) QueryActiveCurrencies(vars interface{}) (res []instantswap.ActiveCurr, err error) {
+func (c *FlypMe) QueryActiveCurrencies(ctx context.Context, vars interface{}) (res []instantswap.ActiveCurr, err error) {
 	//vars not used here
-	r, err := c.client.Do(API_BASE, "GET", "currencies", "", false)
+	r, err := c.client.Do(ctx, c.apiBase, "GET", "currencies", "", false)
 	if err != nil {
-		err = errors.New(LIBNAME + ":error: " + err.Error())
+		err = instantswap.WrapError(LIBNAME, "QueryActiveCurrencies", err)
 		return
 	}
 
 	tmpArr := []instantswap.ActiveCurr{}
 	var v interface{}
 	if err = json.Unmarshal(r, &v); err != nil {
-		err = errors.New(LIBNAME + ":error: " + err.Error())
+		err = instantswap.WrapError(LIBNAME, "QueryActiveCurrencies", err)
 		return
 	}
 	data := v.(map[string]interface{})
@@ -200,14 +192,14 @@ func (c *FlypMe) QueryActiveCurrencies(vars interface{}) (res []instantswap.Acti
 		curr := (v).(map[string]interface{})
 		currMarsh, err := json.Marshal(curr)
 		if err != nil {
-			err = errors.New(LIBNAME + ":error: " + err.Error())
+			err = instantswap.WrapError(LIBNAME, "QueryActiveCurrencies", err)
 			return tmpArr, err
 		}
 
 		var activeCurr instantswap.ActiveCurr
 		err = json.Unmarshal(currMarsh, &activeCurr)
 		if err != nil {
-			err = errors.New(LIBNAME + ":error: " + err.Error())
+			err = instantswap.WrapError(LIBNAME, "QueryActiveCurrencies", err)
 			return tmpArr, err
 		}
 
@@ -218,16 +210,16 @@ func (c *FlypMe) QueryActiveCurrencies(vars interface{}) (res []instantswap.Acti
 }
 
 // QueryLimits Get Exchange Rates (from, to).
-func (c *FlypMe) QueryLimits(fromCurr, toCurr string) (res instantswap.QueryLimits, err error) {
+func (c *FlypMe) QueryLimits(ctx context.Context, fromCurr, toCurr string) (res instantswap.QueryLimits, err error) {
 	// Get max and min limits in {to_currency}.
-	r, err := c.client.Do(API_BASE, "GET", "order/limits/"+fromCurr+"/"+toCurr, "", false)
+	r, err := c.client.Do(ctx, c.apiBase, "GET", "order/limits/"+fromCurr+"/"+toCurr, "", false)
 	if err != nil {
-		err = errors.New(LIBNAME + ":error: " + err.Error())
+		err = instantswap.WrapError(LIBNAME, "QueryLimits", err)
 		return
 	}
 	var tmp QueryLimits
 	if err = json.Unmarshal(r, &tmp); err != nil {
-		err = errors.New(LIBNAME + ":error: " + err.Error())
+		err = instantswap.WrapError(LIBNAME, "QueryLimits", err)
 		return
 	}
 
@@ -238,36 +230,36 @@ func (c *FlypMe) QueryLimits(fromCurr, toCurr string) (res instantswap.QueryLimi
 	return
 }
 
-func (c *FlypMe) CreateOrder(orderInfo instantswap.CreateOrder) (res instantswap.CreateResultInfo, err error) {
+func (c *FlypMe) CreateOrder(ctx context.Context, orderInfo instantswap.CreateOrder) (res instantswap.CreateResultInfo, err error) {
 	newOrder := CreateOrder{
 		Order: CreateOrderInfo{
 			FromCurrency:   orderInfo.FromCurrency,
 			ToCurrency:     orderInfo.ToCurrency,
-			InvoicedAmount: strconv.FormatFloat(orderInfo.InvoicedAmount, 'f', 8, 64), //amount in "from" currency
-			OrderedAmount:  "",                                                        //amount in "to" currency (should be set to 0 for changenow, )
+			InvoicedAmount: orderInfo.InvoicedAmount.TruncateFor(orderInfo.FromCurrency, orderInfo.FromNetwork).String(), //amount in "from" currency
+			OrderedAmount:  "",                                                                                           //amount in "to" currency (should be set to 0 for changenow, )
 			Destination:    orderInfo.Destination,
 			RefundAddress:  orderInfo.RefundAddress,
 		},
 	}
 	payload, err := json.Marshal(newOrder)
 	if err != nil {
-		err = errors.New(LIBNAME + ":error: " + err.Error())
+		err = instantswap.WrapError(LIBNAME, "CreateOrder", err)
 		return
 	}
-	r, err := c.client.Do(API_BASE, "POST", "order/new", string(payload), false)
+	r, err := c.client.Do(ctx, c.apiBase, "POST", "order/new", string(payload), false)
 	if err != nil {
-		err = errors.New(LIBNAME + ":error: " + err.Error())
+		err = instantswap.WrapError(LIBNAME, "CreateOrder", err)
 		return
 	}
 	var tmp CreateResult
 	if err = json.Unmarshal(r, &tmp); err != nil {
-		err = errors.New(LIBNAME + ":error: " + err.Error())
+		err = instantswap.WrapError(LIBNAME, "CreateOrder", err)
 		return
 	}
 	if len(tmp.Errors) > 0 {
 		err = handleErr(tmp.Errors)
 		if err != nil {
-			err = errors.New(LIBNAME + ":error: " + err.Error())
+			err = instantswap.WrapError(LIBNAME, "CreateOrder", err)
 			return
 		}
 	}
@@ -276,24 +268,24 @@ func (c *FlypMe) CreateOrder(orderInfo instantswap.CreateOrder) (res instantswap
 	}
 	acceptPayload, err := json.Marshal(acceptOrder)
 	if err != nil {
-		err = errors.New(LIBNAME + ":error: " + err.Error())
+		err = instantswap.WrapError(LIBNAME, "CreateOrder", err)
 		return
 	}
-	acceptRes, err := c.client.Do(API_BASE, "POST", "order/accept", string(acceptPayload), false)
+	acceptRes, err := c.client.Do(ctx, c.apiBase, "POST", "order/accept", string(acceptPayload), false)
 	if err != nil {
-		err = errors.New(LIBNAME + ":error: " + err.Error())
+		err = instantswap.WrapError(LIBNAME, "CreateOrder", err)
 		return
 	}
 	var tmpAccept AcceptOrderResult
 	if err = json.Unmarshal(acceptRes, &tmpAccept); err != nil {
-		err = errors.New(LIBNAME + ":error: " + err.Error())
+		err = instantswap.WrapError(LIBNAME, "CreateOrder", err)
 		return
 	}
 
-	if len(tmp.Errors) > 0 {
-		err = handleErr(tmp.Errors)
+	if len(tmpAccept.Errors) > 0 {
+		err = handleErr(tmpAccept.Errors)
 		if err != nil {
-			err = errors.New(LIBNAME + ":error: " + err.Error())
+			err = instantswap.WrapError(LIBNAME, "CreateOrder", err)
 			return
 		}
 	}
@@ -315,28 +307,28 @@ func (c *FlypMe) CreateOrder(orderInfo instantswap.CreateOrder) (res instantswap
 }
 
 // UpdateOrder update the information of an order.
-func (c *FlypMe) UpdateOrder(vars interface{}) (res instantswap.UpdateOrderResultInfo, err error) {
+func (c *FlypMe) UpdateOrder(ctx context.Context, vars interface{}) (res instantswap.UpdateOrderResultInfo, err error) {
 	orderInfo := vars.(UpdateOrder)
 	payload, err := json.Marshal(orderInfo)
 	if err != nil {
-		err = errors.New(LIBNAME + ":error: " + err.Error())
+		err = instantswap.WrapError(LIBNAME, "UpdateOrder", err)
 		return
 	}
-	r, err := c.client.Do(API_BASE, "POST", "order/update", string(payload), false)
+	r, err := c.client.Do(ctx, c.apiBase, "POST", "order/update", string(payload), false)
 	if err != nil {
-		err = errors.New(LIBNAME + ":error: " + err.Error())
+		err = instantswap.WrapError(LIBNAME, "UpdateOrder", err)
 		return
 	}
 	var tmp UpdateOrderResult
 	if err = json.Unmarshal(r, &tmp); err != nil {
-		err = errors.New(LIBNAME + ":error: " + err.Error())
+		err = instantswap.WrapError(LIBNAME, "UpdateOrder", err)
 		return
 	}
 
 	if len(tmp.Errors) > 0 {
 		err = handleErr(tmp.Errors)
 		if err != nil {
-			err = errors.New(LIBNAME + ":error: " + err.Error())
+			err = instantswap.WrapError(LIBNAME, "UpdateOrder", err)
 			return
 		}
 	}
@@ -356,29 +348,29 @@ func (c *FlypMe) UpdateOrder(vars interface{}) (res instantswap.UpdateOrderResul
 }
 
 // CancelOrder will delete an order based on its id.
-func (c *FlypMe) CancelOrder(orderId string) (res string, err error) {
+func (c *FlypMe) CancelOrder(ctx context.Context, orderId string) (res string, err error) {
 	cancelOrder := UUID{
 		UUID: orderId,
 	}
 	payload, err := json.Marshal(cancelOrder)
 	if err != nil {
-		err = errors.New(LIBNAME + ":error: " + err.Error())
+		err = instantswap.WrapError(LIBNAME, "CancelOrder", err)
 		return
 	}
-	r, err := c.client.Do(API_BASE, "POST", "order/cancel", string(payload), false)
+	r, err := c.client.Do(ctx, c.apiBase, "POST", "order/cancel", string(payload), false)
 	if err != nil {
-		err = errors.New(LIBNAME + ":error: " + err.Error())
+		err = instantswap.WrapError(LIBNAME, "CancelOrder", err)
 		return
 	}
 	var result jsonResponse
 	if err = json.Unmarshal(r, &result); err != nil {
-		err = errors.New(LIBNAME + ":error: " + err.Error())
+		err = instantswap.WrapError(LIBNAME, "CancelOrder", err)
 		return
 	}
 	if len(result.Errors) > 0 {
 		err = handleErr(result.Errors)
 		if err != nil {
-			err = errors.New(LIBNAME + ":error: " + err.Error())
+			err = instantswap.WrapError(LIBNAME, "CancelOrder", err)
 			return
 		}
 	}
@@ -389,29 +381,29 @@ func (c *FlypMe) CancelOrder(orderId string) (res string, err error) {
 
 // OrderInfo accepts string of orderID value and return
 // its information
-func (c *FlypMe) OrderInfo(orderID string, extraIds ...string) (res instantswap.OrderInfoResult, err error) {
+func (c *FlypMe) OrderInfo(ctx context.Context, orderID string, extraIds ...string) (res instantswap.OrderInfoResult, err error) {
 	getOrderInfo := UUID{
 		UUID: orderID,
 	}
 	payload, err := json.Marshal(getOrderInfo)
 	if err != nil {
-		err = errors.New(LIBNAME + ":error: " + err.Error())
+		err = instantswap.WrapError(LIBNAME, "OrderInfo", err)
 		return
 	}
-	r, err := c.client.Do(API_BASE, "POST", "order/info", string(payload), false)
+	r, err := c.client.Do(instantswap.Idempotent(ctx), c.apiBase, "POST", "order/info", string(payload), false)
 	if err != nil {
 		return
 	}
 	var tmp OrderInfoResult
 	if err = json.Unmarshal(r, &tmp); err != nil {
-		err = errors.New(LIBNAME + ":error: " + err.Error())
+		err = instantswap.WrapError(LIBNAME, "OrderInfo", err)
 		return
 	}
 
 	if len(tmp.Errors) > 0 {
 		err = handleErr(tmp.Errors)
 		if err != nil {
-			err = errors.New(LIBNAME + ":error: " + err.Error())
+			err = instantswap.WrapError(LIBNAME, "OrderInfo", err)
 			return
 		}
 	}
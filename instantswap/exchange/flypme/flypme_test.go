@@ -0,0 +1,143 @@
+package flypme
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/crypto-power/instantswap/instantswap"
+	"github.com/crypto-power/instantswap/instantswap/exchangetest"
+)
+
+const orderFixture = `{"order":{"uuid":"fp-5a1f","destination":"DsUZxxoHJSty8DCfwfartwTYbuhmVct7tJu","exchange_rate":"1810.0","from_currency":"BTC",
+	"to_currency":"DCR","invoiced_amount":"0.1","ordered_amount":"181","charged_fee":"0.05"},"expires":1200}`
+
+func TestFlypMe(t *testing.T) {
+	amount := instantswap.MustParseAmount
+	exchangetest.Run(t, exchangetest.Suite{
+		Exchange: LIBNAME,
+		Fixtures: []exchangetest.Fixture{{
+			Method: "GET", Path: "/api/v1/currencies",
+			Response: `{"BTC":{"code":"BTC","precision":8,"name":"Bitcoin","exchange":true,"send":true},
+				"DCR":{"code":"DCR","precision":8,"name":"Decred","exchange":true,"send":true},
+				"LTC":{"code":"LTC","precision":8,"name":"Litecoin","exchange":true,"send":true}}`,
+		}, {
+			Method: "GET", Path: "/api/v1/order/limits/BTC/DCR",
+			Response: `{"min":"1.81","max":"3620"}`,
+		}, {
+			Method: "GET", Path: "/api/v1/data/exchange_rates",
+			Response: `{"BTC-DCR":"1810.0","DCR-BTC":"0.00054","BTC-LTC":"350.2"}`,
+		}, {
+			Method: "POST", Path: "/api/v1/order/new", Body: `"invoiced_amount":"0.1"`,
+			Response: orderFixture,
+		}, {
+			Method: "POST", Path: "/api/v1/order/accept", Body: `"uuid":"fp-5a1f"`,
+			Response: `{"deposit_address":"bc1qdeposit","expires":1199,"order":{"uuid":"fp-5a1f"}}`,
+		}, {
+			Method: "POST", Path: "/api/v1/order/info", Body: `"uuid":"fp-5a1f"`,
+			Response: `{"order":{"uuid":"fp-5a1f","ordered_amount":"181"},"deposit_address":"bc1qdeposit",
+				"txid":"dcrtxhash","expires":0,"status":"EXECUTED","confirmations":"2"}`,
+		}},
+		Currencies: []string{"BTC", "DCR", "LTC"},
+		Limits: &exchangetest.LimitsTest{
+			From: "btc", To: "dcr",
+			Expected: instantswap.QueryLimits{Min: amount("1.81"), Max: amount("3620")},
+		},
+		Rate: &exchangetest.RateTest{
+			Request: instantswap.ExchangeRateRequest{From: "btc", To: "dcr", Amount: amount("0.1")},
+			Expected: instantswap.ExchangeRateInfo{
+				Min:             amount("0.001"),
+				Max:             amount("2"),
+				ExchangeRate:    1810,
+				EstimatedAmount: amount("181"),
+				DepositAmount:   amount("0.1"),
+				RateType:        instantswap.RateTypeFloat,
+			},
+		},
+		Order: &exchangetest.OrderTest{
+			Request: instantswap.CreateOrder{
+				FromCurrency:   "BTC",
+				ToCurrency:     "DCR",
+				Destination:    "DsUZxxoHJSty8DCfwfartwTYbuhmVct7tJu",
+				RefundAddress:  "bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t4",
+				InvoicedAmount: amount("0.1"),
+			},
+			Expected: instantswap.CreateResultInfo{
+				UUID:           "fp-5a1f",
+				Destination:    "DsUZxxoHJSty8DCfwfartwTYbuhmVct7tJu",
+				ExchangeRate:   1810,
+				FromCurrency:   "BTC",
+				ToCurrency:     "DCR",
+				ChargedFee:     amount("0.05"),
+				InvoicedAmount: amount("0.1"),
+				OrderedAmount:  amount("181"),
+				DepositAddress: "bc1qdeposit",
+				Expires:        1199,
+			},
+		},
+		Info: &exchangetest.InfoTest{
+			OrderID: "fp-5a1f",
+			Expected: instantswap.OrderInfoResult{
+				ReceiveAmount:  amount("181"),
+				Confirmations:  "2",
+				TxID:           "dcrtxhash",
+				Status:         "EXECUTED",
+				InternalStatus: instantswap.OrderStatusCompleted,
+			},
+		},
+		StatusFunc: GetLocalStatus,
+		Statuses: map[string]instantswap.Status{
+			"WAITING_FOR_DEPOSIT": instantswap.OrderStatusWaitingForDeposit,
+			"DEPOSIT_RECEIVED":    instantswap.OrderStatusDepositReceived,
+			"DEPOSIT_CONFIRMED":   instantswap.OrderStatusDepositConfirmed,
+			"EXECUTED":            instantswap.OrderStatusCompleted,
+			"REFUNDED":            instantswap.OrderStatusRefunded,
+			"CANCELED":            instantswap.OrderStatusCanceled,
+			"EXPIRED":             instantswap.OrderStatusExpired,
+			"DRAFT":               instantswap.OrderStatusUnknown,
+		},
+	})
+}
+
+func TestFlypMePendingTxID(t *testing.T) {
+	server := exchangetest.NewServer(t, exchangetest.Fixture{
+		Method: "POST", Path: "/api/v1/order/info", Body: `"uuid":"fp-pending"`,
+		Response: `{"order":{"uuid":"fp-pending","ordered_amount":"181"},
+			"txid":"pending_b1fdc5a8-e470-63c1-a034-eddf78c8fdf6","status":"EXECUTED"}`,
+	})
+	exchange, err := New(server.Config(instantswap.ExchangeConfig{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	info, err := exchange.OrderInfo(context.Background(), "fp-pending")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.TxID != "" || info.InternalStatus != instantswap.OrderStatusExchanging {
+		t.Errorf("unexpected order info: %+v", info)
+	}
+}
+
+func TestFlypMeAcceptError(t *testing.T) {
+	server := exchangetest.NewServer(t, exchangetest.Fixture{
+		Method: "POST", Path: "/api/v1/order/new",
+		Response: orderFixture,
+	}, exchangetest.Fixture{
+		Method: "POST", Path: "/api/v1/order/accept",
+		Response: `{"errors":{"order":["Order has expired"]}}`,
+	})
+	exchange, err := New(server.Config(instantswap.ExchangeConfig{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = exchange.CreateOrder(context.Background(), instantswap.CreateOrder{
+		FromCurrency:   "BTC",
+		ToCurrency:     "DCR",
+		Destination:    "DsUZxxoHJSty8DCfwfartwTYbuhmVct7tJu",
+		InvoicedAmount: instantswap.MustParseAmount("0.1"),
+	})
+	var e *instantswap.Error
+	if !errors.As(err, &e) || e.Op != "CreateOrder" {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
@@ -1,11 +1,11 @@
 package godex
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
 	"strings"
-	"time"
 
 	"github.com/crypto-power/instantswap/instantswap"
 	"github.com/crypto-power/instantswap/instantswap/utils"
@@ -17,20 +17,35 @@ const (
 )
 
 func init() {
-	instantswap.RegisterExchange(LIBNAME, func(config instantswap.ExchangeConfig) (instantswap.IDExchange, error) {
+	instantswap.RegisterExchangeCtx(LIBNAME, func(config instantswap.ExchangeConfig) (instantswap.IDExchangeCtx, error) {
 		return New(config)
 	})
+	instantswap.RegisterCapabilities(LIBNAME, instantswap.Capabilities{
+		FloatingRate:  true,
+		Networks:      true,
+		RefundAddress: true,
+		APIKey:        true,
+	})
+	instantswap.RegisterRateLimit(LIBNAME, instantswap.RateLimit{Rate: 1, Burst: 3})
+	instantswap.RegisterAssetMapping(LIBNAME, instantswap.AssetMapping{
+		CoinNetworks: true,
+		Networks: map[string]string{
+			instantswap.NetworkBSC:      "BSC",
+			instantswap.NetworkArbitrum: "ARBITRUM",
+			instantswap.NetworkOptimism: "OPTIMISM",
+		},
+	})
 }
 
 type GoDEX struct {
-	conf   *instantswap.ExchangeConfig
-	client *instantswap.Client
-	instantswap.IDExchange
+	conf    *instantswap.ExchangeConfig
+	client  *instantswap.Client
+	apiBase string
 }
 
 func New(conf instantswap.ExchangeConfig) (*GoDEX, error) {
 	if conf.ApiKey == "" {
-		return nil, fmt.Errorf("%s:error: APIKEY is blank", LIBNAME)
+		return nil, instantswap.NewError(LIBNAME, instantswap.KindUnauthorized, "", "APIKEY is blank")
 	}
 	client := instantswap.NewClient(LIBNAME, &conf, func(r *http.Request, body string) error {
 		if r.Method == http.MethodPost || r.Method == http.MethodPut {
@@ -38,7 +53,7 @@ func New(conf instantswap.ExchangeConfig) (*GoDEX, error) {
 		}
 		return nil
 	})
-	return &GoDEX{client: client, conf: &conf}, nil
+	return &GoDEX{client: client, conf: &conf, apiBase: conf.BaseURLOrDefault(API_BASE)}, nil
 }
 
 // SetDebug set enable/disable http request/response dump
@@ -46,9 +61,9 @@ func (c *GoDEX) SetDebug(enable bool) {
 	c.conf.Debug = enable
 }
 
-func (c *GoDEX) GetCurrencies() (currencies []instantswap.Currency, err error) {
+func (c *GoDEX) GetCurrencies(ctx context.Context) (currencies []instantswap.Currency, err error) {
 	var r []byte
-	r, err = c.client.Do(API_BASE, "GET", "coins", "", false)
+	r, err = c.client.Do(ctx, c.apiBase, "GET", "coins", "", false)
 	if err != nil {
 		return nil, err
 	}
@@ -57,36 +72,8 @@ func (c *GoDEX) GetCurrencies() (currencies []instantswap.Currency, err error) {
 	if err != nil {
 		return nil, err
 	}
-	currencies = make([]instantswap.Currency, len(fmCurrencies))
-	for i, currency := range fmCurrencies {
-		if currency.Disabled == 0 {
-			currencies[i] = instantswap.Currency{
-				Name:     currency.Name,
-				Symbol:   strings.ToLower(currency.Code),
-				IsFiat:   false,
-				IsStable: false,
-			}
-		}
-	}
-	return
-}
-
-func (c *GoDEX) GetCurrenciesToPair(from string) (currencies []instantswap.Currency, err error) {
-	var r []byte
-	r, err = c.client.Do(API_BASE, "GET", "coins", "", false)
-	if err != nil {
-		return nil, err
-	}
-	var fmCurrencies []Currency
-	err = json.Unmarshal(r, &fmCurrencies)
-	if err != nil {
-		return nil, err
-	}
-	currencies = []instantswap.Currency{}
+	currencies = make([]instantswap.Currency, 0, len(fmCurrencies))
 	for _, currency := range fmCurrencies {
-		if strings.ToLower(currency.Code) == strings.ToLower(from) {
-			continue
-		}
 		if currency.Disabled == 0 {
 			currencies = append(currencies, instantswap.Currency{
 				Name:     currency.Name,
@@ -99,21 +86,27 @@ func (c *GoDEX) GetCurrenciesToPair(from string) (currencies []instantswap.Curre
 	return
 }
 
-func (c *GoDEX) queryRate(req InfoRequest) ([]byte, error) {
+func (c *GoDEX) GetCurrenciesToPair(ctx context.Context, from string) (currencies []instantswap.Currency, err error) {
+	return currencies, instantswap.ErrNotSupported
+}
+
+func (c *GoDEX) queryRate(ctx context.Context, req InfoRequest) ([]byte, error) {
 	body, err := json.Marshal(req)
 	if err != nil {
 		return nil, err
 	}
-	return c.client.Do(API_BASE, "POST", "info", string(body), false)
+	return c.client.Do(instantswap.Idempotent(ctx), c.apiBase, "POST", "info", string(body), false)
 }
 
-func (c *GoDEX) GetExchangeRateInfo(vars instantswap.ExchangeRateRequest) (res instantswap.ExchangeRateInfo, err error) {
+func (c *GoDEX) GetExchangeRateInfo(ctx context.Context, vars instantswap.ExchangeRateRequest) (res instantswap.ExchangeRateInfo, err error) {
 	var req = InfoRequest{
-		From:   strings.ToUpper(vars.From),
-		To:     strings.ToUpper(vars.To),
-		Amount: vars.Amount,
+		From:        strings.ToUpper(vars.From),
+		To:          strings.ToUpper(vars.To),
+		Amount:      json.Number(vars.Amount.TruncateFor(vars.From, vars.FromNetwork).String()),
+		NetworkFrom: vars.FromNetwork,
+		NetworkTo:   vars.ToNetwork,
 	}
-	r, err := c.queryRate(req)
+	r, err := c.queryRate(ctx, req)
 	if err != nil {
 		return res, err
 	}
@@ -122,12 +115,11 @@ func (c *GoDEX) GetExchangeRateInfo(vars instantswap.ExchangeRateRequest) (res i
 	if err != nil {
 		return res, err
 	}
-	var minAmount, _ = info.MinAmount.Float64()
-	var estimatedAmount, _ = info.Amount.Float64()
-	if minAmount > vars.Amount {
-		req.Amount = minAmount
-		time.Sleep(time.Second)
-		r, err := c.queryRate(req)
+	var minAmount = instantswap.AmountFromString(info.MinAmount.String())
+	var estimatedAmount = instantswap.AmountFromString(info.Amount.String())
+	if minAmount.Cmp(vars.Amount) > 0 {
+		req.Amount = json.Number(minAmount.String())
+		r, err := c.queryRate(ctx, req)
 		if err != nil {
 			return res, err
 		}
@@ -135,49 +127,50 @@ func (c *GoDEX) GetExchangeRateInfo(vars instantswap.ExchangeRateRequest) (res i
 		if err != nil {
 			return res, err
 		}
-		estimatedAmount = 0
+		estimatedAmount = instantswap.Amount{}
 	}
 	return instantswap.ExchangeRateInfo{
-		Min:             utils.StrToFloat(info.MinAmount.String()),
-		Max:             utils.StrToFloat(info.MaxAmount.String()),
+		Min:             instantswap.AmountFromString(info.MinAmount.String()),
+		Max:             instantswap.AmountFromString(info.MaxAmount.String()),
 		ExchangeRate:    utils.StrToFloat(info.Rate.String()),
 		EstimatedAmount: estimatedAmount,
-		MaxOrder:        0,
+		MaxOrder:        instantswap.Amount{},
 		Signature:       "",
+		Fees:            instantswap.Fees{NetworkFee: instantswap.AmountFromString(info.Fee.String())},
 	}, err
 }
 
-func (c *GoDEX) QueryRates(vars interface{}) (res []instantswap.QueryRate, err error) {
-	return res, fmt.Errorf("not supported")
+func (c *GoDEX) QueryRates(ctx context.Context, vars interface{}) (res []instantswap.QueryRate, err error) {
+	return res, instantswap.ErrNotSupported
 }
 
-func (c *GoDEX) QueryActiveCurrencies(vars interface{}) (res []instantswap.ActiveCurr, err error) {
-	return
+func (c *GoDEX) QueryActiveCurrencies(ctx context.Context, vars interface{}) (res []instantswap.ActiveCurr, err error) {
+	return res, instantswap.ErrNotSupported
 }
 
-func (c *GoDEX) QueryLimits(fromCurr, toCurr string) (res instantswap.QueryLimits, err error) {
-	return
+func (c *GoDEX) QueryLimits(ctx context.Context, fromCurr, toCurr string) (res instantswap.QueryLimits, err error) {
+	return res, instantswap.ErrNotSupported
 }
 
-func (c *GoDEX) CreateOrder(vars instantswap.CreateOrder) (res instantswap.CreateResultInfo, err error) {
+func (c *GoDEX) CreateOrder(ctx context.Context, vars instantswap.CreateOrder) (res instantswap.CreateResultInfo, err error) {
 	var txReq = TransactionReq{
 		CoinFrom:          vars.FromCurrency,
 		CoinTo:            vars.ToCurrency,
-		DepositAmount:     vars.InvoicedAmount,
+		DepositAmount:     json.Number(vars.InvoicedAmount.TruncateFor(vars.FromCurrency, vars.FromNetwork).String()),
 		Withdrawal:        vars.Destination,
 		WithdrawalExtraId: "",
 		Return:            vars.RefundAddress,
 		ReturnExtraId:     vars.RefundExtraID,
 		AffiliateId:       c.conf.AffiliateId,
-		CoinToNetwork:     "",
-		CoinFromNetwork:   "",
+		CoinToNetwork:     vars.ToNetwork,
+		CoinFromNetwork:   vars.FromNetwork,
 	}
 	body, err := json.Marshal(txReq)
 	if err != nil {
 		return res, err
 	}
 	var r []byte
-	r, err = c.client.Do(API_BASE, "POST", "transaction", string(body), false)
+	r, err = c.client.Do(ctx, c.apiBase, "POST", "transaction", string(body), false)
 	if err != nil {
 		return res, err
 	}
@@ -187,12 +180,12 @@ func (c *GoDEX) CreateOrder(vars instantswap.CreateOrder) (res instantswap.Creat
 		return res, err
 	}
 	return instantswap.CreateResultInfo{
-		ChargedFee:     utils.StrToFloat(tx.Fee.String()),
+		ChargedFee:     instantswap.AmountFromString(tx.Fee.String()),
 		Destination:    tx.Withdrawal,
 		ExchangeRate:   utils.StrToFloat(tx.Rate.String()),
 		FromCurrency:   tx.CoinFrom,
-		InvoicedAmount: utils.StrToFloat(tx.DepositAmount.String()),
-		OrderedAmount:  utils.StrToFloat(tx.WithdrawalAmount.String()),
+		InvoicedAmount: instantswap.AmountFromString(tx.DepositAmount.String()),
+		OrderedAmount:  instantswap.AmountFromString(tx.WithdrawalAmount.String()),
 		ToCurrency:     tx.CoinTo,
 		UUID:           tx.TransactionId,
 		DepositAddress: tx.Deposit,
@@ -203,17 +196,17 @@ func (c *GoDEX) CreateOrder(vars instantswap.CreateOrder) (res instantswap.Creat
 }
 
 // UpdateOrder accepts orderID value and more if needed per lib.
-func (c *GoDEX) UpdateOrder(vars interface{}) (res instantswap.UpdateOrderResultInfo, err error) {
-	return
+func (c *GoDEX) UpdateOrder(ctx context.Context, vars interface{}) (res instantswap.UpdateOrderResultInfo, err error) {
+	return res, instantswap.ErrNotSupported
 }
-func (c *GoDEX) CancelOrder(orderID string) (res string, err error) {
-	return
+func (c *GoDEX) CancelOrder(ctx context.Context, orderID string) (res string, err error) {
+	return res, instantswap.ErrNotSupported
 }
 
 // OrderInfo accepts orderID value and more if needed per lib.
-func (c *GoDEX) OrderInfo(orderID string, extraIds ...string) (res instantswap.OrderInfoResult, err error) {
+func (c *GoDEX) OrderInfo(ctx context.Context, orderID string, extraIds ...string) (res instantswap.OrderInfoResult, err error) {
 	var r []byte
-	r, err = c.client.Do(API_BASE, "GET", fmt.Sprintf("transaction/%s", orderID), "", false)
+	r, err = c.client.Do(ctx, c.apiBase, "GET", fmt.Sprintf("transaction/%s", orderID), "", false)
 	if err != nil {
 		return res, err
 	}
@@ -225,15 +218,15 @@ func (c *GoDEX) OrderInfo(orderID string, extraIds ...string) (res instantswap.O
 	return instantswap.OrderInfoResult{
 		Expires:        0,
 		LastUpdate:     "",
-		ReceiveAmount:  utils.StrToFloat(tx.RealWithdrawalAmount.String()),
+		ReceiveAmount:  instantswap.AmountFromString(tx.RealWithdrawalAmount.String()),
 		TxID:           tx.HashOut,
 		Status:         tx.Status,
 		InternalStatus: GetLocalStatus(tx.Status),
 		Confirmations:  "",
 	}, err
 }
-func (c *GoDEX) EstimateAmount(vars interface{}) (res instantswap.EstimateAmount, err error) {
-	return
+func (c *GoDEX) EstimateAmount(ctx context.Context, vars interface{}) (res instantswap.EstimateAmount, err error) {
+	return res, instantswap.ErrNotSupported
 }
 
 // GetLocalStatus translate local status to instantswap.Status.
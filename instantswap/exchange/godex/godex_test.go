@@ -0,0 +1,163 @@
+package godex
+
+import (
+	"context"
+	"testing"
+
+	"github.com/crypto-power/instantswap/instantswap"
+	"github.com/crypto-power/instantswap/instantswap/exchangetest"
+)
+
+const coinsFixture = `[
+	{"code":"BTC","name":"Bitcoin","disabled":0,"has_extra":0},
+	{"code":"DCR","name":"Decred","disabled":0,"has_extra":0},
+	{"code":"XMR","name":"Monero","disabled":1,"has_extra":0},
+	{"code":"LTC","name":"Litecoin","disabled":0,"has_extra":0}]`
+
+func TestGoDEX(t *testing.T) {
+	amount := instantswap.MustParseAmount
+	exchangetest.Run(t, exchangetest.Suite{
+		Exchange: LIBNAME,
+		Config:   instantswap.ExchangeConfig{ApiKey: "key"},
+		Fixtures: []exchangetest.Fixture{{
+			Method: "GET", Path: "/api/v1/coins",
+			Response: coinsFixture,
+		}, {
+			Method: "POST", Path: "/api/v1/info", Body: `"amount":0.1`,
+			Response: `{"min_amount":"0.0008","max_amount":"4.2","amount":"181.1","fee":"0.3","rate":"1811",
+				"networks_from":[],"networks_to":[]}`,
+		}, {
+			Method: "POST", Path: "/api/v1/transaction", Body: `"withdrawal":"DsUZxxoHJSty8DCfwfartwTYbuhmVct7tJu"`,
+			Response: `{"status":"wait","coin_from":"BTC","coin_to":"DCR","deposit_amount":"0.1","withdrawal":"DsUZxxoHJSty8DCfwfartwTYbuhmVct7tJu",
+				"return":"bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t4","withdrawal_amount":"181.1","deposit":"bc1qdeposit","rate":"1811","fee":"0.3",
+				"transaction_id":"gd777"}`,
+		}, {
+			Method: "GET", Path: "/api/v1/transaction/gd777",
+			Response: `{"status":"success","coin_from":"BTC","coin_to":"DCR","transaction_id":"gd777",
+				"hash_out":"dcrtxhash","real_withdrawal_amount":"181.05"}`,
+		}},
+		Currencies: []string{"BTC", "DCR", "LTC"},
+		Rate: &exchangetest.RateTest{
+			Request: instantswap.ExchangeRateRequest{From: "btc", To: "dcr", Amount: amount("0.1")},
+			Expected: instantswap.ExchangeRateInfo{
+				Min:             amount("0.0008"),
+				Max:             amount("4.2"),
+				ExchangeRate:    1811,
+				EstimatedAmount: amount("181.1"),
+				DepositAmount:   amount("0.1"),
+				RateType:        instantswap.RateTypeFloat,
+				Fees:            instantswap.Fees{NetworkFee: amount("0.3")},
+			},
+		},
+		Order: &exchangetest.OrderTest{
+			Request: instantswap.CreateOrder{
+				FromCurrency:   "BTC",
+				ToCurrency:     "DCR",
+				Destination:    "DsUZxxoHJSty8DCfwfartwTYbuhmVct7tJu",
+				RefundAddress:  "bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t4",
+				InvoicedAmount: amount("0.1"),
+			},
+			Expected: instantswap.CreateResultInfo{
+				UUID:           "gd777",
+				Destination:    "DsUZxxoHJSty8DCfwfartwTYbuhmVct7tJu",
+				ExchangeRate:   1811,
+				FromCurrency:   "BTC",
+				ToCurrency:     "DCR",
+				ChargedFee:     amount("0.3"),
+				InvoicedAmount: amount("0.1"),
+				OrderedAmount:  amount("181.1"),
+				DepositAddress: "bc1qdeposit",
+			},
+		},
+		Info: &exchangetest.InfoTest{
+			OrderID: "gd777",
+			Expected: instantswap.OrderInfoResult{
+				ReceiveAmount:  amount("181.05"),
+				TxID:           "dcrtxhash",
+				Status:         "success",
+				InternalStatus: instantswap.OrderStatusCompleted,
+			},
+		},
+		StatusFunc: GetLocalStatus,
+		Statuses: map[string]instantswap.Status{
+			"wait":                 instantswap.OrderStatusNew,
+			"confirmation":         instantswap.OrderStatusDepositReceived,
+			"confirmed":            instantswap.OrderStatusDepositConfirmed,
+			"exchanging":           instantswap.OrderStatusExchanging,
+			"sending":              instantswap.OrderStatusSending,
+			"sending_confirmation": instantswap.OrderStatusSending,
+			"success":              instantswap.OrderStatusCompleted,
+			"overdue":              instantswap.OrderStatusExpired,
+			"error":                instantswap.OrderStatusFailed,
+			"refunded":             instantswap.OrderStatusRefunded,
+			"SUCCESS":              instantswap.OrderStatusCompleted,
+			"closed":               instantswap.OrderStatusUnknown,
+		},
+	})
+}
+
+func TestGoDEXDisabledCurrencies(t *testing.T) {
+	server := exchangetest.NewServer(t, exchangetest.Fixture{
+		Method: "GET", Path: "/api/v1/coins",
+		Response: coinsFixture,
+	})
+	exchange, err := New(server.Config(instantswap.ExchangeConfig{ApiKey: "key"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	currencies, err := exchange.GetCurrencies(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(currencies) != 3 {
+		t.Fatalf("expected 3 currencies, got %+v", currencies)
+	}
+	for _, c := range currencies {
+		if c.Symbol == "" || c.Symbol == "xmr" {
+			t.Errorf("unexpected currency %+v", c)
+		}
+	}
+}
+
+func TestGoDEXBelowMinimum(t *testing.T) {
+	server := exchangetest.NewServer(t, exchangetest.Fixture{
+		Method: "POST", Path: "/api/v1/info", Body: `"amount":0.0001`,
+		Response: `{"min_amount":"0.0008","max_amount":"4.2","amount":"0","rate":"0"}`,
+	}, exchangetest.Fixture{
+		Method: "POST", Path: "/api/v1/info", Body: `"amount":0.0008`,
+		Response: `{"min_amount":"0.0008","max_amount":"4.2","amount":"1.4488","rate":"1811"}`,
+	})
+	exchange, err := New(server.Config(instantswap.ExchangeConfig{ApiKey: "key"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rate, err := exchange.GetExchangeRateInfo(context.Background(), instantswap.ExchangeRateRequest{
+		From: "BTC", To: "DCR", Amount: instantswap.MustParseAmount("0.0001"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	exchangetest.Compare(t, rate, instantswap.ExchangeRateInfo{
+		Min:          instantswap.MustParseAmount("0.0008"),
+		Max:          instantswap.MustParseAmount("4.2"),
+		ExchangeRate: 1811,
+	})
+}
+
+func TestGoDEXNetworks(t *testing.T) {
+	server := exchangetest.NewServer(t, exchangetest.Fixture{
+		Method: "POST", Path: "/api/v1/info", Body: `"network_from":"BTC","network_to":"BSC"`,
+		Response: `{"min_amount":"0.0008","max_amount":"4.2","amount":"2900","rate":"29000"}`,
+	})
+	exchange, err := instantswap.NewExchangeCtx(LIBNAME, server.Config(instantswap.ExchangeConfig{ApiKey: "key"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = exchange.GetExchangeRateInfo(context.Background(), instantswap.ExchangeRateRequest{
+		From: "BTC", FromNetwork: instantswap.NetworkBitcoin, To: "USDT", ToNetwork: "BEP20",
+		Amount: instantswap.MustParseAmount("0.1"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
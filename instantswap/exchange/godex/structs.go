@@ -2,7 +2,8 @@ package godex
 
 import (
 	"encoding/json"
-	"fmt"
+
+	"github.com/crypto-power/instantswap/instantswap"
 )
 
 type Error struct {
@@ -11,27 +12,29 @@ type Error struct {
 }
 
 type InfoRequest struct {
-	From   string  `json:"from"`
-	To     string  `json:"to"`
-	Amount float64 `json:"amount"`
+	From        string      `json:"from"`
+	To          string      `json:"to"`
+	Amount      json.Number `json:"amount"`
+	NetworkFrom string      `json:"network_from,omitempty"`
+	NetworkTo   string      `json:"network_to,omitempty"`
 }
 
 type RevertResponse struct {
-	MinAmount float64 `json:"min_amount"`
-	MaxAmount int     `json:"max_amount"`
-	Amount    float64 `json:"amount"`
-	Fee       int     `json:"fee"`
-	Rate      float64 `json:"rate"`
+	MinAmount instantswap.Amount `json:"min_amount"`
+	MaxAmount int                `json:"max_amount"`
+	Amount    instantswap.Amount `json:"amount"`
+	Fee       int                `json:"fee"`
+	Rate      float64            `json:"rate"`
 }
 
 func parseResponseData(data []byte, obj interface{}) error {
 	var godexErr Error
 	err := json.Unmarshal(data, &godexErr)
 	if err != nil {
-		return fmt.Errorf(string(data))
+		return instantswap.NewError(LIBNAME, instantswap.ClassifyMessage(string(data)), "", string(data))
 	}
 	if err == nil && len(godexErr.Error) > 0 {
-		return fmt.Errorf(godexErr.Error)
+		return instantswap.NewError(LIBNAME, instantswap.ClassifyMessage(godexErr.Error), "", godexErr.Error)
 	}
 	err = json.Unmarshal(data, obj)
 	return err
@@ -53,16 +56,16 @@ type Network struct {
 }
 
 type TransactionReq struct {
-	CoinFrom          string  `json:"coin_from"`
-	CoinTo            string  `json:"coin_to"`
-	DepositAmount     float64 `json:"deposit_amount"`
-	Withdrawal        string  `json:"withdrawal"`
-	WithdrawalExtraId string  `json:"withdrawal_extra_id"`
-	Return            string  `json:"return"`
-	ReturnExtraId     string  `json:"return_extra_id"`
-	AffiliateId       string  `json:"affiliate_id"`
-	CoinToNetwork     string  `json:"coin_to_network"`
-	CoinFromNetwork   string  `json:"coin_from_network"`
+	CoinFrom          string      `json:"coin_from"`
+	CoinTo            string      `json:"coin_to"`
+	DepositAmount     json.Number `json:"deposit_amount"`
+	Withdrawal        string      `json:"withdrawal"`
+	WithdrawalExtraId string      `json:"withdrawal_extra_id"`
+	Return            string      `json:"return"`
+	ReturnExtraId     string      `json:"return_extra_id"`
+	AffiliateId       string      `json:"affiliate_id"`
+	CoinToNetwork     string      `json:"coin_to_network"`
+	CoinFromNetwork   string      `json:"coin_from_network"`
 }
 
 type Transaction struct {
@@ -1,6 +1,7 @@
 package sideshift
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -16,27 +17,42 @@ const (
 )
 
 func init() {
-	instantswap.RegisterExchange(LIBNAME, func(config instantswap.ExchangeConfig) (instantswap.IDExchange, error) {
+	instantswap.RegisterExchangeCtx(LIBNAME, func(config instantswap.ExchangeConfig) (instantswap.IDExchangeCtx, error) {
 		return New(config)
 	})
+	instantswap.RegisterCapabilities(LIBNAME, instantswap.Capabilities{
+		FixedRate:     true,
+		FloatingRate:  true,
+		ReverseQuote:  true,
+		Networks:      true,
+		RefundAddress: true,
+		APIKey:        true,
+		APISecret:     true,
+	})
+	instantswap.RegisterRateLimit(LIBNAME, instantswap.RateLimit{Rate: 1.0 / 3, Burst: 5})
+	instantswap.RegisterAssetMapping(LIBNAME, instantswap.AssetMapping{
+		LowerCase: true,
+		Networks:  map[string]string{instantswap.NetworkAvalanche: "avax"},
+	})
 }
 
 type SideShift struct {
-	client *instantswap.Client
-	conf   *instantswap.ExchangeConfig
-	instantswap.IDExchange
+	client  *instantswap.Client
+	conf    *instantswap.ExchangeConfig
+	apiBase string
 }
 
 func New(conf instantswap.ExchangeConfig) (*SideShift, error) {
 	if conf.ApiKey == "" {
-		return nil, fmt.Errorf("%s:error: api key is blank, it is account id on sideshift", LIBNAME)
+		return nil, instantswap.NewError(LIBNAME, instantswap.KindUnauthorized, "", "api key is blank, it is account id on sideshift")
 	}
 	if conf.ApiSecret == "" {
-		return nil, fmt.Errorf("%s:error: api secret is blank", LIBNAME)
+		return nil, instantswap.NewError(LIBNAME, instantswap.KindUnauthorized, "", "api secret is blank")
 	}
+	httpClient := conf.HTTPClientOrDefault()
 	client := instantswap.NewClient(LIBNAME, &conf, func(r *http.Request, body string) error {
 		if r.Method == http.MethodPost {
-			ipAddress, err := utils.GetPublicIP()
+			ipAddress, err := utils.GetPublicIPWithClient(r.Context(), httpClient)
 			if err != nil {
 				return err
 			}
@@ -45,13 +61,14 @@ func New(conf instantswap.ExchangeConfig) (*SideShift, error) {
 		}
 		return nil
 	})
-	return &SideShift{client: client, conf: &conf}, nil
+	client.SetErrorParser(parseError)
+	return &SideShift{client: client, conf: &conf, apiBase: conf.BaseURLOrDefault(API_BASE)}, nil
 }
 
-func (s *SideShift) GetCurrencies() (currencies []instantswap.Currency, err error) {
-	r, err := s.client.Do(API_BASE, "GET", "coins", "", false)
+func (s *SideShift) GetCurrencies(ctx context.Context) (currencies []instantswap.Currency, err error) {
+	r, err := s.client.Do(ctx, s.apiBase, "GET", "coins", "", false)
 	if err != nil {
-		err = fmt.Errorf("%s:error:%v", LIBNAME, err)
+		err = instantswap.WrapError(LIBNAME, "GetCurrencies", err)
 		return
 	}
 	var csCurrencies []Currency
@@ -70,39 +87,22 @@ func (s *SideShift) GetCurrencies() (currencies []instantswap.Currency, err erro
 	return
 }
 
-func (s *SideShift) GetCurrenciesToPair(from string) (currencies []instantswap.Currency, err error) {
-	r, err := s.client.Do(API_BASE, "GET", "coins", "", false)
-	if err != nil {
-		err = fmt.Errorf("%s:error:%v", LIBNAME, err)
-		return
-	}
-	var csCurrencies []Currency
-	err = parseResponseData(r, &csCurrencies)
-	if err != nil {
-		return nil, err
-	}
-	from = strings.ToUpper(from)
-	for _, currency := range csCurrencies {
-		if currency.Coin != from {
-			currencies = append(currencies, instantswap.Currency{
-				Name:     currency.Name,
-				Symbol:   currency.Coin,
-				Networks: currency.Networks,
-			})
-		}
-	}
-	return
+func (s *SideShift) GetCurrenciesToPair(ctx context.Context, from string) (currencies []instantswap.Currency, err error) {
+	return currencies, instantswap.ErrNotSupported
 }
 
-func (s *SideShift) QueryRates(vars interface{}) (res []instantswap.QueryRate, err error) {
-	return
+func (s *SideShift) QueryRates(ctx context.Context, vars interface{}) (res []instantswap.QueryRate, err error) {
+	return res, instantswap.ErrNotSupported
 }
 
-func (s *SideShift) QueryLimits(fromCurr, toCurr string) (res instantswap.QueryLimits, err error) {
-	return
+func (s *SideShift) QueryLimits(ctx context.Context, fromCurr, toCurr string) (res instantswap.QueryLimits, err error) {
+	return res, instantswap.ErrNotSupported
 }
 
-func (s *SideShift) CreateOrder(vars instantswap.CreateOrder) (res instantswap.CreateResultInfo, err error) {
+func (s *SideShift) CreateOrder(ctx context.Context, vars instantswap.CreateOrder) (res instantswap.CreateResultInfo, err error) {
+	if vars.RateType == instantswap.RateTypeFloat {
+		return s.createVariableShift(ctx, vars)
+	}
 	req := createFixedShift{
 		SettleAddress: vars.Destination,
 		AffiliateId:   s.conf.ApiKey,
@@ -113,7 +113,7 @@ func (s *SideShift) CreateOrder(vars instantswap.CreateOrder) (res instantswap.C
 	if err != nil {
 		return res, err
 	}
-	r, err := s.client.Do(API_BASE, http.MethodPost, "shifts/fixed", string(body), false)
+	r, err := s.client.Do(ctx, s.apiBase, http.MethodPost, "shifts/fixed", string(body), false)
 	if err != nil {
 		return res, err
 	}
@@ -123,12 +123,12 @@ func (s *SideShift) CreateOrder(vars instantswap.CreateOrder) (res instantswap.C
 		return res, err
 	}
 	return instantswap.CreateResultInfo{
-		ChargedFee:     0,
+		ChargedFee:     instantswap.Amount{},
 		Destination:    shift.SettleAddress,
 		ExchangeRate:   utils.StrToFloat(shift.Rate),
 		FromCurrency:   shift.DepositCoin,
-		InvoicedAmount: utils.StrToFloat(shift.DepositAmount),
-		OrderedAmount:  utils.StrToFloat(shift.SettleAmount),
+		InvoicedAmount: instantswap.AmountFromString(shift.DepositAmount),
+		OrderedAmount:  instantswap.AmountFromString(shift.SettleAmount),
 		ToCurrency:     shift.SettleCoin,
 		UUID:           shift.Id,
 		DepositAddress: shift.DepositAddress,
@@ -138,16 +138,52 @@ func (s *SideShift) CreateOrder(vars instantswap.CreateOrder) (res instantswap.C
 	}, nil
 }
 
-func (s *SideShift) UpdateOrder(vars interface{}) (res instantswap.UpdateOrderResultInfo, err error) {
-	return res, fmt.Errorf("not supported")
+// createVariableShift creates a shift whose rate is set when the deposit is
+// received, the deposit address accepts any amount between the limits.
+func (s *SideShift) createVariableShift(ctx context.Context, vars instantswap.CreateOrder) (res instantswap.CreateResultInfo, err error) {
+	req := createVariableShift{
+		SettleAddress:  vars.Destination,
+		AffiliateId:    s.conf.ApiKey,
+		DepositCoin:    strings.ToLower(vars.FromCurrency),
+		DepositNetwork: vars.FromNetwork,
+		SettleCoin:     strings.ToLower(vars.ToCurrency),
+		SettleNetwork:  vars.ToNetwork,
+		RefundAddress:  vars.RefundAddress,
+	}
+	body, err := json.Marshal(req)
+	if err != nil {
+		return res, err
+	}
+	r, err := s.client.Do(ctx, s.apiBase, http.MethodPost, "shifts/variable", string(body), false)
+	if err != nil {
+		return res, err
+	}
+	var shift FixedShift
+	err = parseResponseData(r, &shift)
+	if err != nil {
+		return res, err
+	}
+	return instantswap.CreateResultInfo{
+		Destination:    shift.SettleAddress,
+		FromCurrency:   shift.DepositCoin,
+		InvoicedAmount: vars.InvoicedAmount,
+		ToCurrency:     shift.SettleCoin,
+		UUID:           shift.Id,
+		DepositAddress: shift.DepositAddress,
+		Expires:        int(shift.ExpiresAt.Unix()),
+	}, nil
+}
+
+func (s *SideShift) UpdateOrder(ctx context.Context, vars interface{}) (res instantswap.UpdateOrderResultInfo, err error) {
+	return res, instantswap.ErrNotSupported
 }
 
-func (s *SideShift) CancelOrder(orderID string) (res string, err error) {
-	return res, fmt.Errorf("not supported")
+func (s *SideShift) CancelOrder(ctx context.Context, orderID string) (res string, err error) {
+	return res, instantswap.ErrNotSupported
 }
 
-func (s *SideShift) OrderInfo(orderID string, extraIds ...string) (res instantswap.OrderInfoResult, err error) {
-	r, err := s.client.Do(API_BASE, http.MethodGet,
+func (s *SideShift) OrderInfo(ctx context.Context, orderID string, extraIds ...string) (res instantswap.OrderInfoResult, err error) {
+	r, err := s.client.Do(ctx, s.apiBase, http.MethodGet,
 		fmt.Sprintf("shifts/%s", orderID), "", false)
 	if err != nil {
 		return res, err
@@ -160,32 +196,38 @@ func (s *SideShift) OrderInfo(orderID string, extraIds ...string) (res instantsw
 	return instantswap.OrderInfoResult{
 		Expires:        int(shift.ExpiresAt.Unix()),
 		LastUpdate:     "",
-		ReceiveAmount:  0,
+		ReceiveAmount:  instantswap.AmountFromString(shift.SettleAmount),
 		TxID:           shift.SettleHash,
-		Status:         "",
+		Status:         shift.Status,
 		InternalStatus: GetLocalStatus(shift.Status),
 		Confirmations:  "",
 	}, nil
 }
 
-func (s *SideShift) GetExchangeRateInfo(vars instantswap.ExchangeRateRequest) (res instantswap.ExchangeRateInfo, err error) {
+func (s *SideShift) GetExchangeRateInfo(ctx context.Context, vars instantswap.ExchangeRateRequest) (res instantswap.ExchangeRateInfo, err error) {
+	if vars.RateType == instantswap.RateTypeFloat {
+		return s.variableRate(ctx, vars)
+	}
 	var req = ExchangeRateRequest{
 		DepositCoin:    strings.ToLower(vars.From),
 		DepositNetwork: vars.FromNetwork,
 		SettleCoin:     strings.ToLower(vars.To),
 		SettleNetwork:  vars.ToNetwork,
-		DepositAmount:  fmt.Sprintf("%f", vars.Amount),
-		SettleAmount:   "",
 		AffiliateId:    s.conf.ApiKey,
 		CommissionRate: "0",
 	}
+	if vars.Direction == instantswap.DirectionTo {
+		req.SettleAmount = vars.Amount.TruncateFor(vars.To, vars.ToNetwork).String()
+	} else {
+		req.DepositAmount = vars.Amount.TruncateFor(vars.From, vars.FromNetwork).String()
+	}
 	body, err := json.Marshal(req)
 	if err != nil {
 		return res, err
 	}
-	r, err := s.client.Do(API_BASE, http.MethodPost, "quotes", string(body), false)
+	r, err := s.client.Do(instantswap.Idempotent(ctx), s.apiBase, http.MethodPost, "quotes", string(body), false)
 	if err != nil {
-		err = fmt.Errorf("%s:error:%v", LIBNAME, err)
+		err = instantswap.WrapError(LIBNAME, "GetExchangeRateInfo", err)
 		return
 	}
 	var quote Quote
@@ -193,22 +235,47 @@ func (s *SideShift) GetExchangeRateInfo(vars instantswap.ExchangeRateRequest) (r
 	if err != nil {
 		return res, err
 	}
-	pair, _ := s.pair(vars)
+	pair, _ := s.pair(ctx, vars)
 	return instantswap.ExchangeRateInfo{
-		Min:             utils.StrToFloat(pair.Min),
-		Max:             utils.StrToFloat(pair.Max),
+		Min:             instantswap.AmountFromString(pair.Min),
+		Max:             instantswap.AmountFromString(pair.Max),
 		ExchangeRate:    utils.StrToFloat(quote.Rate),
-		EstimatedAmount: utils.StrToFloat(quote.SettleAmount),
-		MaxOrder:        0,
+		EstimatedAmount: instantswap.AmountFromString(quote.SettleAmount),
+		DepositAmount:   instantswap.AmountFromString(quote.DepositAmount),
+		MaxOrder:        instantswap.Amount{},
 		Signature:       quote.Id,
+		RateType:        instantswap.RateTypeFixed,
+		ValidUntil:      quote.ExpiresAt,
 	}, nil
 }
 
-func (s *SideShift) pair(vars instantswap.ExchangeRateRequest) (pair PairResponse, err error) {
-	r, err := s.client.Do(API_BASE, http.MethodGet,
-		fmt.Sprintf("pair/%s-%s/%s-%s",
-			strings.ToLower(vars.From), vars.FromNetwork,
-			strings.ToLower(vars.To), vars.ToNetwork), "", false)
+// variableRate estimates a variable shift with the market rate of the pair,
+// the variable shifts have no quote.
+func (s *SideShift) variableRate(ctx context.Context, vars instantswap.ExchangeRateRequest) (res instantswap.ExchangeRateInfo, err error) {
+	pair, err := s.pair(ctx, vars)
+	if err != nil {
+		err = instantswap.WrapError(LIBNAME, "GetExchangeRateInfo", err)
+		return
+	}
+	rate := instantswap.AmountFromString(pair.Rate)
+	res = instantswap.ExchangeRateInfo{
+		Min:             instantswap.AmountFromString(pair.Min),
+		Max:             instantswap.AmountFromString(pair.Max),
+		ExchangeRate:    rate.Float64(),
+		EstimatedAmount: vars.Amount.Mul(rate).TruncateFor(vars.To, vars.ToNetwork),
+		DepositAmount:   vars.Amount,
+		RateType:        instantswap.RateTypeFloat,
+	}
+	if vars.Direction == instantswap.DirectionTo {
+		res.EstimatedAmount = vars.Amount
+		res.DepositAmount = vars.Amount.Quo(rate, 18).TruncateFor(vars.From, vars.FromNetwork)
+	}
+	return res, nil
+}
+
+func (s *SideShift) pair(ctx context.Context, vars instantswap.ExchangeRateRequest) (pair PairResponse, err error) {
+	r, err := s.client.Do(ctx, s.apiBase, http.MethodGet,
+		fmt.Sprintf("pair/%s/%s", coinNetwork(vars.From, vars.FromNetwork), coinNetwork(vars.To, vars.ToNetwork)), "", false)
 	if err != nil {
 		return pair, err
 	}
@@ -216,6 +283,15 @@ func (s *SideShift) pair(vars instantswap.ExchangeRateRequest) (pair PairRespons
 	return pair, err
 }
 
+// coinNetwork returns the coin-network notation of the pair endpoint, the
+// coin alone selects its default network.
+func coinNetwork(coin, network string) string {
+	if network == "" {
+		return strings.ToLower(coin)
+	}
+	return strings.ToLower(coin) + "-" + network
+}
+
 // GetLocalStatus translate local status to instantswap.Status.
 func GetLocalStatus(status string) instantswap.Status {
 	status = strings.ToLower(status)
@@ -0,0 +1,161 @@
+package sideshift
+
+import (
+	"testing"
+	"time"
+
+	"github.com/crypto-power/instantswap/instantswap"
+	"github.com/crypto-power/instantswap/instantswap/exchangetest"
+)
+
+func TestSideShift(t *testing.T) {
+	amount := instantswap.MustParseAmount
+	exchangetest.Run(t, exchangetest.Suite{
+		Exchange: LIBNAME,
+		Config:   instantswap.ExchangeConfig{ApiKey: "account", ApiSecret: "secret"},
+		Fixtures: []exchangetest.Fixture{{
+			Method: "GET", Path: "/raw",
+			Response: "203.0.113.7\n",
+		}, {
+			Method: "GET", Path: "/api/v2/coins",
+			Response: `[{"coin":"BTC","networks":["bitcoin"],"name":"Bitcoin","hasMemo":false},
+				{"coin":"DCR","networks":["decred"],"name":"Decred","hasMemo":false},
+				{"coin":"USDT","networks":["ethereum","tron"],"name":"Tether","hasMemo":false}]`,
+		}, {
+			Method: "POST", Path: "/api/v2/quotes", Body: `"depositAmount":"0.1"`,
+			Response: `{"id":"quote-1","createdAt":"2023-05-01T10:00:00.000Z","depositCoin":"BTC","settleCoin":"DCR",
+				"depositNetwork":"bitcoin","settleNetwork":"decred","expiresAt":"2023-05-01T10:15:00.000Z",
+				"depositAmount":"0.1","settleAmount":"180.9","rate":"1809","affiliateId":"account"}`,
+		}, {
+			Method: "GET", Path: "/api/v2/pair/btc/dcr",
+			Response: `{"min":"0.0002","max":"1.8","rate":"1809","depositCoin":"BTC","settleCoin":"DCR"}`,
+		}, {
+			Method: "POST", Path: "/api/v2/shifts/fixed", Body: `"quoteId":"quote-1"`,
+			Response: `{"id":"shift-1","createdAt":"2023-05-01T10:00:05.000Z","depositCoin":"BTC","settleCoin":"DCR",
+				"depositNetwork":"bitcoin","settleNetwork":"decred","depositAddress":"bc1qdeposit","settleAddress":"DsUZxxoHJSty8DCfwfartwTYbuhmVct7tJu",
+				"refundAddress":"bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t4","type":"fixed","quoteId":"quote-1","depositAmount":"0.1","settleAmount":"180.9",
+				"expiresAt":"2023-05-01T10:15:00.000Z","status":"waiting","rate":"1809"}`,
+		}, {
+			Method: "GET", Path: "/api/v2/shifts/shift-1",
+			Response: `{"id":"shift-1","depositCoin":"BTC","settleCoin":"DCR","depositAmount":"0.1","settleAmount":"180.9",
+				"expiresAt":"2023-05-01T10:15:00.000Z","status":"settled","rate":"1809","settleHash":"dcrtxhash"}`,
+		}},
+		Currencies: []string{"BTC", "DCR", "USDT"},
+		Rate: &exchangetest.RateTest{
+			Request: instantswap.ExchangeRateRequest{From: "BTC", To: "DCR", Amount: amount("0.1")},
+			Expected: instantswap.ExchangeRateInfo{
+				Min:             amount("0.0002"),
+				Max:             amount("1.8"),
+				ExchangeRate:    1809,
+				EstimatedAmount: amount("180.9"),
+				DepositAmount:   amount("0.1"),
+				Signature:       "quote-1",
+				RateType:        instantswap.RateTypeFixed,
+				ValidUntil:      time.Date(2023, 5, 1, 10, 15, 0, 0, time.UTC),
+			},
+		},
+		Order: &exchangetest.OrderTest{
+			Request: instantswap.CreateOrder{
+				FromCurrency:   "BTC",
+				ToCurrency:     "DCR",
+				Destination:    "DsUZxxoHJSty8DCfwfartwTYbuhmVct7tJu",
+				RefundAddress:  "bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t4",
+				InvoicedAmount: amount("0.1"),
+				Signature:      "quote-1",
+			},
+			Expected: instantswap.CreateResultInfo{
+				UUID:           "shift-1",
+				Destination:    "DsUZxxoHJSty8DCfwfartwTYbuhmVct7tJu",
+				ExchangeRate:   1809,
+				FromCurrency:   "BTC",
+				ToCurrency:     "DCR",
+				InvoicedAmount: amount("0.1"),
+				OrderedAmount:  amount("180.9"),
+				DepositAddress: "bc1qdeposit",
+				Expires:        1682936100,
+			},
+		},
+		Info: &exchangetest.InfoTest{
+			OrderID: "shift-1",
+			Expected: instantswap.OrderInfoResult{
+				Expires:        1682936100,
+				ReceiveAmount:  amount("180.9"),
+				TxID:           "dcrtxhash",
+				Status:         "settled",
+				InternalStatus: instantswap.OrderStatusCompleted,
+			},
+		},
+		StatusFunc: GetLocalStatus,
+		Statuses: map[string]instantswap.Status{
+			"waiting":    instantswap.OrderStatusWaitingForDeposit,
+			"pending":    instantswap.OrderStatusDepositReceived,
+			"processing": instantswap.OrderStatusDepositConfirmed,
+			"settling":   instantswap.OrderStatusExchanging,
+			"review":     instantswap.OrderStatusExchanging,
+			"settled":    instantswap.OrderStatusCompleted,
+			"refund":     instantswap.OrderStatusFailed,
+			"refunding":  instantswap.OrderStatusRefunded,
+			"refunded":   instantswap.OrderStatusRefunded,
+			"multiple":   instantswap.OrderStatusUnknown,
+		},
+	})
+}
+
+func TestSideShiftVariable(t *testing.T) {
+	amount := instantswap.MustParseAmount
+	exchangetest.Run(t, exchangetest.Suite{
+		Exchange: LIBNAME,
+		Config:   instantswap.ExchangeConfig{ApiKey: "account", ApiSecret: "secret"},
+		Fixtures: []exchangetest.Fixture{{
+			Method: "GET", Path: "/raw",
+			Response: "203.0.113.7\n",
+		}, {
+			Method: "GET", Path: "/api/v2/pair/btc/dcr",
+			Response: `{"min":"0.0002","max":"1.8","rate":"1809.5","depositCoin":"BTC","settleCoin":"DCR"}`,
+		}, {
+			Method: "POST", Path: "/api/v2/shifts/variable", Body: `"depositCoin":"btc"`,
+			Response: `{"id":"shift-2","createdAt":"2023-05-01T10:00:05.000Z","depositCoin":"BTC","settleCoin":"DCR",
+				"depositNetwork":"bitcoin","settleNetwork":"decred","depositAddress":"bc1qdeposit","settleAddress":"DsUZxxoHJSty8DCfwfartwTYbuhmVct7tJu",
+				"depositMin":"0.0002","depositMax":"1.8","type":"variable","expiresAt":"2023-05-08T10:00:05.000Z",
+				"status":"waiting"}`,
+		}},
+		Rate: &exchangetest.RateTest{
+			Request: instantswap.ExchangeRateRequest{From: "BTC", To: "DCR", Amount: amount("0.1"), RateType: instantswap.RateTypeFloat},
+			Expected: instantswap.ExchangeRateInfo{
+				Min:             amount("0.0002"),
+				Max:             amount("1.8"),
+				ExchangeRate:    1809.5,
+				EstimatedAmount: amount("180.95"),
+				DepositAmount:   amount("0.1"),
+				RateType:        instantswap.RateTypeFloat,
+			},
+		},
+		Order: &exchangetest.OrderTest{
+			Request: instantswap.CreateOrder{
+				FromCurrency:   "BTC",
+				ToCurrency:     "DCR",
+				Destination:    "DsUZxxoHJSty8DCfwfartwTYbuhmVct7tJu",
+				InvoicedAmount: amount("0.1"),
+				RateType:       instantswap.RateTypeFloat,
+			},
+			Expected: instantswap.CreateResultInfo{
+				UUID:           "shift-2",
+				Destination:    "DsUZxxoHJSty8DCfwfartwTYbuhmVct7tJu",
+				FromCurrency:   "BTC",
+				ToCurrency:     "DCR",
+				InvoicedAmount: amount("0.1"),
+				DepositAddress: "bc1qdeposit",
+				Expires:        1683540005,
+			},
+		},
+	})
+}
+
+func TestCoinNetwork(t *testing.T) {
+	if got := coinNetwork("USDT", "tron"); got != "usdt-tron" {
+		t.Errorf("got %q", got)
+	}
+	if got := coinNetwork("BTC", ""); got != "btc" {
+		t.Errorf("got %q", got)
+	}
+}
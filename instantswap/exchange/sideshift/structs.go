@@ -3,12 +3,28 @@ package sideshift
 import (
 	"encoding/json"
 	"time"
+
+	"github.com/crypto-power/instantswap/instantswap"
 )
 
 func parseResponseData(r []byte, obj interface{}) error {
 	return json.Unmarshal(r, obj)
 }
 
+type apiError struct {
+	Error struct {
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// parseError decodes the body of an error response.
+func parseError(e *instantswap.Error, body []byte) {
+	var res apiError
+	if json.Unmarshal(body, &res) == nil && res.Error.Message != "" {
+		e.Message = res.Error.Message
+	}
+}
+
 type Currency struct {
 	Coin           string      `json:"coin"`
 	Networks       []string    `json:"networks"`
@@ -62,6 +78,17 @@ type createFixedShift struct {
 	RefundAddress string `json:"refundAddress"`
 }
 
+type createVariableShift struct {
+	SettleAddress  string `json:"settleAddress"`
+	AffiliateId    string `json:"affiliateId"`
+	DepositCoin    string `json:"depositCoin"`
+	DepositNetwork string `json:"depositNetwork,omitempty"`
+	SettleCoin     string `json:"settleCoin"`
+	SettleNetwork  string `json:"settleNetwork,omitempty"`
+	RefundAddress  string `json:"refundAddress,omitempty"`
+}
+
+// FixedShift is a shift, fixed or variable.
 type FixedShift struct {
 	Id             string    `json:"id"`
 	CreatedAt      time.Time `json:"createdAt"`
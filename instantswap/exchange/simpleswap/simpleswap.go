@@ -1,13 +1,14 @@
 package simpleswap
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"strconv"
 	"strings"
 
 	"github.com/crypto-power/instantswap/instantswap"
-	"github.com/crypto-power/instantswap/instantswap/utils"
 )
 
 const (
@@ -16,23 +17,43 @@ const (
 )
 
 func init() {
-	instantswap.RegisterExchange(LIBNAME, func(config instantswap.ExchangeConfig) (instantswap.IDExchange, error) {
+	instantswap.RegisterExchangeCtx(LIBNAME, func(config instantswap.ExchangeConfig) (instantswap.IDExchangeCtx, error) {
 		return New(config)
 	})
+	instantswap.RegisterCapabilities(LIBNAME, instantswap.Capabilities{
+		FixedRate:     true,
+		FloatingRate:  true,
+		PairListing:   true,
+		RefundAddress: true,
+		APIKey:        true,
+	})
+	instantswap.RegisterRateLimit(LIBNAME, instantswap.RateLimit{Rate: 2, Burst: 5})
+	instantswap.RegisterAssetMapping(LIBNAME, instantswap.AssetMapping{
+		LowerCase: true,
+		Tickers: map[instantswap.Asset]string{
+			{Symbol: "USDT", Network: instantswap.NetworkEthereum}: "usdterc20",
+			{Symbol: "USDT", Network: instantswap.NetworkTron}:     "usdttrc20",
+			{Symbol: "USDT", Network: instantswap.NetworkBSC}:      "usdtbep20",
+			{Symbol: "USDT", Network: instantswap.NetworkSolana}:   "usdtsol",
+			{Symbol: "USDC", Network: instantswap.NetworkEthereum}: "usdc",
+			{Symbol: "BNB", Network: instantswap.NetworkBSC}:       "bnbbsc",
+		},
+	})
 }
 
 type SimpleSwap struct {
-	client *instantswap.Client
-	conf   *instantswap.ExchangeConfig
-	instantswap.IDExchange
+	client  *instantswap.Client
+	conf    *instantswap.ExchangeConfig
+	apiBase string
 }
 
 func New(conf instantswap.ExchangeConfig) (*SimpleSwap, error) {
 	if conf.ApiKey == "" {
-		return nil, fmt.Errorf("%s:error: APIKEY is blank", LIBNAME)
+		return nil, instantswap.NewError(LIBNAME, instantswap.KindUnauthorized, "", "APIKEY is blank")
 	}
 	client := instantswap.NewClient(LIBNAME, &conf)
-	return &SimpleSwap{client: client, conf: &conf}, nil
+	client.SetErrorParser(parseError)
+	return &SimpleSwap{client: client, conf: &conf, apiBase: conf.BaseURLOrDefault(API_BASE)}, nil
 }
 
 // SetDebug set enable/disable http request/response dump
@@ -40,8 +61,8 @@ func (c *SimpleSwap) SetDebug(enable bool) {
 	c.conf.Debug = enable
 }
 
-func (c *SimpleSwap) GetCurrencies() (currencies []instantswap.Currency, err error) {
-	r, err := c.client.Do(API_BASE, http.MethodGet,
+func (c *SimpleSwap) GetCurrencies(ctx context.Context) (currencies []instantswap.Currency, err error) {
+	r, err := c.client.Do(ctx, c.apiBase, http.MethodGet,
 		fmt.Sprintf("get_all_currencies?api_key=%s", c.conf.ApiKey),
 		"", false)
 	if err != nil {
@@ -62,8 +83,8 @@ func (c *SimpleSwap) GetCurrencies() (currencies []instantswap.Currency, err err
 	return currencies, nil
 }
 
-func (c *SimpleSwap) GetCurrenciesToPair(from string) (currencies []instantswap.Currency, err error) {
-	r, err := c.client.Do(API_BASE, http.MethodGet,
+func (c *SimpleSwap) GetCurrenciesToPair(ctx context.Context, from string) (currencies []instantswap.Currency, err error) {
+	r, err := c.client.Do(ctx, c.apiBase, http.MethodGet,
 		fmt.Sprintf("get_pairs?api_key=%s&fixed=true&symbol=%s", c.conf.ApiKey, strings.ToLower(from)),
 		"", false)
 	if err != nil {
@@ -84,41 +105,47 @@ func (c *SimpleSwap) GetCurrenciesToPair(from string) (currencies []instantswap.
 	return
 }
 
-func (c *SimpleSwap) GetExchangeRateInfo(vars instantswap.ExchangeRateRequest) (res instantswap.ExchangeRateInfo, err error) {
+func (c *SimpleSwap) GetExchangeRateInfo(ctx context.Context, vars instantswap.ExchangeRateRequest) (res instantswap.ExchangeRateInfo, err error) {
 	var r []byte
-	r, err = c.client.Do(API_BASE, "GET",
-		fmt.Sprintf("get_estimated?api_key=%s&currency_from=%s&currency_to=%s&fixed=true&amount=%.8f",
-			c.conf.ApiKey, strings.ToLower(vars.From), strings.ToLower(vars.To), vars.Amount),
+	r, err = c.client.Do(ctx, c.apiBase, "GET",
+		fmt.Sprintf("get_estimated?api_key=%s&currency_from=%s&currency_to=%s&fixed=%t&amount=%s",
+			c.conf.ApiKey, strings.ToLower(vars.From), strings.ToLower(vars.To), vars.RateType == instantswap.RateTypeFixed,
+			vars.Amount.TruncateFor(vars.From, vars.FromNetwork)),
 		"", false)
 	if err != nil {
 		return
 	}
 	var response = string(r)
 	if response == "null" {
-		return res, fmt.Errorf("invalid request")
+		return res, instantswap.NewError(LIBNAME, instantswap.KindPairUnavailable, "", "invalid request")
 	}
 	var estimatedAmountStr string
 	err = json.Unmarshal(r, &estimatedAmountStr)
 	if err != nil {
 		return res, err
 	}
-	estimatedAmount := utils.StrToFloat(estimatedAmountStr)
+	estimatedAmount := instantswap.AmountFromString(estimatedAmountStr)
 	return instantswap.ExchangeRateInfo{
-		Min:             0,
-		Max:             0,
-		ExchangeRate:    estimatedAmount / vars.Amount,
+		Min:             instantswap.Amount{},
+		Max:             instantswap.Amount{},
+		ExchangeRate:    instantswap.ExchangeRate(estimatedAmount, vars.Amount),
 		EstimatedAmount: estimatedAmount,
-		MaxOrder:        0,
+		MaxOrder:        instantswap.Amount{},
 		Signature:       "",
+		RateType:        rateType(vars.RateType),
 	}, err
 }
 
-func (c *SimpleSwap) CreateOrder(vars instantswap.CreateOrder) (res instantswap.CreateResultInfo, err error) {
+func (c *SimpleSwap) QueryLimits(ctx context.Context, fromCurr, toCurr string) (res instantswap.QueryLimits, err error) {
+	return res, instantswap.ErrNotSupported
+}
+
+func (c *SimpleSwap) CreateOrder(ctx context.Context, vars instantswap.CreateOrder) (res instantswap.CreateResultInfo, err error) {
 	var form = CreateExchange{
 		CurrencyFrom:      strings.ToLower(vars.FromCurrency),
 		CurrencyTo:        strings.ToLower(vars.ToCurrency),
-		Fixed:             false,
-		Amount:            vars.InvoicedAmount,
+		Fixed:             vars.RateType == instantswap.RateTypeFixed,
+		Amount:            vars.InvoicedAmount.TruncateFor(vars.FromCurrency, vars.FromNetwork),
 		AddressTo:         vars.Destination,
 		ExtraIdTo:         "",
 		UserRefundAddress: vars.RefundAddress,
@@ -131,7 +158,7 @@ func (c *SimpleSwap) CreateOrder(vars instantswap.CreateOrder) (res instantswap.
 	}
 	// do request
 	var r []byte
-	r, err = c.client.Do(API_BASE, "POST", fmt.Sprintf("create_exchange?api_key=%s", c.conf.ApiKey),
+	r, err = c.client.Do(ctx, c.apiBase, "POST", fmt.Sprintf("create_exchange?api_key=%s", c.conf.ApiKey),
 		string(payload), false)
 	if err != nil {
 		return
@@ -141,12 +168,12 @@ func (c *SimpleSwap) CreateOrder(vars instantswap.CreateOrder) (res instantswap.
 	if err != nil {
 		return
 	}
-	var invoicedAmount = utils.StrToFloat(order.AmountFrom)
-	var orderedAmount = utils.StrToFloat(order.AmountTo)
+	var invoicedAmount = instantswap.AmountFromString(order.AmountFrom)
+	var orderedAmount = instantswap.AmountFromString(order.AmountTo)
 	res = instantswap.CreateResultInfo{
-		ChargedFee:     0,
+		ChargedFee:     instantswap.Amount{},
 		Destination:    order.AddressTo,
-		ExchangeRate:   invoicedAmount / orderedAmount,
+		ExchangeRate:   instantswap.ExchangeRate(orderedAmount, invoicedAmount),
 		FromCurrency:   order.CurrencyFrom,
 		InvoicedAmount: invoicedAmount,
 		OrderedAmount:  orderedAmount,
@@ -160,19 +187,27 @@ func (c *SimpleSwap) CreateOrder(vars instantswap.CreateOrder) (res instantswap.
 	return
 }
 
+// rateType returns the type of the orders created with t, floating by default.
+func rateType(t instantswap.RateType) instantswap.RateType {
+	if t == instantswap.RateTypeFixed {
+		return instantswap.RateTypeFixed
+	}
+	return instantswap.RateTypeFloat
+}
+
 // UpdateOrder accepts orderID value and more if needed per lib
-func (c *SimpleSwap) UpdateOrder(vars interface{}) (res instantswap.UpdateOrderResultInfo, err error) {
-	return
+func (c *SimpleSwap) UpdateOrder(ctx context.Context, vars interface{}) (res instantswap.UpdateOrderResultInfo, err error) {
+	return res, instantswap.ErrNotSupported
 }
 
-func (c *SimpleSwap) CancelOrder(orderID string) (res string, err error) {
-	return
+func (c *SimpleSwap) CancelOrder(ctx context.Context, orderID string) (res string, err error) {
+	return res, instantswap.ErrNotSupported
 }
 
 // OrderInfo accepts orderID value and more if needed per lib.
-func (c *SimpleSwap) OrderInfo(orderID string, extraIds ...string) (res instantswap.OrderInfoResult, err error) {
+func (c *SimpleSwap) OrderInfo(ctx context.Context, orderID string, extraIds ...string) (res instantswap.OrderInfoResult, err error) {
 	var r []byte
-	r, err = c.client.Do(API_BASE, "GET",
+	r, err = c.client.Do(ctx, c.apiBase, "GET",
 		fmt.Sprintf("get_exchange?id=%s&api_key=%s", orderID, c.conf.ApiKey),
 		"", false)
 	if err != nil {
@@ -186,7 +221,7 @@ func (c *SimpleSwap) OrderInfo(orderID string, extraIds ...string) (res instants
 	return instantswap.OrderInfoResult{
 		Expires:        0,
 		LastUpdate:     order.UpdatedAt,
-		ReceiveAmount:  utils.StrToFloat(order.AmountTo),
+		ReceiveAmount:  instantswap.AmountFromString(order.AmountTo),
 		TxID:           order.TxTo,
 		Status:         order.Status,
 		InternalStatus: GetLocalStatus(order.Status),
@@ -198,7 +233,8 @@ func parseResponseData(data []byte, obj interface{}) error {
 	var simpleSwapErr Error
 	err := json.Unmarshal(data, &simpleSwapErr)
 	if err == nil && simpleSwapErr.Code > 0 && len(simpleSwapErr.Message) > 0 {
-		return fmt.Errorf(simpleSwapErr.Message)
+		return instantswap.NewError(LIBNAME, instantswap.ClassifyMessage(simpleSwapErr.Message),
+			strconv.Itoa(simpleSwapErr.Code), simpleSwapErr.Message)
 	}
 	err = json.Unmarshal(data, obj)
 	if err != nil {
@@ -235,3 +271,15 @@ func GetLocalStatus(status string) instantswap.Status {
 		return instantswap.OrderStatusUnknown
 	}
 }
+
+// parseError decodes the body of an error response.
+func parseError(e *instantswap.Error, body []byte) {
+	var simpleSwapErr Error
+	if json.Unmarshal(body, &simpleSwapErr) != nil || simpleSwapErr.Message == "" {
+		return
+	}
+	if simpleSwapErr.Code > 0 {
+		e.Code = strconv.Itoa(simpleSwapErr.Code)
+	}
+	e.Message = simpleSwapErr.Message
+}
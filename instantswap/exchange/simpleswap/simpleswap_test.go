@@ -0,0 +1,157 @@
+package simpleswap
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/crypto-power/instantswap/instantswap"
+	"github.com/crypto-power/instantswap/instantswap/exchangetest"
+)
+
+func TestSimpleSwap(t *testing.T) {
+	amount := instantswap.MustParseAmount
+	exchangetest.Run(t, exchangetest.Suite{
+		Exchange: LIBNAME,
+		Config:   instantswap.ExchangeConfig{ApiKey: "key"},
+		Fixtures: []exchangetest.Fixture{{
+			Method: "GET", Path: "/get_all_currencies", Query: "api_key=key",
+			Response: `[{"name":"Bitcoin","symbol":"btc","network":"btc","has_extra_id":false},
+				{"name":"Decred","symbol":"dcr","network":"dcr","has_extra_id":false},
+				{"name":"Litecoin","symbol":"ltc","network":"ltc","has_extra_id":false}]`,
+		}, {
+			Method: "GET", Path: "/get_pairs", Query: "symbol=btc",
+			Response: `["dcr","ltc","eth"]`,
+		}, {
+			Method: "GET", Path: "/get_estimated", Query: "currency_from=btc&currency_to=dcr&fixed=false&amount=0.1",
+			Response: `"180.7"`,
+		}, {
+			Method: "POST", Path: "/create_exchange", Query: "api_key=key", Body: `"amount":"0.1"`,
+			Response: `{"id":"ss-42","type":"floating","timestamp":"2023-05-01T10:00:00.000Z","updated_at":"2023-05-01T10:00:00.000Z",
+				"currency_from":"btc","currency_to":"dcr","amount_from":"0.1","expected_amount":"0.1","amount_to":"180.7",
+				"address_from":"bc1qdeposit","address_to":"DsUZxxoHJSty8DCfwfartwTYbuhmVct7tJu","user_refund_address":"bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t4","status":"waiting"}`,
+		}, {
+			Method: "GET", Path: "/get_exchange", Query: "id=ss-42",
+			Response: `{"id":"ss-42","updated_at":"2023-05-01T10:30:00.000Z","currency_from":"btc","currency_to":"dcr",
+				"amount_from":"0.1","amount_to":"180.65","address_from":"bc1qdeposit","address_to":"DsUZxxoHJSty8DCfwfartwTYbuhmVct7tJu",
+				"tx_to":"dcrtxhash","status":"finished"}`,
+		}},
+		Currencies: []string{"BTC", "DCR", "LTC"},
+		PairFrom:   "BTC",
+		Pairs:      []string{"DCR", "LTC"},
+		Rate: &exchangetest.RateTest{
+			Request: instantswap.ExchangeRateRequest{From: "BTC", To: "DCR", Amount: amount("0.1")},
+			Expected: instantswap.ExchangeRateInfo{
+				ExchangeRate:    1807,
+				EstimatedAmount: amount("180.7"),
+				DepositAmount:   amount("0.1"),
+				RateType:        instantswap.RateTypeFloat,
+			},
+		},
+		Order: &exchangetest.OrderTest{
+			Request: instantswap.CreateOrder{
+				FromCurrency:   "BTC",
+				ToCurrency:     "DCR",
+				Destination:    "DsUZxxoHJSty8DCfwfartwTYbuhmVct7tJu",
+				RefundAddress:  "bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t4",
+				InvoicedAmount: amount("0.1"),
+			},
+			Expected: instantswap.CreateResultInfo{
+				UUID:           "ss-42",
+				Destination:    "DsUZxxoHJSty8DCfwfartwTYbuhmVct7tJu",
+				ExchangeRate:   1807,
+				FromCurrency:   "BTC",
+				ToCurrency:     "DCR",
+				InvoicedAmount: amount("0.1"),
+				OrderedAmount:  amount("180.7"),
+				DepositAddress: "bc1qdeposit",
+			},
+		},
+		Info: &exchangetest.InfoTest{
+			OrderID: "ss-42",
+			Expected: instantswap.OrderInfoResult{
+				LastUpdate:     "2023-05-01T10:30:00.000Z",
+				ReceiveAmount:  amount("180.65"),
+				TxID:           "dcrtxhash",
+				Status:         "finished",
+				InternalStatus: instantswap.OrderStatusCompleted,
+			},
+		},
+		StatusFunc: GetLocalStatus,
+		Statuses: map[string]instantswap.Status{
+			"waiting":    instantswap.OrderStatusWaitingForDeposit,
+			"confirming": instantswap.OrderStatusDepositReceived,
+			"verifying":  instantswap.OrderStatusDepositReceived,
+			"exchanging": instantswap.OrderStatusExchanging,
+			"sending":    instantswap.OrderStatusSending,
+			"finished":   instantswap.OrderStatusCompleted,
+			"failed":     instantswap.OrderStatusFailed,
+			"refunded":   instantswap.OrderStatusRefunded,
+			"expired":    instantswap.OrderStatusExpired,
+			"closed":     instantswap.OrderStatusCanceled,
+			"unknown":    instantswap.OrderStatusUnknown,
+		},
+	})
+}
+
+func TestSimpleSwapFixed(t *testing.T) {
+	amount := instantswap.MustParseAmount
+	exchangetest.Run(t, exchangetest.Suite{
+		Exchange: LIBNAME,
+		Config:   instantswap.ExchangeConfig{ApiKey: "key"},
+		Fixtures: []exchangetest.Fixture{{
+			Method: "GET", Path: "/get_estimated", Query: "currency_from=btc&currency_to=dcr&fixed=true&amount=0.1",
+			Response: `"179.3"`,
+		}, {
+			Method: "POST", Path: "/create_exchange", Query: "api_key=key", Body: `"fixed":true`,
+			Response: `{"id":"ss-43","type":"fixed","timestamp":"2023-05-01T10:00:00.000Z","updated_at":"2023-05-01T10:00:00.000Z",
+				"currency_from":"btc","currency_to":"dcr","amount_from":"0.1","expected_amount":"0.1","amount_to":"179.3",
+				"address_from":"bc1qdeposit","address_to":"DsUZxxoHJSty8DCfwfartwTYbuhmVct7tJu","status":"waiting"}`,
+		}},
+		Rate: &exchangetest.RateTest{
+			Request: instantswap.ExchangeRateRequest{From: "BTC", To: "DCR", Amount: amount("0.1"), RateType: instantswap.RateTypeFixed},
+			Expected: instantswap.ExchangeRateInfo{
+				ExchangeRate:    1793,
+				EstimatedAmount: amount("179.3"),
+				DepositAmount:   amount("0.1"),
+				RateType:        instantswap.RateTypeFixed,
+			},
+		},
+		Order: &exchangetest.OrderTest{
+			Request: instantswap.CreateOrder{
+				FromCurrency:   "BTC",
+				ToCurrency:     "DCR",
+				Destination:    "DsUZxxoHJSty8DCfwfartwTYbuhmVct7tJu",
+				InvoicedAmount: amount("0.1"),
+				RateType:       instantswap.RateTypeFixed,
+			},
+			Expected: instantswap.CreateResultInfo{
+				UUID:           "ss-43",
+				Destination:    "DsUZxxoHJSty8DCfwfartwTYbuhmVct7tJu",
+				ExchangeRate:   1793,
+				FromCurrency:   "BTC",
+				ToCurrency:     "DCR",
+				InvoicedAmount: amount("0.1"),
+				OrderedAmount:  amount("179.3"),
+				DepositAddress: "bc1qdeposit",
+			},
+		},
+	})
+}
+
+func TestSimpleSwapUnavailablePair(t *testing.T) {
+	server := exchangetest.NewServer(t, exchangetest.Fixture{
+		Method: "GET", Path: "/get_estimated", Query: "currency_from=btc&currency_to=xyz",
+		Response: `null`,
+	})
+	exchange, err := New(server.Config(instantswap.ExchangeConfig{ApiKey: "key"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = exchange.GetExchangeRateInfo(context.Background(), instantswap.ExchangeRateRequest{
+		From: "BTC", To: "XYZ", Amount: instantswap.MustParseAmount("0.1"),
+	})
+	if !errors.Is(err, instantswap.ErrPairUnavailable) {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
@@ -1,15 +1,17 @@
 package simpleswap
 
+import "github.com/crypto-power/instantswap/instantswap"
+
 type CreateExchange struct {
-	CurrencyFrom      string  `json:"currency_from"`
-	CurrencyTo        string  `json:"currency_to"`
-	Fixed             bool    `json:"fixed"`
-	Amount            float64 `json:"amount"`
-	AddressTo         string  `json:"address_to"`
-	ExtraIdTo         string  `json:"extraIdTo"`
-	UserRefundAddress string  `json:"userRefundAddress"`
-	UserRefundExtraId string  `json:"userRefundExtraId"`
-	Referral          string  `json:"referral"`
+	CurrencyFrom      string             `json:"currency_from"`
+	CurrencyTo        string             `json:"currency_to"`
+	Fixed             bool               `json:"fixed"`
+	Amount            instantswap.Amount `json:"amount"`
+	AddressTo         string             `json:"address_to"`
+	ExtraIdTo         string             `json:"extraIdTo"`
+	UserRefundAddress string             `json:"userRefundAddress"`
+	UserRefundExtraId string             `json:"userRefundExtraId"`
+	Referral          string             `json:"referral"`
 }
 
 type Error struct {
@@ -1,6 +1,7 @@
 package stealthex
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"github.com/crypto-power/instantswap/instantswap"
@@ -14,14 +15,35 @@ const (
 )
 
 type stealthex struct {
-	client *instantswap.Client
-	conf   *instantswap.ExchangeConfig
+	client  *instantswap.Client
+	conf    *instantswap.ExchangeConfig
+	apiBase string
 }
 
 func init() {
-	instantswap.RegisterExchange(LIBNAME, func(config instantswap.ExchangeConfig) (instantswap.IDExchange, error) {
+	instantswap.RegisterExchangeCtx(LIBNAME, func(config instantswap.ExchangeConfig) (instantswap.IDExchangeCtx, error) {
 		return New(config)
 	})
+	instantswap.RegisterCapabilities(LIBNAME, instantswap.Capabilities{
+		FixedRate:     true,
+		FloatingRate:  true,
+		ReverseQuote:  true,
+		PairListing:   true,
+		RefundAddress: true,
+		APIKey:        true,
+	})
+	instantswap.RegisterRateLimit(LIBNAME, instantswap.RateLimit{Rate: 2, Burst: 5})
+	instantswap.RegisterAssetMapping(LIBNAME, instantswap.AssetMapping{
+		LowerCase: true,
+		Tickers: map[instantswap.Asset]string{
+			{Symbol: "USDT", Network: instantswap.NetworkEthereum}: "usdterc20",
+			{Symbol: "USDT", Network: instantswap.NetworkTron}:     "usdttrc20",
+			{Symbol: "USDT", Network: instantswap.NetworkBSC}:      "usdtbsc",
+			{Symbol: "USDT", Network: instantswap.NetworkSolana}:   "usdtsol",
+			{Symbol: "USDC", Network: instantswap.NetworkEthereum}: "usdc",
+			{Symbol: "BNB", Network: instantswap.NetworkBSC}:       "bnbbsc",
+		},
+	})
 }
 
 // SetDebug set enable/disable http request/response dump.
@@ -32,16 +54,16 @@ func (s *stealthex) SetDebug(enable bool) {
 // New return a stealthex client.
 func New(conf instantswap.ExchangeConfig) (*stealthex, error) {
 	if conf.ApiKey == "" {
-		return nil, fmt.Errorf("%s:error: APIKEY is blank", LIBNAME)
+		return nil, instantswap.NewError(LIBNAME, instantswap.KindUnauthorized, "", "APIKEY is blank")
 	}
 	client := instantswap.NewClient(LIBNAME, &conf, func(r *http.Request, body string) error {
 		return nil
 	})
-	return &stealthex{client: client, conf: &conf}, nil
+	return &stealthex{client: client, conf: &conf, apiBase: conf.BaseURLOrDefault(API_BASE)}, nil
 }
 
-func (s *stealthex) GetCurrencies() (currencies []instantswap.Currency, err error) {
-	r, err := s.client.Do(API_BASE, http.MethodGet,
+func (s *stealthex) GetCurrencies(ctx context.Context) (currencies []instantswap.Currency, err error) {
+	r, err := s.client.Do(ctx, s.apiBase, http.MethodGet,
 		fmt.Sprintf("currency?api_key=%s&fixed=boolean", s.conf.ApiKey), "", false)
 	if err != nil {
 		return nil, err
@@ -66,8 +88,8 @@ func (s *stealthex) GetCurrencies() (currencies []instantswap.Currency, err erro
 	return currencies, nil
 }
 
-func (s *stealthex) GetCurrenciesToPair(from string) (currencies []instantswap.Currency, err error) {
-	r, err := s.client.Do(API_BASE, http.MethodGet,
+func (s *stealthex) GetCurrenciesToPair(ctx context.Context, from string) (currencies []instantswap.Currency, err error) {
+	r, err := s.client.Do(ctx, s.apiBase, http.MethodGet,
 		fmt.Sprintf("pairs/%s?api_key=%s", strings.ToLower(from), s.conf.ApiKey), "", false)
 	if err != nil {
 		return nil, err
@@ -89,10 +111,18 @@ func (s *stealthex) GetCurrenciesToPair(from string) (currencies []instantswap.C
 	return currencies, nil
 }
 
-func (s *stealthex) estimateAmount(vars instantswap.ExchangeRateRequest) (res instantswap.ExchangeRateInfo, err error) {
-	r, err := s.client.Do(API_BASE, http.MethodGet,
-		fmt.Sprintf("estimate/%s/%s?api_key=%s&fixed=true&amount=%.8f",
-			strings.ToLower(vars.From), strings.ToLower(vars.To), s.conf.ApiKey, vars.Amount), "", false)
+func (s *stealthex) estimateAmount(ctx context.Context, vars instantswap.ExchangeRateRequest) (res instantswap.ExchangeRateInfo, err error) {
+	reverse := vars.Direction == instantswap.DirectionTo
+	amount := vars.Amount.TruncateFor(vars.From, vars.FromNetwork)
+	if reverse {
+		amount = vars.Amount.TruncateFor(vars.To, vars.ToNetwork)
+	}
+	path := fmt.Sprintf("estimate/%s/%s?api_key=%s&fixed=%t&amount=%s",
+		strings.ToLower(vars.From), strings.ToLower(vars.To), s.conf.ApiKey, isFixed(vars.RateType), amount)
+	if reverse {
+		path += "&reverse=true"
+	}
+	r, err := s.client.Do(ctx, s.apiBase, http.MethodGet, path, "", false)
 	if err != nil {
 		return res, err
 	}
@@ -102,15 +132,24 @@ func (s *stealthex) estimateAmount(vars instantswap.ExchangeRateRequest) (res in
 		return res, err
 	}
 	res.EstimatedAmount = estimate.EstimatedAmount
-	res.ExchangeRate = vars.Amount / estimate.EstimatedAmount
+	res.DepositAmount = vars.Amount
+	if reverse {
+		// The estimate of a reverse request is the deposit.
+		res.EstimatedAmount, res.DepositAmount = vars.Amount, estimate.EstimatedAmount
+	}
+	res.ExchangeRate = instantswap.ExchangeRate(res.EstimatedAmount, res.DepositAmount)
 	res.Signature = estimate.RateId
+	res.RateType = instantswap.RateTypeFloat
+	if isFixed(vars.RateType) {
+		res.RateType = instantswap.RateTypeFixed
+	}
 	return res, nil
 }
 
-func (s *stealthex) getRange(vars instantswap.ExchangeRateRequest) (*Range, error) {
-	body, err := s.client.Do(API_BASE, http.MethodGet,
-		fmt.Sprintf("range/%s/%s?api_key=%s&fixed=true",
-			strings.ToLower(vars.From), strings.ToLower(vars.To), s.conf.ApiKey), "", false)
+func (s *stealthex) getRange(ctx context.Context, vars instantswap.ExchangeRateRequest) (*Range, error) {
+	body, err := s.client.Do(ctx, s.apiBase, http.MethodGet,
+		fmt.Sprintf("range/%s/%s?api_key=%s&fixed=%t",
+			strings.ToLower(vars.From), strings.ToLower(vars.To), s.conf.ApiKey, isFixed(vars.RateType)), "", false)
 	if err != nil {
 		return nil, err
 	}
@@ -119,37 +158,46 @@ func (s *stealthex) getRange(vars instantswap.ExchangeRateRequest) (*Range, erro
 	return &r, err
 }
 
-func (s *stealthex) GetExchangeRateInfo(vars instantswap.ExchangeRateRequest) (res instantswap.ExchangeRateInfo, err error) {
-	res, err = s.estimateAmount(vars)
+// isFixed reports whether the orders of type t are fixed rate, the default
+// type of stealthex.
+func isFixed(t instantswap.RateType) bool {
+	return t != instantswap.RateTypeFloat
+}
+
+func (s *stealthex) GetExchangeRateInfo(ctx context.Context, vars instantswap.ExchangeRateRequest) (res instantswap.ExchangeRateInfo, err error) {
+	res, err = s.estimateAmount(ctx, vars)
 	if err != nil {
 		return res, err
 	}
-	r, err := s.getRange(vars)
-	if err != nil {
+	r, err := s.getRange(ctx, vars)
+	if err == nil {
 		res.Min = r.MinAmount
 		res.Max = r.MaxAmount
 	}
 	return res, nil
 }
 
-func (s *stealthex) QueryLimits(fromCurr, toCurr string) (res instantswap.QueryLimits, err error) {
-	return res, fmt.Errorf("not supported")
+func (s *stealthex) QueryLimits(ctx context.Context, fromCurr, toCurr string) (res instantswap.QueryLimits, err error) {
+	return res, instantswap.ErrNotSupported
 }
 
-func (s *stealthex) CreateOrder(vars instantswap.CreateOrder) (res instantswap.CreateResultInfo, err error) {
+func (s *stealthex) CreateOrder(ctx context.Context, vars instantswap.CreateOrder) (res instantswap.CreateResultInfo, err error) {
 	var req = OrderRequest{
 		CurrencyFrom:  vars.FromCurrency,
 		CurrencyTo:    vars.ToCurrency,
 		AddressTo:     vars.Destination,
-		AmountFrom:    vars.InvoicedAmount,
+		AmountFrom:    vars.InvoicedAmount.TruncateFor(vars.FromCurrency, vars.FromNetwork),
 		RateId:        vars.Signature,
 		RefundAddress: vars.RefundAddress,
 		RefundExtraId: vars.RefundExtraID,
 		Provider:      vars.Provider,
-		Fixed:         true,
+		Fixed:         isFixed(vars.RateType),
+	}
+	if !req.Fixed {
+		req.RateId = ""
 	}
 	body, _ := json.Marshal(req)
-	r, err := s.client.Do(API_BASE, http.MethodPost, fmt.Sprintf("exchange?api_key=%s", s.conf.ApiKey), string(body), false)
+	r, err := s.client.Do(ctx, s.apiBase, http.MethodPost, fmt.Sprintf("exchange?api_key=%s", s.conf.ApiKey), string(body), false)
 	if err != nil {
 		return res, err
 	}
@@ -159,9 +207,9 @@ func (s *stealthex) CreateOrder(vars instantswap.CreateOrder) (res instantswap.C
 		return res, err
 	}
 	res = instantswap.CreateResultInfo{
-		ChargedFee:     0,
+		ChargedFee:     instantswap.Amount{},
 		Destination:    order.AddressTo,
-		ExchangeRate:   order.AmountFrom / order.AmountTo,
+		ExchangeRate:   instantswap.ExchangeRate(order.AmountTo, order.AmountFrom),
 		FromCurrency:   order.CurrencyFrom,
 		InvoicedAmount: order.AmountFrom,
 		OrderedAmount:  order.AmountTo,
@@ -175,15 +223,15 @@ func (s *stealthex) CreateOrder(vars instantswap.CreateOrder) (res instantswap.C
 	return res, nil
 }
 
-func (s *stealthex) UpdateOrder(vars interface{}) (res instantswap.UpdateOrderResultInfo, err error) {
-	return
+func (s *stealthex) UpdateOrder(ctx context.Context, vars interface{}) (res instantswap.UpdateOrderResultInfo, err error) {
+	return res, instantswap.ErrNotSupported
 }
-func (s *stealthex) CancelOrder(orderID string) (res string, err error) {
-	return
+func (s *stealthex) CancelOrder(ctx context.Context, orderID string) (res string, err error) {
+	return res, instantswap.ErrNotSupported
 }
 
-func (s *stealthex) OrderInfo(orderID string, extraIds ...string) (res instantswap.OrderInfoResult, err error) {
-	r, err := s.client.Do(API_BASE, http.MethodGet, fmt.Sprintf("exchange/%s?api_key=%s", orderID, s.conf.ApiKey), "", false)
+func (s *stealthex) OrderInfo(ctx context.Context, orderID string, extraIds ...string) (res instantswap.OrderInfoResult, err error) {
+	r, err := s.client.Do(ctx, s.apiBase, http.MethodGet, fmt.Sprintf("exchange/%s?api_key=%s", orderID, s.conf.ApiKey), "", false)
 	if err != nil {
 		return res, err
 	}
@@ -204,8 +252,8 @@ func (s *stealthex) OrderInfo(orderID string, extraIds ...string) (res instantsw
 	return
 }
 
-func (s *stealthex) EstimateAmount(vars interface{}) (res instantswap.EstimateAmount, err error) {
-	return
+func (s *stealthex) EstimateAmount(ctx context.Context, vars interface{}) (res instantswap.EstimateAmount, err error) {
+	return res, instantswap.ErrNotSupported
 }
 
 func parseResponseData(data []byte, obj interface{}) error {
@@ -0,0 +1,170 @@
+package stealthex
+
+import (
+	"context"
+	"testing"
+
+	"github.com/crypto-power/instantswap/instantswap"
+	"github.com/crypto-power/instantswap/instantswap/exchangetest"
+)
+
+func TestStealthEx(t *testing.T) {
+	amount := instantswap.MustParseAmount
+	exchangetest.Run(t, exchangetest.Suite{
+		Exchange: LIBNAME,
+		Config:   instantswap.ExchangeConfig{ApiKey: "key"},
+		Fixtures: []exchangetest.Fixture{{
+			Method: "GET", Path: "/api/v2/currency", Query: "api_key=key",
+			Response: `[{"symbol":"btc","network":"BTC","has_extra_id":false,"name":"Bitcoin"},
+				{"symbol":"dcr","network":"DCR","has_extra_id":false,"name":"Decred"},
+				{"symbol":"ltc","network":"LTC","has_extra_id":false,"name":"Litecoin"}]`,
+		}, {
+			Method: "GET", Path: "/api/v2/pairs/btc", Query: "api_key=key",
+			Response: `["dcr","ltc"]`,
+		}, {
+			Method: "GET", Path: "/api/v2/estimate/btc/dcr", Query: "fixed=true&amount=0.1",
+			Response: `{"estimated_amount":"180.5","rate_id":"rate-7"}`,
+		}, {
+			Method: "GET", Path: "/api/v2/range/btc/dcr", Query: "fixed=true",
+			Response: `{"min_amount":"0.0009","max_amount":"3.1"}`,
+		}, {
+			Method: "POST", Path: "/api/v2/exchange", Query: "api_key=key", Body: `"rate_id":"rate-7"`,
+			Response: `{"id":"sx-1","type":"fixed","timestamp":"2023-05-01T10:00:00Z","updated_at":"2023-05-01T10:00:00Z",
+				"currency_from":"btc","currency_to":"dcr","amount_from":"0.1","expected_amount":"0.1","amount_to":"180.5",
+				"address_from":"bc1qdeposit","address_to":"DsUZxxoHJSty8DCfwfartwTYbuhmVct7tJu","status":"waiting","refund_address":"bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t4"}`,
+		}, {
+			Method: "GET", Path: "/api/v2/exchange/sx-1", Query: "api_key=key",
+			Response: `{"id":"sx-1","type":"fixed","timestamp":"2023-05-01T10:00:00Z","updated_at":"2023-05-01T10:40:00Z",
+				"currency_from":"btc","currency_to":"dcr","amount_from":"0.1","amount_to":"180.5",
+				"address_from":"bc1qdeposit","address_to":"DsUZxxoHJSty8DCfwfartwTYbuhmVct7tJu","tx_to":"dcrtxhash","status":"finished"}`,
+		}},
+		Currencies: []string{"BTC", "DCR", "LTC"},
+		PairFrom:   "BTC",
+		Pairs:      []string{"DCR", "LTC"},
+		Rate: &exchangetest.RateTest{
+			Request: instantswap.ExchangeRateRequest{From: "BTC", To: "DCR", Amount: amount("0.1")},
+			Expected: instantswap.ExchangeRateInfo{
+				Min:             amount("0.0009"),
+				Max:             amount("3.1"),
+				ExchangeRate:    1805,
+				EstimatedAmount: amount("180.5"),
+				DepositAmount:   amount("0.1"),
+				Signature:       "rate-7",
+				RateType:        instantswap.RateTypeFixed,
+			},
+		},
+		Order: &exchangetest.OrderTest{
+			Request: instantswap.CreateOrder{
+				FromCurrency:   "btc",
+				ToCurrency:     "dcr",
+				Destination:    "DsUZxxoHJSty8DCfwfartwTYbuhmVct7tJu",
+				RefundAddress:  "bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t4",
+				InvoicedAmount: amount("0.1"),
+				Signature:      "rate-7",
+			},
+			Expected: instantswap.CreateResultInfo{
+				UUID:           "sx-1",
+				Destination:    "DsUZxxoHJSty8DCfwfartwTYbuhmVct7tJu",
+				ExchangeRate:   1805,
+				FromCurrency:   "BTC",
+				ToCurrency:     "DCR",
+				InvoicedAmount: amount("0.1"),
+				OrderedAmount:  amount("180.5"),
+				DepositAddress: "bc1qdeposit",
+			},
+		},
+		Info: &exchangetest.InfoTest{
+			OrderID: "sx-1",
+			Expected: instantswap.OrderInfoResult{
+				ReceiveAmount:  amount("180.5"),
+				TxID:           "dcrtxhash",
+				Status:         "finished",
+				InternalStatus: instantswap.OrderStatusCompleted,
+			},
+		},
+		StatusFunc: parseStatus,
+		Statuses: map[string]instantswap.Status{
+			"waiting":    instantswap.OrderStatusWaitingForDeposit,
+			"confirming": instantswap.OrderStatusDepositReceived,
+			"verifying":  instantswap.OrderStatusDepositReceived,
+			"exchanging": instantswap.OrderStatusExchanging,
+			"sending":    instantswap.OrderStatusSending,
+			"finished":   instantswap.OrderStatusCompleted,
+			"failed":     instantswap.OrderStatusFailed,
+			"refunded":   instantswap.OrderStatusRefunded,
+			"expired":    instantswap.OrderStatusUnknown,
+		},
+	})
+}
+
+func TestStealthExFloat(t *testing.T) {
+	amount := instantswap.MustParseAmount
+	exchangetest.Run(t, exchangetest.Suite{
+		Exchange: LIBNAME,
+		Config:   instantswap.ExchangeConfig{ApiKey: "key"},
+		Fixtures: []exchangetest.Fixture{{
+			Method: "GET", Path: "/api/v2/estimate/btc/dcr", Query: "fixed=false&amount=0.1",
+			Response: `{"estimated_amount":"181.6","rate_id":null}`,
+		}, {
+			Method: "GET", Path: "/api/v2/range/btc/dcr", Query: "fixed=false",
+			Response: `{"min_amount":"0.0005","max_amount":null}`,
+		}, {
+			Method: "POST", Path: "/api/v2/exchange", Query: "api_key=key", Body: `"fixed":false`,
+			Response: `{"id":"sx-2","type":"floating","timestamp":"2023-05-01T10:00:00Z","updated_at":"2023-05-01T10:00:00Z",
+				"currency_from":"btc","currency_to":"dcr","amount_from":"0.1","expected_amount":"0.1","amount_to":"181.6",
+				"address_from":"bc1qdeposit","address_to":"DsUZxxoHJSty8DCfwfartwTYbuhmVct7tJu","status":"waiting"}`,
+		}},
+		Rate: &exchangetest.RateTest{
+			Request: instantswap.ExchangeRateRequest{From: "BTC", To: "DCR", Amount: amount("0.1"), RateType: instantswap.RateTypeFloat},
+			Expected: instantswap.ExchangeRateInfo{
+				Min:             amount("0.0005"),
+				ExchangeRate:    1816,
+				EstimatedAmount: amount("181.6"),
+				DepositAmount:   amount("0.1"),
+				RateType:        instantswap.RateTypeFloat,
+			},
+		},
+		Order: &exchangetest.OrderTest{
+			Request: instantswap.CreateOrder{
+				FromCurrency:   "btc",
+				ToCurrency:     "dcr",
+				Destination:    "DsUZxxoHJSty8DCfwfartwTYbuhmVct7tJu",
+				InvoicedAmount: amount("0.1"),
+				RateType:       instantswap.RateTypeFloat,
+			},
+			Expected: instantswap.CreateResultInfo{
+				UUID:           "sx-2",
+				Destination:    "DsUZxxoHJSty8DCfwfartwTYbuhmVct7tJu",
+				ExchangeRate:   1816,
+				FromCurrency:   "BTC",
+				ToCurrency:     "DCR",
+				InvoicedAmount: amount("0.1"),
+				OrderedAmount:  amount("181.6"),
+				DepositAddress: "bc1qdeposit",
+			},
+		},
+	})
+}
+
+func TestStealthExRangeError(t *testing.T) {
+	server := exchangetest.NewServer(t, exchangetest.Fixture{
+		Method: "GET", Path: "/api/v2/estimate/btc/dcr",
+		Response: `{"estimated_amount":"180.5","rate_id":"rate-7"}`,
+	}, exchangetest.Fixture{
+		Method: "GET", Path: "/api/v2/range/btc/dcr",
+		Status: 500, Response: `{"err":"internal error"}`,
+	})
+	exchange, err := New(server.Config(instantswap.ExchangeConfig{ApiKey: "key"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rate, err := exchange.GetExchangeRateInfo(context.Background(), instantswap.ExchangeRateRequest{
+		From: "BTC", To: "DCR", Amount: instantswap.MustParseAmount("0.1"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !rate.Min.IsZero() || !rate.Max.IsZero() || rate.Signature != "rate-7" {
+		t.Errorf("unexpected rate %+v", rate)
+	}
+}
@@ -1,6 +1,10 @@
 package swapzone
 
-import "time"
+import (
+	"time"
+
+	"github.com/crypto-power/instantswap/instantswap"
+)
 
 type SwapzoneError struct {
 	Error   bool   `json:"error"`
@@ -8,17 +12,17 @@ type SwapzoneError struct {
 }
 
 type ExchangeRate struct {
-	Adapter     string    `json:"adapter"`
-	From        string    `json:"from"`
-	FromNetwork string    `json:"fromNetwork"`
-	To          string    `json:"to"`
-	ToNetwork   string    `json:"toNetwork"`
-	AmountFrom  float64   `json:"amountFrom"`
-	AmountTo    float64   `json:"amountTo"`
-	MinAmount   float64   `json:"minAmount"`
-	MaxAmount   float64   `json:"maxAmount"`
-	QuotaId     string    `json:"quotaId"`
-	ValidUntil  time.Time `json:"validUntil"`
+	Adapter     string             `json:"adapter"`
+	From        string             `json:"from"`
+	FromNetwork string             `json:"fromNetwork"`
+	To          string             `json:"to"`
+	ToNetwork   string             `json:"toNetwork"`
+	AmountFrom  instantswap.Amount `json:"amountFrom"`
+	AmountTo    instantswap.Amount `json:"amountTo"`
+	MinAmount   instantswap.Amount `json:"minAmount"`
+	MaxAmount   instantswap.Amount `json:"maxAmount"`
+	QuotaId     string             `json:"quotaId"`
+	ValidUntil  time.Time          `json:"validUntil"`
 }
 
 type Order struct {
@@ -1,6 +1,7 @@
 package swapzone
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -8,7 +9,6 @@ import (
 	"strings"
 
 	"github.com/crypto-power/instantswap/instantswap"
-	"github.com/crypto-power/instantswap/instantswap/utils"
 )
 
 const (
@@ -17,29 +17,46 @@ const (
 )
 
 func init() {
-	instantswap.RegisterExchange(LIBNAME, func(config instantswap.ExchangeConfig) (instantswap.IDExchange, error) {
+	instantswap.RegisterExchangeCtx(LIBNAME, func(config instantswap.ExchangeConfig) (instantswap.IDExchangeCtx, error) {
 		return New(config)
 	})
+	instantswap.RegisterCapabilities(LIBNAME, instantswap.Capabilities{
+		FloatingRate:  true,
+		Networks:      true,
+		RefundAddress: true,
+		APIKey:        true,
+	})
+	instantswap.RegisterRateLimit(LIBNAME, instantswap.RateLimit{Rate: 1, Burst: 3})
+	instantswap.RegisterAssetMapping(LIBNAME, instantswap.AssetMapping{
+		LowerCase:    true,
+		CoinNetworks: true,
+		Networks: map[string]string{
+			instantswap.NetworkBSC:      "BSC",
+			instantswap.NetworkArbitrum: "ARBITRUM",
+			instantswap.NetworkOptimism: "OPTIMISM",
+		},
+	})
 }
 
 // New return a SwapZone client.
 func New(conf instantswap.ExchangeConfig) (*SwapZone, error) {
 	if conf.ApiKey == "" {
-		return nil, fmt.Errorf("%s:error: APIKEY is blank", LIBNAME)
+		return nil, instantswap.NewError(LIBNAME, instantswap.KindUnauthorized, "", "APIKEY is blank")
 	}
 	client := instantswap.NewClient(LIBNAME, &conf, func(r *http.Request, body string) error {
 		r.Header.Set("x-api-key", conf.ApiKey)
 		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
 		return nil
 	})
-	return &SwapZone{client: client, conf: &conf}, nil
+	client.SetErrorParser(parseError)
+	return &SwapZone{client: client, conf: &conf, apiBase: conf.BaseURLOrDefault(API_BASE)}, nil
 }
 
 // SwapZone represent a SwapZone client.
 type SwapZone struct {
-	client *instantswap.Client
-	conf   *instantswap.ExchangeConfig
-	instantswap.IDExchange
+	client  *instantswap.Client
+	conf    *instantswap.ExchangeConfig
+	apiBase string
 }
 
 // SetDebug set enable/disable http request/response dump.
@@ -47,9 +64,9 @@ func (c *SwapZone) SetDebug(enable bool) {
 	c.conf.Debug = enable
 }
 
-func (c *SwapZone) GetCurrencies() (currencies []instantswap.Currency, err error) {
+func (c *SwapZone) GetCurrencies(ctx context.Context) (currencies []instantswap.Currency, err error) {
 	var r []byte
-	r, err = c.client.Do(API_BASE, "GET", "exchange/currencies", "", false)
+	r, err = c.client.Do(ctx, c.apiBase, "GET", "exchange/currencies", "", false)
 	if err != nil {
 		return
 	}
@@ -61,41 +78,45 @@ func (c *SwapZone) GetCurrencies() (currencies []instantswap.Currency, err error
 	currencies = make([]instantswap.Currency, len(szCurrencies))
 	for i, curr := range szCurrencies {
 		currencies[i] = instantswap.Currency{
-			Name:   curr.Name,
-			Symbol: curr.Ticker,
+			Name:     curr.Name,
+			Symbol:   curr.Ticker,
+			Networks: networks(curr.Network),
 		}
 	}
 	return
 }
-func (c *SwapZone) GetCurrenciesToPair(from string) (currencies []instantswap.Currency, err error) {
-	var r []byte
-	r, err = c.client.Do(API_BASE, "GET", "exchange/currencies", "", false)
-	if err != nil {
-		return
+
+// networks returns the networks of a currency of the list, none if network is
+// empty.
+func networks(network string) []string {
+	if network == "" {
+		return nil
 	}
-	var szCurrencies []Currency
-	err = parseResponseData(r, &szCurrencies)
-	if err != nil {
-		return
+	return []string{network}
+}
+
+// networkParams returns the query parameters of the networks which are set.
+func networkParams(fromNetwork, toNetwork string) string {
+	var params string
+	if fromNetwork != "" {
+		params += "&fromNetwork=" + url.QueryEscape(fromNetwork)
 	}
-	currencies = []instantswap.Currency{}
-	for _, curr := range szCurrencies {
-		if strings.ToLower(curr.Ticker) == strings.ToLower(from) {
-			continue
-		}
-		currencies = append(currencies, instantswap.Currency{
-			Name:   curr.Name,
-			Symbol: curr.Ticker,
-		})
+	if toNetwork != "" {
+		params += "&toNetwork=" + url.QueryEscape(toNetwork)
 	}
-	return
+	return params
 }
 
-func (c *SwapZone) GetExchangeRateInfo(vars instantswap.ExchangeRateRequest) (res instantswap.ExchangeRateInfo, err error) {
+func (c *SwapZone) GetCurrenciesToPair(ctx context.Context, from string) (currencies []instantswap.Currency, err error) {
+	return currencies, instantswap.ErrNotSupported
+}
+
+func (c *SwapZone) GetExchangeRateInfo(ctx context.Context, vars instantswap.ExchangeRateRequest) (res instantswap.ExchangeRateInfo, err error) {
 	var r []byte
-	r, err = c.client.Do(API_BASE, "GET",
-		fmt.Sprintf("exchange/get-rate?from=%s&to=%s&amount=%.8f&rateType=all&availableInUSA=false&chooseRate=best&noRefundAddress=false",
-			strings.ToLower(vars.From), strings.ToLower(vars.To), vars.Amount),
+	r, err = c.client.Do(ctx, c.apiBase, "GET",
+		fmt.Sprintf("exchange/get-rate?from=%s&to=%s&amount=%s&rateType=all&availableInUSA=false&chooseRate=best&noRefundAddress=false",
+			strings.ToLower(vars.From), strings.ToLower(vars.To), vars.Amount.TruncateFor(vars.From, vars.FromNetwork))+
+			networkParams(vars.FromNetwork, vars.ToNetwork),
 		"", false)
 	if err != nil {
 		return
@@ -108,28 +129,29 @@ func (c *SwapZone) GetExchangeRateInfo(vars instantswap.ExchangeRateRequest) (re
 	res.Min = exchangeRate.MinAmount
 	res.Max = exchangeRate.MaxAmount
 	res.EstimatedAmount = exchangeRate.AmountTo
-	res.ExchangeRate = exchangeRate.AmountTo / exchangeRate.AmountFrom
+	res.ExchangeRate = instantswap.ExchangeRate(exchangeRate.AmountTo, exchangeRate.AmountFrom)
 	res.Signature = exchangeRate.QuotaId
+	res.Provider = exchangeRate.Adapter
 	return
 }
 
-func (c *SwapZone) QueryRates(vars interface{}) (res []instantswap.QueryRate, err error) {
-	return res, fmt.Errorf("not supported")
+func (c *SwapZone) QueryRates(ctx context.Context, vars interface{}) (res []instantswap.QueryRate, err error) {
+	return res, instantswap.ErrNotSupported
 }
 
-func (c *SwapZone) QueryActiveCurrencies(vars interface{}) (res []instantswap.ActiveCurr, err error) {
-	return
+func (c *SwapZone) QueryActiveCurrencies(ctx context.Context, vars interface{}) (res []instantswap.ActiveCurr, err error) {
+	return res, instantswap.ErrNotSupported
 }
 
-func (c *SwapZone) QueryLimits(fromCurr, toCurr string) (res instantswap.QueryLimits, err error) {
-	return
+func (c *SwapZone) QueryLimits(ctx context.Context, fromCurr, toCurr string) (res instantswap.QueryLimits, err error) {
+	return res, instantswap.ErrNotSupported
 }
 
-func (c *SwapZone) CreateOrder(vars instantswap.CreateOrder) (res instantswap.CreateResultInfo, err error) {
+func (c *SwapZone) CreateOrder(ctx context.Context, vars instantswap.CreateOrder) (res instantswap.CreateResultInfo, err error) {
 	var form = make(url.Values)
 	form.Set("from", strings.ToLower(vars.FromCurrency))
 	form.Set("to", strings.ToLower(vars.ToCurrency))
-	form.Set("amountDeposit", fmt.Sprintf("%.8f", vars.InvoicedAmount))
+	form.Set("amountDeposit", vars.InvoicedAmount.TruncateFor(vars.FromCurrency, vars.FromNetwork).String())
 	form.Set("addressReceive", vars.Destination)
 	form.Set("extraIdReceive", "") // Memo tag (optional)
 	form.Set("refundAddress", vars.RefundAddress)
@@ -137,9 +159,15 @@ func (c *SwapZone) CreateOrder(vars instantswap.CreateOrder) (res instantswap.Cr
 	if len(vars.Signature) > 0 {
 		form.Set("quotaId", vars.Signature)
 	}
+	if vars.FromNetwork != "" {
+		form.Set("fromNetwork", vars.FromNetwork)
+	}
+	if vars.ToNetwork != "" {
+		form.Set("toNetwork", vars.ToNetwork)
+	}
 
 	var r []byte
-	r, err = c.client.Do(API_BASE, "POST", "exchange/create",
+	r, err = c.client.Do(ctx, c.apiBase, "POST", "exchange/create",
 		form.Encode(), false)
 	if err != nil {
 		return
@@ -150,12 +178,12 @@ func (c *SwapZone) CreateOrder(vars instantswap.CreateOrder) (res instantswap.Cr
 		return
 	}
 	var order = tx.Transaction
-	var invoicedAmount = utils.StrToFloat(order.AmountDeposit)
-	var orderedAmount = utils.StrToFloat(order.AmountEstimated)
+	var invoicedAmount = instantswap.AmountFromString(order.AmountDeposit)
+	var orderedAmount = instantswap.AmountFromString(order.AmountEstimated)
 	res = instantswap.CreateResultInfo{
-		ChargedFee:     0,
+		ChargedFee:     instantswap.Amount{},
 		Destination:    order.AddressReceive,
-		ExchangeRate:   invoicedAmount / orderedAmount,
+		ExchangeRate:   instantswap.ExchangeRate(orderedAmount, invoicedAmount),
 		FromCurrency:   order.From,
 		InvoicedAmount: invoicedAmount,
 		OrderedAmount:  orderedAmount,
@@ -170,17 +198,17 @@ func (c *SwapZone) CreateOrder(vars instantswap.CreateOrder) (res instantswap.Cr
 }
 
 // UpdateOrder accepts orderID value and more if needed per lib.
-func (c *SwapZone) UpdateOrder(vars interface{}) (res instantswap.UpdateOrderResultInfo, err error) {
-	return
+func (c *SwapZone) UpdateOrder(ctx context.Context, vars interface{}) (res instantswap.UpdateOrderResultInfo, err error) {
+	return res, instantswap.ErrNotSupported
 }
-func (c *SwapZone) CancelOrder(orderID string) (res string, err error) {
-	return
+func (c *SwapZone) CancelOrder(ctx context.Context, orderID string) (res string, err error) {
+	return res, instantswap.ErrNotSupported
 }
 
 // OrderInfo accepts orderID value and more if needed per lib.
-func (c *SwapZone) OrderInfo(orderID string, extraIds ...string) (res instantswap.OrderInfoResult, err error) {
+func (c *SwapZone) OrderInfo(ctx context.Context, orderID string, extraIds ...string) (res instantswap.OrderInfoResult, err error) {
 	var r []byte
-	r, err = c.client.Do(API_BASE, "GET",
+	r, err = c.client.Do(ctx, c.apiBase, "GET",
 		fmt.Sprintf("exchange/tx?id=%s", orderID),
 		"", false)
 	if err != nil {
@@ -195,27 +223,24 @@ func (c *SwapZone) OrderInfo(orderID string, extraIds ...string) (res instantswa
 	res = instantswap.OrderInfoResult{
 		Expires:        0,
 		LastUpdate:     "",
-		ReceiveAmount:  0,
+		ReceiveAmount:  instantswap.AmountFromString(order.AmountEstimated),
 		TxID:           "",
 		Status:         order.Status,
 		InternalStatus: GetLocalStatus(order.Status),
 		Confirmations:  "",
 	}
-	if order.Status == "" {
-		res.ReceiveAmount = utils.StrToFloat(order.AmountEstimated)
-	}
 	return
 }
 
-func (c *SwapZone) EstimateAmount(vars interface{}) (res instantswap.EstimateAmount, err error) {
-	return
+func (c *SwapZone) EstimateAmount(ctx context.Context, vars interface{}) (res instantswap.EstimateAmount, err error) {
+	return res, instantswap.ErrNotSupported
 }
 
 func parseResponseData(data []byte, obj interface{}) error {
 	var swapzoneErr SwapzoneError
 	err := json.Unmarshal(data, &swapzoneErr)
 	if err == nil && swapzoneErr.Error {
-		return fmt.Errorf(swapzoneErr.Message)
+		return instantswap.NewError(LIBNAME, instantswap.ClassifyMessage(swapzoneErr.Message), "", swapzoneErr.Message)
 	}
 	err = json.Unmarshal(data, obj)
 	if err != nil {
@@ -248,3 +273,11 @@ func GetLocalStatus(status string) instantswap.Status {
 		return instantswap.OrderStatusUnknown
 	}
 }
+
+// parseError decodes the body of an error response.
+func parseError(e *instantswap.Error, body []byte) {
+	var swapzoneErr SwapzoneError
+	if json.Unmarshal(body, &swapzoneErr) == nil && swapzoneErr.Message != "" {
+		e.Message = swapzoneErr.Message
+	}
+}
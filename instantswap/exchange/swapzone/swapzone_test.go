@@ -0,0 +1,123 @@
+package swapzone
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/crypto-power/instantswap/instantswap"
+	"github.com/crypto-power/instantswap/instantswap/exchangetest"
+)
+
+const orderFixture = `{"transaction":{"id":"sz-9","quotaId":"quota-3","from":"btc","fromNetwork":"BTC","to":"dcr","toNetwork":"DCR",
+	"status":"%s","addressReceive":"DsUZxxoHJSty8DCfwfartwTYbuhmVct7tJu","addressDeposit":"bc1qdeposit","amountDeposit":"0.1","amountEstimated":"180.3",
+	"createdAt":"2023-05-01T10:00:00.000Z","refundAddress":"bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t4"}}`
+
+func TestSwapZone(t *testing.T) {
+	amount := instantswap.MustParseAmount
+	exchangetest.Run(t, exchangetest.Suite{
+		Exchange: LIBNAME,
+		Config:   instantswap.ExchangeConfig{ApiKey: "key"},
+		Fixtures: []exchangetest.Fixture{{
+			Method: "GET", Path: "/v1/exchange/currencies",
+			Response: `[{"name":"Bitcoin","ticker":"btc","network":"BTC","smartContract":null},
+				{"name":"Decred","ticker":"dcr","network":"DCR","smartContract":null},
+				{"name":"Litecoin","ticker":"ltc","network":"LTC","smartContract":null}]`,
+		}, {
+			Method: "GET", Path: "/v1/exchange/get-rate", Query: "from=btc&to=dcr&amount=0.1",
+			Response: `{"adapter":"changenow","from":"btc","fromNetwork":"BTC","to":"dcr","toNetwork":"DCR",
+				"amountFrom":0.1,"amountTo":180.3,"minAmount":0.0012,"maxAmount":2.5,"quotaId":"quota-3",
+				"validUntil":"2023-05-01T10:05:00.000Z"}`,
+		}, {
+			Method: "POST", Path: "/v1/exchange/create", Body: "quotaId=quota-3",
+			Response: fmt.Sprintf(orderFixture, "waiting"),
+		}, {
+			Method: "GET", Path: "/v1/exchange/tx", Query: "id=sz-9",
+			Response: fmt.Sprintf(orderFixture, "finished"),
+		}},
+		Currencies: []string{"BTC", "DCR", "LTC"},
+		Rate: &exchangetest.RateTest{
+			Request: instantswap.ExchangeRateRequest{From: "BTC", To: "DCR", Amount: amount("0.1")},
+			Expected: instantswap.ExchangeRateInfo{
+				Min:             amount("0.0012"),
+				Max:             amount("2.5"),
+				ExchangeRate:    1803,
+				EstimatedAmount: amount("180.3"),
+				DepositAmount:   amount("0.1"),
+				Signature:       "quota-3",
+				Provider:        "changenow",
+				RateType:        instantswap.RateTypeFloat,
+			},
+		},
+		Order: &exchangetest.OrderTest{
+			Request: instantswap.CreateOrder{
+				FromCurrency:   "BTC",
+				ToCurrency:     "DCR",
+				Destination:    "DsUZxxoHJSty8DCfwfartwTYbuhmVct7tJu",
+				RefundAddress:  "bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t4",
+				InvoicedAmount: amount("0.1"),
+				Signature:      "quota-3",
+			},
+			Expected: instantswap.CreateResultInfo{
+				UUID:           "sz-9",
+				Destination:    "DsUZxxoHJSty8DCfwfartwTYbuhmVct7tJu",
+				ExchangeRate:   1803,
+				FromCurrency:   "BTC",
+				ToCurrency:     "DCR",
+				InvoicedAmount: amount("0.1"),
+				OrderedAmount:  amount("180.3"),
+				DepositAddress: "bc1qdeposit",
+			},
+		},
+		Info: &exchangetest.InfoTest{
+			OrderID: "sz-9",
+			Expected: instantswap.OrderInfoResult{
+				ReceiveAmount:  amount("180.3"),
+				Status:         "finished",
+				InternalStatus: instantswap.OrderStatusCompleted,
+			},
+		},
+		StatusFunc: GetLocalStatus,
+		Statuses: map[string]instantswap.Status{
+			"waiting":    instantswap.OrderStatusNew,
+			"confirming": instantswap.OrderStatusDepositReceived,
+			"exchanging": instantswap.OrderStatusExchanging,
+			"sending":    instantswap.OrderStatusSending,
+			"finished":   instantswap.OrderStatusCompleted,
+			"refunded":   instantswap.OrderStatusRefunded,
+			"failed":     instantswap.OrderStatusFailed,
+			"overdue":    instantswap.OrderStatusExpired,
+			"hold":       instantswap.OrderStatusUnknown,
+		},
+	})
+}
+
+func TestSwapZoneNetworks(t *testing.T) {
+	server := exchangetest.NewServer(t, exchangetest.Fixture{
+		Method: "GET", Path: "/v1/exchange/currencies",
+		Response: `[{"name":"Tether","ticker":"usdt","network":"TRX","smartContract":"TR7NHqjeKQxGTCi8q8ZY4pL8otSzgjLj6t"}]`,
+	}, exchangetest.Fixture{
+		Method: "GET", Path: "/v1/exchange/get-rate", Query: "fromNetwork=BTC&toNetwork=TRX",
+		Response: `{"adapter":"changenow","from":"btc","fromNetwork":"BTC","to":"usdt","toNetwork":"TRX",
+			"amountFrom":0.1,"amountTo":2900,"quotaId":"quota-4"}`,
+	})
+	exchange, err := instantswap.NewExchangeCtx(LIBNAME, server.Config(instantswap.ExchangeConfig{ApiKey: "key"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	currencies, err := exchange.GetCurrencies(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c := currencies[0]; c.Symbol != "USDT" || len(c.Networks) != 1 || c.Networks[0] != instantswap.NetworkTron {
+		t.Errorf("unexpected currency %+v", c)
+	}
+	_, err = exchange.GetExchangeRateInfo(ctx, instantswap.ExchangeRateRequest{
+		From: "BTC", FromNetwork: instantswap.NetworkBitcoin, To: "USDT", ToNetwork: "TRC20",
+		Amount: instantswap.MustParseAmount("0.1"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
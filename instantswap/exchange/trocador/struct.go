@@ -7,13 +7,13 @@ import (
 )
 
 type Coin struct {
-	Name    string  `json:"name"`
-	Ticker  string  `json:"ticker"`
-	Network string  `json:"network"`
-	Memo    bool    `json:"memo"`
-	Image   string  `json:"image"`
-	Minimum float64 `json:"minimum"`
-	Maximum float64 `json:"maximum"`
+	Name    string             `json:"name"`
+	Ticker  string             `json:"ticker"`
+	Network string             `json:"network"`
+	Memo    bool               `json:"memo"`
+	Image   string             `json:"image"`
+	Minimum instantswap.Amount `json:"minimum"`
+	Maximum instantswap.Amount `json:"maximum"`
 }
 
 type Error struct {
@@ -21,19 +21,19 @@ type Error struct {
 }
 
 type Rate struct {
-	TradeId     string  `json:"trade_id"`
-	Date        string  `json:"date"`
-	TickerFrom  string  `json:"ticker_from"`
-	TickerTo    string  `json:"ticker_to"`
-	CoinFrom    string  `json:"coin_from"`
-	CoinTo      string  `json:"coin_to"`
-	NetworkFrom string  `json:"network_from"`
-	NetworkTo   string  `json:"network_to"`
-	AmountFrom  float64 `json:"amount_from"`
-	AmountTo    float64 `json:"amount_to"`
-	Provider    string  `json:"provider"`
-	Fixed       bool    `json:"fixed"`
-	Status      string  `json:"status"`
+	TradeId     string             `json:"trade_id"`
+	Date        string             `json:"date"`
+	TickerFrom  string             `json:"ticker_from"`
+	TickerTo    string             `json:"ticker_to"`
+	CoinFrom    string             `json:"coin_from"`
+	CoinTo      string             `json:"coin_to"`
+	NetworkFrom string             `json:"network_from"`
+	NetworkTo   string             `json:"network_to"`
+	AmountFrom  instantswap.Amount `json:"amount_from"`
+	AmountTo    instantswap.Amount `json:"amount_to"`
+	Provider    string             `json:"provider"`
+	Fixed       bool               `json:"fixed"`
+	Status      string             `json:"status"`
 	Quotes      struct {
 		Quotes []Quote `json:"quotes"`
 	} `json:"quotes"`
@@ -50,43 +50,43 @@ func (r *Rate) maxProvider() string {
 }
 
 func (r *Rate) rate() float64 {
-	return r.AmountTo / r.AmountFrom
+	return instantswap.ExchangeRate(r.AmountTo, r.AmountFrom)
 }
 
 type Quote struct {
-	Provider  string  `json:"provider"`
-	KycRating string  `json:"kycrating"`
-	LogPolicy string  `json:"logpolicy"`
-	Insurance int     `json:"insurance"`
-	Fixed     string  `json:"fixed"`
-	AmountTo  float64 `json:"amount_to,string"`
-	Waste     float64 `json:"waste,string"`
-	Eta       float64 `json:"eta"`
+	Provider  string             `json:"provider"`
+	KycRating string             `json:"kycrating"`
+	LogPolicy string             `json:"logpolicy"`
+	Insurance int                `json:"insurance"`
+	Fixed     string             `json:"fixed"`
+	AmountTo  instantswap.Amount `json:"amount_to"`
+	Waste     float64            `json:"waste,string"`
+	Eta       float64            `json:"eta"`
 }
 
 type Trade struct {
-	TradeId             string    `json:"trade_id"`
-	Date                time.Time `json:"date"`
-	TickerFrom          string    `json:"ticker_from"`
-	TickerTo            string    `json:"ticker_to"`
-	CoinFrom            string    `json:"coin_from"`
-	CoinTo              string    `json:"coin_to"`
-	NetworkFrom         string    `json:"network_from"`
-	NetworkTo           string    `json:"network_to"`
-	AmountFrom          float64   `json:"amount_from"`
-	AmountTo            float64   `json:"amount_to"`
-	Provider            string    `json:"provider"`
-	Fixed               bool      `json:"fixed"`
-	Payment             bool      `json:"payment"`
-	Status              string    `json:"status"`
-	AddressProvider     string    `json:"address_provider"`
-	AddressProviderMemo string    `json:"address_provider_memo"`
-	AddressUser         string    `json:"address_user"`
-	AddressUserMemo     string    `json:"address_user_memo"`
-	RefundAddress       string    `json:"refund_address"`
-	RefundAddressMemo   string    `json:"refund_address_memo"`
-	Password            string    `json:"password"`
-	IdProvider          string    `json:"id_provider"`
+	TradeId             string             `json:"trade_id"`
+	Date                time.Time          `json:"date"`
+	TickerFrom          string             `json:"ticker_from"`
+	TickerTo            string             `json:"ticker_to"`
+	CoinFrom            string             `json:"coin_from"`
+	CoinTo              string             `json:"coin_to"`
+	NetworkFrom         string             `json:"network_from"`
+	NetworkTo           string             `json:"network_to"`
+	AmountFrom          instantswap.Amount `json:"amount_from"`
+	AmountTo            instantswap.Amount `json:"amount_to"`
+	Provider            string             `json:"provider"`
+	Fixed               bool               `json:"fixed"`
+	Payment             bool               `json:"payment"`
+	Status              string             `json:"status"`
+	AddressProvider     string             `json:"address_provider"`
+	AddressProviderMemo string             `json:"address_provider_memo"`
+	AddressUser         string             `json:"address_user"`
+	AddressUserMemo     string             `json:"address_user_memo"`
+	RefundAddress       string             `json:"refund_address"`
+	RefundAddressMemo   string             `json:"refund_address_memo"`
+	Password            string             `json:"password"`
+	IdProvider          string             `json:"id_provider"`
 	Quotes              struct {
 		Support struct {
 			TxUrl      string `json:"tx_url"`
@@ -116,14 +116,12 @@ type TradeDetail struct {
 }
 
 func (t *TradeDetail) tx() string {
-	if t.Hashout == nil {
-		return ""
-	}
-	return t.Hashout.(string)
+	hash, _ := t.Hashout.(string)
+	return hash
 }
 
 func (t *Trade) rate() float64 {
-	return t.AmountTo / t.AmountFrom
+	return instantswap.ExchangeRate(t.AmountTo, t.AmountFrom)
 }
 
 //- new: you have rates, but did not create the swap yet;
@@ -140,7 +138,7 @@ var (
 	statusMap = map[string]instantswap.Status{
 		"new":        instantswap.OrderStatusNew,
 		"waiting":    instantswap.OrderStatusWaitingForDeposit,
-		"confirming": instantswap.OrderStatusWaitingForDeposit,
+		"confirming": instantswap.OrderStatusDepositReceived,
 		"sending":    instantswap.OrderStatusSending,
 		"finished":   instantswap.OrderStatusCompleted,
 		"failed":     instantswap.OrderStatusFailed,
@@ -1,6 +1,7 @@
 package trocador
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"github.com/crypto-power/instantswap/instantswap"
@@ -10,19 +11,46 @@ import (
 )
 
 const (
-	API_BASE = "https://trocador.app/api/"
-	LIBNAME  = "trocador"
+	API_BASE       = "https://trocador.app/api/"
+	ONION_API_BASE = "http://trocadorfyhlu27aefre5u7zri66gudtzdyelymftvr4yjwcxhfaqsid.onion/api/"
+	LIBNAME        = "trocador"
 )
 
 type trocador struct {
-	client *instantswap.Client
-	conf   *instantswap.ExchangeConfig
+	client  *instantswap.Client
+	conf    *instantswap.ExchangeConfig
+	apiBase string
 }
 
 func init() {
-	instantswap.RegisterExchange(LIBNAME, func(config instantswap.ExchangeConfig) (instantswap.IDExchange, error) {
+	instantswap.RegisterExchangeCtx(LIBNAME, func(config instantswap.ExchangeConfig) (instantswap.IDExchangeCtx, error) {
 		return New(config)
 	})
+	instantswap.RegisterCapabilities(LIBNAME, instantswap.Capabilities{
+		FixedRate:     true,
+		FloatingRate:  true,
+		Networks:      true,
+		Limits:        true,
+		RefundAddress: true,
+		Onion:         true,
+		APIKey:        true,
+	})
+	instantswap.RegisterRateLimit(LIBNAME, instantswap.RateLimit{Rate: 1, Burst: 3})
+	instantswap.RegisterAssetMapping(LIBNAME, instantswap.AssetMapping{
+		LowerCase: true,
+		Native:    "Mainnet",
+		Networks: map[string]string{
+			instantswap.NetworkEthereum:  "ERC20",
+			instantswap.NetworkTron:      "TRC20",
+			instantswap.NetworkBSC:       "BEP20",
+			instantswap.NetworkPolygon:   "Polygon",
+			instantswap.NetworkSolana:    "Solana",
+			instantswap.NetworkArbitrum:  "Arbitrum",
+			instantswap.NetworkOptimism:  "Optimism",
+			instantswap.NetworkAvalanche: "AVAXC",
+			instantswap.NetworkLightning: "Lightning",
+		},
+	})
 }
 
 // SetDebug set enable/disable http request/response dump.
@@ -33,18 +61,24 @@ func (t *trocador) SetDebug(enable bool) {
 // New return a trocador client.
 func New(conf instantswap.ExchangeConfig) (*trocador, error) {
 	if conf.ApiKey == "" {
-		return nil, fmt.Errorf("%s:error: APIKEY is blank", LIBNAME)
+		return nil, instantswap.NewError(LIBNAME, instantswap.KindUnauthorized, "", "APIKEY is blank")
 	}
 	client := instantswap.NewClient(LIBNAME, &conf, func(r *http.Request, body string) error {
 		return nil
 	})
-	return &trocador{client: client, conf: &conf}, nil
+	client.SetErrorParser(parseError)
+	apiBase := API_BASE
+	if conf.Onion {
+		apiBase = ONION_API_BASE
+	}
+	apiBase = conf.BaseURLOrDefault(apiBase)
+	return &trocador{client: client, conf: &conf, apiBase: apiBase}, nil
 }
 
-func (t *trocador) currenciesMap() (map[string]instantswap.Currency, error) {
+func (t *trocador) currenciesMap(ctx context.Context) (map[string]instantswap.Currency, error) {
 	var form = url.Values{}
 	form.Set("api_key", t.conf.ApiKey)
-	r, err := t.client.Do(API_BASE, "GET", "coins?"+form.Encode(), "", false)
+	r, err := t.client.Do(ctx, t.apiBase, "GET", "coins?"+form.Encode(), "", false)
 	if err != nil {
 		return nil, err
 	}
@@ -72,11 +106,12 @@ func (t *trocador) currenciesMap() (map[string]instantswap.Currency, error) {
 	return mapCurrencies, nil
 }
 
-func (t *trocador) coin(ticker string) (*Coin, error) {
+// coin returns the coin ticker on its first listed network.
+func (t *trocador) coin(ctx context.Context, ticker string) (*Coin, error) {
 	var form = url.Values{}
 	form.Set("api_key", t.conf.ApiKey)
 	form.Set("ticker", strings.ToLower(ticker))
-	r, err := t.client.Do(API_BASE, "GET", "coin?"+form.Encode(), "", false)
+	r, err := t.client.Do(ctx, t.apiBase, "GET", "coin?"+form.Encode(), "", false)
 	if err != nil {
 		return nil, err
 	}
@@ -86,29 +121,24 @@ func (t *trocador) coin(ticker string) (*Coin, error) {
 		return nil, err
 	}
 	if len(coins) == 0 {
-		return nil, fmt.Errorf("coin not found")
+		return nil, instantswap.NewError(LIBNAME, instantswap.KindPairUnavailable, "", "coin not found")
 	}
 	return &coins[0], nil
 }
 
-func (t *trocador) GetCurrencies() (currencies []instantswap.Currency, err error) {
-	mapCurrencies, err := t.currenciesMap()
+func (t *trocador) GetCurrencies(ctx context.Context) (currencies []instantswap.Currency, err error) {
+	mapCurrencies, err := t.currenciesMap(ctx)
 	for _, curr := range mapCurrencies {
 		currencies = append(currencies, curr)
 	}
 	return
 }
 
-func (t *trocador) GetCurrenciesToPair(from string) (currencies []instantswap.Currency, err error) {
-	mapCurrencies, err := t.currenciesMap()
-	delete(mapCurrencies, strings.ToLower(from))
-	for _, curr := range mapCurrencies {
-		currencies = append(currencies, curr)
-	}
-	return
+func (t *trocador) GetCurrenciesToPair(ctx context.Context, from string) (currencies []instantswap.Currency, err error) {
+	return currencies, instantswap.ErrNotSupported
 }
 
-func (t *trocador) GetExchangeRateInfo(vars instantswap.ExchangeRateRequest) (res instantswap.ExchangeRateInfo, err error) {
+func (t *trocador) GetExchangeRateInfo(ctx context.Context, vars instantswap.ExchangeRateRequest) (res instantswap.ExchangeRateInfo, err error) {
 	var r []byte
 	var form = url.Values{}
 	form.Set("api_key", t.conf.ApiKey)
@@ -116,8 +146,8 @@ func (t *trocador) GetExchangeRateInfo(vars instantswap.ExchangeRateRequest) (re
 	form.Set("ticker_to", strings.ToLower(vars.To))
 	form.Set("network_from", vars.FromNetwork)
 	form.Set("network_to", vars.ToNetwork)
-	form.Set("amount_from", fmt.Sprintf("%.8f", vars.Amount))
-	r, err = t.client.Do(API_BASE, "GET", "new_rate?"+form.Encode(), "", false)
+	form.Set("amount_from", vars.Amount.TruncateFor(vars.From, vars.FromNetwork).String())
+	r, err = t.client.Do(ctx, t.apiBase, "GET", "new_rate?"+form.Encode(), "", false)
 	if err != nil {
 		return res, err
 	}
@@ -126,34 +156,44 @@ func (t *trocador) GetExchangeRateInfo(vars instantswap.ExchangeRateRequest) (re
 	if err != nil {
 		return res, err
 	}
-	coin, err := t.coin(vars.From)
-	if err != nil {
-		return res, err
-	}
+	// The limits are not quoted, QueryLimits returns them.
 	return instantswap.ExchangeRateInfo{
-		Min:             coin.Minimum,
-		Max:             coin.Maximum,
 		ExchangeRate:    rate.rate(),
 		EstimatedAmount: rate.AmountTo,
-		MaxOrder:        0,
+		MaxOrder:        instantswap.Amount{},
 		Signature:       rate.TradeId,
 		Provider:        rate.maxProvider(),
+		RateType:        rateType(vars.RateType),
 	}, nil
 }
 
-func (t *trocador) QueryRates(vars interface{}) (res []instantswap.QueryRate, err error) {
-	return res, fmt.Errorf("not supported")
+// rateType returns the type of the trades created with t, fixed by default.
+func rateType(t instantswap.RateType) instantswap.RateType {
+	if t == instantswap.RateTypeFloat {
+		return instantswap.RateTypeFloat
+	}
+	return instantswap.RateTypeFixed
 }
 
-func (t *trocador) QueryActiveCurrencies(vars interface{}) (res []instantswap.ActiveCurr, err error) {
-	return res, fmt.Errorf("not supported")
+func (t *trocador) QueryRates(ctx context.Context, vars interface{}) (res []instantswap.QueryRate, err error) {
+	return res, instantswap.ErrNotSupported
 }
 
-func (t *trocador) QueryLimits(fromCurr, toCurr string) (res instantswap.QueryLimits, err error) {
-	return res, fmt.Errorf("not supported")
+func (t *trocador) QueryActiveCurrencies(ctx context.Context, vars interface{}) (res []instantswap.ActiveCurr, err error) {
+	return res, instantswap.ErrNotSupported
 }
 
-func (t *trocador) CreateOrder(vars instantswap.CreateOrder) (res instantswap.CreateResultInfo, err error) {
+// QueryLimits returns the limits of the coin fromCurr, on its first listed
+// network. They do not depend on toCurr.
+func (t *trocador) QueryLimits(ctx context.Context, fromCurr, toCurr string) (res instantswap.QueryLimits, err error) {
+	coin, err := t.coin(ctx, fromCurr)
+	if err != nil {
+		return res, err
+	}
+	return instantswap.QueryLimits{Min: coin.Minimum, Max: coin.Maximum}, nil
+}
+
+func (t *trocador) CreateOrder(ctx context.Context, vars instantswap.CreateOrder) (res instantswap.CreateResultInfo, err error) {
 	var r []byte
 	var form = url.Values{}
 	if len(vars.Signature) > 0 {
@@ -164,13 +204,17 @@ func (t *trocador) CreateOrder(vars instantswap.CreateOrder) (res instantswap.Cr
 	form.Set("ticker_to", strings.ToLower(vars.ToCurrency))
 	form.Set("network_from", vars.FromNetwork)
 	form.Set("network_to", vars.ToNetwork)
-	form.Set("amount_from", fmt.Sprintf("%.8f", vars.InvoicedAmount))
+	form.Set("amount_from", vars.InvoicedAmount.TruncateFor(vars.FromCurrency, vars.FromNetwork).String())
 	form.Set("address", vars.Destination)
-	form.Set("fixed", "True")
+	if rateType(vars.RateType) == instantswap.RateTypeFixed {
+		form.Set("fixed", "True")
+	} else {
+		form.Set("fixed", "False")
+	}
 	form.Set("refund", vars.RefundAddress)
 	form.Set("provider", vars.Provider)
 	form.Set("refund_memo", "0")
-	r, err = t.client.Do(API_BASE, "GET", "new_trade?"+form.Encode(), "", false)
+	r, err = t.client.Do(instantswap.NotIdempotent(ctx), t.apiBase, "GET", "new_trade?"+form.Encode(), "", false)
 	if err != nil {
 		return res, err
 	}
@@ -181,7 +225,7 @@ func (t *trocador) CreateOrder(vars instantswap.CreateOrder) (res instantswap.Cr
 	}
 
 	return instantswap.CreateResultInfo{
-		ChargedFee:     0,
+		ChargedFee:     instantswap.Amount{},
 		Destination:    trade.AddressUser,
 		ExchangeRate:   trade.rate(),
 		FromCurrency:   strings.ToUpper(trade.TickerFrom),
@@ -197,20 +241,20 @@ func (t *trocador) CreateOrder(vars instantswap.CreateOrder) (res instantswap.Cr
 }
 
 // UpdateOrder accepts orderID value and more if needed per lib.
-func (t *trocador) UpdateOrder(vars interface{}) (res instantswap.UpdateOrderResultInfo, err error) {
-	return
+func (t *trocador) UpdateOrder(ctx context.Context, vars interface{}) (res instantswap.UpdateOrderResultInfo, err error) {
+	return res, instantswap.ErrNotSupported
 }
-func (t *trocador) CancelOrder(orderID string) (res string, err error) {
-	return
+func (t *trocador) CancelOrder(ctx context.Context, orderID string) (res string, err error) {
+	return res, instantswap.ErrNotSupported
 }
 
 // OrderInfo accepts orderID value and more if needed per lib.
-func (t *trocador) OrderInfo(orderID string, extraIds ...string) (res instantswap.OrderInfoResult, err error) {
+func (t *trocador) OrderInfo(ctx context.Context, orderID string, extraIds ...string) (res instantswap.OrderInfoResult, err error) {
 	var r []byte
 	var form = url.Values{}
 	form.Set("id", orderID)
 	form.Set("api_key", t.conf.ApiKey)
-	r, err = t.client.Do(API_BASE, http.MethodGet,
+	r, err = t.client.Do(ctx, t.apiBase, http.MethodGet,
 		fmt.Sprintf("trade?%s", form.Encode()),
 		"", false)
 	if err != nil {
@@ -222,14 +266,14 @@ func (t *trocador) OrderInfo(orderID string, extraIds ...string) (res instantswa
 		return
 	}
 	if len(trades) == 0 {
-		return res, fmt.Errorf("order not found")
+		return res, instantswap.NewError(LIBNAME, instantswap.KindNotFound, "", "order not found")
 	}
 	trade := trades[0]
 
 	res = instantswap.OrderInfoResult{
 		Expires:        0,
 		LastUpdate:     "",
-		ReceiveAmount:  0,
+		ReceiveAmount:  trade.AmountTo,
 		TxID:           trade.Details.tx(),
 		Status:         trade.Status,
 		InternalStatus: localStatus(trade.Status),
@@ -238,16 +282,24 @@ func (t *trocador) OrderInfo(orderID string, extraIds ...string) (res instantswa
 	return
 }
 
-func (t *trocador) EstimateAmount(vars interface{}) (res instantswap.EstimateAmount, err error) {
-	return
+func (t *trocador) EstimateAmount(ctx context.Context, vars interface{}) (res instantswap.EstimateAmount, err error) {
+	return res, instantswap.ErrNotSupported
 }
 
 func parseResponseData(data []byte, obj interface{}) error {
 	var err Error
 	if json.Unmarshal(data, &err) == nil {
 		if len(err.Error) > 0 {
-			return fmt.Errorf(err.Error)
+			return instantswap.NewError(LIBNAME, instantswap.ClassifyMessage(err.Error), "", err.Error)
 		}
 	}
 	return json.Unmarshal(data, obj)
 }
+
+// parseError decodes the body of an error response.
+func parseError(e *instantswap.Error, body []byte) {
+	var trocadorErr Error
+	if json.Unmarshal(body, &trocadorErr) == nil && trocadorErr.Error != "" {
+		e.Message = trocadorErr.Error
+	}
+}
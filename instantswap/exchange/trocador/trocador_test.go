@@ -0,0 +1,225 @@
+package trocador
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/crypto-power/instantswap/instantswap"
+	"github.com/crypto-power/instantswap/instantswap/exchangetest"
+)
+
+func TestTrocador(t *testing.T) {
+	amount := instantswap.MustParseAmount
+	exchangetest.Run(t, exchangetest.Suite{
+		Exchange: LIBNAME,
+		Config:   instantswap.ExchangeConfig{ApiKey: "key"},
+		Fixtures: []exchangetest.Fixture{{
+			Method: "GET", Path: "/api/coins", Query: "api_key=key",
+			Response: `[{"name":"Bitcoin","ticker":"btc","network":"Mainnet","memo":false,"minimum":0.0001,"maximum":20},
+				{"name":"Decred","ticker":"dcr","network":"Mainnet","memo":false,"minimum":0.5,"maximum":50000},
+				{"name":"Tether","ticker":"usdt","network":"ERC20","memo":false,"minimum":10,"maximum":100000},
+				{"name":"Tether","ticker":"usdt","network":"TRC20","memo":false,"minimum":10,"maximum":100000}]`,
+		}, {
+			Method: "GET", Path: "/api/new_rate", Query: "amount_from=0.1",
+			Response: `{"trade_id":"tr-5","date":"2023-05-01 10:00:00","ticker_from":"btc","ticker_to":"dcr",
+				"coin_from":"Bitcoin","coin_to":"Decred","network_from":"Mainnet","network_to":"Mainnet",
+				"amount_from":0.1,"amount_to":180.1,"provider":"FixedFloat","fixed":false,"status":"new",
+				"quotes":{"quotes":[{"provider":"ChangeNow","amount_to":"179.9","waste":"0.11"},
+					{"provider":"FixedFloat","amount_to":"180.1","waste":"0.0"}]},"payment":false}`,
+		}, {
+			Method: "GET", Path: "/api/coin", Query: "ticker=btc",
+			Response: `[{"name":"Bitcoin","ticker":"btc","network":"Mainnet","memo":false,"minimum":0.0001,"maximum":20}]`,
+		}, {
+			Method: "GET", Path: "/api/new_trade", Query: "fixed=True&id=tr-5",
+			Response: `{"trade_id":"tr-5","date":"2023-05-01T10:00:00Z","ticker_from":"btc","ticker_to":"dcr",
+				"amount_from":0.1,"amount_to":180.1,"provider":"FixedFloat","fixed":true,"status":"waiting",
+				"address_provider":"bc1qdeposit","address_user":"DsUZxxoHJSty8DCfwfartwTYbuhmVct7tJu","refund_address":"bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t4",
+				"details":{"hashout":null}}`,
+		}, {
+			Method: "GET", Path: "/api/trade", Query: "id=tr-5",
+			Response: `[{"trade_id":"tr-5","date":"2023-05-01T10:00:00Z","ticker_from":"btc","ticker_to":"dcr",
+				"amount_from":0.1,"amount_to":180.1,"provider":"FixedFloat","fixed":true,"status":"finished",
+				"address_provider":"bc1qdeposit","address_user":"DsUZxxoHJSty8DCfwfartwTYbuhmVct7tJu","details":{"hashout":"dcrtxhash"}}]`,
+		}},
+		Currencies: []string{"BTC", "DCR", "USDT"},
+		Rate: &exchangetest.RateTest{
+			Request: instantswap.ExchangeRateRequest{From: "BTC", To: "DCR", Amount: amount("0.1")},
+			Expected: instantswap.ExchangeRateInfo{
+				ExchangeRate:    1801,
+				EstimatedAmount: amount("180.1"),
+				DepositAmount:   amount("0.1"),
+				Signature:       "tr-5",
+				Provider:        "FixedFloat",
+				RateType:        instantswap.RateTypeFixed,
+			},
+		},
+		Limits: &exchangetest.LimitsTest{
+			From: "BTC", To: "DCR",
+			Expected: instantswap.QueryLimits{Min: amount("0.0001"), Max: amount("20")},
+		},
+		Order: &exchangetest.OrderTest{
+			Request: instantswap.CreateOrder{
+				FromCurrency:   "BTC",
+				ToCurrency:     "DCR",
+				Destination:    "DsUZxxoHJSty8DCfwfartwTYbuhmVct7tJu",
+				RefundAddress:  "bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t4",
+				InvoicedAmount: amount("0.1"),
+				Signature:      "tr-5",
+				Provider:       "FixedFloat",
+			},
+			Expected: instantswap.CreateResultInfo{
+				UUID:           "tr-5",
+				Destination:    "DsUZxxoHJSty8DCfwfartwTYbuhmVct7tJu",
+				ExchangeRate:   1801,
+				FromCurrency:   "BTC",
+				ToCurrency:     "DCR",
+				InvoicedAmount: amount("0.1"),
+				OrderedAmount:  amount("180.1"),
+				DepositAddress: "bc1qdeposit",
+			},
+		},
+		Info: &exchangetest.InfoTest{
+			OrderID: "tr-5",
+			Expected: instantswap.OrderInfoResult{
+				ReceiveAmount:  amount("180.1"),
+				TxID:           "dcrtxhash",
+				Status:         "finished",
+				InternalStatus: instantswap.OrderStatusCompleted,
+			},
+		},
+		StatusFunc: localStatus,
+		Statuses: map[string]instantswap.Status{
+			"new":        instantswap.OrderStatusNew,
+			"waiting":    instantswap.OrderStatusWaitingForDeposit,
+			"confirming": instantswap.OrderStatusDepositReceived,
+			"sending":    instantswap.OrderStatusSending,
+			"finished":   instantswap.OrderStatusCompleted,
+			"failed":     instantswap.OrderStatusFailed,
+			"expired":    instantswap.OrderStatusExpired,
+			"halted":     instantswap.OrderStatusFailed,
+			"refunded":   instantswap.OrderStatusRefunded,
+			"FINISHED":   instantswap.OrderStatusCompleted,
+			"paid":       instantswap.OrderStatusUnknown,
+		},
+	})
+}
+
+func TestTrocadorFloat(t *testing.T) {
+	amount := instantswap.MustParseAmount
+	exchangetest.Run(t, exchangetest.Suite{
+		Exchange: LIBNAME,
+		Config:   instantswap.ExchangeConfig{ApiKey: "key"},
+		Fixtures: []exchangetest.Fixture{{
+			Method: "GET", Path: "/api/new_trade", Query: "fixed=False",
+			Response: `{"trade_id":"tr-6","date":"2023-05-01T10:00:00Z","ticker_from":"btc","ticker_to":"dcr",
+				"amount_from":0.1,"amount_to":180.4,"provider":"ChangeNow","fixed":false,"status":"waiting",
+				"address_provider":"bc1qdeposit","address_user":"DsUZxxoHJSty8DCfwfartwTYbuhmVct7tJu","details":{"hashout":null}}`,
+		}},
+		Order: &exchangetest.OrderTest{
+			Request: instantswap.CreateOrder{
+				FromCurrency:   "BTC",
+				ToCurrency:     "DCR",
+				Destination:    "DsUZxxoHJSty8DCfwfartwTYbuhmVct7tJu",
+				InvoicedAmount: amount("0.1"),
+				RateType:       instantswap.RateTypeFloat,
+			},
+			Expected: instantswap.CreateResultInfo{
+				UUID:           "tr-6",
+				Destination:    "DsUZxxoHJSty8DCfwfartwTYbuhmVct7tJu",
+				ExchangeRate:   1804,
+				FromCurrency:   "BTC",
+				ToCurrency:     "DCR",
+				InvoicedAmount: amount("0.1"),
+				OrderedAmount:  amount("180.4"),
+				DepositAddress: "bc1qdeposit",
+			},
+		},
+	})
+}
+
+func TestTrocadorErrors(t *testing.T) {
+	server := exchangetest.NewServer(t, exchangetest.Fixture{
+		Method: "GET", Path: "/api/trade", Query: "id=missing",
+		Response: `[]`,
+	}, exchangetest.Fixture{
+		Method: "GET", Path: "/api/new_rate",
+		Response: `{"error":"Pair not available"}`,
+	})
+	exchange, err := New(server.Config(instantswap.ExchangeConfig{ApiKey: "key"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	if _, err := exchange.OrderInfo(ctx, "missing"); !errors.Is(err, instantswap.ErrNotFound) {
+		t.Errorf("unexpected order error: %v", err)
+	}
+	_, err = exchange.GetExchangeRateInfo(ctx, instantswap.ExchangeRateRequest{
+		From: "BTC", To: "XYZ", Amount: instantswap.MustParseAmount("0.1"),
+	})
+	if !errors.Is(err, instantswap.ErrPairUnavailable) {
+		t.Errorf("unexpected rate error: %v", err)
+	}
+}
+
+func TestTrocadorOnion(t *testing.T) {
+	server := exchangetest.NewServer(t, exchangetest.Fixture{
+		Method: "GET", Path: "/api/coins",
+		Response: `[]`,
+	})
+	exchange, err := New(server.Config(instantswap.ExchangeConfig{ApiKey: "key", Onion: true}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := exchange.GetCurrencies(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	requests := server.Requests()
+	if len(requests) != 1 || "http://"+requests[0].Host+"/api/" != ONION_API_BASE {
+		t.Errorf("unexpected requests %+v", requests)
+	}
+}
+
+func TestTrocadorNetworks(t *testing.T) {
+	server := exchangetest.NewServer(t, exchangetest.Fixture{
+		Method: "GET", Path: "/api/coins",
+		Response: `[{"name":"Bitcoin","ticker":"btc","network":"Mainnet","minimum":0.0001,"maximum":20},
+			{"name":"Tether","ticker":"usdt","network":"ERC20","minimum":10,"maximum":100000},
+			{"name":"Tether","ticker":"usdt","network":"TRC20","minimum":10,"maximum":100000}]`,
+	}, exchangetest.Fixture{
+		Method: "GET", Path: "/api/new_rate", Query: "network_from=Mainnet&network_to=ERC20&ticker_from=btc&ticker_to=usdt",
+		Response: `{"trade_id":"tr-6","ticker_from":"btc","ticker_to":"usdt","network_from":"Mainnet","network_to":"ERC20",
+			"amount_from":0.1,"amount_to":2900,"provider":"FixedFloat","fixed":true,"quotes":{"quotes":[]}}`,
+	})
+	exchange, err := instantswap.NewExchangeCtx(LIBNAME, server.Config(instantswap.ExchangeConfig{ApiKey: "key"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	currencies, err := exchange.GetCurrencies(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	networks := make(map[string][]string)
+	for _, c := range currencies {
+		networks[c.Symbol] = c.Networks
+	}
+	if n := networks["BTC"]; len(n) != 1 || n[0] != instantswap.NetworkBitcoin {
+		t.Errorf("unexpected BTC networks %v", n)
+	}
+	if n := networks["USDT"]; len(n) != 2 || n[0] != instantswap.NetworkEthereum || n[1] != instantswap.NetworkTron {
+		t.Errorf("unexpected USDT networks %v", n)
+	}
+	res, err := exchange.GetExchangeRateInfo(ctx, instantswap.ExchangeRateRequest{
+		From: "btc", FromNetwork: instantswap.NetworkBitcoin, To: "USDT", ToNetwork: "erc-20", Amount: instantswap.MustParseAmount("0.1"),
+	})
+	if err != nil || res.EstimatedAmount.String() != "2900" {
+		t.Errorf("unexpected rate %+v, %v", res, err)
+	}
+
+	if _, err := exchange.GetExchangeRateInfo(ctx, instantswap.ExchangeRateRequest{
+		From: "BTC", FromNetwork: "mainnet", To: "USDT", ToNetwork: instantswap.NetworkEthereum, Amount: instantswap.MustParseAmount("0.1"),
+	}); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -1,6 +1,7 @@
 package wizardswap
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"github.com/crypto-power/instantswap/instantswap"
@@ -14,14 +15,23 @@ const (
 )
 
 type wizardswap struct {
-	client *instantswap.Client
-	conf   *instantswap.ExchangeConfig
+	client  *instantswap.Client
+	conf    *instantswap.ExchangeConfig
+	apiBase string
 }
 
 func init() {
-	instantswap.RegisterExchange(LIBNAME, func(config instantswap.ExchangeConfig) (instantswap.IDExchange, error) {
+	instantswap.RegisterExchangeCtx(LIBNAME, func(config instantswap.ExchangeConfig) (instantswap.IDExchangeCtx, error) {
 		return New(config)
 	})
+	instantswap.RegisterCapabilities(LIBNAME, instantswap.Capabilities{
+		FloatingRate:  true,
+		PairListing:   true,
+		RefundAddress: true,
+		APIKey:        true,
+	})
+	instantswap.RegisterRateLimit(LIBNAME, instantswap.RateLimit{Rate: 1, Burst: 3})
+	instantswap.RegisterAssetMapping(LIBNAME, instantswap.AssetMapping{LowerCase: true})
 }
 
 // SetDebug set enable/disable http request/response dump.
@@ -32,16 +42,16 @@ func (w *wizardswap) SetDebug(enable bool) {
 // New return a wizardswap client.
 func New(conf instantswap.ExchangeConfig) (*wizardswap, error) {
 	if conf.ApiKey == "" {
-		return nil, fmt.Errorf("%s:error: APIKEY is blank", LIBNAME)
+		return nil, instantswap.NewError(LIBNAME, instantswap.KindUnauthorized, "", "APIKEY is blank")
 	}
 	client := instantswap.NewClient(LIBNAME, &conf, func(r *http.Request, body string) error {
 		return nil
 	})
-	return &wizardswap{client: client, conf: &conf}, nil
+	return &wizardswap{client: client, conf: &conf, apiBase: conf.BaseURLOrDefault(API_BASE)}, nil
 }
 
-func (w *wizardswap) GetCurrencies() (currencies []instantswap.Currency, err error) {
-	r, err := w.client.Do(API_BASE, http.MethodGet, "currency", "", false)
+func (w *wizardswap) GetCurrencies(ctx context.Context) (currencies []instantswap.Currency, err error) {
+	r, err := w.client.Do(ctx, w.apiBase, http.MethodGet, "currency", "", false)
 	if err != nil {
 		return nil, err
 	}
@@ -63,9 +73,9 @@ func (w *wizardswap) GetCurrencies() (currencies []instantswap.Currency, err err
 	return currencies, nil
 }
 
-func (w *wizardswap) GetCurrenciesToPair(from string) (currencies []instantswap.Currency, err error) {
+func (w *wizardswap) GetCurrenciesToPair(ctx context.Context, from string) (currencies []instantswap.Currency, err error) {
 	from = strings.ToLower(from)
-	r, err := w.client.Do(API_BASE, http.MethodGet,
+	r, err := w.client.Do(ctx, w.apiBase, http.MethodGet,
 		fmt.Sprintf("pairs/%s", from), "", false)
 	if err != nil {
 		return nil, err
@@ -90,15 +100,15 @@ func (w *wizardswap) GetCurrenciesToPair(from string) (currencies []instantswap.
 	return currencies, nil
 }
 
-func (w *wizardswap) GetExchangeRateInfo(vars instantswap.ExchangeRateRequest) (res instantswap.ExchangeRateInfo, err error) {
+func (w *wizardswap) GetExchangeRateInfo(ctx context.Context, vars instantswap.ExchangeRateRequest) (res instantswap.ExchangeRateInfo, err error) {
 	f := map[string]string{
 		"currency_from": strings.ToLower(vars.From),
 		"currency_to":   strings.ToLower(vars.To),
-		"amount_from":   fmt.Sprintf("%.8f", vars.Amount),
+		"amount_from":   vars.Amount.TruncateFor(vars.From, vars.FromNetwork).String(),
 		"api_key":       w.conf.ApiKey,
 	}
 	data, _ := json.Marshal(f)
-	r, err := w.client.Do(API_BASE, http.MethodPost, "estimate", string(data), false)
+	r, err := w.client.Do(instantswap.Idempotent(ctx), w.apiBase, http.MethodPost, "estimate", string(data), false)
 	if err != nil {
 		return res, err
 	}
@@ -108,25 +118,25 @@ func (w *wizardswap) GetExchangeRateInfo(vars instantswap.ExchangeRateRequest) (
 		return res, err
 	}
 	res.EstimatedAmount = estimate.EstimatedAmount
-	res.ExchangeRate = estimate.EstimatedAmount / vars.Amount
+	res.ExchangeRate = instantswap.ExchangeRate(estimate.EstimatedAmount, vars.Amount)
 	return res, nil
 }
 
-func (w *wizardswap) QueryLimits(fromCurr, toCurr string) (res instantswap.QueryLimits, err error) {
-	return res, fmt.Errorf("not supported")
+func (w *wizardswap) QueryLimits(ctx context.Context, fromCurr, toCurr string) (res instantswap.QueryLimits, err error) {
+	return res, instantswap.ErrNotSupported
 }
 
-func (w *wizardswap) CreateOrder(vars instantswap.CreateOrder) (res instantswap.CreateResultInfo, err error) {
+func (w *wizardswap) CreateOrder(ctx context.Context, vars instantswap.CreateOrder) (res instantswap.CreateResultInfo, err error) {
 	f := map[string]string{
 		"currency_from":  strings.ToLower(vars.FromCurrency),
 		"currency_to":    strings.ToLower(vars.ToCurrency),
-		"amount_from":    fmt.Sprintf("%.8f", vars.InvoicedAmount),
+		"amount_from":    vars.InvoicedAmount.TruncateFor(vars.FromCurrency, vars.FromNetwork).String(),
 		"api_key":        w.conf.ApiKey,
 		"address_to":     vars.Destination,
 		"refund_address": vars.RefundAddress,
 	}
 	data, _ := json.Marshal(f)
-	r, err := w.client.Do(API_BASE, http.MethodPost, "exchange", string(data), false)
+	r, err := w.client.Do(ctx, w.apiBase, http.MethodPost, "exchange", string(data), false)
 	if err != nil {
 		return res, err
 	}
@@ -136,9 +146,9 @@ func (w *wizardswap) CreateOrder(vars instantswap.CreateOrder) (res instantswap.
 		return res, err
 	}
 	res = instantswap.CreateResultInfo{
-		ChargedFee:     0,
+		ChargedFee:     instantswap.Amount{},
 		Destination:    order.AddressTo,
-		ExchangeRate:   order.AmountFrom / order.AmountTo,
+		ExchangeRate:   instantswap.ExchangeRate(order.AmountTo, order.AmountFrom),
 		FromCurrency:   order.CurrencyFrom,
 		InvoicedAmount: order.AmountFrom,
 		OrderedAmount:  order.AmountTo,
@@ -152,15 +162,15 @@ func (w *wizardswap) CreateOrder(vars instantswap.CreateOrder) (res instantswap.
 	return res, nil
 }
 
-func (w *wizardswap) UpdateOrder(vars interface{}) (res instantswap.UpdateOrderResultInfo, err error) {
-	return
+func (w *wizardswap) UpdateOrder(ctx context.Context, vars interface{}) (res instantswap.UpdateOrderResultInfo, err error) {
+	return res, instantswap.ErrNotSupported
 }
-func (w *wizardswap) CancelOrder(orderID string) (res string, err error) {
-	return
+func (w *wizardswap) CancelOrder(ctx context.Context, orderID string) (res string, err error) {
+	return res, instantswap.ErrNotSupported
 }
 
-func (w *wizardswap) OrderInfo(orderID string, extraIds ...string) (res instantswap.OrderInfoResult, err error) {
-	r, err := w.client.Do(API_BASE, http.MethodGet, fmt.Sprintf("exchange/%s", orderID), "", false)
+func (w *wizardswap) OrderInfo(ctx context.Context, orderID string, extraIds ...string) (res instantswap.OrderInfoResult, err error) {
+	r, err := w.client.Do(ctx, w.apiBase, http.MethodGet, fmt.Sprintf("exchange/%s", orderID), "", false)
 	if err != nil {
 		return res, err
 	}
@@ -181,8 +191,8 @@ func (w *wizardswap) OrderInfo(orderID string, extraIds ...string) (res instants
 	return res, nil
 }
 
-func (w *wizardswap) EstimateAmount(vars interface{}) (res instantswap.EstimateAmount, err error) {
-	return
+func (w *wizardswap) EstimateAmount(ctx context.Context, vars interface{}) (res instantswap.EstimateAmount, err error) {
+	return res, instantswap.ErrNotSupported
 }
 
 func parseResponseData(data []byte, obj interface{}) error {
@@ -0,0 +1,89 @@
+package wizardswap
+
+import (
+	"testing"
+
+	"github.com/crypto-power/instantswap/instantswap"
+	"github.com/crypto-power/instantswap/instantswap/exchangetest"
+)
+
+func TestWizardSwap(t *testing.T) {
+	amount := instantswap.MustParseAmount
+	exchangetest.Run(t, exchangetest.Suite{
+		Exchange: LIBNAME,
+		Config:   instantswap.ExchangeConfig{ApiKey: "key"},
+		Fixtures: []exchangetest.Fixture{{
+			Method: "GET", Path: "/api/currency",
+			Response: `[{"id":1,"symbol":"btc","name":"Bitcoin","decimals":8,"minconf":2,"minamt":"0.0005","enabled":1},
+				{"id":2,"symbol":"dcr","name":"Decred","decimals":8,"minconf":2,"minamt":"1","enabled":1},
+				{"id":3,"symbol":"xmr","name":"Monero","decimals":12,"minconf":10,"minamt":"0.01","enabled":1}]`,
+		}, {
+			Method: "GET", Path: "/api/pairs/btc",
+			Response: `["dcr","xmr","btc"]`,
+		}, {
+			Method: "POST", Path: "/api/estimate", Body: `"amount_from":"0.1"`,
+			Response: `{"estimated_amount":"179.8"}`,
+		}, {
+			Method: "POST", Path: "/api/exchange", Body: `"address_to":"DsUZxxoHJSty8DCfwfartwTYbuhmVct7tJu"`,
+			Response: `{"id":"wz-3","type":"float","timestamp":"2023-05-01 10:00:00","currency_from":"btc","currency_to":"dcr",
+				"amount_from":"0.1","expected_amount":"0.1","amount_to":"179.8","address_from":"bc1qdeposit",
+				"address_to":"DsUZxxoHJSty8DCfwfartwTYbuhmVct7tJu","status":"waiting","refund_address":"bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t4"}`,
+		}, {
+			Method: "GET", Path: "/api/exchange/wz-3",
+			Response: `{"id":"wz-3","currency_from":"btc","currency_to":"dcr","amount_from":"0.1","amount_to":"179.75",
+				"address_from":"bc1qdeposit","address_to":"DsUZxxoHJSty8DCfwfartwTYbuhmVct7tJu","tx_to":"dcrtxhash","status":"finished","currencies":[]}`,
+		}},
+		Currencies: []string{"BTC", "DCR", "XMR"},
+		PairFrom:   "BTC",
+		Pairs:      []string{"DCR", "XMR"},
+		Rate: &exchangetest.RateTest{
+			Request: instantswap.ExchangeRateRequest{From: "BTC", To: "DCR", Amount: amount("0.1")},
+			Expected: instantswap.ExchangeRateInfo{
+				ExchangeRate:    1798,
+				EstimatedAmount: amount("179.8"),
+				DepositAmount:   amount("0.1"),
+				RateType:        instantswap.RateTypeFloat,
+			},
+		},
+		Order: &exchangetest.OrderTest{
+			Request: instantswap.CreateOrder{
+				FromCurrency:   "BTC",
+				ToCurrency:     "DCR",
+				Destination:    "DsUZxxoHJSty8DCfwfartwTYbuhmVct7tJu",
+				RefundAddress:  "bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t4",
+				InvoicedAmount: amount("0.1"),
+			},
+			Expected: instantswap.CreateResultInfo{
+				UUID:           "wz-3",
+				Destination:    "DsUZxxoHJSty8DCfwfartwTYbuhmVct7tJu",
+				ExchangeRate:   1798,
+				FromCurrency:   "BTC",
+				ToCurrency:     "DCR",
+				InvoicedAmount: amount("0.1"),
+				OrderedAmount:  amount("179.8"),
+				DepositAddress: "bc1qdeposit",
+			},
+		},
+		Info: &exchangetest.InfoTest{
+			OrderID: "wz-3",
+			Expected: instantswap.OrderInfoResult{
+				ReceiveAmount:  amount("179.75"),
+				TxID:           "dcrtxhash",
+				Status:         "finished",
+				InternalStatus: instantswap.OrderStatusCompleted,
+			},
+		},
+		StatusFunc: parseStatus,
+		Statuses: map[string]instantswap.Status{
+			"waiting":    instantswap.OrderStatusWaitingForDeposit,
+			"confirming": instantswap.OrderStatusDepositReceived,
+			"verifying":  instantswap.OrderStatusDepositReceived,
+			"exchanging": instantswap.OrderStatusExchanging,
+			"sending":    instantswap.OrderStatusSending,
+			"finished":   instantswap.OrderStatusCompleted,
+			"failed":     instantswap.OrderStatusFailed,
+			"refunded":   instantswap.OrderStatusRefunded,
+			"expired":    instantswap.OrderStatusUnknown,
+		},
+	})
+}
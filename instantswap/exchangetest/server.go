@@ -0,0 +1,159 @@
+// Package exchangetest runs the exchange adapters against recorded responses
+// of their api, served by an httptest.Server, to test them without network.
+package exchangetest
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/crypto-power/instantswap/instantswap"
+)
+
+// Fixture is a recorded response of an exchange api.
+type Fixture struct {
+	// Method and Path match the request, an empty Method matches any method.
+	Method string
+	Path   string
+	// Query and Body, if set, must be contained in the raw query and the body
+	// of the request, to tell apart the calls of a JSON-RPC api for example.
+	Query string
+	Body  string
+	// Status is the http status of the response, 200 if zero.
+	Status   int
+	Response string
+}
+
+func (f *Fixture) match(r *Request) bool {
+	return (f.Method == "" || f.Method == r.Method) &&
+		f.Path == r.Path &&
+		strings.Contains(r.Query, f.Query) &&
+		strings.Contains(r.Body, f.Body)
+}
+
+func (f *Fixture) String() string {
+	s := f.Method + " " + f.Path
+	if f.Query != "" {
+		s += "?" + f.Query
+	}
+	if f.Body != "" {
+		s += " " + f.Body
+	}
+	return s
+}
+
+// Request is a request received by the Server.
+type Request struct {
+	Method string
+	// Host is the host the request was sent to before being redirected to
+	// the Server.
+	Host   string
+	Path   string
+	Query  string
+	Header http.Header
+	Body   string
+}
+
+// Server answers the requests with the first matching fixture. A request
+// without fixture fails the test.
+type Server struct {
+	*httptest.Server
+	t        testing.TB
+	fixtures []Fixture
+
+	mu       sync.Mutex
+	requests []Request
+}
+
+const originalHostHeader = "X-Exchangetest-Host"
+
+// NewServer starts a Server closed at the end of the test.
+func NewServer(t testing.TB, fixtures ...Fixture) *Server {
+	s := &Server{t: t, fixtures: fixtures}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serve))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *Server) serve(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		s.t.Errorf("reading request body: %v", err)
+	}
+	req := Request{
+		Method: r.Method,
+		Host:   r.Header.Get(originalHostHeader),
+		Path:   r.URL.Path,
+		Query:  r.URL.RawQuery,
+		Header: r.Header,
+		Body:   string(body),
+	}
+	s.mu.Lock()
+	s.requests = append(s.requests, req)
+	s.mu.Unlock()
+
+	for i := range s.fixtures {
+		f := &s.fixtures[i]
+		if !f.match(&req) {
+			continue
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if f.Status != 0 {
+			w.WriteHeader(f.Status)
+		}
+		io.WriteString(w, f.Response)
+		return
+	}
+	s.t.Errorf("no fixture for request %s %s?%s %s", req.Method, req.Path, req.Query, req.Body)
+	http.Error(w, `{"error":"no fixture"}`, http.StatusNotFound)
+}
+
+// Requests returns the requests received so far.
+func (s *Server) Requests() []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Request(nil), s.requests...)
+}
+
+// Transport returns a RoundTripper sending every request to the Server,
+// whatever its host.
+func (s *Server) Transport() http.RoundTripper {
+	target, err := url.Parse(s.URL)
+	if err != nil {
+		panic(err)
+	}
+	return &redirectTransport{target: target, base: s.Client().Transport}
+}
+
+// Config returns conf with the requests sent to the Server, without retries
+// nor rate limit.
+func (s *Server) Config(conf instantswap.ExchangeConfig) instantswap.ExchangeConfig {
+	conf.HTTPClient = nil
+	conf.Transport = s.Transport()
+	conf.Retry = &instantswap.NoRetry
+	conf.RateLimit = &instantswap.NoRateLimit
+	return conf
+}
+
+type redirectTransport struct {
+	target *url.URL
+	base   http.RoundTripper
+}
+
+func (t *redirectTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	r = r.Clone(r.Context())
+	r.Header.Set(originalHostHeader, r.URL.Host)
+	r.URL.Scheme = t.target.Scheme
+	r.URL.Host = t.target.Host
+	r.Host = t.target.Host
+	res, err := t.base.RoundTrip(r)
+	if err != nil {
+		return nil, fmt.Errorf("exchangetest: %w", err)
+	}
+	return res, nil
+}
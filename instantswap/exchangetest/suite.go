@@ -0,0 +1,266 @@
+package exchangetest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/crypto-power/instantswap/instantswap"
+)
+
+// Suite describes the expected results of an exchange adapter against its
+// fixtures. The tests of the nil or empty fields are skipped.
+type Suite struct {
+	// Exchange is the name the adapter is registered with.
+	Exchange string
+	// Config is the configuration of the adapter, its requests are sent to
+	// the fixtures server.
+	Config   instantswap.ExchangeConfig
+	Fixtures []Fixture
+
+	// Currencies are symbols expected in the result of GetCurrencies.
+	Currencies []string
+	// PairFrom and Pairs are the symbols expected in the result of
+	// GetCurrenciesToPair(PairFrom).
+	PairFrom string
+	Pairs    []string
+
+	Limits *LimitsTest
+	Rate   *RateTest
+	Order  *OrderTest
+	Info   *InfoTest
+
+	// StatusFunc maps the statuses of the exchange, Statuses lists the
+	// expected results.
+	StatusFunc func(status string) instantswap.Status
+	Statuses   map[string]instantswap.Status
+}
+
+// LimitsTest is the expected result of QueryLimits(From, To).
+type LimitsTest struct {
+	From, To string
+	Expected instantswap.QueryLimits
+}
+
+// RateTest is the expected result of GetExchangeRateInfo(Request).
+type RateTest struct {
+	Request  instantswap.ExchangeRateRequest
+	Expected instantswap.ExchangeRateInfo
+}
+
+// OrderTest is the expected result of CreateOrder(Request).
+type OrderTest struct {
+	Request  instantswap.CreateOrder
+	Expected instantswap.CreateResultInfo
+}
+
+// InfoTest is the expected result of OrderInfo(OrderID, ExtraIDs...).
+type InfoTest struct {
+	OrderID  string
+	ExtraIDs []string
+	Expected instantswap.OrderInfoResult
+}
+
+// Run runs the suite. The methods the exchange does not support according to
+// its Capabilities must return instantswap.ErrNotSupported without request.
+func Run(t *testing.T, s Suite) {
+	server := NewServer(t, s.Fixtures...)
+	exchange, err := instantswap.NewExchangeCtx(s.Exchange, server.Config(s.Config))
+	if err != nil {
+		t.Fatalf("creating %s: %v", s.Exchange, err)
+	}
+	ctx := context.Background()
+
+	if len(s.Currencies) > 0 {
+		t.Run("GetCurrencies", func(t *testing.T) {
+			currencies, err := exchange.GetCurrencies(ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+			checkSymbols(t, currencies, s.Currencies)
+		})
+	}
+	if s.PairFrom != "" {
+		t.Run("GetCurrenciesToPair", func(t *testing.T) {
+			currencies, err := exchange.GetCurrenciesToPair(ctx, s.PairFrom)
+			if err != nil {
+				t.Fatal(err)
+			}
+			checkSymbols(t, currencies, s.Pairs)
+		})
+	}
+	if s.Limits != nil {
+		t.Run("QueryLimits", func(t *testing.T) {
+			limits, err := exchange.QueryLimits(ctx, s.Limits.From, s.Limits.To)
+			if err != nil {
+				t.Fatal(err)
+			}
+			Compare(t, limits, s.Limits.Expected)
+		})
+	}
+	if s.Rate != nil {
+		t.Run("GetExchangeRateInfo", func(t *testing.T) {
+			rate, err := exchange.GetExchangeRateInfo(ctx, s.Rate.Request)
+			if err != nil {
+				t.Fatal(err)
+			}
+			Compare(t, rate, s.Rate.Expected)
+		})
+	}
+	if s.Order != nil {
+		t.Run("CreateOrder", func(t *testing.T) {
+			order, err := exchange.CreateOrder(ctx, s.Order.Request)
+			if err != nil {
+				t.Fatal(err)
+			}
+			Compare(t, order, s.Order.Expected)
+		})
+	}
+	if s.Info != nil {
+		t.Run("OrderInfo", func(t *testing.T) {
+			info, err := exchange.OrderInfo(ctx, s.Info.OrderID, s.Info.ExtraIDs...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			Compare(t, info, s.Info.Expected)
+		})
+	}
+	if s.StatusFunc != nil {
+		t.Run("Status", func(t *testing.T) {
+			for status, expected := range s.Statuses {
+				if got := s.StatusFunc(status); got != expected {
+					t.Errorf("status %q: got %v, expected %v", status, got, expected)
+				}
+			}
+		})
+	}
+	if capabilities, ok := instantswap.ExchangeCapabilities(s.Exchange); ok {
+		t.Run("Capabilities", func(t *testing.T) {
+			checkNotSupported(t, server, exchange, capabilities)
+		})
+	}
+}
+
+func checkSymbols(t *testing.T, currencies []instantswap.Currency, expected []string) {
+	t.Helper()
+	symbols := make(map[string]bool, len(currencies))
+	for _, c := range currencies {
+		symbols[strings.ToUpper(c.Symbol)] = true
+	}
+	for _, symbol := range expected {
+		if !symbols[strings.ToUpper(symbol)] {
+			t.Errorf("%s not found in %d currencies", symbol, len(currencies))
+		}
+	}
+}
+
+func checkNotSupported(t *testing.T, server *Server, exchange instantswap.IDExchangeCtx, capabilities instantswap.Capabilities) {
+	ctx := context.Background()
+	var calls []func() error
+	if !capabilities.Cancel {
+		calls = append(calls, func() error {
+			_, err := exchange.CancelOrder(ctx, "order")
+			return err
+		})
+	}
+	if !capabilities.Update {
+		calls = append(calls, func() error {
+			_, err := exchange.UpdateOrder(ctx, "order")
+			return err
+		})
+	}
+	if !capabilities.PairListing {
+		calls = append(calls, func() error {
+			_, err := exchange.GetCurrenciesToPair(ctx, "BTC")
+			return err
+		})
+	}
+	if !capabilities.Limits {
+		calls = append(calls, func() error {
+			_, err := exchange.QueryLimits(ctx, "BTC", "LTC")
+			return err
+		})
+	}
+	for _, rateType := range []instantswap.RateType{instantswap.RateTypeFloat, instantswap.RateTypeFixed} {
+		if capabilities.SupportsRateType(rateType) {
+			continue
+		}
+		rateType := rateType
+		calls = append(calls, func() error {
+			_, err := exchange.GetExchangeRateInfo(ctx, instantswap.ExchangeRateRequest{
+				From: "BTC", To: "LTC", Amount: instantswap.MustParseAmount("1"), RateType: rateType,
+			})
+			return err
+		}, func() error {
+			_, err := exchange.CreateOrder(ctx, instantswap.CreateOrder{
+				FromCurrency: "BTC", ToCurrency: "LTC", InvoicedAmount: instantswap.MustParseAmount("1"),
+				Destination: "address", RateType: rateType,
+			})
+			return err
+		})
+	}
+	before := len(server.Requests())
+	for _, call := range calls {
+		if err := call(); !errors.Is(err, instantswap.ErrNotSupported) {
+			t.Errorf("expected ErrNotSupported, got %v", err)
+		}
+	}
+	if n := len(server.Requests()) - before; n != 0 {
+		t.Errorf("unsupported methods sent %d requests", n)
+	}
+}
+
+var (
+	amountType = reflect.TypeOf(instantswap.Amount{})
+	timeType   = reflect.TypeOf(time.Time{})
+)
+
+// Compare fails the test if got is not expected. Amounts and times are
+// compared by value and floats with a relative tolerance of 1e-9.
+func Compare(t testing.TB, got, expected interface{}) {
+	t.Helper()
+	for _, diff := range compare("", reflect.ValueOf(got), reflect.ValueOf(expected)) {
+		t.Error(diff)
+	}
+}
+
+func compare(path string, got, expected reflect.Value) (diffs []string) {
+	if got.Type() != expected.Type() {
+		return []string{fmt.Sprintf("%s: got type %s, expected %s", path, got.Type(), expected.Type())}
+	}
+	switch {
+	case got.Type() == amountType:
+		a, b := got.Interface().(instantswap.Amount), expected.Interface().(instantswap.Amount)
+		if a.Cmp(b) != 0 {
+			diffs = append(diffs, fmt.Sprintf("%s: got %s, expected %s", path, a, b))
+		}
+	case got.Type() == timeType:
+		a, b := got.Interface().(time.Time), expected.Interface().(time.Time)
+		if !a.Equal(b) {
+			diffs = append(diffs, fmt.Sprintf("%s: got %s, expected %s", path, a, b))
+		}
+	case got.Kind() == reflect.Float64 || got.Kind() == reflect.Float32:
+		a, b := got.Float(), expected.Float()
+		if math.Abs(a-b) > 1e-9*math.Max(math.Abs(a), math.Abs(b)) {
+			diffs = append(diffs, fmt.Sprintf("%s: got %v, expected %v", path, a, b))
+		}
+	case got.Kind() == reflect.Struct:
+		for i := 0; i < got.NumField(); i++ {
+			name := got.Type().Field(i).Name
+			if path != "" {
+				name = path + "." + name
+			}
+			diffs = append(diffs, compare(name, got.Field(i), expected.Field(i))...)
+		}
+	default:
+		if !reflect.DeepEqual(got.Interface(), expected.Interface()) {
+			diffs = append(diffs, fmt.Sprintf("%s: got %#v, expected %#v", path, got.Interface(), expected.Interface()))
+		}
+	}
+	return diffs
+}
@@ -0,0 +1,52 @@
+package index
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/crypto-power/instantswap/instantswap"
+)
+
+func TestBaseURL(t *testing.T) {
+	var mu sync.Mutex
+	var paths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		paths = append(paths, r.URL.Path)
+		mu.Unlock()
+		http.Error(w, "{}", http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	for _, name := range instantswap.ExchangeNames() {
+		mu.Lock()
+		paths = nil
+		mu.Unlock()
+		exchange, err := instantswap.NewExchangeCtx(name, instantswap.ExchangeConfig{
+			ApiKey:    "key",
+			ApiSecret: "secret",
+			Retry:     &instantswap.NoRetry,
+			RateLimit: &instantswap.NoRateLimit,
+			Onion:     true,
+			BaseURL:   server.URL + "/mirror",
+		})
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		exchange.GetCurrencies(context.Background())
+		mu.Lock()
+		if len(paths) == 0 {
+			t.Errorf("%s: no request sent to the base url", name)
+		}
+		for _, path := range paths {
+			if !strings.HasPrefix(path, "/mirror/") {
+				t.Errorf("%s: request path %q outside of the base url", name, path)
+			}
+		}
+		mu.Unlock()
+	}
+}
@@ -1,8 +1,10 @@
 package instantswap
 
 import (
+	"context"
 	"fmt"
 	"log"
+	"sort"
 	"sync"
 )
 
@@ -25,27 +27,58 @@ type IDExchange interface {
 	GetExchangeRateInfo(vars ExchangeRateRequest) (res ExchangeRateInfo, err error)
 }
 
+// IDExchangeCtx is the context-aware form of IDExchange. Every call is bound to
+// ctx, cancelling ctx or reaching its deadline aborts the in-flight request.
+type IDExchangeCtx interface {
+	// GetCurrencies returns current active currencies in the exchange
+	GetCurrencies(ctx context.Context) (currencies []Currency, err error)
+	// GetCurrenciesToPair return a list of available currencies for a specific currency
+	GetCurrenciesToPair(ctx context.Context, from string) (currencies []Currency, err error)
+	QueryLimits(ctx context.Context, fromCurr, toCurr string) (res QueryLimits, err error)
+	CreateOrder(ctx context.Context, vars CreateOrder) (res CreateResultInfo, err error)
+	//UpdateOrder accepts orderID value and more if needed per lib
+	UpdateOrder(ctx context.Context, vars interface{}) (res UpdateOrderResultInfo, err error)
+	CancelOrder(ctx context.Context, orderID string) (res string, err error)
+
+	//OrderInfo accepts orderID value and more if needed per lib
+	OrderInfo(ctx context.Context, orderID string, extraIds ...string) (res OrderInfoResult, err error)
+
+	GetExchangeRateInfo(ctx context.Context, vars ExchangeRateRequest) (res ExchangeRateInfo, err error)
+}
+
 type ExchangeRateRequest struct {
 	From        string
 	FromNetwork string
 	To          string
 	ToNetwork   string
-	Amount      float64
+	// Amount is the amount sent, or received when Direction is DirectionTo.
+	Amount Amount
+	// Direction selects a reverse quote, the deposit required to receive
+	// Amount is returned in ExchangeRateInfo.DepositAmount.
+	Direction Direction
+	// RateType selects a fixed or floating rate quote, ErrNotSupported is
+	// returned if the exchange does not offer it.
+	RateType RateType
 }
 
 var driv = driver{
-	mux:   new(sync.RWMutex),
-	stack: make(map[string]NewExchangeFunc),
+	mux:          new(sync.RWMutex),
+	stack:        make(map[string]NewExchangeCtxFunc),
+	capabilities: make(map[string]Capabilities),
 }
 
 type NewExchangeFunc func(config ExchangeConfig) (IDExchange, error)
 
+// NewExchangeCtxFunc builds a context-aware exchange client.
+type NewExchangeCtxFunc func(config ExchangeConfig) (IDExchangeCtx, error)
+
 type driver struct {
-	mux   *sync.RWMutex
-	stack map[string]NewExchangeFunc
+	mux          *sync.RWMutex
+	stack        map[string]NewExchangeCtxFunc
+	capabilities map[string]Capabilities
 }
 
-func (d *driver) registerExchange(symbol string, newExchange NewExchangeFunc) {
+func (d *driver) registerExchange(symbol string, newExchange NewExchangeCtxFunc) {
 	d.mux.Lock()
 	defer d.mux.Unlock()
 	_, ok := d.stack[symbol]
@@ -55,20 +88,65 @@ func (d *driver) registerExchange(symbol string, newExchange NewExchangeFunc) {
 	d.stack[symbol] = newExchange
 }
 
-func (d *driver) newExchange(name string, config ExchangeConfig) (IDExchange, error) {
+func (d *driver) exchangeNames() []string {
+	d.mux.RLock()
+	defer d.mux.RUnlock()
+	names := make([]string, 0, len(d.stack))
+	for name := range d.stack {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (d *driver) newExchange(name string, config ExchangeConfig) (IDExchangeCtx, error) {
 	d.mux.Lock()
 	defer d.mux.Unlock()
 	newExplorer, ok := d.stack[name]
 	if !ok {
 		return nil, fmt.Errorf("[%s] exchange is not registered yet", name)
 	}
-	return newExplorer(config)
+	exchange, err := newExplorer(config)
+	if err != nil {
+		return nil, err
+	}
+	return &errorExchange{name: name, exchange: exchange}, nil
 }
 
+// RegisterExchange registers an exchange implementing the legacy IDExchange
+// interface. Its methods are wrapped with WithContext.
 func RegisterExchange(symbol string, newExchange NewExchangeFunc) {
+	driv.registerExchange(symbol, func(config ExchangeConfig) (IDExchangeCtx, error) {
+		exchange, err := newExchange(config)
+		if err != nil {
+			return nil, err
+		}
+		return WithContext(exchange), nil
+	})
+}
+
+// RegisterExchangeCtx registers an exchange implementing IDExchangeCtx.
+func RegisterExchangeCtx(symbol string, newExchange NewExchangeCtxFunc) {
 	driv.registerExchange(symbol, newExchange)
 }
 
+// NewExchange returns the registered exchange as an IDExchange, every call
+// runs with context.Background().
 func NewExchange(symbol string, config ExchangeConfig) (IDExchange, error) {
+	exchange, err := driv.newExchange(symbol, config)
+	if err != nil {
+		return nil, err
+	}
+	return WithoutContext(exchange), nil
+}
+
+// ExchangeNames returns the sorted names of all registered exchanges.
+func ExchangeNames() []string {
+	return driv.exchangeNames()
+}
+
+// NewExchangeCtx returns the registered exchange as an IDExchangeCtx. The
+// errors it returns are *Error values.
+func NewExchangeCtx(symbol string, config ExchangeConfig) (IDExchangeCtx, error) {
 	return driv.newExchange(symbol, config)
 }
@@ -1,5 +1,11 @@
 package instantswap
 
+import (
+	"net/http"
+	"strings"
+	"time"
+)
+
 type ExchangeConfig struct {
 	Debug     bool
 	ApiKey    string
@@ -7,6 +13,43 @@ type ExchangeConfig struct {
 	// AffiliateId is used to earn refer coin from transaction
 	AffiliateId string
 	UserId      string
+	// Retry is the retry policy of the requests, DefaultRetryPolicy if nil.
+	Retry *RetryPolicy
+	// RateLimit is the limit of the requests sent to the exchange by all its
+	// instances in the process with the same ApiKey and BaseURL, the default
+	// of the exchange if nil. See SharedRateLimiter.
+	RateLimit *RateLimit
+	// HTTPClient is the client sending the requests to the exchange, to
+	// share a connection pool or set custom TLS roots. A client using
+	// Transport is created if nil.
+	HTTPClient *http.Client
+	// Transport is the RoundTripper of the client created when HTTPClient is
+	// nil, to route the requests through a SOCKS5 proxy or Tor for example.
+	Transport http.RoundTripper
+	// Onion selects the onion address of the exchanges which publish one, see
+	// Capabilities.Onion. The client must be able to reach onion services.
+	Onion bool
+	// BaseURL replaces the api endpoint of the exchange, to use a mirror, a
+	// staging server or a local stand-in. It takes precedence over Onion.
+	BaseURL string
+}
+
+// HTTPClientOrDefault returns HTTPClient, or a new client using Transport if
+// nil.
+func (c *ExchangeConfig) HTTPClientOrDefault() *http.Client {
+	if c.HTTPClient != nil {
+		return c.HTTPClient
+	}
+	return &http.Client{Transport: c.Transport}
+}
+
+// BaseURLOrDefault returns BaseURL, or def if empty. The result ends with a
+// slash as the adapters append the api paths to it.
+func (c *ExchangeConfig) BaseURLOrDefault(def string) string {
+	if c.BaseURL == "" {
+		return def
+	}
+	return strings.TrimSuffix(c.BaseURL, "/") + "/"
 }
 
 //DECENTRALIZED EXCHANGES
@@ -37,21 +80,24 @@ type ActiveCurr struct {
 }
 
 type QueryLimits struct {
-	Max float64 `json:"max,string"`
-	Min float64 `json:"min,string"`
+	Max Amount `json:"max"`
+	Min Amount `json:"min"`
 }
 
 // CREATE
 type CreateOrder struct {
-	RefundAddress  string  `json:"refund_address"`
-	Destination    string  `json:"destination"`
-	FromCurrency   string  `json:"from_currency"`
-	OrderedAmount  float64 `json:"ordered_amount,string"`  //amount in "to" currency. you want to be received
-	InvoicedAmount float64 `json:"invoiced_amount,string"` //amount in "from" currency. you will send it
-	ToCurrency     string  `json:"to_currency"`
-	FromNetwork    string  `json:"from_network"`
-	ToNetwork      string  `json:"to_network"`
-	Provider       string  `json:"Provider"` // used for some intermediate exchange
+	RefundAddress  string `json:"refund_address"`
+	Destination    string `json:"destination"`
+	FromCurrency   string `json:"from_currency"`
+	OrderedAmount  Amount `json:"ordered_amount"`  //amount in "to" currency. you want to be received
+	InvoicedAmount Amount `json:"invoiced_amount"` //amount in "from" currency. you will send it
+	ToCurrency     string `json:"to_currency"`
+	FromNetwork    string `json:"from_network"`
+	ToNetwork      string `json:"to_network"`
+	Provider       string `json:"Provider"` // used for some intermediate exchange
+	// RateType selects a fixed or floating rate order. A fixed rate order
+	// usually requires the Signature of a quote of the same type.
+	RateType RateType `json:"rate_type,omitempty"`
 
 	//changenow.io
 	ExtraID string `json:"extraId,omitempty"` //changenow.io requirement
@@ -65,12 +111,13 @@ type CreateOrder struct {
 	RefundExtraID string `json:"refundExtraId,omitempty"`
 }
 type CreateResultInfo struct {
-	ChargedFee     float64 `json:"charged_fee,string,omitempty"`
-	Destination    string  `json:"destination,omitempty"`
+	ChargedFee  Amount `json:"charged_fee,omitempty"`
+	Destination string `json:"destination,omitempty"`
+	// ExchangeRate is in units of ToCurrency per unit of FromCurrency.
 	ExchangeRate   float64 `json:"exchange_rate,string,omitempty"`
 	FromCurrency   string  `json:"from_currency,omitempty"`
-	InvoicedAmount float64 `json:"invoiced_amount,string,omitempty"`
-	OrderedAmount  float64 `json:"ordered_amount,string,omitempty"`
+	InvoicedAmount Amount  `json:"invoiced_amount,omitempty"`
+	OrderedAmount  Amount  `json:"ordered_amount,omitempty"`
 	ToCurrency     string  `json:"to_currency,omitempty"`
 	UUID           string  `json:"uuid,omitempty"`
 
@@ -86,21 +133,21 @@ type CreateResult struct {
 
 // UPDATE
 type UpdateOrderInfo struct {
-	Destination   string  `json:"destination"`
-	OrderedAmount float64 `json:"ordered_amount,string"`
-	RefundAddress string  `json:"refund_address"`
-	UUID          string  `json:"uuid"`
+	Destination   string `json:"destination"`
+	OrderedAmount Amount `json:"ordered_amount"`
+	RefundAddress string `json:"refund_address"`
+	UUID          string `json:"uuid"`
 }
 type UpdateOrder struct {
 	Order UpdateOrderInfo `json:"order"`
 }
 type UpdateOrderResultInfo struct {
-	ChargedFee     float64 `json:"charged_fee,string"`
+	ChargedFee     Amount  `json:"charged_fee"`
 	Destination    string  `json:"destination"`
 	ExchangeRate   float64 `json:"exchange_rate,string"`
 	FromCurrency   string  `json:"from_currency"`
-	InvoicedAmount float64 `json:"invoiced_amount,string"`
-	OrderedAmount  float64 `json:"ordered_amount,string"`
+	InvoicedAmount Amount  `json:"invoiced_amount"`
+	OrderedAmount  Amount  `json:"ordered_amount"`
 	ToCurrency     string  `json:"to_currency"`
 	UUID           string  `json:"uuid"`
 }
@@ -131,7 +178,7 @@ type AcceptOrderResult struct {
 type OrderInfoResult struct {
 	Expires        int
 	LastUpdate     string // should be datetime object
-	ReceiveAmount  float64
+	ReceiveAmount  Amount
 	TxID           string
 	Status         string
 	InternalStatus Status
@@ -143,9 +190,9 @@ type UUID struct {
 }
 
 type EstimateAmount struct {
-	EstimatedAmount          float64     `json:"estimatedAmount"` //destinationCurrency
-	DepositAmount            float64     `json:"depositAmount,omitempty"`
-	NetworkFee               float64     `json:"networkFee,omitempty"`
+	EstimatedAmount          Amount      `json:"estimatedAmount"` //destinationCurrency
+	DepositAmount            Amount      `json:"depositAmount,omitempty"`
+	NetworkFee               Amount      `json:"networkFee,omitempty"`
 	ServiceCommission        float64     `json:"serviceCommission,omitempty"`
 	TransactionSpeedForecast string      `json:"transactionSpeedForecast,omitempty"`
 	WarningMessage           interface{} `json:"warningMessage,omitempty"`
@@ -156,15 +203,50 @@ type EstimateAmount struct {
 
 type ExchangeRateInfo struct {
 	// Min is the smallest amount will be accepted by the exchange
-	Min float64
+	Min Amount
 	// Max is the maximum amount will be accepted by the exchange
 	// return Max = 0 means: there are not limited amount
-	Max             float64
+	Max Amount
+	// ExchangeRate is in units of To per unit of From, the currencies of the
+	// request, whatever its Direction.
 	ExchangeRate    float64
-	EstimatedAmount float64
-	MaxOrder        float64
-	Signature       string
-	Provider        string // used for some intermediate exchange
+	EstimatedAmount Amount
+	// DepositAmount is the amount to send to receive EstimatedAmount, the
+	// requested amount of a DirectionFrom quote.
+	DepositAmount Amount
+	MaxOrder      Amount
+	Signature     string
+	Provider      string // used for some intermediate exchange
+	// RateType is the type of the quoted rate, RateTypeDefault if the
+	// exchange does not tell.
+	RateType RateType
+	// ValidUntil is the expiry of the quote, zero if unknown. The orders of
+	// a fixed rate quote must be created before it.
+	ValidUntil time.Time
+	// Fees explains the difference between EstimatedAmount and the amount
+	// the market rate would give.
+	Fees Fees
+}
+
+// Fees is the breakdown of the fees of a quote, as far as the exchange
+// reports them: a zero field is unknown rather than free.
+type Fees struct {
+	// NetworkFee is the fee of the transaction sending the "to" currency,
+	// deducted from the received amount.
+	NetworkFee Amount
+	// ServiceFeePercent is the commission of the exchange, in percent of the
+	// exchanged amount.
+	ServiceFeePercent float64
+	// ServiceFee is the commission of the exchange, in the "to" currency.
+	ServiceFee Amount
+	// AffiliateFeePercent is the markup added for the affiliate of
+	// ExchangeConfig.AffiliateId, in percent.
+	AffiliateFeePercent float64
+}
+
+// IsZero reports whether the exchange reported no fee.
+func (f Fees) IsZero() bool {
+	return f.NetworkFee.IsZero() && f.ServiceFeePercent == 0 && f.ServiceFee.IsZero() && f.AffiliateFeePercent == 0
 }
 
 type Status int
@@ -213,6 +295,17 @@ func (s Status) String() string {
 	}
 }
 
+// IsFinal reports whether the order can not change anymore.
+func (s Status) IsFinal() bool {
+	switch s {
+	case OrderStatusCompleted, OrderStatusRefunded, OrderStatusCanceled,
+		OrderStatusExpired, OrderStatusFailed:
+		return true
+	default:
+		return false
+	}
+}
+
 type Currency struct {
 	Name     string
 	Symbol   string
@@ -0,0 +1,294 @@
+package instantswap
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"sync"
+	"time"
+)
+
+// OrderStore persists the orders created on the exchanges and the statuses
+// observed for them, so open orders can be resumed after a restart. The
+// orders are identified by the name of their exchange and their id.
+type OrderStore interface {
+	// SaveOrder records an order created with CreateOrder on exchange.
+	SaveOrder(exchange string, req CreateOrder, res CreateResultInfo) error
+	// AddStatus records a status returned by OrderInfo for the order orderID
+	// of exchange. A status equal to the last recorded one is ignored.
+	AddStatus(exchange, orderID string, info OrderInfoResult) error
+	// Order returns a recorded order, ErrOrderNotFound if it is unknown.
+	Order(exchange, orderID string) (StoredOrder, error)
+	// Orders returns every recorded order, oldest first.
+	Orders() ([]StoredOrder, error)
+	// OpenOrders returns the recorded orders which have not reached a final
+	// status, oldest first.
+	OpenOrders() ([]StoredOrder, error)
+	Close() error
+}
+
+// StoredOrder is an order recorded in an OrderStore.
+type StoredOrder struct {
+	Exchange  string           `json:"exchange"`
+	OrderID   string           `json:"order_id"`
+	Request   CreateOrder      `json:"request"`
+	Result    CreateResultInfo `json:"result"`
+	Statuses  []OrderStatus    `json:"statuses,omitempty"`
+	CreatedAt time.Time        `json:"created_at"`
+}
+
+// OrderStatus is a status of an order observed with OrderInfo.
+type OrderStatus struct {
+	Status         Status    `json:"status"`
+	ExchangeStatus string    `json:"exchange_status,omitempty"`
+	TxID           string    `json:"tx_id,omitempty"`
+	ReceiveAmount  Amount    `json:"receive_amount"`
+	Confirmations  string    `json:"confirmations,omitempty"`
+	Time           time.Time `json:"time"`
+}
+
+// Status returns the last recorded status of the order.
+func (o StoredOrder) Status() Status {
+	if len(o.Statuses) == 0 {
+		return OrderStatusUnknown
+	}
+	return o.Statuses[len(o.Statuses)-1].Status
+}
+
+// ExtraIDs returns the extra ids to pass to OrderInfo for this order.
+func (o StoredOrder) ExtraIDs() []string {
+	if o.Result.ExtraID == "" {
+		return nil
+	}
+	return []string{o.Result.ExtraID}
+}
+
+// orderKey identifies an order of a FileOrderStore, the ids of different
+// exchanges may collide.
+type orderKey struct {
+	exchange string
+	orderID  string
+}
+
+// FileOrderStore is an OrderStore keeping the orders as JSON lines in a
+// file. Each change rewrites a temporary file renamed over the store, so an
+// interrupted write leaves the previous content.
+type FileOrderStore struct {
+	mux    sync.RWMutex
+	path   string
+	closed bool
+	orders map[orderKey]*StoredOrder
+}
+
+// OpenFileOrderStore opens the order file at path, it is created by the
+// first order saved.
+func OpenFileOrderStore(path string) (*FileOrderStore, error) {
+	s := &FileOrderStore{
+		path:   path,
+		orders: make(map[orderKey]*StoredOrder),
+	}
+	if err := s.load(); err != nil {
+		return nil, fmt.Errorf("loading order store %s: %w", path, err)
+	}
+	return s, nil
+}
+
+func (s *FileOrderStore) load() error {
+	file, err := os.Open(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	dec := json.NewDecoder(file)
+	for {
+		var order StoredOrder
+		err := dec.Decode(&order)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		s.orders[orderKey{order.Exchange, order.OrderID}] = &order
+	}
+}
+
+// write replaces the file with the orders of the store.
+func (s *FileOrderStore) write() (err error) {
+	if s.closed {
+		return fmt.Errorf("order store is closed")
+	}
+	tmp := s.path + ".tmp"
+	file, err := os.OpenFile(tmp, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			file.Close()
+			os.Remove(tmp)
+		}
+	}()
+	w := bufio.NewWriter(file)
+	enc := json.NewEncoder(w)
+	for _, order := range s.sorted(func(StoredOrder) bool { return true }) {
+		if err = enc.Encode(order); err != nil {
+			return err
+		}
+	}
+	if err = w.Flush(); err != nil {
+		return err
+	}
+	if err = file.Sync(); err != nil {
+		return err
+	}
+	if err = file.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.path)
+}
+
+// SaveOrder implements OrderStore.
+func (s *FileOrderStore) SaveOrder(exchange string, req CreateOrder, res CreateResultInfo) error {
+	if res.UUID == "" {
+		return fmt.Errorf("order has no id")
+	}
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	key := orderKey{exchange, res.UUID}
+	if _, ok := s.orders[key]; ok {
+		return fmt.Errorf("order %s of %s already exists", res.UUID, exchange)
+	}
+	s.orders[key] = &StoredOrder{
+		Exchange:  exchange,
+		OrderID:   res.UUID,
+		Request:   req,
+		Result:    res,
+		CreatedAt: time.Now().UTC(),
+	}
+	if err := s.write(); err != nil {
+		delete(s.orders, key)
+		return err
+	}
+	return nil
+}
+
+// AddStatus implements OrderStore.
+func (s *FileOrderStore) AddStatus(exchange, orderID string, info OrderInfoResult) error {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	order, ok := s.orders[orderKey{exchange, orderID}]
+	if !ok {
+		return ErrOrderNotFound
+	}
+	status := OrderStatus{
+		Status:         info.InternalStatus,
+		ExchangeStatus: info.Status,
+		TxID:           info.TxID,
+		ReceiveAmount:  info.ReceiveAmount,
+		Confirmations:  info.Confirmations,
+	}
+	n := len(order.Statuses)
+	if n > 0 && order.Statuses[n-1].equal(status) {
+		return nil
+	}
+	status.Time = time.Now().UTC()
+	order.Statuses = append(order.Statuses, status)
+	if err := s.write(); err != nil {
+		order.Statuses = order.Statuses[:n]
+		return err
+	}
+	return nil
+}
+
+func (s OrderStatus) equal(other OrderStatus) bool {
+	return s.Status == other.Status && s.ExchangeStatus == other.ExchangeStatus &&
+		s.TxID == other.TxID && s.ReceiveAmount.Cmp(other.ReceiveAmount) == 0 &&
+		s.Confirmations == other.Confirmations
+}
+
+// Order implements OrderStore.
+func (s *FileOrderStore) Order(exchange, orderID string) (StoredOrder, error) {
+	s.mux.RLock()
+	defer s.mux.RUnlock()
+	order, ok := s.orders[orderKey{exchange, orderID}]
+	if !ok {
+		return StoredOrder{}, ErrOrderNotFound
+	}
+	return order.copy(), nil
+}
+
+// Orders implements OrderStore.
+func (s *FileOrderStore) Orders() ([]StoredOrder, error) {
+	return s.list(func(StoredOrder) bool { return true }), nil
+}
+
+// OpenOrders implements OrderStore.
+func (s *FileOrderStore) OpenOrders() ([]StoredOrder, error) {
+	return s.list(func(o StoredOrder) bool { return !o.Status().IsFinal() }), nil
+}
+
+func (s *FileOrderStore) list(filter func(StoredOrder) bool) []StoredOrder {
+	s.mux.RLock()
+	defer s.mux.RUnlock()
+	return s.sorted(filter)
+}
+
+// sorted returns the orders matching filter, oldest first. The lock must be
+// held.
+func (s *FileOrderStore) sorted(filter func(StoredOrder) bool) []StoredOrder {
+	orders := make([]StoredOrder, 0, len(s.orders))
+	for _, order := range s.orders {
+		if filter(*order) {
+			orders = append(orders, order.copy())
+		}
+	}
+	sort.Slice(orders, func(i, j int) bool {
+		if !orders[i].CreatedAt.Equal(orders[j].CreatedAt) {
+			return orders[i].CreatedAt.Before(orders[j].CreatedAt)
+		}
+		if orders[i].Exchange != orders[j].Exchange {
+			return orders[i].Exchange < orders[j].Exchange
+		}
+		return orders[i].OrderID < orders[j].OrderID
+	})
+	return orders
+}
+
+func (o *StoredOrder) copy() StoredOrder {
+	c := *o
+	c.Statuses = append([]OrderStatus(nil), o.Statuses...)
+	return c
+}
+
+// Close implements OrderStore.
+func (s *FileOrderStore) Close() error {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	s.closed = true
+	return nil
+}
+
+// RecordEvents returns an OrderTracker event handler adding the statuses of
+// the tracked orders to store before passing the event to next. The orders
+// must be tracked with the exchange name they were saved with. Errors of the
+// store are reported to onError. next and onError may be nil.
+func RecordEvents(store OrderStore, next func(OrderEvent), onError func(error)) func(OrderEvent) {
+	return func(event OrderEvent) {
+		if event.Err == nil {
+			if err := store.AddStatus(event.Exchange, event.OrderID, event.Info); err != nil && onError != nil {
+				onError(err)
+			}
+		}
+		if next != nil {
+			next(event)
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package instantswap
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFileOrderStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "orders.jsonl")
+	store, err := OpenFileOrderStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req := CreateOrder{FromCurrency: "BTC", ToCurrency: "DCR", InvoicedAmount: MustParseAmount("0.5")}
+	for _, id := range []string{"open", "done"} {
+		res := CreateResultInfo{UUID: id, DepositAddress: "deposit-" + id, ExtraID: "token-" + id}
+		if err := store.SaveOrder("flypme", req, res); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := store.SaveOrder("flypme", req, CreateResultInfo{UUID: "open"}); err == nil {
+		t.Error("expected error saving an order twice")
+	}
+	// The same id on another exchange is another order.
+	if err := store.SaveOrder("godex", req, CreateResultInfo{UUID: "done"}); err != nil {
+		t.Fatal(err)
+	}
+	statuses := []OrderInfoResult{
+		{InternalStatus: OrderStatusWaitingForDeposit},
+		{InternalStatus: OrderStatusWaitingForDeposit},
+		{InternalStatus: OrderStatusCompleted, TxID: "tx", ReceiveAmount: MustParseAmount("12.000000000001")},
+	}
+	for _, info := range statuses {
+		if err := store.AddStatus("flypme", "done", info); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := store.AddStatus("flypme", "open", statuses[0]); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.AddStatus("flypme", "unknown", statuses[0]); !errors.Is(err, ErrOrderNotFound) {
+		t.Errorf("expected ErrOrderNotFound, got %v", err)
+	}
+	if err := store.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Simulate a write interrupted by a crash.
+	if err := os.WriteFile(path+".tmp", []byte(`{"exchange":"flypme","order_id":"open","sta`), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	store, err = OpenFileOrderStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+	orders, err := store.Orders()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(orders) != 3 {
+		t.Fatalf("got %d orders, expected 3", len(orders))
+	}
+	done, err := store.Order("flypme", "done")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(done.Statuses) != 2 || done.Status() != OrderStatusCompleted {
+		t.Fatalf("unexpected statuses: %+v", done.Statuses)
+	}
+	if last := done.Statuses[1]; last.TxID != "tx" || last.ReceiveAmount.String() != "12.000000000001" {
+		t.Errorf("unexpected last status: %+v", last)
+	}
+	if done.Request.InvoicedAmount.String() != "0.5" || done.Result.DepositAddress != "deposit-done" {
+		t.Errorf("unexpected order: %+v", done)
+	}
+	if other, err := store.Order("godex", "done"); err != nil || len(other.Statuses) != 0 {
+		t.Errorf("unexpected godex order %+v, %v", other, err)
+	}
+
+	open, err := store.OpenOrders()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(open) != 2 || open[0].OrderID != "open" || open[0].Status() != OrderStatusWaitingForDeposit {
+		t.Fatalf("unexpected open orders: %+v", open)
+	}
+	if ids := open[0].ExtraIDs(); len(ids) != 1 || ids[0] != "token-open" {
+		t.Errorf("unexpected extra ids: %v", ids)
+	}
+	if err := store.AddStatus("flypme", "open", OrderInfoResult{InternalStatus: OrderStatusExpired}); err != nil {
+		t.Fatal(err)
+	}
+	if open, _ = store.OpenOrders(); len(open) != 1 || open[0].Exchange != "godex" {
+		t.Errorf("unexpected open orders: %+v", open)
+	}
+}
@@ -0,0 +1,378 @@
+package instantswap
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+)
+
+const defaultPairMatrixRefresh = time.Hour
+
+// PairMatrixConfig configures a PairMatrix.
+type PairMatrixConfig struct {
+	// Exchanges lists the exchanges of the matrix, all registered exchanges
+	// are used when it is empty.
+	Exchanges []string
+	// Configs holds the config of each exchange by name. Exchanges without
+	// an entry are created with an empty ExchangeConfig.
+	Configs map[string]ExchangeConfig
+	// Symbols restricts the matrix to the pairs of these currencies, every
+	// listed currency is used when it is empty. The pairs of each currency
+	// are requested from the exchanges supporting GetCurrenciesToPair, so
+	// a short list saves many requests.
+	Symbols []string
+	// Limits requests the limits of every pair from the exchanges
+	// supporting QueryLimits.
+	Limits bool
+	// Timeout bounds the time spent building the pairs of each exchange.
+	// Zero means only the caller's context applies.
+	Timeout time.Duration
+	// RefreshInterval is the interval of the rebuilds started by Start, 1
+	// hour by default.
+	RefreshInterval time.Duration
+}
+
+// PairEdge is a swap offered by an exchange, from an asset on a network to
+// another.
+type PairEdge struct {
+	Exchange    string `json:"exchange"`
+	From        string `json:"from"`
+	FromNetwork string `json:"from_network,omitempty"`
+	To          string `json:"to"`
+	ToNetwork   string `json:"to_network,omitempty"`
+	// Min and Max are the limits of the pair in From, nil when they are
+	// not known.
+	Min *Amount `json:"min,omitempty"`
+	Max *Amount `json:"max,omitempty"`
+	// Verified tells whether the exchange listed the pair. The exchanges
+	// which do not list their pairs get an unverified edge between every
+	// two currencies, the quote tells whether they swap them.
+	Verified bool `json:"verified"`
+}
+
+// PairMatrix is the graph of the pairs traded by several exchanges, it
+// answers which exchanges swap a currency to another. Refresh builds it,
+// Start rebuilds it periodically.
+type PairMatrix struct {
+	conf   PairMatrixConfig
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	mux       sync.RWMutex
+	exchanges map[string]IDExchangeCtx
+	// edges are the pairs of each exchange, kept when a rebuild fails.
+	edges map[string][]PairEdge
+	// errors are the errors of the last build of each exchange.
+	errors  map[string]error
+	updated time.Time
+	started bool
+}
+
+// NewPairMatrix creates an empty PairMatrix over the configured exchanges.
+// Exchanges which can not be created are reported by Errors.
+func NewPairMatrix(conf PairMatrixConfig) *PairMatrix {
+	if conf.RefreshInterval <= 0 {
+		conf.RefreshInterval = defaultPairMatrixRefresh
+	}
+	symbols := make([]string, len(conf.Symbols))
+	for i, symbol := range conf.Symbols {
+		symbols[i] = CanonicalSymbol(symbol)
+	}
+	conf.Symbols = symbols
+	ctx, cancel := context.WithCancel(context.Background())
+	m := &PairMatrix{
+		conf:      conf,
+		ctx:       ctx,
+		cancel:    cancel,
+		exchanges: make(map[string]IDExchangeCtx),
+		edges:     make(map[string][]PairEdge),
+		errors:    make(map[string]error),
+	}
+	names := conf.Exchanges
+	if len(names) == 0 {
+		names = ExchangeNames()
+	}
+	for _, name := range names {
+		exchange, err := NewExchangeCtx(name, conf.Configs[name])
+		if err != nil {
+			m.errors[name] = err
+			continue
+		}
+		m.exchanges[name] = exchange
+	}
+	return m
+}
+
+// Add adds or replaces an exchange of the matrix, its pairs are built by the
+// next Refresh.
+func (m *PairMatrix) Add(name string, exchange IDExchangeCtx) {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+	delete(m.errors, name)
+	delete(m.edges, name)
+	m.exchanges[name] = exchange
+}
+
+// Refresh rebuilds the pairs of every exchange concurrently. The pairs of an
+// exchange which fails are kept from the previous build and its error is
+// reported by Errors. The error returned is the context error, if any.
+func (m *PairMatrix) Refresh(ctx context.Context) error {
+	m.mux.RLock()
+	exchanges := make(map[string]IDExchangeCtx, len(m.exchanges))
+	for name, exchange := range m.exchanges {
+		exchanges[name] = exchange
+	}
+	m.mux.RUnlock()
+
+	var wg sync.WaitGroup
+	for name, exchange := range exchanges {
+		wg.Add(1)
+		go func(name string, exchange IDExchangeCtx) {
+			defer wg.Done()
+			edges, err := m.build(ctx, name, exchange)
+			m.mux.Lock()
+			defer m.mux.Unlock()
+			if m.exchanges[name] != exchange {
+				// Replaced by Add during the build.
+				return
+			}
+			if err != nil {
+				m.errors[name] = err
+				return
+			}
+			delete(m.errors, name)
+			m.edges[name] = edges
+		}(name, exchange)
+	}
+	wg.Wait()
+	m.mux.Lock()
+	m.updated = time.Now()
+	m.mux.Unlock()
+	return ctx.Err()
+}
+
+// Start refreshes the matrix in the background, at once and then every
+// RefreshInterval, until Close is called.
+func (m *PairMatrix) Start() {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+	if m.started || m.ctx.Err() != nil {
+		return
+	}
+	m.started = true
+	m.wg.Add(1)
+	go func() {
+		defer m.wg.Done()
+		ticker := time.NewTicker(m.conf.RefreshInterval)
+		defer ticker.Stop()
+		for {
+			m.Refresh(m.ctx)
+			select {
+			case <-ticker.C:
+			case <-m.ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+// Close stops the background refreshes and waits for them to return.
+func (m *PairMatrix) Close() {
+	m.cancel()
+	m.wg.Wait()
+}
+
+// build returns the pairs of an exchange.
+func (m *PairMatrix) build(ctx context.Context, name string, exchange IDExchangeCtx) ([]PairEdge, error) {
+	if m.conf.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, m.conf.Timeout)
+		defer cancel()
+	}
+	capabilities, _ := ExchangeCapabilities(name)
+	currencies, err := exchange.GetCurrencies(ctx)
+	if err != nil {
+		return nil, err
+	}
+	networks := make(map[string][]string)
+	for _, c := range currencies {
+		symbol := CanonicalSymbol(c.Symbol)
+		if len(m.conf.Symbols) > 0 && !containsString(m.conf.Symbols, symbol) {
+			continue
+		}
+		networks[symbol] = currencyNetworks(symbol, c.Networks, networks[symbol])
+	}
+
+	var edges []PairEdge
+	for from, fromNetworks := range networks {
+		targets, verified := networks, false
+		if capabilities.PairListing {
+			pairs, err := exchange.GetCurrenciesToPair(ctx, from)
+			switch {
+			case errors.Is(err, ErrPairUnavailable):
+				continue
+			case err != nil && !errors.Is(err, ErrNotSupported):
+				return nil, err
+			case err == nil:
+				targets, verified = make(map[string][]string), true
+				for _, c := range pairs {
+					symbol := CanonicalSymbol(c.Symbol)
+					if _, ok := networks[symbol]; ok {
+						targets[symbol] = currencyNetworks(symbol, c.Networks, targets[symbol])
+					}
+				}
+			}
+		}
+		for to, toNetworks := range targets {
+			if to == from {
+				continue
+			}
+			var min, max *Amount
+			if m.conf.Limits && capabilities.Limits {
+				limits, err := exchange.QueryLimits(ctx, from, to)
+				switch {
+				case err == nil:
+					if limits.Min.Sign() > 0 {
+						min = &limits.Min
+					}
+					if limits.Max.Sign() > 0 {
+						max = &limits.Max
+					}
+				case errors.Is(err, ErrPairUnavailable):
+					continue
+				case ctx.Err() != nil:
+					return nil, err
+				}
+			}
+			for _, fromNetwork := range fromNetworks {
+				for _, toNetwork := range toNetworks {
+					edges = append(edges, PairEdge{
+						Exchange:    name,
+						From:        from,
+						FromNetwork: fromNetwork,
+						To:          to,
+						ToNetwork:   toNetwork,
+						Min:         min,
+						Max:         max,
+						Verified:    verified,
+					})
+				}
+			}
+		}
+	}
+	return edges, nil
+}
+
+// currencyNetworks adds the networks of a listed currency to list, the
+// network of the coin when the exchange does not list them.
+func currencyNetworks(symbol string, networks, list []string) []string {
+	if len(networks) == 0 {
+		native, _ := NativeNetwork(symbol)
+		networks = []string{native}
+	}
+	for _, network := range networks {
+		network = CanonicalNetwork(symbol, network)
+		if !containsString(list, network) {
+			list = append(list, network)
+		}
+	}
+	return list
+}
+
+// Edges returns all the pairs of the matrix, sorted by exchange, from and to.
+func (m *PairMatrix) Edges() []PairEdge {
+	return m.Pairs("", "", "", "")
+}
+
+// Pairs returns the pairs swapping from on fromNetwork to to on toNetwork,
+// sorted by exchange, verified or not. An empty symbol or network matches
+// any.
+func (m *PairMatrix) Pairs(from, fromNetwork, to, toNetwork string) []PairEdge {
+	fromNetwork = CanonicalNetwork(from, fromNetwork)
+	toNetwork = CanonicalNetwork(to, toNetwork)
+	from, to = CanonicalSymbol(from), CanonicalSymbol(to)
+	m.mux.RLock()
+	var pairs []PairEdge
+	for _, edges := range m.edges {
+		for _, e := range edges {
+			if (from == "" || e.From == from) && (fromNetwork == "" || e.FromNetwork == fromNetwork) &&
+				(to == "" || e.To == to) && (toNetwork == "" || e.ToNetwork == toNetwork) {
+				pairs = append(pairs, e)
+			}
+		}
+	}
+	m.mux.RUnlock()
+	sort.Slice(pairs, func(i, j int) bool {
+		pi, pj := pairs[i], pairs[j]
+		switch {
+		case pi.Exchange != pj.Exchange:
+			return pi.Exchange < pj.Exchange
+		case pi.From != pj.From:
+			return pi.From < pj.From
+		case pi.FromNetwork != pj.FromNetwork:
+			return pi.FromNetwork < pj.FromNetwork
+		case pi.To != pj.To:
+			return pi.To < pj.To
+		default:
+			return pi.ToNetwork < pj.ToNetwork
+		}
+	})
+	return pairs
+}
+
+// Exchanges returns the sorted names of the exchanges swapping from on
+// fromNetwork to to on toNetwork. An empty network matches any.
+func (m *PairMatrix) Exchanges(from, fromNetwork, to, toNetwork string) []string {
+	var names []string
+	for _, e := range m.Pairs(from, fromNetwork, to, toNetwork) {
+		if !containsString(names, e.Exchange) {
+			names = append(names, e.Exchange)
+		}
+	}
+	return names
+}
+
+// Errors returns the errors of the exchanges whose last build failed, or
+// which could not be created.
+func (m *PairMatrix) Errors() map[string]error {
+	m.mux.RLock()
+	defer m.mux.RUnlock()
+	errs := make(map[string]error, len(m.errors))
+	for name, err := range m.errors {
+		errs[name] = err
+	}
+	return errs
+}
+
+// Updated returns the time of the last Refresh, zero before the first.
+func (m *PairMatrix) Updated() time.Time {
+	m.mux.RLock()
+	defer m.mux.RUnlock()
+	return m.updated
+}
+
+// MarshalJSON exports the matrix: its update time, its pairs and the errors
+// of the exchanges.
+func (m *PairMatrix) MarshalJSON() ([]byte, error) {
+	errs := make(map[string]string)
+	for name, err := range m.Errors() {
+		errs[name] = err.Error()
+	}
+	return json.Marshal(struct {
+		Updated time.Time         `json:"updated"`
+		Pairs   []PairEdge        `json:"pairs"`
+		Errors  map[string]string `json:"errors,omitempty"`
+	}{m.Updated(), m.Edges(), errs})
+}
+
+// exchange returns the exchange name of the matrix.
+func (m *PairMatrix) exchange(name string) (IDExchangeCtx, bool) {
+	m.mux.RLock()
+	defer m.mux.RUnlock()
+	exchange, ok := m.exchanges[name]
+	return exchange, ok
+}
@@ -0,0 +1,115 @@
+package instantswap
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+type pairExchange struct {
+	IDExchangeCtx
+	currencies []Currency
+	pairs      map[string][]Currency
+	err        error
+}
+
+func (p *pairExchange) GetCurrencies(ctx context.Context) ([]Currency, error) {
+	return p.currencies, p.err
+}
+
+func (p *pairExchange) GetCurrenciesToPair(ctx context.Context, from string) ([]Currency, error) {
+	pairs, ok := p.pairs[from]
+	if !ok {
+		return nil, NewError("", KindPairUnavailable, "", "unknown currency")
+	}
+	return pairs, nil
+}
+
+func (p *pairExchange) QueryLimits(ctx context.Context, fromCurr, toCurr string) (QueryLimits, error) {
+	return QueryLimits{Min: MustParseAmount("0.01")}, nil
+}
+
+func TestPairMatrix(t *testing.T) {
+	RegisterCapabilities("matrix-listing", Capabilities{PairListing: true, Limits: true})
+	m := NewPairMatrix(PairMatrixConfig{Exchanges: []string{"unregistered"}, Symbols: []string{"btc", "DCR", "USDT"}, Limits: true})
+	defer m.Close()
+	// Every pair of the listed currencies.
+	m.Add("matrix-all", &pairExchange{currencies: []Currency{
+		{Symbol: "BTC"}, {Symbol: "DCR"}, {Symbol: "XMR"},
+	}})
+	m.Add("matrix-listing", &pairExchange{
+		currencies: []Currency{{Symbol: "BTC"}, {Symbol: "DCR"}, {Symbol: "USDT", Networks: []string{NetworkEthereum, NetworkTron}}},
+		pairs: map[string][]Currency{
+			"BTC":  {{Symbol: "USDT", Networks: []string{NetworkTron}}},
+			"USDT": {{Symbol: "BTC"}, {Symbol: "DCR"}},
+		},
+	})
+	failing := &pairExchange{err: errors.New("boom")}
+	m.Add("matrix-failing", failing)
+	if err := m.Refresh(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		from, fromNetwork, to, toNetwork string
+		exchanges                        []string
+	}{
+		{"BTC", "", "DCR", "", []string{"matrix-all"}},
+		{"btc", "", "USDT", "", []string{"matrix-listing"}},
+		{"BTC", "", "USDT", "ERC20", nil},
+		{"USDT", "trc20", "DCR", "", []string{"matrix-listing"}},
+		{"DCR", "", "BTC", "", []string{"matrix-all"}},
+		{"BTC", "", "XMR", "", nil},
+		{"BTC", "", "", "", []string{"matrix-all", "matrix-listing"}},
+	}
+	for _, test := range tests {
+		got := m.Exchanges(test.from, test.fromNetwork, test.to, test.toNetwork)
+		if strings.Join(got, ",") != strings.Join(test.exchanges, ",") {
+			t.Errorf("%s on %q to %s on %q: got %v, expected %v", test.from, test.fromNetwork, test.to, test.toNetwork, got, test.exchanges)
+		}
+	}
+
+	pairs := m.Pairs("USDT", "", "BTC", "")
+	if len(pairs) != 2 || pairs[0].FromNetwork != NetworkEthereum || pairs[0].ToNetwork != NetworkBitcoin ||
+		pairs[0].Min == nil || pairs[0].Min.String() != "0.01" || pairs[0].Max != nil {
+		t.Errorf("unexpected pairs %+v", pairs)
+	}
+	if !pairs[0].Verified {
+		t.Errorf("listed pair not verified: %+v", pairs[0])
+	}
+	if pairs := m.Pairs("BTC", "", "DCR", ""); len(pairs) != 1 || pairs[0].Min != nil || pairs[0].Verified {
+		t.Errorf("pair of an exchange without pair listing: %+v", pairs)
+	}
+
+	errs := m.Errors()
+	if len(errs) != 2 || errs["matrix-failing"] == nil || errs["unregistered"] == nil {
+		t.Errorf("unexpected errors %v", errs)
+	}
+
+	// The pairs of an exchange are kept when its rebuild fails.
+	failing.err = nil
+	failing.currencies = []Currency{{Symbol: "BTC"}, {Symbol: "DCR"}}
+	m.Refresh(context.Background())
+	failing.err = errors.New("boom")
+	m.Refresh(context.Background())
+	if got := m.Exchanges("BTC", "", "DCR", ""); len(got) != 2 || got[1] != "matrix-failing" {
+		t.Errorf("got exchanges %v", got)
+	}
+
+	b, err := json.Marshal(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var exported struct {
+		Pairs  []PairEdge
+		Errors map[string]string
+	}
+	if err := json.Unmarshal(b, &exported); err != nil {
+		t.Fatal(err)
+	}
+	if len(exported.Pairs) != len(m.Edges()) || exported.Errors["matrix-failing"] != "boom" {
+		t.Errorf("unexpected export %s", b)
+	}
+}
@@ -0,0 +1,168 @@
+package instantswap
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// RateLimit is the client-side limit of the requests sent to an exchange.
+type RateLimit struct {
+	// Rate is the number of requests per second, 0 disables the limit.
+	Rate float64
+	// Burst is the number of requests which can be sent at once.
+	Burst int
+}
+
+// DefaultRateLimit is used for the exchanges which did not register a limit
+// with RegisterRateLimit.
+var DefaultRateLimit = RateLimit{Rate: 2, Burst: 5}
+
+// NoRateLimit disables the rate limit.
+var NoRateLimit = RateLimit{}
+
+// RateLimiter is a token bucket limiting the requests to an exchange.
+type RateLimiter struct {
+	mu     sync.Mutex
+	limit  RateLimit
+	tokens float64
+	last   time.Time
+}
+
+// NewRateLimiter returns a RateLimiter with a full bucket.
+func NewRateLimiter(limit RateLimit) *RateLimiter {
+	return &RateLimiter{
+		limit:  limit,
+		tokens: float64(limit.burst()),
+		last:   time.Now(),
+	}
+}
+
+func (l RateLimit) burst() int {
+	if l.Burst < 1 {
+		return 1
+	}
+	return l.Burst
+}
+
+// SetLimit changes the limit, the tokens already available are kept up to
+// the new burst.
+func (l *RateLimiter) SetLimit(limit RateLimit) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.advance(time.Now())
+	l.limit = limit
+	if burst := float64(limit.burst()); l.tokens > burst {
+		l.tokens = burst
+	}
+}
+
+// Limit returns the current limit.
+func (l *RateLimiter) Limit() RateLimit {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.limit
+}
+
+// advance adds the tokens accumulated since the last update.
+func (l *RateLimiter) advance(now time.Time) {
+	if now.After(l.last) {
+		l.tokens += now.Sub(l.last).Seconds() * l.limit.Rate
+		if burst := float64(l.limit.burst()); l.tokens > burst {
+			l.tokens = burst
+		}
+		l.last = now
+	}
+}
+
+// Wait blocks until a request can be sent or ctx is done. It returns
+// ctx.Err() without waiting when the deadline of ctx is too close.
+func (l *RateLimiter) Wait(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	l.mu.Lock()
+	if l.limit.Rate <= 0 {
+		l.mu.Unlock()
+		return nil
+	}
+	now := time.Now()
+	l.advance(now)
+	l.tokens--
+	if l.tokens >= 0 {
+		l.mu.Unlock()
+		return nil
+	}
+	delay := time.Duration(-l.tokens / l.limit.Rate * float64(time.Second))
+	if deadline, ok := ctx.Deadline(); ok && deadline.Before(now.Add(delay)) {
+		l.tokens++
+		l.mu.Unlock()
+		return context.DeadlineExceeded
+	}
+	l.mu.Unlock()
+
+	if err := sleep(ctx, delay); err != nil {
+		// give back the reserved token
+		l.mu.Lock()
+		l.advance(time.Now())
+		l.tokens++
+		if burst := float64(l.limit.burst()); l.tokens > burst {
+			l.tokens = burst
+		}
+		l.mu.Unlock()
+		return err
+	}
+	return nil
+}
+
+// rateLimitKey identifies the clients sharing a RateLimiter: the clients of
+// an exchange using the same api key on the same endpoint.
+type rateLimitKey struct {
+	exchange, apiKey, baseURL string
+}
+
+var rateLimits = struct {
+	mu       sync.Mutex
+	defaults map[string]RateLimit
+	limiters map[rateLimitKey]*RateLimiter
+}{
+	defaults: make(map[string]RateLimit),
+	limiters: make(map[rateLimitKey]*RateLimiter),
+}
+
+// RegisterRateLimit sets the default rate limit of the exchange symbol. It is
+// called by the exchange packages next to RegisterExchangeCtx.
+func RegisterRateLimit(symbol string, limit RateLimit) {
+	rateLimits.mu.Lock()
+	defer rateLimits.mu.Unlock()
+	rateLimits.defaults[symbol] = limit
+}
+
+// SharedRateLimiter returns the RateLimiter shared by the clients of the
+// exchange in the process which use the ApiKey and the BaseURL of conf, as
+// the exchanges limit the requests of an account or of an address. The
+// clients with another key or endpoint have their own limiter.
+// ExchangeConfig.RateLimit replaces the limit of the shared limiter when set,
+// for all the clients sharing it, the registered default of the exchange or
+// DefaultRateLimit is used otherwise.
+func SharedRateLimiter(exchange string, conf *ExchangeConfig) *RateLimiter {
+	key := rateLimitKey{exchange: exchange, apiKey: conf.ApiKey, baseURL: conf.BaseURL}
+	rateLimits.mu.Lock()
+	defer rateLimits.mu.Unlock()
+	limiter, ok := rateLimits.limiters[key]
+	switch {
+	case ok && conf.RateLimit != nil:
+		limiter.SetLimit(*conf.RateLimit)
+	case !ok:
+		limit, registered := rateLimits.defaults[exchange]
+		if !registered {
+			limit = DefaultRateLimit
+		}
+		if conf.RateLimit != nil {
+			limit = *conf.RateLimit
+		}
+		limiter = NewRateLimiter(limit)
+		rateLimits.limiters[key] = limiter
+	}
+	return limiter
+}
@@ -0,0 +1,85 @@
+package instantswap
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestRateLimiterWait(t *testing.T) {
+	limiter := NewRateLimiter(RateLimit{Rate: 20, Burst: 3})
+	ctx := context.Background()
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		if err := limiter.Wait(ctx); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed > 20*time.Millisecond {
+		t.Fatalf("burst was limited, took %v", elapsed)
+	}
+	if err := limiter.Wait(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed < 40*time.Millisecond {
+		t.Errorf("request was not limited, took %v", elapsed)
+	}
+
+	// The deadline is before the next token, Wait must fail at once.
+	deadlineCtx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	start = time.Now()
+	if err := limiter.Wait(deadlineCtx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected deadline exceeded, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Millisecond {
+		t.Errorf("Wait blocked for %v", elapsed)
+	}
+
+	limiter = NewRateLimiter(RateLimit{Rate: 0.1, Burst: 1})
+	limiter.Wait(ctx)
+	cancelCtx, cancel := context.WithCancel(ctx)
+	time.AfterFunc(20*time.Millisecond, cancel)
+	if err := limiter.Wait(cancelCtx); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected canceled, got %v", err)
+	}
+
+	limiter.SetLimit(NoRateLimit)
+	for i := 0; i < 100; i++ {
+		if err := limiter.Wait(ctx); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestSharedRateLimiter(t *testing.T) {
+	RegisterRateLimit("ratelimit-test", RateLimit{Rate: 1, Burst: 1})
+	a := SharedRateLimiter("ratelimit-test", &ExchangeConfig{})
+	if limit := a.Limit(); limit.Rate != 1 || limit.Burst != 1 {
+		t.Errorf("registered limit not used: %+v", limit)
+	}
+	b := SharedRateLimiter("ratelimit-test", &ExchangeConfig{RateLimit: &RateLimit{Rate: 5, Burst: 2}})
+	if a != b {
+		t.Fatal("limiter not shared")
+	}
+	if limit := a.Limit(); limit.Rate != 5 || limit.Burst != 2 {
+		t.Errorf("configured limit not applied: %+v", limit)
+	}
+	if limit := SharedRateLimiter("ratelimit-other", &ExchangeConfig{}).Limit(); limit != DefaultRateLimit {
+		t.Errorf("expected the default limit, got %+v", limit)
+	}
+
+	// The clients of another account or endpoint keep their limit.
+	c := SharedRateLimiter("ratelimit-test", &ExchangeConfig{ApiKey: "key", RateLimit: &NoRateLimit})
+	d := SharedRateLimiter("ratelimit-test", &ExchangeConfig{BaseURL: "http://localhost:8080"})
+	if c == a || d == a || c == d {
+		t.Fatal("limiter shared by other api keys or endpoints")
+	}
+	if limit := a.Limit(); limit.Rate != 5 || limit.Burst != 2 {
+		t.Errorf("limit changed by another api key: %+v", limit)
+	}
+	if limit := d.Limit(); limit.Rate != 1 || limit.Burst != 1 {
+		t.Errorf("registered limit not used for another endpoint: %+v", limit)
+	}
+}
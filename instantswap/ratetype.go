@@ -0,0 +1,145 @@
+package instantswap
+
+import (
+	"context"
+	"fmt"
+)
+
+// RateType selects whether the rate of an order is fixed when the order is
+// created or set by the market when the deposit is received.
+type RateType int
+
+const (
+	// RateTypeDefault lets the exchange choose, ExchangeRateInfo.RateType
+	// reports the type it quoted.
+	RateTypeDefault RateType = iota
+	// RateTypeFloat is the market rate when the deposit is received, the
+	// received amount may differ from the estimate.
+	RateTypeFloat
+	// RateTypeFixed guarantees the quoted rate if the deposit is received
+	// before ExchangeRateInfo.ValidUntil.
+	RateTypeFixed
+)
+
+func (t RateType) String() string {
+	switch t {
+	case RateTypeFloat:
+		return "float"
+	case RateTypeFixed:
+		return "fixed"
+	default:
+		return "default"
+	}
+}
+
+// ParseRateType parses the String form of a RateType, the empty string being
+// RateTypeDefault.
+func ParseRateType(s string) (RateType, error) {
+	switch s {
+	case "", "default":
+		return RateTypeDefault, nil
+	case "float":
+		return RateTypeFloat, nil
+	case "fixed":
+		return RateTypeFixed, nil
+	default:
+		return RateTypeDefault, fmt.Errorf("unknown rate type %q", s)
+	}
+}
+
+func (t RateType) MarshalText() ([]byte, error) {
+	return []byte(t.String()), nil
+}
+
+func (t *RateType) UnmarshalText(text []byte) (err error) {
+	*t, err = ParseRateType(string(text))
+	return err
+}
+
+// SupportsRateType reports whether orders of type t can be created,
+// RateTypeDefault is always supported.
+func (c Capabilities) SupportsRateType(t RateType) bool {
+	switch t {
+	case RateTypeFloat:
+		return c.FloatingRate
+	case RateTypeFixed:
+		return c.FixedRate
+	default:
+		return true
+	}
+}
+
+// defaultRateType returns the only rate type of an exchange supporting one,
+// RateTypeDefault otherwise.
+func (c Capabilities) defaultRateType() RateType {
+	switch {
+	case c.FixedRate && !c.FloatingRate:
+		return RateTypeFixed
+	case c.FloatingRate && !c.FixedRate:
+		return RateTypeFloat
+	default:
+		return RateTypeDefault
+	}
+}
+
+// checkRateType returns ErrNotSupported if the exchange name declared
+// capabilities which do not include t.
+func checkRateType(name string, t RateType) error {
+	capabilities, ok := ExchangeCapabilities(name)
+	if !ok || capabilities.SupportsRateType(t) {
+		return nil
+	}
+	return fmt.Errorf("%w: %s rate", ErrNotSupported, t)
+}
+
+// quotedRateType returns the rate type of a quote requested with t, when the
+// adapter did not report it.
+func quotedRateType(name string, t RateType) RateType {
+	if t != RateTypeDefault {
+		return t
+	}
+	capabilities, _ := ExchangeCapabilities(name)
+	return capabilities.defaultRateType()
+}
+
+func (e *errorExchange) createOrder(ctx context.Context, vars CreateOrder) (CreateResultInfo, error) {
+	if err := checkRateType(e.name, vars.RateType); err != nil {
+		return CreateResultInfo{}, err
+	}
+	if err := checkExtraID(e.name, vars); err != nil {
+		return CreateResultInfo{}, err
+	}
+	if err := ValidateOrder(vars); err != nil {
+		return CreateResultInfo{}, err
+	}
+	m, ok := ExchangeAssetMapping(e.name)
+	if !ok {
+		return e.exchange.CreateOrder(ctx, vars)
+	}
+	vars, err := m.createOrder(vars)
+	if err != nil {
+		return CreateResultInfo{}, err
+	}
+	res, err := e.exchange.CreateOrder(ctx, vars)
+	res.FromCurrency, _ = m.asset(res.FromCurrency)
+	res.ToCurrency, _ = m.asset(res.ToCurrency)
+	return res, err
+}
+
+func (e *errorExchange) getExchangeRateInfo(ctx context.Context, vars ExchangeRateRequest) (ExchangeRateInfo, error) {
+	if err := checkRateType(e.name, vars.RateType); err != nil {
+		return ExchangeRateInfo{}, err
+	}
+	decimals := depositDecimals(vars)
+	if m, ok := ExchangeAssetMapping(e.name); ok {
+		var err error
+		if vars, err = m.exchangeRateRequest(vars); err != nil {
+			return ExchangeRateInfo{}, err
+		}
+	}
+	res, err := e.quote(ctx, vars, decimals)
+	if err == nil && res.RateType == RateTypeDefault {
+		res.RateType = quotedRateType(e.name, vars.RateType)
+	}
+	return res, err
+}
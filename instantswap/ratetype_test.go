@@ -0,0 +1,52 @@
+package instantswap
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestRateType(t *testing.T) {
+	for _, rateType := range []RateType{RateTypeDefault, RateTypeFloat, RateTypeFixed} {
+		parsed, err := ParseRateType(rateType.String())
+		if err != nil || parsed != rateType {
+			t.Errorf("%v: got %v, %v", rateType, parsed, err)
+		}
+	}
+	if parsed, err := ParseRateType(""); err != nil || parsed != RateTypeDefault {
+		t.Errorf("empty rate type: got %v, %v", parsed, err)
+	}
+	if _, err := ParseRateType("floating"); err == nil {
+		t.Error("expected an error")
+	}
+
+	data, err := json.Marshal(CreateOrder{RateType: RateTypeFixed})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), `"rate_type":"fixed"`) {
+		t.Errorf("unexpected json %s", data)
+	}
+	var order CreateOrder
+	if err := json.Unmarshal(data, &order); err != nil || order.RateType != RateTypeFixed {
+		t.Errorf("got %v, %v", order.RateType, err)
+	}
+	data, _ = json.Marshal(CreateOrder{})
+	if strings.Contains(string(data), "rate_type") {
+		t.Errorf("unexpected json %s", data)
+	}
+}
+
+func TestSupportsRateType(t *testing.T) {
+	fixed := Capabilities{FixedRate: true}
+	if !fixed.SupportsRateType(RateTypeDefault) || !fixed.SupportsRateType(RateTypeFixed) || fixed.SupportsRateType(RateTypeFloat) {
+		t.Error("unexpected support of the fixed rate exchange")
+	}
+	if fixed.defaultRateType() != RateTypeFixed {
+		t.Errorf("got default %v", fixed.defaultRateType())
+	}
+	both := Capabilities{FixedRate: true, FloatingRate: true}
+	if both.defaultRateType() != RateTypeDefault {
+		t.Errorf("got default %v", both.defaultRateType())
+	}
+}
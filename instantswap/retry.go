@@ -0,0 +1,138 @@
+package instantswap
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RetryPolicy configures the retries of the requests which failed with a rate
+// limit or a transient network error. Only idempotent requests are retried:
+// GET, HEAD, OPTIONS, PUT and DELETE requests unless bound to a context
+// returned by NotIdempotent, and the requests bound to a context returned by
+// Idempotent. Orders are never created twice because a response was lost.
+type RetryPolicy struct {
+	// MaxRetries is the number of retries after the first attempt, 0
+	// disables the retries.
+	MaxRetries int
+	// MinBackoff is the delay before the first retry, it doubles on each
+	// retry up to MaxBackoff. A random jitter of up to half the delay is
+	// subtracted.
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+	// MaxRetryAfter is the longest Retry-After delay of a response which is
+	// honored, the error is returned without retry when the exchange asks
+	// to wait longer. MaxBackoff is used if zero.
+	MaxRetryAfter time.Duration
+}
+
+// DefaultRetryPolicy is used by the exchanges when ExchangeConfig.Retry is nil.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxRetries:    3,
+	MinBackoff:    500 * time.Millisecond,
+	MaxBackoff:    10 * time.Second,
+	MaxRetryAfter: 30 * time.Second,
+}
+
+// NoRetry disables the retries.
+var NoRetry = RetryPolicy{}
+
+type idempotentKey struct{}
+
+// Idempotent returns a context marking the requests made with it as safe to
+// retry, for the exchange apis which query rates or orders with POST
+// requests.
+func Idempotent(ctx context.Context) context.Context {
+	return context.WithValue(ctx, idempotentKey{}, true)
+}
+
+// NotIdempotent returns a context marking the requests made with it as unsafe
+// to retry, for the exchange apis which create orders with GET requests.
+func NotIdempotent(ctx context.Context) context.Context {
+	return context.WithValue(ctx, idempotentKey{}, false)
+}
+
+func isIdempotent(ctx context.Context, method string) bool {
+	if idempotent, ok := ctx.Value(idempotentKey{}).(bool); ok {
+		return idempotent
+	}
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// backoff returns the delay before the retry attempt, starting at 0.
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	delay := p.MinBackoff
+	for i := 0; i < attempt && delay < p.MaxBackoff; i++ {
+		delay *= 2
+	}
+	if p.MaxBackoff > 0 && delay > p.MaxBackoff {
+		delay = p.MaxBackoff
+	}
+	if delay <= 0 {
+		return 0
+	}
+	return delay - time.Duration(rand.Int63n(int64(delay)/2+1))
+}
+
+// delay returns the delay before retrying after err, ok is false if the
+// request must not be retried.
+func (p RetryPolicy) delay(attempt int, err error) (delay time.Duration, ok bool) {
+	if attempt >= p.MaxRetries {
+		return 0, false
+	}
+	var e *Error
+	if !errors.As(err, &e) || !e.Temporary() {
+		return 0, false
+	}
+	delay = p.backoff(attempt)
+	if e.RetryAfter > 0 {
+		maxRetryAfter := p.MaxRetryAfter
+		if maxRetryAfter == 0 {
+			maxRetryAfter = p.MaxBackoff
+		}
+		if e.RetryAfter > maxRetryAfter {
+			return 0, false
+		}
+		if e.RetryAfter > delay {
+			delay = e.RetryAfter
+		}
+	}
+	return delay, true
+}
+
+// parseRetryAfter parses the Retry-After header, in seconds or as an http
+// date.
+func parseRetryAfter(header string, now time.Time) time.Duration {
+	if header == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(header); err == nil {
+		if seconds < 0 {
+			return 0
+		}
+		return time.Duration(seconds) * time.Second
+	}
+	if date, err := http.ParseTime(header); err == nil && date.After(now) {
+		return date.Sub(now)
+	}
+	return 0
+}
+
+// sleep waits for d or until ctx is done.
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,103 @@
+package instantswap
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestClientDoRetry(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/limited":
+			if atomic.AddInt32(&calls, 1) < 3 {
+				w.Header().Set("Retry-After", "0")
+				w.WriteHeader(http.StatusTooManyRequests)
+				return
+			}
+		case "/wait":
+			atomic.AddInt32(&calls, 1)
+			w.Header().Set("Retry-After", "3600")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		case "/bad":
+			atomic.AddInt32(&calls, 1)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	policy := RetryPolicy{MaxRetries: 3, MinBackoff: time.Millisecond, MaxBackoff: 10 * time.Millisecond}
+	client := NewClient("test", &ExchangeConfig{Retry: &policy, RateLimit: &NoRateLimit})
+	ctx := context.Background()
+
+	tests := []struct {
+		ctx      context.Context
+		method   string
+		resource string
+		calls    int32
+		ok       bool
+	}{
+		{ctx, http.MethodGet, "limited", 3, true},
+		{ctx, http.MethodPost, "limited", 1, false},
+		{Idempotent(ctx), http.MethodPost, "limited", 3, true},
+		{NotIdempotent(ctx), http.MethodGet, "limited", 1, false},
+		{ctx, http.MethodGet, "wait", 1, false},
+		{ctx, http.MethodGet, "bad", 1, false},
+	}
+	for _, test := range tests {
+		atomic.StoreInt32(&calls, 0)
+		res, err := client.Do(test.ctx, server.URL+"/", test.method, test.resource, "", false)
+		if test.ok && (err != nil || string(res) != "ok") {
+			t.Errorf("%s %s: unexpected error: %v", test.method, test.resource, err)
+		}
+		if !test.ok && err == nil {
+			t.Errorf("%s %s: expected error", test.method, test.resource)
+		}
+		if n := atomic.LoadInt32(&calls); n != test.calls {
+			t.Errorf("%s %s: got %d calls, expected %d", test.method, test.resource, n, test.calls)
+		}
+	}
+
+	var e *Error
+	_, err := client.Do(ctx, server.URL+"/", http.MethodGet, "wait", "", false)
+	if !errors.As(err, &e) || e.RetryAfter != time.Hour || !errors.Is(err, TooManyRequestsError) {
+		t.Errorf("unexpected error: %+v", err)
+	}
+}
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	p := RetryPolicy{MaxRetries: 10, MinBackoff: 100 * time.Millisecond, MaxBackoff: time.Second}
+	for attempt, max := range []time.Duration{100, 200, 400, 800, 1000, 1000} {
+		max *= time.Millisecond
+		for i := 0; i < 20; i++ {
+			if d := p.backoff(attempt); d < max/2 || d > max {
+				t.Fatalf("attempt %d: backoff %v out of [%v, %v]", attempt, d, max/2, max)
+			}
+		}
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	tests := map[string]time.Duration{
+		"":                              0,
+		"120":                           2 * time.Minute,
+		"-1":                            0,
+		"soon":                          0,
+		"Mon, 01 Jan 2024 00:00:30 GMT": 30 * time.Second,
+		"Sun, 31 Dec 2023 23:00:00 GMT": 0,
+	}
+	for header, expected := range tests {
+		if d := parseRetryAfter(header, now); d != expected {
+			t.Errorf("%q: got %v, expected %v", header, d, expected)
+		}
+	}
+}
@@ -0,0 +1,191 @@
+package instantswap
+
+import (
+	"context"
+	"errors"
+	"math"
+	"math/big"
+)
+
+// Direction tells which side of a quote ExchangeRateRequest.Amount is.
+type Direction int
+
+const (
+	// DirectionFrom quotes the amount received for sending Amount.
+	DirectionFrom Direction = iota
+	// DirectionTo quotes the deposit required to receive Amount, a reverse
+	// quote.
+	DirectionTo
+)
+
+func (d Direction) String() string {
+	if d == DirectionTo {
+		return "to"
+	}
+	return "from"
+}
+
+const (
+	// maxSearchQuotes bounds the quotes requested by the search of the
+	// deposit of a reverse quote.
+	maxSearchQuotes = 12
+	// searchTolerance is the relative excess of the received amount over the
+	// requested one at which the search stops.
+	searchTolerance = 1e-4
+	// defaultSearchDecimals is the precision of the deposits of the
+	// currencies whose decimals are unknown.
+	defaultSearchDecimals = 8
+)
+
+// searchProbes are the powers of ten applied to the requested amount to find
+// a first deposit accepted by the exchange.
+var searchProbes = []int{0, -2, 2, -4, 4, -6, 6}
+
+// quoteFunc is the GetExchangeRateInfo method of an exchange.
+type quoteFunc func(ctx context.Context, vars ExchangeRateRequest) (ExchangeRateInfo, error)
+
+// depositDecimals returns the precision of the deposits searched for a
+// reverse quote of vars. It must be called before vars is mapped to the names
+// of the exchange, the decimals are known by the canonical symbols.
+func depositDecimals(vars ExchangeRateRequest) int {
+	decimals, ok := CurrencyDecimals(vars.From, vars.FromNetwork)
+	if !ok {
+		return defaultSearchDecimals
+	}
+	return decimals
+}
+
+// quote requests a quote from the adapter. The DirectionTo requests are
+// searched with forward quotes of deposits of decimals unless the adapter
+// estimates them, see Capabilities.ReverseQuote.
+func (e *errorExchange) quote(ctx context.Context, vars ExchangeRateRequest, decimals int) (ExchangeRateInfo, error) {
+	if vars.Direction != DirectionTo {
+		res, err := e.exchange.GetExchangeRateInfo(ctx, vars)
+		if err == nil && res.DepositAmount.IsZero() {
+			res.DepositAmount = vars.Amount
+		}
+		return res, err
+	}
+	if capabilities, _ := ExchangeCapabilities(e.name); capabilities.ReverseQuote {
+		res, err := e.exchange.GetExchangeRateInfo(ctx, vars)
+		if !errors.Is(err, ErrNotSupported) {
+			return res, err
+		}
+	}
+	return searchDeposit(ctx, vars, decimals, e.exchange.GetExchangeRateInfo)
+}
+
+// searchDeposit emulates a reverse quote: it looks for the smallest deposit
+// whose forward quote receives at least vars.Amount. The first deposits are
+// powers of ten of the requested amount until one is in the limits of the
+// exchange, the next ones are found with the secant method. The deposits are
+// rounded up to decimals.
+func searchDeposit(ctx context.Context, vars ExchangeRateRequest, decimals int, quote quoteFunc) (ExchangeRateInfo, error) {
+	target := vars.Amount
+	if target.Sign() <= 0 {
+		return ExchangeRateInfo{}, errors.New("the amount of a reverse quote must be positive")
+	}
+	req := vars
+	req.Direction = DirectionFrom
+	quotes := 0
+	try := func(deposit Amount) (ExchangeRateInfo, Amount, error) {
+		quotes++
+		req.Amount = deposit
+		res, err := quote(ctx, req)
+		if err != nil {
+			return res, Amount{}, err
+		}
+		res.DepositAmount = deposit
+		received := res.EstimatedAmount
+		if received.Sign() <= 0 {
+			received = deposit.MulFloat(res.ExchangeRate)
+		}
+		return res, received, nil
+	}
+
+	var res ExchangeRateInfo
+	var received Amount
+	var err error
+	for _, exp := range searchProbes {
+		deposit := ceilAmount(target.Float64()*math.Pow10(exp), decimals)
+		res, received, err = try(deposit)
+		if err == nil && received.Sign() > 0 {
+			break
+		}
+		if err == nil {
+			err = ErrAmountOutOfRange
+		}
+		if !errors.Is(err, ErrAmountOutOfRange) {
+			return ExchangeRateInfo{}, err
+		}
+	}
+	if err != nil {
+		return ExchangeRateInfo{}, err
+	}
+
+	var best ExchangeRateInfo
+	found := false
+	var prevDeposit, prevReceived float64
+	for {
+		deposit, got := res.DepositAmount.Float64(), received.Float64()
+		if received.Cmp(target) >= 0 {
+			if !found || res.DepositAmount.Cmp(best.DepositAmount) < 0 {
+				best, found = res, true
+			}
+			if got-target.Float64() <= target.Float64()*searchTolerance {
+				break
+			}
+		}
+		if quotes >= maxSearchQuotes {
+			break
+		}
+		// Nothing received for a deposit accepted by the exchange, the
+		// search cannot go on from it.
+		if got <= 0 {
+			if found {
+				break
+			}
+			return ExchangeRateInfo{}, ErrAmountOutOfRange
+		}
+		// The received amount is about linear in the deposit, minus the
+		// fees: the secant converges in a few quotes.
+		next := deposit * target.Float64() / got
+		if prevReceived > 0 && got != prevReceived {
+			if secant := deposit + (target.Float64()-got)*(deposit-prevDeposit)/(got-prevReceived); secant > 0 {
+				next = secant
+			}
+		}
+		nextDeposit := ceilAmount(next, decimals)
+		if nextDeposit.Cmp(res.DepositAmount) == 0 {
+			if found {
+				break
+			}
+			nextDeposit = nextDeposit.Add(AmountFromUnits(big.NewInt(1), decimals))
+		}
+		prevDeposit, prevReceived = deposit, got
+		var nextRes ExchangeRateInfo
+		var nextReceived Amount
+		nextRes, nextReceived, err = try(nextDeposit)
+		if err != nil {
+			if found {
+				break
+			}
+			return ExchangeRateInfo{}, err
+		}
+		res, received = nextRes, nextReceived
+	}
+	if !found {
+		return ExchangeRateInfo{}, errors.New("no deposit found for the requested amount")
+	}
+	return best, nil
+}
+
+// ceilAmount returns f rounded up to decimals.
+func ceilAmount(f float64, decimals int) Amount {
+	amount := AmountFromFloat(f)
+	truncated := amount.Truncate(decimals)
+	if truncated.Cmp(amount) < 0 {
+		truncated = truncated.Add(AmountFromUnits(big.NewInt(1), decimals))
+	}
+	return truncated
+}
@@ -0,0 +1,181 @@
+package instantswap
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+// linearQuote quotes rate*amount - fee, rejecting the amounts outside of
+// [min, max].
+func linearQuote(rate, fee, min, max float64, calls *int) quoteFunc {
+	return func(ctx context.Context, vars ExchangeRateRequest) (ExchangeRateInfo, error) {
+		*calls++
+		if vars.Direction != DirectionFrom {
+			return ExchangeRateInfo{}, errors.New("reverse request sent to the exchange")
+		}
+		amount := vars.Amount.Float64()
+		if amount < min || amount > max {
+			return ExchangeRateInfo{}, NewError("test", KindAmountOutOfRange, "", "out of range")
+		}
+		return ExchangeRateInfo{
+			Min:             AmountFromFloat(min),
+			Max:             AmountFromFloat(max),
+			ExchangeRate:    rate,
+			EstimatedAmount: AmountFromFloat(amount*rate - fee).Truncate(8),
+			Signature:       vars.Amount.String(),
+		}, nil
+	}
+}
+
+func TestSearchDeposit(t *testing.T) {
+	tests := []struct {
+		name                string
+		rate, fee, min, max float64
+		target              string
+		deposit             string
+	}{
+		// 180.5 = 1805 * 0.1, in the limits at the first quote.
+		{"exact", 1805, 0, 0.001, 2.5, "180.5", "0.1"},
+		// (180.5 + 0.3) / 1805 = 0.10016620..., rounded up.
+		{"fee", 1805, 0.3, 0.001, 2.5, "180.5", "0.10016621"},
+		// The first probes of 180.5 and 1.805 BTC are above the maximum.
+		{"probe", 1805, 0.3, 0.001, 1, "180.5", "0.10016621"},
+		// 0.05 BTC for 1 ETH, the first probe is below the minimum.
+		{"inverse", 0.05, 0.0001, 5, 1000, "1", "20.002"},
+	}
+	for _, test := range tests {
+		calls := 0
+		res, err := searchDeposit(context.Background(), ExchangeRateRequest{
+			From: "BTC", To: "DCR", Amount: MustParseAmount(test.target), Direction: DirectionTo,
+		}, 8, linearQuote(test.rate, test.fee, test.min, test.max, &calls))
+		if err != nil {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+		// The search stops once the received amount is within the tolerance.
+		deposit := MustParseAmount(test.deposit)
+		if res.DepositAmount.Cmp(deposit) < 0 || res.DepositAmount.Cmp(deposit.MulFloat(1+searchTolerance)) > 0 {
+			t.Errorf("%s: got deposit %s, expected %s", test.name, res.DepositAmount, test.deposit)
+		}
+		if res.EstimatedAmount.Cmp(MustParseAmount(test.target)) < 0 {
+			t.Errorf("%s: %s received for the deposit, expected at least %s", test.name, res.EstimatedAmount, test.target)
+		}
+		if res.Signature != res.DepositAmount.String() {
+			t.Errorf("%s: signature %s of another quote", test.name, res.Signature)
+		}
+		if calls > maxSearchQuotes {
+			t.Errorf("%s: %d quotes", test.name, calls)
+		}
+	}
+
+	calls := 0
+	_, err := searchDeposit(context.Background(), ExchangeRateRequest{
+		From: "BTC", To: "DCR", Amount: MustParseAmount("1000000"), Direction: DirectionTo,
+	}, 8, linearQuote(1805, 0, 0.001, 2.5, &calls))
+	if !errors.Is(err, ErrAmountOutOfRange) {
+		t.Errorf("expected ErrAmountOutOfRange, got %v", err)
+	}
+
+	// Half of the amount is received at the first quote, nothing at the
+	// next ones.
+	calls = 0
+	_, err = searchDeposit(context.Background(), ExchangeRateRequest{
+		From: "BTC", To: "DCR", Amount: MustParseAmount("1"), Direction: DirectionTo,
+	}, 8, func(ctx context.Context, vars ExchangeRateRequest) (ExchangeRateInfo, error) {
+		calls++
+		if calls > 1 {
+			return ExchangeRateInfo{}, nil
+		}
+		return ExchangeRateInfo{EstimatedAmount: vars.Amount.Quo(MustParseAmount("2"), 8)}, nil
+	})
+	if !errors.Is(err, ErrAmountOutOfRange) {
+		t.Errorf("nothing received: expected ErrAmountOutOfRange, got %v", err)
+	}
+}
+
+type reverseExchange struct {
+	IDExchangeCtx
+	requests []ExchangeRateRequest
+}
+
+func (r *reverseExchange) GetExchangeRateInfo(ctx context.Context, vars ExchangeRateRequest) (ExchangeRateInfo, error) {
+	r.requests = append(r.requests, vars)
+	if vars.Direction == DirectionTo {
+		if vars.RateType != RateTypeFixed {
+			return ExchangeRateInfo{}, ErrNotSupported
+		}
+		return ExchangeRateInfo{EstimatedAmount: vars.Amount, DepositAmount: MustParseAmount("0.2")}, nil
+	}
+	return ExchangeRateInfo{EstimatedAmount: vars.Amount.MulFloat(10)}, nil
+}
+
+func TestReverseQuote(t *testing.T) {
+	RegisterCapabilities("reverse-test", Capabilities{FixedRate: true, FloatingRate: true, ReverseQuote: true})
+	exchange := &reverseExchange{}
+	e := &errorExchange{name: "reverse-test", exchange: exchange}
+	ctx := context.Background()
+
+	res, err := e.GetExchangeRateInfo(ctx, ExchangeRateRequest{From: "BTC", To: "DCR", Amount: MustParseAmount("1")})
+	if err != nil || res.DepositAmount.String() != "1" {
+		t.Errorf("forward quote: got deposit %s, %v", res.DepositAmount, err)
+	}
+
+	exchange.requests = nil
+	res, err = e.GetExchangeRateInfo(ctx, ExchangeRateRequest{
+		From: "BTC", To: "DCR", Amount: MustParseAmount("1"), Direction: DirectionTo, RateType: RateTypeFixed,
+	})
+	if err != nil || res.DepositAmount.String() != "0.2" || len(exchange.requests) != 1 {
+		t.Errorf("reverse quote of the exchange: got deposit %s, %v after %d requests", res.DepositAmount, err, len(exchange.requests))
+	}
+
+	exchange.requests = nil
+	res, err = e.GetExchangeRateInfo(ctx, ExchangeRateRequest{
+		From: "BTC", To: "DCR", Amount: MustParseAmount("1"), Direction: DirectionTo, RateType: RateTypeFloat,
+	})
+	if err != nil || res.DepositAmount.String() != "0.1" || len(exchange.requests) < 2 {
+		t.Errorf("searched reverse quote: got deposit %s, %v after %d requests", res.DepositAmount, err, len(exchange.requests))
+	}
+	if res.RateType != RateTypeFloat {
+		t.Errorf("got rate type %v", res.RateType)
+	}
+}
+
+func TestReverseQuoteDecimals(t *testing.T) {
+	RegisterCapabilities("reverse-decimals-test", Capabilities{FloatingRate: true})
+	RegisterAssetMapping("reverse-decimals-test", AssetMapping{
+		LowerCase: true,
+		Tickers:   map[Asset]string{{Symbol: "USDT", Network: NetworkBSC}: "usdtbsc"},
+	})
+	e := &errorExchange{name: "reverse-decimals-test", exchange: &reverseExchange{}}
+
+	// The exchange only knows usdtbsc, the deposits have the 18 decimals
+	// of USDT on BSC and not the 8 of an unknown currency.
+	res, err := e.GetExchangeRateInfo(context.Background(), ExchangeRateRequest{
+		From: "USDT", FromNetwork: "bep20", To: "DCR", Amount: MustParseAmount("0.000000001"), Direction: DirectionTo,
+	})
+	if err != nil || res.DepositAmount.String() != "0.0000000001" {
+		t.Errorf("got deposit %s, %v", res.DepositAmount, err)
+	}
+}
+
+func TestAggregatorReverseQuote(t *testing.T) {
+	a := &Aggregator{exchanges: make(map[string]IDExchangeCtx), failed: make(map[string]error)}
+	a.Add("cheap", &fakeExchange{info: ExchangeRateInfo{DepositAmount: MustParseAmount("0.1"), Min: MustParseAmount("0.01")}})
+	a.Add("expensive", &fakeExchange{info: ExchangeRateInfo{DepositAmount: MustParseAmount("0.12")}})
+	a.Add("rate", &fakeExchange{info: ExchangeRateInfo{ExchangeRate: 9}})
+	a.Add("limited", &fakeExchange{info: ExchangeRateInfo{DepositAmount: MustParseAmount("0.05"), Min: MustParseAmount("0.06")}})
+
+	results := a.Quote(context.Background(), ExchangeRateRequest{
+		From: "BTC", To: "DCR", Amount: MustParseAmount("1"), Direction: DirectionTo,
+	})
+	expected := []string{"cheap", "rate", "expensive", "limited"}
+	for i, name := range expected {
+		if i >= len(results) || results[i].Exchange != name {
+			t.Fatalf("got results %+v, expected %v", results, expected)
+		}
+	}
+	if !errors.Is(results[3].Err, ErrAmountOutOfRange) {
+		t.Errorf("limited: expected out of range error, got %v", results[3].Err)
+	}
+}
@@ -0,0 +1,291 @@
+package instantswap
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+const defaultMaxRoutes = 20
+
+// defaultIntermediates are the assets tried in the middle of the two hop
+// routes, the most traded ones.
+var defaultIntermediates = []string{"BTC", "USDT", "ETH", "LTC"}
+
+// RouteConfig configures a RoutePlanner.
+type RouteConfig struct {
+	// Intermediates are the currencies tried in the middle of the two hop
+	// routes, BTC, USDT, ETH and LTC by default.
+	Intermediates []string
+	// MaxRoutes bounds the number of routes quoted by Routes, the paths
+	// over listed pairs, with the fewest hops and accepting the amount
+	// first. 20 by default.
+	MaxRoutes int
+	// Timeout bounds the time spent waiting on each quote. Zero means only
+	// the caller's context applies.
+	Timeout time.Duration
+}
+
+// RouteHop is a swap of a route on one exchange.
+type RouteHop struct {
+	Exchange    string
+	From        string
+	FromNetwork string
+	To          string
+	ToNetwork   string
+	// Amount is the amount sent to the exchange, the amount received from
+	// the previous hop.
+	Amount Amount
+	// Info is the quote of the hop, zero if it was not quoted because a
+	// previous hop failed.
+	Info ExchangeRateInfo
+}
+
+// Route is a path from a currency to another through one or two exchanges.
+type Route struct {
+	Hops []RouteHop
+	// EstimatedAmount is the amount received at the end of the route, after
+	// the fees of every hop.
+	EstimatedAmount Amount
+	// Err is set when a hop could not be quoted or its amount is outside
+	// of the exchange limits (ErrAmountOutOfRange).
+	Err error
+}
+
+// RoutePlanner finds the routes between two currencies in the pairs of a
+// PairMatrix, directly or through an intermediate currency, and quotes them
+// with the exchanges of the matrix.
+type RoutePlanner struct {
+	matrix *PairMatrix
+	conf   RouteConfig
+}
+
+// NewRoutePlanner creates a RoutePlanner over the pairs of matrix, which
+// must have been refreshed.
+func NewRoutePlanner(matrix *PairMatrix, conf RouteConfig) *RoutePlanner {
+	if len(conf.Intermediates) == 0 {
+		conf.Intermediates = defaultIntermediates
+	}
+	if conf.MaxRoutes <= 0 {
+		conf.MaxRoutes = defaultMaxRoutes
+	}
+	return &RoutePlanner{matrix: matrix, conf: conf}
+}
+
+// Paths returns the direct pairs and the two hop paths swapping from on
+// fromNetwork to to on toNetwork, the direct ones first. The intermediate
+// currency of a two hop path is sent on the same network by both hops. An
+// empty network matches any.
+func (p *RoutePlanner) Paths(from, fromNetwork, to, toNetwork string) [][]PairEdge {
+	var paths [][]PairEdge
+	for _, e := range p.matrix.Pairs(from, fromNetwork, to, toNetwork) {
+		paths = append(paths, []PairEdge{e})
+	}
+	from, to = CanonicalSymbol(from), CanonicalSymbol(to)
+	for _, middle := range p.conf.Intermediates {
+		middle = CanonicalSymbol(middle)
+		if middle == from || middle == to {
+			continue
+		}
+		second := p.matrix.Pairs(middle, "", to, toNetwork)
+		for _, e1 := range p.matrix.Pairs(from, fromNetwork, middle, "") {
+			for _, e2 := range second {
+				if e1.ToNetwork == e2.FromNetwork {
+					paths = append(paths, []PairEdge{e1, e2})
+				}
+			}
+		}
+	}
+	return paths
+}
+
+// rankPaths sorts paths by how likely they are to be quoted, before they are
+// cut to MaxRoutes: the paths whose first hop does not accept amount come
+// last, then the paths with pairs the exchanges did not list, and the paths
+// with the fewest hops come first.
+func rankPaths(paths [][]PairEdge, amount Amount) [][]PairEdge {
+	type rankedPath struct {
+		path                   []PairEdge
+		outOfRange, unverified int
+	}
+	ranked := make([]rankedPath, len(paths))
+	for i, path := range paths {
+		ranked[i].path = path
+		if e := path[0]; (e.Min != nil && amount.Cmp(*e.Min) < 0) || (e.Max != nil && amount.Cmp(*e.Max) > 0) {
+			ranked[i].outOfRange = 1
+		}
+		for _, e := range path {
+			if !e.Verified {
+				ranked[i].unverified++
+			}
+		}
+	}
+	sort.SliceStable(ranked, func(i, j int) bool {
+		ri, rj := ranked[i], ranked[j]
+		switch {
+		case ri.outOfRange != rj.outOfRange:
+			return ri.outOfRange < rj.outOfRange
+		case ri.unverified != rj.unverified:
+			return ri.unverified < rj.unverified
+		default:
+			return len(ri.path) < len(rj.path)
+		}
+	})
+	for i := range ranked {
+		paths[i] = ranked[i].path
+	}
+	return paths
+}
+
+// Routes quotes the paths from vars.From to vars.To for sending vars.Amount,
+// and returns the routes ranked from the best EstimatedAmount to the worst.
+// Routes with an error are placed after the successful ones. The quotes of
+// the routes sharing a hop are requested once. ErrPairUnavailable is returned
+// when there is no path, and reverse quotes are not supported.
+func (p *RoutePlanner) Routes(ctx context.Context, vars ExchangeRateRequest) ([]Route, error) {
+	if vars.Direction != DirectionFrom {
+		return nil, fmt.Errorf("%w: reverse route", ErrNotSupported)
+	}
+	paths := p.Paths(vars.From, vars.FromNetwork, vars.To, vars.ToNetwork)
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("%w: no route from %s to %s", ErrPairUnavailable, vars.From, vars.To)
+	}
+	paths = rankPaths(paths, vars.Amount)
+	if len(paths) > p.conf.MaxRoutes {
+		paths = paths[:p.conf.MaxRoutes]
+	}
+
+	routes := make([]Route, len(paths))
+	for i, path := range paths {
+		routes[i].Hops = make([]RouteHop, len(path))
+		for j, e := range path {
+			routes[i].Hops[j] = RouteHop{
+				Exchange:    e.Exchange,
+				From:        e.From,
+				FromNetwork: e.FromNetwork,
+				To:          e.To,
+				ToNetwork:   e.ToNetwork,
+			}
+		}
+		routes[i].Hops[0].Amount = vars.Amount
+		routes[i].EstimatedAmount = vars.Amount
+	}
+	// Each hop is quoted for the amount received from the previous one, all
+	// the routes at the same hop concurrently.
+	for hop := 0; hop < 2; hop++ {
+		p.quoteHops(ctx, routes, hop, vars.RateType)
+	}
+
+	sort.SliceStable(routes, func(i, j int) bool {
+		ri, rj := routes[i], routes[j]
+		if (ri.Err == nil) != (rj.Err == nil) {
+			return ri.Err == nil
+		}
+		if ri.Err == nil {
+			if c := ri.EstimatedAmount.Cmp(rj.EstimatedAmount); c != 0 {
+				return c > 0
+			}
+		}
+		return len(ri.Hops) < len(rj.Hops)
+	})
+	return routes, nil
+}
+
+// BestRoute returns the first successful route of ranked routes.
+func BestRoute(routes []Route) (Route, bool) {
+	for _, route := range routes {
+		if route.Err == nil {
+			return route, true
+		}
+	}
+	return Route{}, false
+}
+
+// hopKey identifies the quote of a hop, shared by the routes.
+type hopKey struct {
+	exchange, from, fromNetwork, to, toNetwork string
+	amount                                     string
+}
+
+type hopQuote struct {
+	info ExchangeRateInfo
+	err  error
+}
+
+// quoteHops quotes the hop of index hop of the routes which have not failed,
+// and sets the amount of their next hop.
+func (p *RoutePlanner) quoteHops(ctx context.Context, routes []Route, hop int, rateType RateType) {
+	quotes := make(map[hopKey]*hopQuote)
+	var wg sync.WaitGroup
+	for i := range routes {
+		if routes[i].Err != nil || hop >= len(routes[i].Hops) {
+			continue
+		}
+		h := routes[i].Hops[hop]
+		key := hopKey{h.Exchange, h.From, h.FromNetwork, h.To, h.ToNetwork, h.Amount.String()}
+		if _, ok := quotes[key]; ok {
+			continue
+		}
+		q := &hopQuote{}
+		quotes[key] = q
+		wg.Add(1)
+		go func(h RouteHop) {
+			defer wg.Done()
+			q.info, q.err = p.quote(ctx, h, rateType)
+		}(h)
+	}
+	wg.Wait()
+
+	for i := range routes {
+		r := &routes[i]
+		if r.Err != nil || hop >= len(r.Hops) {
+			continue
+		}
+		h := &r.Hops[hop]
+		q := quotes[hopKey{h.Exchange, h.From, h.FromNetwork, h.To, h.ToNetwork, h.Amount.String()}]
+		h.Info = q.info
+		if q.err != nil {
+			r.Err = q.err
+			continue
+		}
+		r.EstimatedAmount = estimatedOutput(h.Amount, q.info)
+		if hop+1 < len(r.Hops) {
+			r.Hops[hop+1].Amount = r.EstimatedAmount
+		}
+	}
+}
+
+// quote returns the quote of a hop, ErrAmountOutOfRange if its amount is
+// outside of the limits of the exchange.
+func (p *RoutePlanner) quote(ctx context.Context, h RouteHop, rateType RateType) (ExchangeRateInfo, error) {
+	exchange, ok := p.matrix.exchange(h.Exchange)
+	if !ok {
+		return ExchangeRateInfo{}, fmt.Errorf("%w: exchange %s removed from the matrix", ErrPairUnavailable, h.Exchange)
+	}
+	if p.conf.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, p.conf.Timeout)
+		defer cancel()
+	}
+	info, err := exchange.GetExchangeRateInfo(ctx, ExchangeRateRequest{
+		From:        h.From,
+		FromNetwork: h.FromNetwork,
+		To:          h.To,
+		ToNetwork:   h.ToNetwork,
+		Amount:      h.Amount,
+		RateType:    rateType,
+	})
+	if err != nil {
+		return info, err
+	}
+	if h.Amount.Cmp(info.Min) < 0 || (info.Max.Sign() > 0 && h.Amount.Cmp(info.Max) > 0) {
+		return info, fmt.Errorf("%w: %s accepts [%v, %v] %s, got %v", ErrAmountOutOfRange,
+			h.Exchange, info.Min, info.Max, h.From, h.Amount)
+	}
+	if estimatedOutput(h.Amount, info).Sign() <= 0 {
+		return info, fmt.Errorf("%s quoted nothing received for %v %s", h.Exchange, h.Amount, h.From)
+	}
+	return info, nil
+}
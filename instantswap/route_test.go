@@ -0,0 +1,112 @@
+package instantswap
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+type routeExchange struct {
+	pairExchange
+	// rates are the rates of the pairs, by From+To.
+	rates map[string]float64
+	min   Amount
+	fee   Amount
+}
+
+func (r *routeExchange) GetExchangeRateInfo(ctx context.Context, vars ExchangeRateRequest) (ExchangeRateInfo, error) {
+	rate, ok := r.rates[vars.From+vars.To]
+	if !ok {
+		return ExchangeRateInfo{}, NewError("", KindPairUnavailable, "", "pair unavailable")
+	}
+	return ExchangeRateInfo{Min: r.min, ExchangeRate: rate, Fees: Fees{NetworkFee: r.fee}}, nil
+}
+
+func TestRoutePlanner(t *testing.T) {
+	m := NewPairMatrix(PairMatrixConfig{Exchanges: []string{"unregistered"}})
+	defer m.Close()
+	// DCR to XYZ is only reachable through BTC or USDT.
+	m.Add("route-a", &routeExchange{
+		pairExchange: pairExchange{currencies: []Currency{{Symbol: "DCR"}, {Symbol: "BTC"}, {Symbol: "USDT", Networks: []string{NetworkEthereum}}}},
+		rates:        map[string]float64{"DCRBTC": 0.0003, "DCRUSDT": 18, "BTCUSDT": 60000},
+	})
+	m.Add("route-b", &routeExchange{
+		pairExchange: pairExchange{currencies: []Currency{{Symbol: "BTC"}, {Symbol: "XYZ"}}},
+		rates:        map[string]float64{"BTCXYZ": 100000, "XYZBTC": 0.00001},
+		fee:          MustParseAmount("1"),
+	})
+	m.Add("route-c", &routeExchange{
+		pairExchange: pairExchange{currencies: []Currency{{Symbol: "USDT", Networks: []string{NetworkEthereum}}, {Symbol: "XYZ"}}},
+		rates:        map[string]float64{"USDTXYZ": 2, "XYZUSDT": 0.5},
+		min:          MustParseAmount("50"),
+	})
+	if err := m.Refresh(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	planner := NewRoutePlanner(m, RouteConfig{})
+
+	if paths := planner.Paths("DCR", "", "XYZ", ""); len(paths) != 2 {
+		t.Fatalf("got paths %+v", paths)
+	}
+
+	// 10 DCR: 0.003 BTC -> 299 XYZ, 180 USDT -> 360 XYZ.
+	routes, err := planner.Routes(context.Background(), ExchangeRateRequest{From: "DCR", To: "XYZ", Amount: MustParseAmount("10")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	best, ok := BestRoute(routes)
+	if !ok || len(best.Hops) != 2 || best.Hops[1].Exchange != "route-c" || best.EstimatedAmount.String() != "360" ||
+		best.Hops[1].Amount.String() != "180" {
+		t.Errorf("unexpected best route %+v", best)
+	}
+	if routes[1].Err != nil || routes[1].EstimatedAmount.String() != "299" {
+		t.Errorf("unexpected second route %+v", routes[1])
+	}
+
+	// 2 DCR give 36 USDT, below the minimum of route-c.
+	routes, err = planner.Routes(context.Background(), ExchangeRateRequest{From: "DCR", To: "XYZ", Amount: MustParseAmount("2")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if routes[0].Err != nil || routes[0].Hops[0].To != "BTC" || !errors.Is(routes[1].Err, ErrAmountOutOfRange) {
+		t.Errorf("unexpected routes %+v", routes)
+	}
+
+	if _, err := planner.Routes(context.Background(), ExchangeRateRequest{From: "DCR", To: "ABC", Amount: MustParseAmount("1")}); !errors.Is(err, ErrPairUnavailable) {
+		t.Errorf("expected ErrPairUnavailable, got %v", err)
+	}
+}
+
+func TestRoutePlannerMaxRoutes(t *testing.T) {
+	RegisterCapabilities("route-listing", Capabilities{PairListing: true})
+	m := NewPairMatrix(PairMatrixConfig{Exchanges: []string{"unregistered"}})
+	defer m.Close()
+	currencies := []Currency{{Symbol: "DCR"}, {Symbol: "BTC"}, {Symbol: "XYZ"}}
+	// route-any does not list its pairs and only swaps DCR to BTC, its
+	// direct path comes first in the paths.
+	m.Add("route-any", &routeExchange{
+		pairExchange: pairExchange{currencies: currencies},
+		rates:        map[string]float64{"DCRBTC": 0.0003},
+	})
+	m.Add("route-listing", &routeExchange{
+		pairExchange: pairExchange{currencies: currencies, pairs: map[string][]Currency{
+			"DCR": {{Symbol: "XYZ"}}, "BTC": nil, "XYZ": nil,
+		}},
+		rates: map[string]float64{"DCRXYZ": 20},
+	})
+	if err := m.Refresh(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	planner := NewRoutePlanner(m, RouteConfig{MaxRoutes: 1})
+	if paths := planner.Paths("DCR", "", "XYZ", ""); len(paths) != 3 || paths[0][0].Exchange != "route-any" {
+		t.Fatalf("got paths %+v", paths)
+	}
+	routes, err := planner.Routes(context.Background(), ExchangeRateRequest{From: "DCR", To: "XYZ", Amount: MustParseAmount("10")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(routes) != 1 || routes[0].Err != nil || routes[0].Hops[0].Exchange != "route-listing" ||
+		routes[0].EstimatedAmount.String() != "200" {
+		t.Errorf("unexpected routes %+v", routes)
+	}
+}
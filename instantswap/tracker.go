@@ -0,0 +1,327 @@
+package instantswap
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+const (
+	defaultTrackerMinInterval = 10 * time.Second
+	defaultTrackerMaxInterval = 5 * time.Minute
+	defaultTrackerEventBuffer = 64
+)
+
+// TrackerConfig configures an OrderTracker.
+type TrackerConfig struct {
+	// MinInterval is the polling interval used after a status change,
+	// 10 seconds by default.
+	MinInterval time.Duration
+	// MaxInterval bounds the polling interval, which grows while the status
+	// of an order does not change or OrderInfo fails. 5 minutes by default.
+	MaxInterval time.Duration
+	// OnEvent, when set, is called with every event from the polling
+	// goroutine of the order instead of sending it to the Events channel.
+	// It must not call Stop or Untrack for the order of the event.
+	OnEvent func(OrderEvent)
+	// EventBuffer is the size of the Events channel, 64 by default.
+	EventBuffer int
+}
+
+// OrderEvent reports a status transition of a tracked order, or a polling
+// error when Err is set. In that case OldStatus and NewStatus are equal.
+type OrderEvent struct {
+	// Exchange is the name the order was tracked with.
+	Exchange      string
+	OrderID       string
+	OldStatus     Status
+	NewStatus     Status
+	TxID          string
+	ReceiveAmount Amount
+	Info          OrderInfoResult
+	Err           error
+	Time          time.Time
+}
+
+// OrderRef identifies a tracked order, the ids of different exchanges may
+// collide.
+type OrderRef struct {
+	Exchange string
+	OrderID  string
+}
+
+// OrderTracker polls OrderInfo of many orders concurrently and emits an event
+// each time the InternalStatus of an order changes. Orders are dropped once
+// they reach a final status.
+type OrderTracker struct {
+	mux    sync.Mutex
+	conf   TrackerConfig
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+	events chan OrderEvent
+	orders map[OrderRef]*trackedOrder
+	closed bool
+}
+
+type trackedOrder struct {
+	ref      OrderRef
+	exchange IDExchangeCtx
+	extraIds []string
+	status   Status
+	// cancel and done are nil while the order is stopped.
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewOrderTracker creates an OrderTracker. Close must be called to stop the
+// polling goroutines.
+func NewOrderTracker(conf TrackerConfig) *OrderTracker {
+	if conf.MinInterval <= 0 {
+		conf.MinInterval = defaultTrackerMinInterval
+	}
+	if conf.MaxInterval < conf.MinInterval {
+		conf.MaxInterval = defaultTrackerMaxInterval
+		if conf.MaxInterval < conf.MinInterval {
+			conf.MaxInterval = conf.MinInterval
+		}
+	}
+	if conf.EventBuffer <= 0 {
+		conf.EventBuffer = defaultTrackerEventBuffer
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	return &OrderTracker{
+		conf:   conf,
+		ctx:    ctx,
+		cancel: cancel,
+		events: make(chan OrderEvent, conf.EventBuffer),
+		orders: make(map[OrderRef]*trackedOrder),
+	}
+}
+
+// Events returns the channel the events are delivered on when
+// TrackerConfig.OnEvent is not set. It is closed by Close.
+func (t *OrderTracker) Events() <-chan OrderEvent {
+	return t.events
+}
+
+// Track starts polling the order orderID of exchange, whose events carry the
+// exchange name.
+func (t *OrderTracker) Track(name string, exchange IDExchangeCtx, orderID string, extraIds ...string) error {
+	return t.TrackFrom(name, exchange, orderID, OrderStatusUnknown, extraIds...)
+}
+
+// TrackFrom is like Track for an order whose last known status is status, a
+// transition is only emitted when the exchange reports a different status.
+// It is meant to resume tracking after a restart.
+func (t *OrderTracker) TrackFrom(name string, exchange IDExchangeCtx, orderID string, status Status, extraIds ...string) error {
+	t.mux.Lock()
+	defer t.mux.Unlock()
+	if t.closed {
+		return fmt.Errorf("order tracker is closed")
+	}
+	ref := OrderRef{Exchange: name, OrderID: orderID}
+	if _, ok := t.orders[ref]; ok {
+		return fmt.Errorf("order %s of %s is already tracked", orderID, name)
+	}
+	o := &trackedOrder{
+		ref:      ref,
+		exchange: exchange,
+		extraIds: extraIds,
+		status:   status,
+	}
+	t.orders[ref] = o
+	t.start(o)
+	return nil
+}
+
+// Stop pauses the polling of the order orderID of the exchange name, its
+// last status is kept until Resume.
+func (t *OrderTracker) Stop(name, orderID string) error {
+	t.mux.Lock()
+	o, ok := t.orders[OrderRef{name, orderID}]
+	if !ok {
+		t.mux.Unlock()
+		return ErrOrderNotTracked
+	}
+	done := t.stop(o)
+	t.mux.Unlock()
+	if done != nil {
+		<-done
+	}
+	return nil
+}
+
+// Resume restarts the polling of a stopped order.
+func (t *OrderTracker) Resume(name, orderID string) error {
+	t.mux.Lock()
+	defer t.mux.Unlock()
+	o, ok := t.orders[OrderRef{name, orderID}]
+	if !ok {
+		return ErrOrderNotTracked
+	}
+	if o.cancel == nil && !t.closed {
+		t.start(o)
+	}
+	return nil
+}
+
+// Untrack stops the polling of an order and forgets it.
+func (t *OrderTracker) Untrack(name, orderID string) error {
+	t.mux.Lock()
+	o, ok := t.orders[OrderRef{name, orderID}]
+	if !ok {
+		t.mux.Unlock()
+		return ErrOrderNotTracked
+	}
+	delete(t.orders, o.ref)
+	done := t.stop(o)
+	t.mux.Unlock()
+	if done != nil {
+		<-done
+	}
+	return nil
+}
+
+// Status returns the last status seen for a tracked order.
+func (t *OrderTracker) Status(name, orderID string) (Status, bool) {
+	t.mux.Lock()
+	defer t.mux.Unlock()
+	o, ok := t.orders[OrderRef{name, orderID}]
+	if !ok {
+		return OrderStatusUnknown, false
+	}
+	return o.status, true
+}
+
+// Orders returns the tracked orders, stopped ones included.
+func (t *OrderTracker) Orders() []OrderRef {
+	t.mux.Lock()
+	defer t.mux.Unlock()
+	refs := make([]OrderRef, 0, len(t.orders))
+	for ref := range t.orders {
+		refs = append(refs, ref)
+	}
+	return refs
+}
+
+// Close stops the tracking of every order and closes the Events channel.
+func (t *OrderTracker) Close() {
+	t.mux.Lock()
+	if t.closed {
+		t.mux.Unlock()
+		return
+	}
+	t.closed = true
+	t.mux.Unlock()
+	t.cancel()
+	t.wg.Wait()
+	close(t.events)
+}
+
+// start launches the polling goroutine of o, t.mux must be held.
+func (t *OrderTracker) start(o *trackedOrder) {
+	ctx, cancel := context.WithCancel(t.ctx)
+	o.cancel = cancel
+	o.done = make(chan struct{})
+	done := o.done
+	t.wg.Add(1)
+	go func() {
+		defer cancel()
+		t.poll(ctx, o, done)
+	}()
+}
+
+// stop cancels the polling goroutine of o and returns the channel closed when
+// it exits, t.mux must be held.
+func (t *OrderTracker) stop(o *trackedOrder) chan struct{} {
+	if o.cancel == nil {
+		return nil
+	}
+	o.cancel()
+	done := o.done
+	o.cancel, o.done = nil, nil
+	return done
+}
+
+func (t *OrderTracker) poll(ctx context.Context, o *trackedOrder, done chan struct{}) {
+	defer t.wg.Done()
+	defer close(done)
+
+	interval := t.conf.MinInterval
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+		}
+
+		info, err := o.exchange.OrderInfo(ctx, o.ref.OrderID, o.extraIds...)
+		if ctx.Err() != nil {
+			return
+		}
+		t.mux.Lock()
+		old := o.status
+		if err == nil && info.InternalStatus != old {
+			o.status = info.InternalStatus
+			if o.status.IsFinal() && t.orders[o.ref] == o {
+				delete(t.orders, o.ref)
+			}
+		}
+		t.mux.Unlock()
+
+		switch {
+		case err != nil:
+			t.emit(ctx, OrderEvent{
+				Exchange:  o.ref.Exchange,
+				OrderID:   o.ref.OrderID,
+				OldStatus: old,
+				NewStatus: old,
+				Err:       err,
+				Time:      time.Now(),
+			})
+			interval = t.nextInterval(interval)
+		case info.InternalStatus != old:
+			t.emit(ctx, OrderEvent{
+				Exchange:      o.ref.Exchange,
+				OrderID:       o.ref.OrderID,
+				OldStatus:     old,
+				NewStatus:     info.InternalStatus,
+				TxID:          info.TxID,
+				ReceiveAmount: info.ReceiveAmount,
+				Info:          info,
+				Time:          time.Now(),
+			})
+			if info.InternalStatus.IsFinal() {
+				return
+			}
+			interval = t.conf.MinInterval
+		default:
+			interval = t.nextInterval(interval)
+		}
+		timer.Reset(interval)
+	}
+}
+
+// nextInterval grows the polling interval by half, up to MaxInterval.
+func (t *OrderTracker) nextInterval(interval time.Duration) time.Duration {
+	interval += interval / 2
+	if interval > t.conf.MaxInterval {
+		return t.conf.MaxInterval
+	}
+	return interval
+}
+
+func (t *OrderTracker) emit(ctx context.Context, event OrderEvent) {
+	if t.conf.OnEvent != nil {
+		t.conf.OnEvent(event)
+		return
+	}
+	select {
+	case t.events <- event:
+	case <-ctx.Done():
+	}
+}
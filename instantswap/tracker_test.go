@@ -0,0 +1,176 @@
+package instantswap
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+type statusExchange struct {
+	IDExchangeCtx
+	mux      sync.Mutex
+	statuses []Status
+	calls    int
+}
+
+func (s *statusExchange) OrderInfo(ctx context.Context, orderID string, extraIds ...string) (OrderInfoResult, error) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	i := s.calls
+	s.calls++
+	if i >= len(s.statuses) {
+		i = len(s.statuses) - 1
+	}
+	if s.statuses[i] == OrderStatusUnknown {
+		return OrderInfoResult{}, errors.New("unavailable")
+	}
+	return OrderInfoResult{InternalStatus: s.statuses[i], TxID: "tx-" + orderID}, nil
+}
+
+func TestOrderTracker(t *testing.T) {
+	tracker := NewOrderTracker(TrackerConfig{
+		MinInterval: time.Millisecond,
+		MaxInterval: 5 * time.Millisecond,
+	})
+	defer tracker.Close()
+
+	exchange := &statusExchange{statuses: []Status{
+		OrderStatusWaitingForDeposit,
+		OrderStatusWaitingForDeposit,
+		OrderStatusUnknown,
+		OrderStatusExchanging,
+		OrderStatusExchanging,
+		OrderStatusCompleted,
+	}}
+	if err := tracker.Track("test", exchange, "order"); err != nil {
+		t.Fatal(err)
+	}
+	if err := tracker.Track("test", exchange, "order"); err == nil {
+		t.Error("expected error tracking the same order twice")
+	}
+
+	expected := []struct {
+		old, new Status
+		err      bool
+	}{
+		{OrderStatusUnknown, OrderStatusWaitingForDeposit, false},
+		{OrderStatusWaitingForDeposit, OrderStatusWaitingForDeposit, true},
+		{OrderStatusWaitingForDeposit, OrderStatusExchanging, false},
+		{OrderStatusExchanging, OrderStatusCompleted, false},
+	}
+	for i, exp := range expected {
+		select {
+		case event := <-tracker.Events():
+			if event.OldStatus != exp.old || event.NewStatus != exp.new || (event.Err != nil) != exp.err {
+				t.Fatalf("event %d: got %v -> %v (err %v), expected %v -> %v", i,
+					event.OldStatus, event.NewStatus, event.Err, exp.old, exp.new)
+			}
+			if event.Exchange != "test" || event.OrderID != "order" {
+				t.Errorf("event %d: unexpected order %s of %s", i, event.OrderID, event.Exchange)
+			}
+			if event.Err == nil && event.TxID != "tx-order" {
+				t.Errorf("event %d: unexpected tx id %q", i, event.TxID)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("timeout waiting for event %d", i)
+		}
+	}
+	if _, ok := tracker.Status("test", "order"); ok {
+		t.Error("completed order is still tracked")
+	}
+}
+
+func TestOrderTrackerStopResume(t *testing.T) {
+	tracker := NewOrderTracker(TrackerConfig{MinInterval: time.Hour})
+	exchange := &statusExchange{statuses: []Status{OrderStatusNew, OrderStatusCompleted}}
+	if err := tracker.TrackFrom("test", exchange, "order", OrderStatusNew); err != nil {
+		t.Fatal(err)
+	}
+	// Wait for the first poll, the next one is an hour later.
+	for deadline := time.Now().Add(time.Second); ; time.Sleep(time.Millisecond) {
+		exchange.mux.Lock()
+		calls := exchange.calls
+		exchange.mux.Unlock()
+		if calls > 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("order was not polled")
+		}
+	}
+	if err := tracker.Stop("test", "order"); err != nil {
+		t.Fatal(err)
+	}
+	if status, ok := tracker.Status("test", "order"); !ok || status != OrderStatusNew {
+		t.Fatalf("unexpected status of stopped order: %v, %v", status, ok)
+	}
+	if err := tracker.Resume("test", "order"); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case event := <-tracker.Events():
+		if event.OldStatus != OrderStatusNew || event.NewStatus != OrderStatusCompleted {
+			t.Errorf("unexpected event %v -> %v", event.OldStatus, event.NewStatus)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timeout waiting for event")
+	}
+	if err := tracker.Resume("test", "unknown"); !errors.Is(err, ErrOrderNotTracked) {
+		t.Errorf("expected ErrOrderNotTracked, got %v", err)
+	}
+	tracker.Close()
+	if _, ok := <-tracker.Events(); ok {
+		t.Error("events channel not closed")
+	}
+}
+
+func TestOrderTrackerSameID(t *testing.T) {
+	store, err := OpenFileOrderStore(filepath.Join(t.TempDir(), "orders.jsonl"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+	for _, name := range []string{"first", "second"} {
+		if err := store.SaveOrder(name, CreateOrder{}, CreateResultInfo{UUID: "order"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	events := make(chan OrderEvent, 2)
+	tracker := NewOrderTracker(TrackerConfig{
+		MinInterval: time.Hour,
+		OnEvent: RecordEvents(store, func(event OrderEvent) { events <- event }, func(err error) {
+			t.Error(err)
+		}),
+	})
+	defer tracker.Close()
+	first := &statusExchange{statuses: []Status{OrderStatusWaitingForDeposit}}
+	second := &statusExchange{statuses: []Status{OrderStatusCompleted}}
+	if err := tracker.Track("first", first, "order"); err != nil {
+		t.Fatal(err)
+	}
+	if err := tracker.Track("second", second, "order"); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 2; i++ {
+		select {
+		case event := <-events:
+			if (event.Exchange == "first") != (event.NewStatus == OrderStatusWaitingForDeposit) {
+				t.Errorf("unexpected event %+v", event)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("timeout waiting for event %d", i)
+		}
+	}
+	if status, ok := tracker.Status("first", "order"); !ok || status != OrderStatusWaitingForDeposit {
+		t.Errorf("unexpected status of the first order: %v, %v", status, ok)
+	}
+	for name, expected := range map[string]Status{"first": OrderStatusWaitingForDeposit, "second": OrderStatusCompleted} {
+		order, err := store.Order(name, "order")
+		if err != nil || order.Status() != expected {
+			t.Errorf("stored order of %s: %+v, %v", name, order, err)
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package utils
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"io/ioutil"
@@ -8,8 +9,18 @@ import (
 	"strings"
 )
 
-func GetPublicIP() (ip string, err error) {
-	resp, err := http.Get("https://myexternalip.com/raw")
+func GetPublicIP(ctx context.Context) (ip string, err error) {
+	return GetPublicIPWithClient(ctx, http.DefaultClient)
+}
+
+// GetPublicIPWithClient is GetPublicIP sending the request with client, so
+// the lookup goes through the same transport as the exchange requests.
+func GetPublicIPWithClient(ctx context.Context, client *http.Client) (ip string, err error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "https://myexternalip.com/raw", nil)
+	if err != nil {
+		return "", err
+	}
+	resp, err := client.Do(req)
 	if err != nil {
 		return "", err
 	}
@@ -0,0 +1,159 @@
+package instantswap
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/crypto-power/instantswap/instantswap/address"
+)
+
+var validators = struct {
+	sync.RWMutex
+	addresses map[string]func(string) error
+	// extraIDs are the validators of the ExtraID of the networks whose
+	// addresses take a memo or destination tag.
+	extraIDs map[string]func(string) error
+}{
+	addresses: map[string]func(string) error{
+		NetworkBitcoin:   address.Bitcoin,
+		NetworkLitecoin:  address.Litecoin,
+		NetworkDogecoin:  address.Dogecoin,
+		NetworkDash:      address.Dash,
+		NetworkDecred:    address.Decred,
+		NetworkZcash:     address.Zcash,
+		NetworkMonero:    address.Monero,
+		NetworkEthereum:  address.Ethereum,
+		NetworkBSC:       address.Ethereum,
+		NetworkPolygon:   address.Ethereum,
+		NetworkArbitrum:  address.Ethereum,
+		NetworkOptimism:  address.Ethereum,
+		NetworkAvalanche: address.Ethereum,
+		NetworkTron:      address.Tron,
+		NetworkSolana:    address.Solana,
+		NetworkAptos:     address.Aptos,
+	},
+	extraIDs: map[string]func(string) error{
+		NetworkRipple:  address.DestinationTag,
+		NetworkStellar: address.Memo(28),
+		NetworkEOS:     address.Memo(256),
+		NetworkCosmos:  address.Memo(256),
+		NetworkHedera:  address.Memo(100),
+		NetworkTON:     address.Memo(120),
+	},
+}
+
+// RegisterAddressValidator sets the validator of the addresses of the
+// canonical network, replacing the validator of the address package. The
+// validator returns an error wrapping address.ErrInvalid for the invalid
+// addresses.
+func RegisterAddressValidator(network string, validate func(address string) error) {
+	validators.Lock()
+	defer validators.Unlock()
+	validators.addresses[network] = validate
+}
+
+// RegisterExtraIDValidator declares that the addresses of the canonical
+// network take an ExtraID, validated by validate.
+func RegisterExtraIDValidator(network string, validate func(extraID string) error) {
+	validators.Lock()
+	defer validators.Unlock()
+	validators.extraIDs[network] = validate
+}
+
+// orderNetwork returns the canonical network of symbol on network, the
+// network of the coin if network is empty.
+func orderNetwork(symbol, network string) string {
+	if network != "" {
+		return CanonicalNetwork(symbol, network)
+	}
+	native, _ := NativeNetwork(symbol)
+	return native
+}
+
+// ValidateAddress checks that addr is an address of symbol on network, or
+// of the network of the coin if network is empty. The addresses of the
+// networks without a validator are not checked.
+func ValidateAddress(symbol, network, addr string) error {
+	network = orderNetwork(symbol, network)
+	validators.RLock()
+	validate, ok := validators.addresses[network]
+	validators.RUnlock()
+	if !ok {
+		return nil
+	}
+	if err := validate(addr); err != nil {
+		e := NewError("", KindInvalidAddress, "", CanonicalSymbol(symbol)+" address "+addr+": "+err.Error())
+		e.Err = err
+		return e
+	}
+	return nil
+}
+
+// UsesExtraID reports whether the addresses of symbol on network take an
+// ExtraID, a memo or destination tag. The deposits to the shared addresses
+// of exchanges and custodial wallets are credited by their ExtraID, an
+// order sending to such an address without it loses the funds.
+func UsesExtraID(symbol, network string) bool {
+	network = orderNetwork(symbol, network)
+	validators.RLock()
+	defer validators.RUnlock()
+	_, ok := validators.extraIDs[network]
+	return ok
+}
+
+// validateExtraID checks extraID, which is only accepted by the networks
+// taking an ExtraID.
+func validateExtraID(symbol, network, extraID string) error {
+	if extraID == "" {
+		return nil
+	}
+	network = orderNetwork(symbol, network)
+	validators.RLock()
+	validate, ok := validators.extraIDs[network]
+	_, known := validators.addresses[network]
+	validators.RUnlock()
+	var err error
+	switch {
+	case ok:
+		err = validate(extraID)
+	case known:
+		err = fmt.Errorf("%w: %s addresses take no extra id", address.ErrInvalid, network)
+	}
+	if err != nil {
+		e := NewError("", KindInvalidAddress, "", CanonicalSymbol(symbol)+" extra id "+extraID+": "+err.Error())
+		e.Err = err
+		return e
+	}
+	return nil
+}
+
+// ValidateOrder checks the destination and refund addresses of vars and
+// their ExtraIDs before the order is created. The registered exchanges call
+// it from CreateOrder, so that a mistyped address is rejected before it is
+// sent to the exchange.
+func ValidateOrder(vars CreateOrder) error {
+	if vars.Destination != "" {
+		if err := ValidateAddress(vars.ToCurrency, vars.ToNetwork, vars.Destination); err != nil {
+			return err
+		}
+	}
+	if vars.RefundAddress != "" {
+		if err := ValidateAddress(vars.FromCurrency, vars.FromNetwork, vars.RefundAddress); err != nil {
+			return err
+		}
+	}
+	if err := validateExtraID(vars.ToCurrency, vars.ToNetwork, vars.ExtraID); err != nil {
+		return err
+	}
+	return validateExtraID(vars.FromCurrency, vars.FromNetwork, vars.RefundExtraID)
+}
+
+// checkExtraID returns ErrNotSupported if the ExtraID of vars would not be
+// sent by the exchange name, which declared its capabilities.
+func checkExtraID(name string, vars CreateOrder) error {
+	capabilities, ok := ExchangeCapabilities(name)
+	if !ok || capabilities.ExtraID || vars.ExtraID == "" {
+		return nil
+	}
+	return fmt.Errorf("%w: extra id", ErrNotSupported)
+}
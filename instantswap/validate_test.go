@@ -0,0 +1,77 @@
+package instantswap
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+const (
+	testBTCAddress = "bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t4"
+	testDCRAddress = "DsUZxxoHJSty8DCfwfartwTYbuhmVct7tJu"
+	testETHAddress = "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed"
+)
+
+func TestValidateOrder(t *testing.T) {
+	tests := []struct {
+		name  string
+		order CreateOrder
+		valid bool
+	}{
+		{"valid", CreateOrder{FromCurrency: "btc", ToCurrency: "DCR", RefundAddress: testBTCAddress, Destination: testDCRAddress}, true},
+		{"no refund", CreateOrder{FromCurrency: "BTC", ToCurrency: "DCR", Destination: testDCRAddress}, true},
+		{"swapped", CreateOrder{FromCurrency: "BTC", ToCurrency: "DCR", RefundAddress: testDCRAddress, Destination: testBTCAddress}, false},
+		{"token", CreateOrder{FromCurrency: "BTC", ToCurrency: "USDT", ToNetwork: "ERC20", Destination: testETHAddress}, true},
+		{"token on tron", CreateOrder{FromCurrency: "BTC", ToCurrency: "USDT", ToNetwork: "TRC20", Destination: testETHAddress}, false},
+		// The network of USDT is unknown, the address is not checked.
+		{"token without network", CreateOrder{FromCurrency: "BTC", ToCurrency: "USDT", Destination: "anything"}, true},
+		{"unknown currency", CreateOrder{FromCurrency: "BTC", ToCurrency: "XYZ", Destination: "anything"}, true},
+		{"destination tag", CreateOrder{FromCurrency: "BTC", ToCurrency: "XRP", Destination: "rAddress", ExtraID: "12345"}, true},
+		{"bad destination tag", CreateOrder{FromCurrency: "BTC", ToCurrency: "XRP", Destination: "rAddress", ExtraID: "tag"}, false},
+		{"extra id without memo", CreateOrder{FromCurrency: "BTC", ToCurrency: "DCR", Destination: testDCRAddress, ExtraID: "1"}, false},
+	}
+	for _, test := range tests {
+		err := ValidateOrder(test.order)
+		if test.valid && err != nil {
+			t.Errorf("%s: %v", test.name, err)
+		}
+		if !test.valid && (!errors.Is(err, ErrInvalidAddress) || ErrorKindOf(err) != KindInvalidAddress) {
+			t.Errorf("%s: expected an invalid address error, got %v", test.name, err)
+		}
+	}
+}
+
+func TestUsesExtraID(t *testing.T) {
+	if !UsesExtraID("XRP", "") || !UsesExtraID("xlm", "mainnet") || UsesExtraID("BTC", "") || UsesExtraID("USDT", "ERC20") {
+		t.Error("unexpected memo networks")
+	}
+}
+
+type orderExchange struct {
+	IDExchangeCtx
+	orders []CreateOrder
+}
+
+func (o *orderExchange) CreateOrder(ctx context.Context, vars CreateOrder) (CreateResultInfo, error) {
+	o.orders = append(o.orders, vars)
+	return CreateResultInfo{Destination: vars.Destination}, nil
+}
+
+func TestCreateOrderValidation(t *testing.T) {
+	RegisterCapabilities("validate-test", Capabilities{})
+	exchange := &orderExchange{}
+	e := &errorExchange{name: "validate-test", exchange: exchange}
+	ctx := context.Background()
+
+	_, err := e.CreateOrder(ctx, CreateOrder{FromCurrency: "BTC", ToCurrency: "DCR", Destination: testDCRAddress[1:]})
+	if !errors.Is(err, ErrInvalidAddress) || len(exchange.orders) != 0 {
+		t.Errorf("invalid destination: got %v after %d orders", err, len(exchange.orders))
+	}
+	_, err = e.CreateOrder(ctx, CreateOrder{FromCurrency: "BTC", ToCurrency: "XRP", Destination: "rAddress", ExtraID: "1"})
+	if !errors.Is(err, ErrNotSupported) || len(exchange.orders) != 0 {
+		t.Errorf("extra id: got %v after %d orders", err, len(exchange.orders))
+	}
+	if _, err = e.CreateOrder(ctx, CreateOrder{FromCurrency: "BTC", ToCurrency: "DCR", Destination: testDCRAddress}); err != nil || len(exchange.orders) != 1 {
+		t.Errorf("valid order: got %v after %d orders", err, len(exchange.orders))
+	}
+}